@@ -0,0 +1,59 @@
+// Copyright (c) 2020 - for information on the respective copyright owner
+// see the NOTICE file and/or the repository at
+// https://github.com/hyperledger-labs/perun-node
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package admin
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/pkg/errors"
+
+	"github.com/hyperledger-labs/perun-node/comm/tcp"
+)
+
+// AccessList is a set of rules for accepting incoming off-chain connections, that can be updated at runtime.
+type AccessList interface {
+	Rules() tcp.AccessRules
+	SetRules(tcp.AccessRules) error
+}
+
+// AccessHandler returns a handler for the access rules of incoming off-chain connections. The response
+// for each request is the current rules as a JSON object.
+//
+// GET returns the rules. PUT replaces the rules with the ones given as a JSON object in the request body.
+// The rules apply to the connections accepted afterwards.
+func AccessHandler(a AccessList) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+		case http.MethodPut:
+			var rules tcp.AccessRules
+			if err := json.NewDecoder(r.Body).Decode(&rules); err != nil {
+				writeError(w, http.StatusBadRequest, errors.Wrap(err, "decoding request"))
+				return
+			}
+			if err := a.SetRules(rules); err != nil {
+				writeError(w, http.StatusBadRequest, err)
+				return
+			}
+		default:
+			writeError(w, http.StatusMethodNotAllowed, errors.New("method not allowed"))
+			return
+		}
+		writeJSON(w, http.StatusOK, a.Rules())
+	})
+}
@@ -0,0 +1,77 @@
+// Copyright (c) 2020 - for information on the respective copyright owner
+// see the NOTICE file and/or the repository at
+// https://github.com/hyperledger-labs/perun-node
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package admin_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"perun.network/go-perun/pkg/test"
+	"perun.network/go-perun/wallet"
+
+	"github.com/hyperledger-labs/perun-node/admin"
+	"github.com/hyperledger-labs/perun-node/blockchain/ethereum/ethereumtest"
+	"github.com/hyperledger-labs/perun-node/comm/tcp"
+)
+
+func Test_AccessHandler(t *testing.T) {
+	rng := test.Prng(t)
+	parseAddr := func(s string) (wallet.Address, error) {
+		if s == "invalid" {
+			return nil, errors.New("invalid address")
+		}
+		return ethereumtest.NewRandomAddress(rng), nil
+	}
+	newHandler := func(t *testing.T) http.Handler {
+		a, err := tcp.NewAccessList(tcp.AccessRules{DenyCIDRs: []string{"10.0.0.0/8"}}, parseAddr)
+		require.NoError(t, err)
+		return admin.AccessHandler(a)
+	}
+
+	t.Run("happy_get", func(t *testing.T) {
+		rec := serve(newHandler(t), http.MethodGet, "")
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, `{"allowCIDRs": null, "denyCIDRs": ["10.0.0.0/8"], "allowPeers": null, "denyPeers": null}`,
+			rec.Body.String())
+	})
+
+	t.Run("happy_put", func(t *testing.T) {
+		rec := serve(newHandler(t), http.MethodPut, `{"allowCIDRs": ["192.168.0.0/16"], "denyPeers": ["peer"]}`)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, `{"allowCIDRs": ["192.168.0.0/16"], "denyCIDRs": null, "allowPeers": null,
+			"denyPeers": ["peer"]}`, rec.Body.String())
+	})
+
+	t.Run("err_invalid_rules", func(t *testing.T) {
+		handler := newHandler(t)
+		rec := serve(handler, http.MethodPut, `{"denyCIDRs": ["10.0.0.0"]}`)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		rec = serve(handler, http.MethodPut, `{"denyPeers": ["invalid"]}`)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+		rec = serve(handler, http.MethodGet, "")
+		assert.Contains(t, rec.Body.String(), "10.0.0.0/8", "rules should be retained on error")
+	})
+
+	t.Run("err_method", func(t *testing.T) {
+		rec := serve(newHandler(t), http.MethodDelete, "")
+		assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+	})
+}
@@ -0,0 +1,59 @@
+// Copyright (c) 2020 - for information on the respective copyright owner
+// see the NOTICE file and/or the repository at
+// https://github.com/hyperledger-labs/perun-node
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package admin
+
+import (
+	"net/http"
+
+	"github.com/pkg/errors"
+
+	"github.com/hyperledger-labs/perun-node/payment"
+)
+
+// PaymentApprover approves payments that exceed the spending limits.
+type PaymentApprover interface {
+	Approvals() []payment.Approval
+	Approve(id string) error
+	Reject(id string) error
+}
+
+// ApprovalsHandler returns a handler for approving payments that exceed the spending limits. The response for
+// each request is the list of approvals.
+//
+// GET returns the approvals. PUT approves and DELETE rejects the payment for the approval given as "id" in
+// the query parameters.
+func ApprovalsHandler(approver PaymentApprover) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var err error
+		id := r.URL.Query().Get("id")
+		switch r.Method {
+		case http.MethodGet:
+		case http.MethodPut:
+			err = approver.Approve(id)
+		case http.MethodDelete:
+			err = approver.Reject(id)
+		default:
+			writeError(w, http.StatusMethodNotAllowed, errors.New("method not allowed"))
+			return
+		}
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, approver.Approvals())
+	})
+}
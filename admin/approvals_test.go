@@ -0,0 +1,88 @@
+// Copyright (c) 2020 - for information on the respective copyright owner
+// see the NOTICE file and/or the repository at
+// https://github.com/hyperledger-labs/perun-node
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package admin_test
+
+import (
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger-labs/perun-node/admin"
+	"github.com/hyperledger-labs/perun-node/clock"
+	"github.com/hyperledger-labs/perun-node/payment"
+)
+
+func Test_ApprovalsHandler(t *testing.T) {
+	setup := func(t *testing.T) (http.Handler, string) {
+		clk, err := clock.New("UTC")
+		require.NoError(t, err)
+		budget, err := payment.NewBudget(clk, []payment.Limit{
+			{Period: payment.Daily, Amount: big.NewInt(1), RequireApproval: true},
+		}, nil)
+		require.NoError(t, err)
+		var approvalErr payment.ErrApprovalRequired
+		require.True(t, errors.As(budget.Spend("bob", "sub", big.NewInt(2)), &approvalErr))
+		return admin.ApprovalsHandler(budget), approvalErr.ID
+	}
+	do := func(handler http.Handler, method, target string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(method, target, nil))
+		return rec
+	}
+	decode := func(t *testing.T, rec *httptest.ResponseRecorder) []payment.Approval {
+		var approvals []payment.Approval
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &approvals))
+		return approvals
+	}
+
+	t.Run("happy_get_approve", func(t *testing.T) {
+		handler, id := setup(t)
+		rec := do(handler, http.MethodGet, "/")
+		require.Equal(t, http.StatusOK, rec.Code)
+		approvals := decode(t, rec)
+		require.Len(t, approvals, 1)
+		assert.Equal(t, id, approvals[0].ID)
+		assert.False(t, approvals[0].Approved)
+
+		rec = do(handler, http.MethodPut, "/?id="+id)
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.True(t, decode(t, rec)[0].Approved)
+	})
+
+	t.Run("happy_reject", func(t *testing.T) {
+		handler, id := setup(t)
+		rec := do(handler, http.MethodDelete, "/?id="+id)
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.Empty(t, decode(t, rec))
+	})
+
+	t.Run("err_unknown_approval", func(t *testing.T) {
+		handler, _ := setup(t)
+		assert.Equal(t, http.StatusBadRequest, do(handler, http.MethodPut, "/?id=unknown").Code)
+	})
+
+	t.Run("err_method", func(t *testing.T) {
+		handler, _ := setup(t)
+		assert.Equal(t, http.StatusMethodNotAllowed, do(handler, http.MethodPost, "/").Code)
+	})
+}
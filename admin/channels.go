@@ -0,0 +1,68 @@
+// Copyright (c) 2020 - for information on the respective copyright owner
+// see the NOTICE file and/or the repository at
+// https://github.com/hyperledger-labs/perun-node
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package admin
+
+import (
+	"context"
+	"encoding/hex"
+	"net/http"
+	"sort"
+
+	"github.com/pkg/errors"
+	"perun.network/go-perun/channel"
+)
+
+// ChannelCloseResult is the result of closing a channel. Channel is the channel ID as hex string and Error is
+// empty if the channel was closed successfully.
+type ChannelCloseResult struct {
+	Channel string `json:"channel"`
+	Error   string `json:"error,omitempty"`
+}
+
+// CloseAllHandler returns a handler for closing (POST) all the open channels of the node.
+//
+// The response is sent once all the channels have been closed or have failed to close, which can take as long
+// as the challenge duration when the peers do not respond. It contains the ChannelCloseResult for each channel.
+func CloseAllHandler(closeAll func(context.Context) map[channel.ID]error) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			writeError(w, http.StatusMethodNotAllowed, errors.New("method not allowed"))
+			return
+		}
+		results := make([]ChannelCloseResult, 0)
+		for id, err := range closeAll(r.Context()) {
+			result := ChannelCloseResult{Channel: hex.EncodeToString(id[:])}
+			if err != nil {
+				result.Error = err.Error()
+			}
+			results = append(results, result)
+		}
+		sort.Slice(results, func(i, j int) bool { return results[i].Channel < results[j].Channel })
+		writeJSON(w, http.StatusOK, results)
+	})
+}
+
+// parseChannelID parses the channel ID given as hex string.
+func parseChannelID(s string) (channel.ID, error) {
+	var id channel.ID
+	b, err := hex.DecodeString(s)
+	if err != nil || len(b) != len(id) {
+		return channel.ID{}, errors.Errorf("invalid channel id %q", s)
+	}
+	copy(id[:], b)
+	return id, nil
+}
@@ -0,0 +1,59 @@
+// Copyright (c) 2020 - for information on the respective copyright owner
+// see the NOTICE file and/or the repository at
+// https://github.com/hyperledger-labs/perun-node
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package admin_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"perun.network/go-perun/channel"
+
+	"github.com/hyperledger-labs/perun-node/admin"
+)
+
+func Test_CloseAllHandler(t *testing.T) {
+	closeAll := func(context.Context) map[channel.ID]error {
+		return map[channel.ID]error{{2}: assert.AnError, {1}: nil}
+	}
+
+	t.Run("happy", func(t *testing.T) {
+		rec := serve(admin.CloseAllHandler(closeAll), http.MethodPost, "")
+		require.Equal(t, http.StatusOK, rec.Code)
+		var results []admin.ChannelCloseResult
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &results))
+		require.Len(t, results, 2)
+		assert.Equal(t, admin.ChannelCloseResult{Channel: "01" + strings.Repeat("00", 31)}, results[0])
+		assert.Equal(t, "02"+strings.Repeat("00", 31), results[1].Channel)
+		assert.Equal(t, assert.AnError.Error(), results[1].Error)
+	})
+
+	t.Run("happy_no_channels", func(t *testing.T) {
+		handler := admin.CloseAllHandler(func(context.Context) map[channel.ID]error { return nil })
+		rec := serve(handler, http.MethodPost, "")
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, "[]", rec.Body.String())
+	})
+
+	t.Run("err_method", func(t *testing.T) {
+		assert.Equal(t, http.StatusMethodNotAllowed, serve(admin.CloseAllHandler(closeAll), http.MethodGet, "").Code)
+	})
+}
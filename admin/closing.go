@@ -0,0 +1,67 @@
+// Copyright (c) 2020 - for information on the respective copyright owner
+// see the NOTICE file and/or the repository at
+// https://github.com/hyperledger-labs/perun-node
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package admin
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/pkg/errors"
+	"perun.network/go-perun/channel"
+
+	"github.com/hyperledger-labs/perun-node/client"
+)
+
+// ClosingModes manages the closing modes of the open channels.
+type ClosingModes interface {
+	ClosingModes() []client.ChannelClosingMode
+	SetClosingMode(channel.ID, client.ClosingMode) error
+}
+
+// ClosingModesHandler returns a handler for the closing modes of the open channels. The response for each request
+// is the list of closing modes of all open channels.
+//
+// GET returns the closing modes. PUT sets the closing mode of the channel given as client.ChannelClosingMode in
+// the request body, overriding the default mode in the config. It is retained across restarts.
+func ClosingModesHandler(modes ClosingModes) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+		case http.MethodPut:
+			if err := setClosingMode(modes, r); err != nil {
+				writeError(w, http.StatusBadRequest, err)
+				return
+			}
+		default:
+			writeError(w, http.StatusMethodNotAllowed, errors.New("method not allowed"))
+			return
+		}
+		writeJSON(w, http.StatusOK, modes.ClosingModes())
+	})
+}
+
+func setClosingMode(modes ClosingModes, r *http.Request) error {
+	var req client.ChannelClosingMode
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return errors.Wrap(err, "decoding request")
+	}
+	id, err := parseChannelID(req.Channel)
+	if err != nil {
+		return err
+	}
+	return modes.SetClosingMode(id, req.Mode)
+}
@@ -0,0 +1,90 @@
+// Copyright (c) 2020 - for information on the respective copyright owner
+// see the NOTICE file and/or the repository at
+// https://github.com/hyperledger-labs/perun-node
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package admin_test
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"perun.network/go-perun/channel"
+
+	"github.com/hyperledger-labs/perun-node/admin"
+	"github.com/hyperledger-labs/perun-node/client"
+)
+
+type fakeClosingModes struct {
+	modes map[channel.ID]client.ClosingMode
+}
+
+func (f *fakeClosingModes) ClosingModes() []client.ChannelClosingMode {
+	modes := make([]client.ChannelClosingMode, 0, len(f.modes))
+	for id, mode := range f.modes {
+		id := id
+		modes = append(modes, client.ChannelClosingMode{Channel: hex.EncodeToString(id[:]), Mode: mode})
+	}
+	return modes
+}
+
+func (f *fakeClosingModes) SetClosingMode(id channel.ID, mode client.ClosingMode) error {
+	if _, ok := f.modes[id]; !ok {
+		return client.ErrChannelNotFound
+	}
+	parsed, err := client.ParseClosingMode(string(mode))
+	f.modes[id] = parsed
+	return err
+}
+
+func Test_ClosingModesHandler(t *testing.T) {
+	id := "01" + strings.Repeat("00", 31)
+	newModes := func() *fakeClosingModes {
+		return &fakeClosingModes{modes: map[channel.ID]client.ClosingMode{{1}: client.ClosingModeManual}}
+	}
+
+	t.Run("happy_get", func(t *testing.T) {
+		rec := serve(admin.ClosingModesHandler(newModes()), http.MethodGet, "")
+		require.Equal(t, http.StatusOK, rec.Code)
+		var modes []client.ChannelClosingMode
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &modes))
+		assert.Equal(t, []client.ChannelClosingMode{{Channel: id, Mode: client.ClosingModeManual}}, modes)
+	})
+	t.Run("happy_put", func(t *testing.T) {
+		modes := newModes()
+		rec := serve(admin.ClosingModesHandler(modes), http.MethodPut, `{"channel":"`+id+`","mode":"auto"}`)
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, client.ClosingModeAuto, modes.modes[channel.ID{1}])
+	})
+	t.Run("err_put", func(t *testing.T) {
+		for _, body := range []string{
+			`{"channel":"01","mode":"auto"}`,
+			`{"channel":"` + id + `","mode":"immediate"}`,
+			`{"channel":"02` + strings.Repeat("00", 31) + `","mode":"auto"}`,
+			`invalid`,
+		} {
+			rec := serve(admin.ClosingModesHandler(newModes()), http.MethodPut, body)
+			assert.Equal(t, http.StatusBadRequest, rec.Code, body)
+		}
+	})
+	t.Run("err_method", func(t *testing.T) {
+		rec := serve(admin.ClosingModesHandler(newModes()), http.MethodDelete, "")
+		assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+	})
+}
@@ -0,0 +1,64 @@
+// Copyright (c) 2020 - for information on the respective copyright owner
+// see the NOTICE file and/or the repository at
+// https://github.com/hyperledger-labs/perun-node
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package admin_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/hyperledger-labs/perun-node/admin"
+)
+
+func Test_ConfigHandler(t *testing.T) {
+	t.Run("happy", func(t *testing.T) {
+		handler := admin.ConfigHandler(func() (interface{}, error) {
+			return map[string]interface{}{"loglevel": "debug"}, nil
+		})
+		rec := serve(handler, http.MethodGet, "")
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, `{"loglevel": "debug"}`, rec.Body.String())
+	})
+
+	t.Run("err_method_not_allowed", func(t *testing.T) {
+		handler := admin.ConfigHandler(func() (interface{}, error) { return nil, nil })
+		rec := serve(handler, http.MethodPut, "{}")
+		assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+	})
+
+	t.Run("err_config", func(t *testing.T) {
+		handler := admin.ConfigHandler(func() (interface{}, error) { return nil, errors.New("error") })
+		rec := serve(handler, http.MethodGet, "")
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	})
+}
+
+func Test_StatsHandler(t *testing.T) {
+	handler := admin.StatsHandler(func() interface{} { return map[string]int{"accepted": 1} })
+
+	t.Run("happy", func(t *testing.T) {
+		rec := serve(handler, http.MethodGet, "")
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, `{"accepted": 1}`, rec.Body.String())
+	})
+
+	t.Run("err_method_not_allowed", func(t *testing.T) {
+		assert.Equal(t, http.StatusMethodNotAllowed, serve(handler, http.MethodPost, "").Code)
+	})
+}
@@ -0,0 +1,28 @@
+// Copyright (c) 2020 - for information on the respective copyright owner
+// see the NOTICE file and/or the repository at
+// https://github.com/hyperledger-labs/perun-node
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package admin implements an HTTP API for administering a running node.
+//
+// The API is intended for the operators of the node and does not implement
+// authentication. So, the server listens only on a loopback address. To protect
+// it from web pages open in a browser on the same host, requests are rejected if
+// the Host header is not a loopback name or address, or if the Origin header (if
+// any) is not a loopback origin. POST requests should have the content type
+// application/json.
+//
+// Requests and responses use JSON encoding. Errors are returned with an
+// appropriate status code and a JSON object with an "error" field.
+package admin
@@ -0,0 +1,67 @@
+// Copyright (c) 2020 - for information on the respective copyright owner
+// see the NOTICE file and/or the repository at
+// https://github.com/hyperledger-labs/perun-node
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package admin
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/pkg/errors"
+)
+
+// FeatureFlags is a set of feature flags that can be overridden at runtime.
+type FeatureFlags interface {
+	All() map[string]bool
+	Override(name string, enabled bool) error
+	Reset(name string)
+}
+
+// FeaturesHandler returns a handler for the feature flags. The response for each request is the state
+// of all features as a JSON object mapping the name of each feature to its state.
+//
+// GET returns the state of features. PUT overrides the state of features given as a JSON object in the
+// request body. DELETE resets the override for the feature given as "name" in the query parameters.
+func FeaturesHandler(f FeatureFlags) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+		case http.MethodPut:
+			var overrides map[string]bool
+			if err := json.NewDecoder(r.Body).Decode(&overrides); err != nil {
+				writeError(w, http.StatusBadRequest, errors.Wrap(err, "decoding request"))
+				return
+			}
+			for name, enabled := range overrides {
+				if err := f.Override(name, enabled); err != nil {
+					writeError(w, http.StatusBadRequest, err)
+					return
+				}
+			}
+		case http.MethodDelete:
+			name := r.URL.Query().Get("name")
+			if name == "" {
+				writeError(w, http.StatusBadRequest, errors.New("name of the feature should be specified"))
+				return
+			}
+			f.Reset(name)
+		default:
+			writeError(w, http.StatusMethodNotAllowed, errors.New("method not allowed"))
+			return
+		}
+		writeJSON(w, http.StatusOK, f.All())
+	})
+}
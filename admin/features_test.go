@@ -0,0 +1,73 @@
+// Copyright (c) 2020 - for information on the respective copyright owner
+// see the NOTICE file and/or the repository at
+// https://github.com/hyperledger-labs/perun-node
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package admin_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger-labs/perun-node/admin"
+	"github.com/hyperledger-labs/perun-node/features"
+)
+
+func Test_FeaturesHandler(t *testing.T) {
+	newHandler := func(t *testing.T) http.Handler {
+		f, err := features.New([]string{features.Compression})
+		require.NoError(t, err)
+		return admin.FeaturesHandler(f)
+	}
+
+	t.Run("happy_get", func(t *testing.T) {
+		rec := serve(newHandler(t), http.MethodGet, "")
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, `{"compression": true, "pipelined-updates": false, "virtual-channels": false}`,
+			rec.Body.String())
+	})
+
+	t.Run("happy_put_delete", func(t *testing.T) {
+		handler := newHandler(t)
+		rec := serve(handler, http.MethodPut, `{"compression": false, "virtual-channels": true}`)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, `{"compression": false, "pipelined-updates": false, "virtual-channels": true}`,
+			rec.Body.String())
+
+		rec = httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, "/?name=compression", nil))
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, `{"compression": true, "pipelined-updates": false, "virtual-channels": true}`,
+			rec.Body.String())
+	})
+
+	t.Run("err_unknown_feature", func(t *testing.T) {
+		rec := serve(newHandler(t), http.MethodPut, `{"unknown": true}`)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("err_delete_without_name", func(t *testing.T) {
+		rec := serve(newHandler(t), http.MethodDelete, "")
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("err_invalid_body", func(t *testing.T) {
+		rec := serve(newHandler(t), http.MethodPut, `invalid`)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+}
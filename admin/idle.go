@@ -0,0 +1,111 @@
+// Copyright (c) 2020 - for information on the respective copyright owner
+// see the NOTICE file and/or the repository at
+// https://github.com/hyperledger-labs/perun-node
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package admin
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/pkg/errors"
+	"perun.network/go-perun/channel"
+
+	"github.com/hyperledger-labs/perun-node/client"
+)
+
+// IdleChannels manages the idle policy of the open channels.
+type IdleChannels interface {
+	IdleChannels() []client.IdleChannel
+	SetIdlePolicy(channel.ID, client.IdlePolicy) error
+}
+
+// IdlePolicyRequest is the request body for setting the idle policy of a channel. Channel is the channel ID as
+// hex string, Timeout and WarnBefore are duration strings such as "72h". Zero timeout means the channel is not
+// closed when idle.
+type IdlePolicyRequest struct {
+	Channel    string `json:"channel"`
+	Timeout    string `json:"timeout"`
+	WarnBefore string `json:"warnBefore"`
+}
+
+// IdleChannelStatus is the idle status of an open channel. Deadline is the time at which the channel is closed
+// if not updated, it is omitted if the channel is not closed when idle.
+type IdleChannelStatus struct {
+	Channel    string     `json:"channel"`
+	LastUpdate time.Time  `json:"lastUpdate"`
+	Timeout    string     `json:"timeout"`
+	WarnBefore string     `json:"warnBefore"`
+	Deadline   *time.Time `json:"deadline,omitempty"`
+}
+
+// IdleHandler returns a handler for the idle policy of the open channels. The response for each request is the
+// list of IdleChannelStatus for all open channels.
+//
+// GET returns the idle status of the channels. PUT sets the idle policy of the channel given as
+// IdlePolicyRequest in the request body, overriding the default policy in the config.
+func IdleHandler(idle IdleChannels) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+		case http.MethodPut:
+			if err := setIdlePolicy(idle, r); err != nil {
+				writeError(w, http.StatusBadRequest, err)
+				return
+			}
+		default:
+			writeError(w, http.StatusMethodNotAllowed, errors.New("method not allowed"))
+			return
+		}
+		chs := idle.IdleChannels()
+		statuses := make([]IdleChannelStatus, len(chs))
+		for i, ch := range chs {
+			statuses[i] = IdleChannelStatus{
+				Channel:    hex.EncodeToString(ch.Channel[:]),
+				LastUpdate: ch.LastUpdate,
+				Timeout:    ch.Policy.Timeout.String(),
+				WarnBefore: ch.Policy.WarnBefore.String(),
+			}
+			if !ch.Deadline.IsZero() {
+				deadline := ch.Deadline
+				statuses[i].Deadline = &deadline
+			}
+		}
+		writeJSON(w, http.StatusOK, statuses)
+	})
+}
+
+func setIdlePolicy(idle IdleChannels, r *http.Request) error {
+	var req IdlePolicyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return errors.Wrap(err, "decoding request")
+	}
+	id, err := parseChannelID(req.Channel)
+	if err != nil {
+		return err
+	}
+	var policy client.IdlePolicy
+	if policy.Timeout, err = time.ParseDuration(req.Timeout); err != nil {
+		return errors.Wrap(err, "parsing timeout")
+	}
+	if req.WarnBefore != "" {
+		if policy.WarnBefore, err = time.ParseDuration(req.WarnBefore); err != nil {
+			return errors.Wrap(err, "parsing warnBefore")
+		}
+	}
+	return idle.SetIdlePolicy(id, policy)
+}
@@ -0,0 +1,87 @@
+// Copyright (c) 2020 - for information on the respective copyright owner
+// see the NOTICE file and/or the repository at
+// https://github.com/hyperledger-labs/perun-node
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package admin_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"perun.network/go-perun/channel"
+
+	"github.com/hyperledger-labs/perun-node/admin"
+	"github.com/hyperledger-labs/perun-node/client"
+)
+
+type fakeIdleChannels struct {
+	chs []client.IdleChannel
+}
+
+func (f *fakeIdleChannels) IdleChannels() []client.IdleChannel { return f.chs }
+
+func (f *fakeIdleChannels) SetIdlePolicy(id channel.ID, policy client.IdlePolicy) error {
+	for i := range f.chs {
+		if f.chs[i].Channel == id {
+			f.chs[i].Policy = policy
+			return nil
+		}
+	}
+	return errors.New("channel not found")
+}
+
+func Test_IdleHandler(t *testing.T) {
+	lastUpdate := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	id := "01" + strings.Repeat("00", 31)
+	newIdle := func() *fakeIdleChannels {
+		return &fakeIdleChannels{chs: []client.IdleChannel{{Channel: channel.ID{1}, LastUpdate: lastUpdate}}}
+	}
+
+	t.Run("happy_get", func(t *testing.T) {
+		rec := serve(admin.IdleHandler(newIdle()), http.MethodGet, "")
+		require.Equal(t, http.StatusOK, rec.Code)
+		var statuses []admin.IdleChannelStatus
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &statuses))
+		require.Len(t, statuses, 1)
+		assert.Equal(t, id, statuses[0].Channel)
+		assert.Equal(t, "0s", statuses[0].Timeout)
+		assert.Nil(t, statuses[0].Deadline)
+	})
+	t.Run("happy_put", func(t *testing.T) {
+		idle := newIdle()
+		rec := serve(admin.IdleHandler(idle), http.MethodPut, `{"channel":"`+id+`","timeout":"72h","warnBefore":"1h"}`)
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, client.IdlePolicy{Timeout: 72 * time.Hour, WarnBefore: time.Hour}, idle.chs[0].Policy)
+	})
+	t.Run("err_put", func(t *testing.T) {
+		for _, body := range []string{
+			`{"channel":"01","timeout":"72h"}`,
+			`{"channel":"` + id + `","timeout":"72"}`,
+			`{"channel":"02` + strings.Repeat("00", 31) + `","timeout":"72h"}`,
+			`invalid`,
+		} {
+			assert.Equal(t, http.StatusBadRequest, serve(admin.IdleHandler(newIdle()), http.MethodPut, body).Code, body)
+		}
+	})
+	t.Run("err_method", func(t *testing.T) {
+		assert.Equal(t, http.StatusMethodNotAllowed, serve(admin.IdleHandler(newIdle()), http.MethodPost, "").Code)
+	})
+}
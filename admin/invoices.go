@@ -0,0 +1,156 @@
+// Copyright (c) 2020 - for information on the respective copyright owner
+// see the NOTICE file and/or the repository at
+// https://github.com/hyperledger-labs/perun-node
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"time"
+
+	"github.com/pkg/errors"
+	"perun.network/go-perun/channel"
+
+	"github.com/hyperledger-labs/perun-node/payment"
+)
+
+// DefaultInvoiceExpiry is the expiry used for invoices, when none is specified in the request.
+const DefaultInvoiceExpiry = time.Hour
+
+// InvoiceIssuer generates invoices for receiving payments.
+type InvoiceIssuer interface {
+	CreateInvoice(amount *big.Int, expiry time.Duration, memo string) (payment.InvoiceRecord, error)
+	CreateFiatInvoice(fiat payment.FiatAmount, expiry time.Duration, memo string) (payment.InvoiceRecord, error)
+	ListInvoices() []payment.InvoiceRecord
+	GetInvoice(id payment.InvoiceID) (payment.InvoiceRecord, bool)
+}
+
+// InvoiceRequest is the request body for creating an invoice. Amount is a decimal string in the smallest unit
+// of the asset, Fiat is an amount in fiat currency such as "5.00 EUR" (only one of them should be set) and
+// Expiry is a duration string such as "30m".
+type InvoiceRequest struct {
+	Amount string `json:"amount"`
+	Fiat   string `json:"fiat"`
+	Expiry string `json:"expiry"`
+	Memo   string `json:"memo"`
+}
+
+// InvoicesHandler returns a handler for invoices.
+//
+// GET returns the list of invoices or, if "invoiceId" is given in the query parameters, only the invoice with
+// that ID. POST creates an invoice for the InvoiceRequest in the request body and
+// returns it, the "encoded" field is to be shared with the payer.
+func InvoicesHandler(issuer InvoiceIssuer) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			getInvoices(w, r, issuer)
+		case http.MethodPost:
+			record, err := createInvoice(issuer, r)
+			if err != nil {
+				writeError(w, http.StatusBadRequest, err)
+				return
+			}
+			writeJSON(w, http.StatusCreated, record)
+		default:
+			writeError(w, http.StatusMethodNotAllowed, errors.New("method not allowed"))
+		}
+	})
+}
+
+func getInvoices(w http.ResponseWriter, r *http.Request, issuer InvoiceIssuer) {
+	idParam := r.URL.Query().Get("invoiceId")
+	if idParam == "" {
+		writeJSON(w, http.StatusOK, issuer.ListInvoices())
+		return
+	}
+	var id payment.InvoiceID
+	if err := id.UnmarshalText([]byte(idParam)); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	record, ok := issuer.GetInvoice(id)
+	if !ok {
+		writeError(w, http.StatusNotFound, errors.New("no invoice with the ID"))
+		return
+	}
+	writeJSON(w, http.StatusOK, record)
+}
+
+func createInvoice(issuer InvoiceIssuer, r *http.Request) (payment.InvoiceRecord, error) {
+	var req InvoiceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return payment.InvoiceRecord{}, errors.Wrap(err, "decoding request")
+	}
+	expiry := DefaultInvoiceExpiry
+	if req.Expiry != "" {
+		var err error
+		if expiry, err = time.ParseDuration(req.Expiry); err != nil {
+			return payment.InvoiceRecord{}, errors.Wrap(err, "parsing expiry")
+		}
+	}
+	switch {
+	case req.Fiat != "" && req.Amount != "":
+		return payment.InvoiceRecord{}, errors.New("only one of amount and fiat should be set")
+	case req.Fiat != "":
+		fiat, err := payment.ParseFiat(req.Fiat)
+		if err != nil {
+			return payment.InvoiceRecord{}, err
+		}
+		return issuer.CreateFiatInvoice(fiat, expiry, req.Memo)
+	}
+	amount, ok := new(big.Int).SetString(req.Amount, 10)
+	if !ok {
+		return payment.InvoiceRecord{}, errors.Errorf("invalid amount %q", req.Amount)
+	}
+	return issuer.CreateInvoice(amount, expiry, req.Memo)
+}
+
+// PayInvoiceRequest is the request body for paying an invoice. Channel is the ID (as hex string) of the channel
+// over which the invoice is paid and Invoice is the encoded invoice.
+type PayInvoiceRequest struct {
+	Channel string `json:"channel"`
+	Invoice string `json:"invoice"`
+}
+
+// PayInvoiceHandler returns a handler for paying (POST) the invoice given as PayInvoiceRequest in the request
+// body, using the pay function. The response is the paid invoice.
+func PayInvoiceHandler(pay func(context.Context, channel.ID, string) (payment.Invoice, error)) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			writeError(w, http.StatusMethodNotAllowed, errors.New("method not allowed"))
+			return
+		}
+		var req PayInvoiceRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, errors.Wrap(err, "decoding request"))
+			return
+		}
+		id, err := parseChannelID(req.Channel)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		inv, err := pay(r.Context(), id, req.Invoice)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, inv)
+	})
+}
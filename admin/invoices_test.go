@@ -0,0 +1,188 @@
+// Copyright (c) 2020 - for information on the respective copyright owner
+// see the NOTICE file and/or the repository at
+// https://github.com/hyperledger-labs/perun-node
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package admin_test
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"perun.network/go-perun/channel"
+
+	"github.com/hyperledger-labs/perun-node/admin"
+	"github.com/hyperledger-labs/perun-node/clock"
+	"github.com/hyperledger-labs/perun-node/payment"
+)
+
+type invoiceIssuer struct {
+	*payment.Invoices
+}
+
+func (i invoiceIssuer) CreateInvoice(amount *big.Int, expiry time.Duration, memo string) (
+	payment.InvoiceRecord, error) {
+	return i.Create(amount, "asset", expiry, memo)
+}
+
+func (i invoiceIssuer) CreateFiatInvoice(fiat payment.FiatAmount, expiry time.Duration, memo string) (
+	payment.InvoiceRecord, error) {
+	return i.CreateFiat(fiat, "asset", expiry, memo)
+}
+
+func (i invoiceIssuer) ListInvoices() []payment.InvoiceRecord {
+	return i.List()
+}
+
+func (i invoiceIssuer) GetInvoice(id payment.InvoiceID) (payment.InvoiceRecord, bool) {
+	return i.Get(id)
+}
+
+func Test_InvoicesHandler(t *testing.T) {
+	newHandler := func(t *testing.T) http.Handler {
+		clk, err := clock.New("UTC")
+		require.NoError(t, err)
+		return admin.InvoicesHandler(invoiceIssuer{payment.NewInvoices(clk, nil)})
+	}
+
+	t.Run("happy_post_get", func(t *testing.T) {
+		handler := newHandler(t)
+		rec := serve(handler, http.MethodPost, `{"amount": "1000", "memo": "coffee"}`)
+		require.Equal(t, http.StatusCreated, rec.Code)
+		var created payment.InvoiceRecord
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &created))
+		assert.Equal(t, payment.InvoiceOpen, created.Status)
+
+		inv, err := payment.DecodeInvoice(created.Encoded)
+		require.NoError(t, err)
+		assert.EqualValues(t, 1000, inv.Amount.Int64())
+		assert.Equal(t, "coffee", inv.Memo)
+		assert.WithinDuration(t, time.Now().Add(admin.DefaultInvoiceExpiry), inv.Expiry, time.Minute)
+
+		rec = serve(handler, http.MethodGet, "")
+		assert.Equal(t, http.StatusOK, rec.Code)
+		var list []payment.InvoiceRecord
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &list))
+		require.Len(t, list, 1)
+		assert.Equal(t, created.Encoded, list[0].Encoded)
+
+		id, err := created.ID.MarshalText()
+		require.NoError(t, err)
+		rec = httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/?invoiceId="+string(id), nil))
+		assert.Equal(t, http.StatusOK, rec.Code)
+		var got payment.InvoiceRecord
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &got))
+		assert.Equal(t, created.Encoded, got.Encoded)
+	})
+
+	t.Run("err_get_unknown_payment_hash", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		newHandler(t).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/?invoiceId="+strings.Repeat("00", 32), nil))
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+
+	t.Run("err_fiat_oracle_not_configured", func(t *testing.T) {
+		rec := serve(newHandler(t), http.MethodPost, `{"fiat": "5 EUR"}`)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("err_amount_and_fiat", func(t *testing.T) {
+		rec := serve(newHandler(t), http.MethodPost, `{"amount": "1", "fiat": "5 EUR"}`)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("err_invalid_amount", func(t *testing.T) {
+		rec := serve(newHandler(t), http.MethodPost, `{"amount": "1e3"}`)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("err_invalid_expiry", func(t *testing.T) {
+		rec := serve(newHandler(t), http.MethodPost, `{"amount": "1", "expiry": "tomorrow"}`)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("err_method", func(t *testing.T) {
+		rec := serve(newHandler(t), http.MethodDelete, "")
+		assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+	})
+}
+
+func Test_PayInvoiceHandler(t *testing.T) {
+	setup := func(t *testing.T) (*paymentChannel, *payment.Invoices, http.Handler) {
+		clk, err := clock.New("UTC")
+		require.NoError(t, err)
+		ch := newPaymentChannel(t, 10)
+		pay := func(ctx context.Context, id channel.ID, encoded string) (payment.Invoice, error) {
+			inv, err := payment.DecodeInvoice(encoded)
+			if err != nil {
+				return payment.Invoice{}, err
+			}
+			paying, err := ch.lookup(id)
+			if err != nil {
+				return payment.Invoice{}, err
+			}
+			_, err = payment.PayInvoice(ctx, paying, inv, nil, nil)
+			return inv, err
+		}
+		return ch, payment.NewInvoices(clk, nil), admin.PayInvoiceHandler(pay)
+	}
+
+	t.Run("happy_pay_and_settle", func(t *testing.T) {
+		ch, payee, handler := setup(t)
+		record, err := payee.Create(big.NewInt(3), payment.Asset(ch.State()), time.Minute, "coffee")
+		require.NoError(t, err)
+
+		rec := serve(handler, http.MethodPost, `{"channel": "`+ch.id()+`", "invoice": "`+record.Encoded+`"}`)
+		require.Equal(t, http.StatusOK, rec.Code)
+		var paid payment.Invoice
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &paid))
+		assert.Equal(t, "coffee", paid.Memo)
+		assert.EqualValues(t, 3, ch.received())
+
+		settled, ok := payee.Settle(context.Background(),
+			payment.Received{Channel: ch.state.ID, Asset: payment.Asset(ch.State()), Amount: big.NewInt(ch.received())})
+		require.True(t, ok)
+		assert.Equal(t, payment.InvoiceSettled, settled.Status)
+		assert.Equal(t, record.ID, settled.ID)
+	})
+
+	t.Run("err_invalid_request", func(t *testing.T) {
+		ch, payee, handler := setup(t)
+		record, err := payee.Create(big.NewInt(30), payment.Asset(ch.State()), time.Minute, "")
+		require.NoError(t, err)
+		for _, body := range []string{
+			`invalid`,
+			`{"channel": "invalid", "invoice": "` + record.Encoded + `"}`,
+			`{"channel": "` + ch.id() + `", "invoice": "invalid"}`,
+			`{"channel": "` + ch.id() + `", "invoice": "` + record.Encoded + `"}`, // Insufficient balance.
+		} {
+			assert.Equal(t, http.StatusBadRequest, serve(handler, http.MethodPost, body).Code, body)
+		}
+		assert.EqualValues(t, 0, ch.received())
+	})
+
+	t.Run("err_method", func(t *testing.T) {
+		_, _, handler := setup(t)
+		assert.Equal(t, http.StatusMethodNotAllowed, serve(handler, http.MethodGet, "").Code)
+	})
+}
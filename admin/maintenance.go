@@ -0,0 +1,55 @@
+// Copyright (c) 2020 - for information on the respective copyright owner
+// see the NOTICE file and/or the repository at
+// https://github.com/hyperledger-labs/perun-node
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package admin
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/pkg/errors"
+)
+
+// Maintainer is a component that can be put in maintenance mode.
+type Maintainer interface {
+	SetMaintenance(enabled bool)
+	InMaintenance() bool
+}
+
+// MaintenanceStatus is the request and response body for the maintenance endpoint.
+type MaintenanceStatus struct {
+	Enabled bool `json:"enabled"`
+}
+
+// MaintenanceHandler returns a handler for reading (GET) and updating (PUT) the maintenance mode.
+func MaintenanceHandler(m Maintainer) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+		case http.MethodPut:
+			var status MaintenanceStatus
+			if err := json.NewDecoder(r.Body).Decode(&status); err != nil {
+				writeError(w, http.StatusBadRequest, errors.Wrap(err, "decoding request"))
+				return
+			}
+			m.SetMaintenance(status.Enabled)
+		default:
+			writeError(w, http.StatusMethodNotAllowed, errors.New("method not allowed"))
+			return
+		}
+		writeJSON(w, http.StatusOK, MaintenanceStatus{Enabled: m.InMaintenance()})
+	})
+}
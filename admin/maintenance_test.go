@@ -0,0 +1,76 @@
+// Copyright (c) 2020 - for information on the respective copyright owner
+// see the NOTICE file and/or the repository at
+// https://github.com/hyperledger-labs/perun-node
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package admin_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/hyperledger-labs/perun-node/admin"
+)
+
+type maintainer struct {
+	enabled bool
+}
+
+func (m *maintainer) SetMaintenance(enabled bool) { m.enabled = enabled }
+func (m *maintainer) InMaintenance() bool         { return m.enabled }
+
+func Test_MaintenanceHandler(t *testing.T) {
+	t.Run("happy_get", func(t *testing.T) {
+		handler := admin.MaintenanceHandler(&maintainer{enabled: true})
+		rec := serve(handler, http.MethodGet, "")
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, `{"enabled": true}`, rec.Body.String())
+	})
+
+	t.Run("happy_put", func(t *testing.T) {
+		m := &maintainer{}
+		handler := admin.MaintenanceHandler(m)
+		rec := serve(handler, http.MethodPut, `{"enabled": true}`)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, `{"enabled": true}`, rec.Body.String())
+		assert.True(t, m.enabled)
+
+		rec = serve(handler, http.MethodPut, `{"enabled": false}`)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.False(t, m.enabled)
+	})
+
+	t.Run("err_invalid_body", func(t *testing.T) {
+		m := &maintainer{}
+		rec := serve(admin.MaintenanceHandler(m), http.MethodPut, `invalid`)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"error"`)
+		assert.False(t, m.enabled)
+	})
+
+	t.Run("err_method_not_allowed", func(t *testing.T) {
+		rec := serve(admin.MaintenanceHandler(&maintainer{}), http.MethodDelete, "")
+		assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+	})
+}
+
+func serve(handler http.Handler, method, body string) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(method, "/", strings.NewReader(body)))
+	return rec
+}
@@ -0,0 +1,81 @@
+// Copyright (c) 2020 - for information on the respective copyright owner
+// see the NOTICE file and/or the repository at
+// https://github.com/hyperledger-labs/perun-node
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package admin
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/pkg/errors"
+
+	"github.com/hyperledger-labs/perun-node/payment"
+)
+
+// Payer holds the invoices paid by the node and the receipts sent by the payees for them.
+type Payer interface {
+	ListPayments() []payment.PaidInvoice
+	GetPayment(id payment.InvoiceID) (payment.PaidInvoice, bool)
+	AddReceipt(receipt payment.Receipt) error
+}
+
+// PaymentsHandler returns a handler for the invoices paid by the node.
+//
+// GET returns the list of paid invoices or, if "invoiceId" is given in the query parameters, only the latest
+// payment of that invoice. The receipt is included once the payee has sent it. POST stores the receipt in the
+// request body with the payment, after verifying it against the payment and the payee. It is used for receipts
+// obtained out of band, such as when the node was offline when the payee sent it.
+func PaymentsHandler(payer Payer) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			getPayments(w, r, payer)
+		case http.MethodPost:
+			var receipt payment.Receipt
+			if err := json.NewDecoder(r.Body).Decode(&receipt); err != nil {
+				writeError(w, http.StatusBadRequest, errors.Wrap(err, "decoding request"))
+				return
+			}
+			if err := payer.AddReceipt(receipt); err != nil {
+				writeError(w, http.StatusBadRequest, err)
+				return
+			}
+			paid, _ := payer.GetPayment(receipt.InvoiceID)
+			writeJSON(w, http.StatusOK, paid)
+		default:
+			writeError(w, http.StatusMethodNotAllowed, errors.New("method not allowed"))
+		}
+	})
+}
+
+func getPayments(w http.ResponseWriter, r *http.Request, payer Payer) {
+	idParam := r.URL.Query().Get("invoiceId")
+	if idParam == "" {
+		writeJSON(w, http.StatusOK, payer.ListPayments())
+		return
+	}
+	var id payment.InvoiceID
+	if err := id.UnmarshalText([]byte(idParam)); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	paid, ok := payer.GetPayment(id)
+	if !ok {
+		writeError(w, http.StatusNotFound, errors.New("no payment with the ID"))
+		return
+	}
+	writeJSON(w, http.StatusOK, paid)
+}
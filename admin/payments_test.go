@@ -0,0 +1,126 @@
+// Copyright (c) 2020 - for information on the respective copyright owner
+// see the NOTICE file and/or the repository at
+// https://github.com/hyperledger-labs/perun-node
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package admin_test
+
+import (
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger-labs/perun-node/admin"
+	"github.com/hyperledger-labs/perun-node/payment"
+)
+
+type fakePayer struct {
+	paid []payment.PaidInvoice
+}
+
+func (p *fakePayer) ListPayments() []payment.PaidInvoice {
+	return p.paid
+}
+
+func (p *fakePayer) GetPayment(id payment.InvoiceID) (payment.PaidInvoice, bool) {
+	for _, paid := range p.paid {
+		if paid.ID == id {
+			return paid, true
+		}
+	}
+	return payment.PaidInvoice{}, false
+}
+
+func (p *fakePayer) AddReceipt(receipt payment.Receipt) error {
+	for i := range p.paid {
+		if p.paid[i].ID == receipt.InvoiceID {
+			p.paid[i].Receipt = &receipt
+			return nil
+		}
+	}
+	return assert.AnError
+}
+
+func Test_PaymentsHandler(t *testing.T) {
+	setup := func() (*fakePayer, http.Handler) {
+		payer := &fakePayer{paid: []payment.PaidInvoice{
+			{Invoice: payment.Invoice{ID: payment.InvoiceID{1}}, Paid: big.NewInt(1)},
+			{Invoice: payment.Invoice{ID: payment.InvoiceID{2}}, Paid: big.NewInt(2)},
+		}}
+		return payer, admin.PaymentsHandler(payer)
+	}
+	get := func(handler http.Handler, query string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/"+query, nil))
+		return rec
+	}
+
+	t.Run("happy_list", func(t *testing.T) {
+		_, handler := setup()
+		rec := get(handler, "")
+		assert.Equal(t, http.StatusOK, rec.Code)
+		var got []payment.PaidInvoice
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &got))
+		assert.Len(t, got, 2)
+	})
+
+	t.Run("happy_by_payment_hash", func(t *testing.T) {
+		_, handler := setup()
+		id, err := payment.InvoiceID{2}.MarshalText()
+		require.NoError(t, err)
+		rec := get(handler, "?invoiceId="+string(id))
+		assert.Equal(t, http.StatusOK, rec.Code)
+		var got payment.PaidInvoice
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &got))
+		assert.EqualValues(t, 2, got.Paid.Int64())
+	})
+
+	t.Run("happy_add_receipt", func(t *testing.T) {
+		payer, handler := setup()
+		body, err := json.Marshal(payment.Receipt{InvoiceID: payment.InvoiceID{2}, Amount: big.NewInt(2)})
+		require.NoError(t, err)
+		rec := serve(handler, http.MethodPost, string(body))
+		assert.Equal(t, http.StatusOK, rec.Code)
+		require.NotNil(t, payer.paid[1].Receipt)
+		var got payment.PaidInvoice
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &got))
+		assert.NotNil(t, got.Receipt)
+	})
+
+	t.Run("err_invalid_receipt", func(t *testing.T) {
+		_, handler := setup()
+		body, err := json.Marshal(payment.Receipt{InvoiceID: payment.InvoiceID{3}, Amount: big.NewInt(2)})
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, serve(handler, http.MethodPost, string(body)).Code)
+		assert.Equal(t, http.StatusBadRequest, serve(handler, http.MethodPost, "{").Code)
+	})
+
+	t.Run("err_not_found", func(t *testing.T) {
+		_, handler := setup()
+		id, err := payment.InvoiceID{3}.MarshalText()
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusNotFound, get(handler, "?invoiceId="+string(id)).Code)
+		assert.Equal(t, http.StatusBadRequest, get(handler, "?invoiceId=xyz").Code)
+	})
+
+	t.Run("err_method", func(t *testing.T) {
+		_, handler := setup()
+		assert.Equal(t, http.StatusMethodNotAllowed, serve(handler, http.MethodDelete, "").Code)
+	})
+}
@@ -0,0 +1,53 @@
+// Copyright (c) 2020 - for information on the respective copyright owner
+// see the NOTICE file and/or the repository at
+// https://github.com/hyperledger-labs/perun-node
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package admin
+
+import (
+	"net/http"
+
+	"github.com/pkg/errors"
+
+	"github.com/hyperledger-labs/perun-node/payment"
+)
+
+// ReceiptsHandler returns a handler for reading (GET) the receipts issued for payments received by the node.
+// If "invoiceId" is given in the query parameters, only the receipt for that invoice is returned.
+func ReceiptsHandler(receipts func() []payment.Receipt) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			writeError(w, http.StatusMethodNotAllowed, errors.New("method not allowed"))
+			return
+		}
+		idParam := r.URL.Query().Get("invoiceId")
+		if idParam == "" {
+			writeJSON(w, http.StatusOK, receipts())
+			return
+		}
+		var id payment.InvoiceID
+		if err := id.UnmarshalText([]byte(idParam)); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		for _, receipt := range receipts() {
+			if receipt.InvoiceID == id {
+				writeJSON(w, http.StatusOK, receipt)
+				return
+			}
+		}
+		writeError(w, http.StatusNotFound, errors.New("no receipt with the ID"))
+	})
+}
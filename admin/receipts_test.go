@@ -0,0 +1,76 @@
+// Copyright (c) 2020 - for information on the respective copyright owner
+// see the NOTICE file and/or the repository at
+// https://github.com/hyperledger-labs/perun-node
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package admin_test
+
+import (
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger-labs/perun-node/admin"
+	"github.com/hyperledger-labs/perun-node/payment"
+)
+
+func Test_ReceiptsHandler(t *testing.T) {
+	receipts := []payment.Receipt{
+		{InvoiceID: payment.InvoiceID{1}, Amount: big.NewInt(1)},
+		{InvoiceID: payment.InvoiceID{2}, Amount: big.NewInt(2)},
+	}
+	handler := admin.ReceiptsHandler(func() []payment.Receipt { return receipts })
+	get := func(query string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/"+query, nil))
+		return rec
+	}
+
+	t.Run("happy_list", func(t *testing.T) {
+		rec := get("")
+		assert.Equal(t, http.StatusOK, rec.Code)
+		var got []payment.Receipt
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &got))
+		assert.Equal(t, receipts, got)
+	})
+
+	t.Run("happy_by_payment_hash", func(t *testing.T) {
+		id, err := receipts[1].InvoiceID.MarshalText()
+		require.NoError(t, err)
+		rec := get("?invoiceId=" + string(id))
+		assert.Equal(t, http.StatusOK, rec.Code)
+		var got payment.Receipt
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &got))
+		assert.Equal(t, receipts[1], got)
+	})
+
+	t.Run("err_not_found", func(t *testing.T) {
+		id, err := payment.InvoiceID{3}.MarshalText()
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusNotFound, get("?invoiceId="+string(id)).Code)
+	})
+
+	t.Run("err_invalid_hash", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadRequest, get("?invoiceId=xyz").Code)
+	})
+
+	t.Run("err_method", func(t *testing.T) {
+		assert.Equal(t, http.StatusMethodNotAllowed, serve(handler, http.MethodPost, "").Code)
+	})
+}
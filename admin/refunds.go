@@ -0,0 +1,56 @@
+// Copyright (c) 2020 - for information on the respective copyright owner
+// see the NOTICE file and/or the repository at
+// https://github.com/hyperledger-labs/perun-node
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/pkg/errors"
+
+	"github.com/hyperledger-labs/perun-node/payment"
+)
+
+// RefundRequest is the request body for refunding an invoice. InvoiceID identifies the invoice (as hex string).
+type RefundRequest struct {
+	InvoiceID payment.InvoiceID `json:"invoiceId"`
+	Reason    string            `json:"reason"`
+}
+
+// RefundsHandler returns a handler for refunding (POST) the settled invoice given as RefundRequest in the
+// request body, using the refund function. The response is the refunded invoice.
+func RefundsHandler(
+	refund func(context.Context, payment.InvoiceID, string) (payment.InvoiceRecord, error)) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			writeError(w, http.StatusMethodNotAllowed, errors.New("method not allowed"))
+			return
+		}
+		var req RefundRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, errors.Wrap(err, "decoding request"))
+			return
+		}
+		record, err := refund(r.Context(), req.InvoiceID, req.Reason)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, record)
+	})
+}
@@ -0,0 +1,69 @@
+// Copyright (c) 2020 - for information on the respective copyright owner
+// see the NOTICE file and/or the repository at
+// https://github.com/hyperledger-labs/perun-node
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package admin_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger-labs/perun-node/admin"
+	"github.com/hyperledger-labs/perun-node/payment"
+)
+
+func Test_RefundsHandler(t *testing.T) {
+	var gotID payment.InvoiceID
+	var gotReason string
+	handler := admin.RefundsHandler(func(_ context.Context, id payment.InvoiceID, reason string) (
+		payment.InvoiceRecord, error) {
+		if id == (payment.InvoiceID{}) {
+			return payment.InvoiceRecord{}, assert.AnError
+		}
+		gotID, gotReason = id, reason
+		return payment.InvoiceRecord{Status: payment.InvoiceRefunded}, nil
+	})
+	id, err := payment.InvoiceID{1}.MarshalText()
+	require.NoError(t, err)
+
+	t.Run("happy", func(t *testing.T) {
+		rec := serve(handler, http.MethodPost, `{"invoiceId": "`+string(id)+`", "reason": "duplicate"}`)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"status":"refunded"`)
+		assert.Equal(t, payment.InvoiceID{1}, gotID)
+		assert.Equal(t, "duplicate", gotReason)
+	})
+
+	t.Run("err_invalid_hash", func(t *testing.T) {
+		rec := serve(handler, http.MethodPost, `{"invoiceId": "xyz"}`)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("err_refund", func(t *testing.T) {
+		var zero payment.InvoiceID
+		zeroID, err := zero.MarshalText()
+		require.NoError(t, err)
+		rec := serve(handler, http.MethodPost, `{"invoiceId": "`+string(zeroID)+`"}`)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("err_method", func(t *testing.T) {
+		assert.Equal(t, http.StatusMethodNotAllowed, serve(handler, http.MethodGet, "").Code)
+	})
+}
@@ -0,0 +1,156 @@
+// Copyright (c) 2020 - for information on the respective copyright owner
+// see the NOTICE file and/or the repository at
+// https://github.com/hyperledger-labs/perun-node
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/hyperledger-labs/perun-node/log"
+)
+
+// readHeaderTimeout is the timeout for reading the request headers.
+const readHeaderTimeout = 10 * time.Second
+
+// Server serves the admin API over HTTP.
+type Server struct {
+	log.Logger
+
+	mux      *http.ServeMux
+	server   *http.Server
+	listener net.Listener
+}
+
+// NewServer returns a server for the admin API. Handlers should be registered before starting it.
+func NewServer() *Server {
+	mux := http.NewServeMux()
+	return &Server{
+		Logger: log.NewLoggerWithField("component", "admin"),
+		mux:    mux,
+		server: &http.Server{Handler: guard(mux), ReadHeaderTimeout: readHeaderTimeout},
+	}
+}
+
+// Handle registers the handler for the given path.
+func (s *Server) Handle(path string, handler http.Handler) {
+	s.mux.Handle(path, handler)
+}
+
+// Start starts listening at the given address and serves the requests in a go-routine. The address should
+// be a loopback address, as the API does not implement authentication.
+func (s *Server) Start(addr string) error {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return errors.Wrap(err, "parsing address for admin api")
+	}
+	if !isLoopback(host) {
+		return errors.Errorf("admin api should listen on a loopback address, not %q", addr)
+	}
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return errors.Wrap(err, "starting listener for admin api")
+	}
+	s.listener = listener
+	go func() {
+		if serveErr := s.server.Serve(listener); serveErr != http.ErrServerClosed {
+			s.Error("Serving admin api: ", serveErr)
+		}
+	}()
+	return nil
+}
+
+// Addr returns the address at which the server is listening. It returns nil if the server is not started.
+func (s *Server) Addr() net.Addr {
+	if s.listener == nil {
+		return nil
+	}
+	return s.listener.Addr()
+}
+
+// Shutdown gracefully shuts down the server, waiting for the active requests to complete until
+// the context expires.
+func (s *Server) Shutdown(ctx context.Context) error {
+	return errors.Wrap(s.server.Shutdown(ctx), "shutting down admin api server")
+}
+
+// guard rejects the requests that a web page open in a browser on the same host can send to the API: requests
+// for a host name other than a loopback one (DNS rebinding), requests from a web page of another origin and
+// POST requests without a JSON body, which can be sent from another origin without a preflight (CSRF).
+func guard(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !isLoopback(hostname(r.Host)) {
+			writeError(w, http.StatusForbidden, errors.Errorf("host %q is not allowed", r.Host))
+			return
+		}
+		if origin := r.Header.Get("Origin"); origin != "" {
+			u, err := url.Parse(origin)
+			if err != nil || !isLoopback(u.Hostname()) {
+				writeError(w, http.StatusForbidden, errors.Errorf("origin %q is not allowed", origin))
+				return
+			}
+		}
+		if r.Method == http.MethodPost {
+			mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+			if err != nil || mediaType != "application/json" {
+				writeError(w, http.StatusUnsupportedMediaType, errors.New("content type should be application/json"))
+				return
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// hostname returns the host in the host header of a request, without the port.
+func hostname(hostPort string) string {
+	if host, _, err := net.SplitHostPort(hostPort); err == nil {
+		return host
+	}
+	return strings.Trim(hostPort, "[]")
+}
+
+// isLoopback returns true if the host is localhost or a loopback IP address.
+func isLoopback(host string) bool {
+	if strings.EqualFold(host, "localhost") {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// errorResponse is the body of the response when a request fails.
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// writeJSON writes the value as JSON encoded response with the given status code.
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v) // nolint: errcheck, gosec  // nothing to do if writing response fails.
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, errorResponse{Error: err.Error()})
+}
@@ -0,0 +1,108 @@
+// Copyright (c) 2020 - for information on the respective copyright owner
+// see the NOTICE file and/or the repository at
+// https://github.com/hyperledger-labs/perun-node
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package admin_test
+
+import (
+	"context"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger-labs/perun-node/admin"
+)
+
+func Test_Server(t *testing.T) {
+	t.Run("happy", func(t *testing.T) {
+		server := admin.NewServer()
+		server.Handle("/maintenance", admin.MaintenanceHandler(&maintainer{}))
+		require.NoError(t, server.Start("127.0.0.1:0"))
+
+		resp, err := http.Get("http://" + server.Addr().String() + "/maintenance")
+		require.NoError(t, err)
+		body, err := ioutil.ReadAll(resp.Body)
+		require.NoError(t, err)
+		require.NoError(t, resp.Body.Close())
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.JSONEq(t, `{"enabled": false}`, string(body))
+
+		assert.NoError(t, server.Shutdown(context.Background()))
+	})
+
+	t.Run("err_invalid_addr", func(t *testing.T) {
+		assert.Error(t, admin.NewServer().Start("invalid-addr"))
+	})
+
+	t.Run("err_non_loopback_addr", func(t *testing.T) {
+		assert.Error(t, admin.NewServer().Start(":0"))
+		assert.Error(t, admin.NewServer().Start("0.0.0.0:0"))
+	})
+}
+
+func Test_Server_Guard(t *testing.T) {
+	server := admin.NewServer()
+	server.Handle("/", http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {}))
+	require.NoError(t, server.Start("localhost:0"))
+	t.Cleanup(func() { assert.NoError(t, server.Shutdown(context.Background())) })
+	_, port, err := net.SplitHostPort(server.Addr().String())
+	require.NoError(t, err)
+
+	// do sends the request with the host and the headers (as name, value pairs) and returns the status code.
+	do := func(t *testing.T, method, host string, headers ...string) int {
+		req, err := http.NewRequest(method, "http://"+server.Addr().String()+"/", strings.NewReader(`{}`))
+		require.NoError(t, err)
+		req.Host = host
+		for i := 0; i+1 < len(headers); i += 2 {
+			req.Header.Set(headers[i], headers[i+1])
+		}
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		require.NoError(t, resp.Body.Close())
+		return resp.StatusCode
+	}
+
+	t.Run("happy", func(t *testing.T) {
+		for _, host := range []string{"localhost:" + port, "127.0.0.1:" + port, "[::1]:" + port, "localhost"} {
+			assert.Equal(t, http.StatusOK, do(t, http.MethodGet, host), host)
+		}
+		assert.Equal(t, http.StatusOK, do(t, http.MethodPost, "localhost", "Content-Type", "application/json",
+			"Origin", "http://127.0.0.1:3000"))
+		assert.Equal(t, http.StatusOK, do(t, http.MethodPut, "localhost"))
+	})
+
+	t.Run("err_host", func(t *testing.T) {
+		assert.Equal(t, http.StatusForbidden, do(t, http.MethodGet, "rebind.example.com:"+port))
+		assert.Equal(t, http.StatusForbidden, do(t, http.MethodGet, "192.168.1.1:"+port))
+	})
+
+	t.Run("err_origin", func(t *testing.T) {
+		for _, origin := range []string{"http://evil.example.com", "null"} {
+			assert.Equal(t, http.StatusForbidden, do(t, http.MethodPost, "localhost",
+				"Content-Type", "application/json", "Origin", origin), origin)
+		}
+	})
+
+	t.Run("err_content_type", func(t *testing.T) {
+		assert.Equal(t, http.StatusUnsupportedMediaType, do(t, http.MethodPost, "localhost"))
+		assert.Equal(t, http.StatusUnsupportedMediaType, do(t, http.MethodPost, "localhost",
+			"Content-Type", "text/plain"))
+	})
+}
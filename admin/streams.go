@@ -0,0 +1,114 @@
+// Copyright (c) 2020 - for information on the respective copyright owner
+// see the NOTICE file and/or the repository at
+// https://github.com/hyperledger-labs/perun-node
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+	"perun.network/go-perun/channel"
+
+	"github.com/hyperledger-labs/perun-node/payment"
+)
+
+// StreamManager manages the streams of payments over open channels.
+type StreamManager interface {
+	Start(ch channel.ID, amount, limit *big.Int, interval time.Duration) (payment.StreamInfo, error)
+	Charge(ctx context.Context, id string, units int64) error
+	Stop(id string) error
+	List() []payment.StreamInfo
+}
+
+// StreamRequest is the request body for starting a stream. Channel is the channel ID as hex string, Amount and
+// Limit (optional) are decimal strings in the smallest unit of the asset and Interval (optional) is a duration
+// string such as "1s". Streams without an interval are paid for the units of usage charged using the API.
+type StreamRequest struct {
+	Channel  string `json:"channel"`
+	Amount   string `json:"amount"`
+	Limit    string `json:"limit"`
+	Interval string `json:"interval"`
+}
+
+// StreamsHandler returns a handler for streams of payments. The response for each request is the list of all
+// streams.
+//
+// GET returns the streams. POST starts the stream given as StreamRequest in the request body. PUT charges the
+// stream given as "id" in the query parameters for the number of "units" of usage. DELETE stops the stream
+// given as "id" in the query parameters.
+func StreamsHandler(streams StreamManager) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var err error
+		id := r.URL.Query().Get("id")
+		switch r.Method {
+		case http.MethodGet:
+		case http.MethodPost:
+			err = startStream(streams, r)
+		case http.MethodPut:
+			var units int64
+			if units, err = strconv.ParseInt(r.URL.Query().Get("units"), 10, 64); err == nil {
+				err = streams.Charge(r.Context(), id, units)
+			}
+		case http.MethodDelete:
+			err = streams.Stop(id)
+		default:
+			writeError(w, http.StatusMethodNotAllowed, errors.New("method not allowed"))
+			return
+		}
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, streams.List())
+	})
+}
+
+func startStream(streams StreamManager, r *http.Request) error {
+	var req StreamRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return errors.Wrap(err, "decoding request")
+	}
+	id, err := parseChannelID(req.Channel)
+	if err != nil {
+		return err
+	}
+	amount, ok := new(big.Int).SetString(req.Amount, 10)
+	if !ok {
+		return errors.Errorf("invalid amount %q", req.Amount)
+	}
+	var limit *big.Int
+	if req.Limit != "" {
+		if limit, ok = new(big.Int).SetString(req.Limit, 10); !ok {
+			return errors.Errorf("invalid limit %q", req.Limit)
+		}
+	}
+	var interval time.Duration
+	if req.Interval != "" {
+		if interval, err = time.ParseDuration(req.Interval); err != nil {
+			return errors.Wrap(err, "parsing interval")
+		}
+		if interval <= 0 {
+			return errors.New("interval should be positive")
+		}
+	}
+	_, err = streams.Start(id, amount, limit, interval)
+	return err
+}
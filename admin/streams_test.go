@@ -0,0 +1,176 @@
+// Copyright (c) 2020 - for information on the respective copyright owner
+// see the NOTICE file and/or the repository at
+// https://github.com/hyperledger-labs/perun-node
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package admin_test
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"perun.network/go-perun/channel"
+	"perun.network/go-perun/client"
+	"perun.network/go-perun/pkg/test"
+	"perun.network/go-perun/wire"
+
+	"github.com/hyperledger-labs/perun-node/admin"
+	"github.com/hyperledger-labs/perun-node/blockchain/ethereum/ethereumtest"
+	"github.com/hyperledger-labs/perun-node/payment"
+)
+
+// paymentChannel is a two party channel, in which the node pays from its balance at index 0.
+type paymentChannel struct {
+	mutex sync.Mutex
+	state *channel.State
+}
+
+func newPaymentChannel(t *testing.T, balance int64) *paymentChannel {
+	rng := test.Prng(t)
+	state := &channel.State{
+		App:  channel.NewMockApp(ethereumtest.NewRandomAddress(rng)),
+		Data: channel.NewMockOp(channel.OpValid),
+	}
+	_, err := rng.Read(state.ID[:])
+	require.NoError(t, err)
+	state.Balances = [][]*big.Int{{big.NewInt(balance), big.NewInt(0)}}
+	return &paymentChannel{state: state}
+}
+
+func (ch *paymentChannel) Idx() channel.Index    { return 0 }
+func (ch *paymentChannel) Peers() []wire.Address { return nil }
+
+func (ch *paymentChannel) State() *channel.State {
+	ch.mutex.Lock()
+	defer ch.mutex.Unlock()
+	return ch.state.Clone()
+}
+
+func (ch *paymentChannel) Update(_ context.Context, up client.ChannelUpdate) error {
+	ch.mutex.Lock()
+	defer ch.mutex.Unlock()
+	ch.state = up.State
+	return nil
+}
+
+func (ch *paymentChannel) lookup(id channel.ID) (payment.Channel, error) {
+	if id != ch.state.ID {
+		return nil, assert.AnError
+	}
+	return ch, nil
+}
+
+func (ch *paymentChannel) received() int64 {
+	return ch.State().Balances[0][1].Int64()
+}
+
+func (ch *paymentChannel) id() string {
+	return hex.EncodeToString(ch.state.ID[:])
+}
+
+func Test_StreamsHandler(t *testing.T) {
+	setup := func(t *testing.T) (*paymentChannel, http.Handler) {
+		ch := newPaymentChannel(t, 10)
+		return ch, admin.StreamsHandler(payment.NewStreams(ch.lookup, nil, nil))
+	}
+	decode := func(t *testing.T, rec *httptest.ResponseRecorder) []map[string]interface{} {
+		var streams []map[string]interface{}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &streams))
+		return streams
+	}
+	do := func(handler http.Handler, method, target string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(method, target, strings.NewReader("")))
+		return rec
+	}
+
+	t.Run("happy_start_charge_stop", func(t *testing.T) {
+		ch, handler := setup(t)
+		rec := serve(handler, http.MethodPost, `{"channel": "`+ch.id()+`", "amount": "2", "limit": "6"}`)
+		require.Equal(t, http.StatusOK, rec.Code)
+		streams := decode(t, rec)
+		require.Len(t, streams, 1)
+		assert.EqualValues(t, payment.StreamActive, streams[0]["status"])
+		assert.Equal(t, ch.id(), streams[0]["channel"])
+		assert.Equal(t, "", streams[0]["interval"])
+		id := streams[0]["id"].(string)
+
+		rec = do(handler, http.MethodPut, "/?id="+id+"&units=2")
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.EqualValues(t, 4, decode(t, rec)[0]["paid"])
+		assert.EqualValues(t, 4, ch.received())
+
+		rec = do(handler, http.MethodPut, "/?id="+id+"&units=2")
+		assert.Equal(t, http.StatusBadRequest, rec.Code, "limit reached")
+		assert.EqualValues(t, 4, ch.received())
+
+		rec = do(handler, http.MethodDelete, "/?id="+id)
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.EqualValues(t, payment.StreamStopped, decode(t, rec)[0]["status"])
+
+		rec = do(handler, http.MethodGet, "/")
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.Len(t, decode(t, rec), 1)
+	})
+
+	t.Run("happy_timed", func(t *testing.T) {
+		ch, handler := setup(t)
+		rec := serve(handler, http.MethodPost, `{"channel": "`+ch.id()+`", "amount": "1", "interval": "1h"}`)
+		require.Equal(t, http.StatusOK, rec.Code)
+		streams := decode(t, rec)
+		assert.Equal(t, "1h0m0s", streams[0]["interval"])
+		id := streams[0]["id"].(string)
+
+		rec = do(handler, http.MethodPut, "/?id="+id+"&units=1")
+		assert.Equal(t, http.StatusBadRequest, rec.Code, "timed streams cannot be charged")
+		assert.Equal(t, http.StatusOK, do(handler, http.MethodDelete, "/?id="+id).Code)
+	})
+
+	t.Run("err_invalid_request", func(t *testing.T) {
+		ch, handler := setup(t)
+		for _, body := range []string{
+			`invalid`,
+			`{"channel": "invalid", "amount": "1"}`,
+			`{"channel": "` + ch.id() + `", "amount": "invalid"}`,
+			`{"channel": "` + ch.id() + `", "amount": "0"}`,
+			`{"channel": "` + ch.id() + `", "amount": "1", "limit": "invalid"}`,
+			`{"channel": "` + ch.id() + `", "amount": "1", "interval": "invalid"}`,
+			`{"channel": "` + ch.id() + `", "amount": "1", "interval": "-1s"}`,
+			`{"channel": "0102030405060708091011121314151617181920212223242526272829303132", "amount": "1"}`,
+		} {
+			assert.Equal(t, http.StatusBadRequest, serve(handler, http.MethodPost, body).Code, body)
+		}
+	})
+
+	t.Run("err_unknown_stream", func(t *testing.T) {
+		_, handler := setup(t)
+		assert.Equal(t, http.StatusBadRequest, do(handler, http.MethodPut, "/?id=unknown&units=1").Code)
+		assert.Equal(t, http.StatusBadRequest, do(handler, http.MethodPut, "/?id=unknown&units=invalid").Code)
+		assert.Equal(t, http.StatusBadRequest, do(handler, http.MethodDelete, "/?id=unknown").Code)
+	})
+
+	t.Run("err_method", func(t *testing.T) {
+		_, handler := setup(t)
+		assert.Equal(t, http.StatusMethodNotAllowed, serve(handler, http.MethodPatch, "").Code)
+	})
+}
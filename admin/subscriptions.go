@@ -0,0 +1,112 @@
+// Copyright (c) 2020 - for information on the respective copyright owner
+// see the NOTICE file and/or the repository at
+// https://github.com/hyperledger-labs/perun-node
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package admin
+
+import (
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"time"
+
+	"github.com/pkg/errors"
+	"perun.network/go-perun/channel"
+
+	"github.com/hyperledger-labs/perun-node/payment"
+)
+
+// SubscriptionManager manages the recurring payments over open channels.
+type SubscriptionManager interface {
+	Add(ch channel.ID, amount *big.Int, interval time.Duration, end time.Time) (payment.Subscription, error)
+	Pause(id string) error
+	Resume(id string) error
+	Cancel(id string) error
+	List() []payment.Subscription
+}
+
+// SubscriptionRequest is the request body for adding a subscription. Channel is the channel ID as hex string,
+// Amount is a decimal string in the smallest unit of the asset, Interval is a duration string such as "24h" and
+// End (optional) is the end date in RFC 3339 format.
+type SubscriptionRequest struct {
+	Channel  string `json:"channel"`
+	Amount   string `json:"amount"`
+	Interval string `json:"interval"`
+	End      string `json:"end"`
+}
+
+// SubscriptionsHandler returns a handler for subscriptions. The response for each request is the list of all
+// subscriptions.
+//
+// GET returns the subscriptions. POST adds the subscription given as SubscriptionRequest in the request body.
+// PUT pauses or resumes the subscription given as "id" in the query parameters, depending on the "action"
+// ("pause" or "resume"). DELETE cancels the subscription given as "id" in the query parameters.
+func SubscriptionsHandler(subs SubscriptionManager) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var err error
+		id := r.URL.Query().Get("id")
+		switch r.Method {
+		case http.MethodGet:
+		case http.MethodPost:
+			err = addSubscription(subs, r)
+		case http.MethodPut:
+			switch action := r.URL.Query().Get("action"); action {
+			case "pause":
+				err = subs.Pause(id)
+			case "resume":
+				err = subs.Resume(id)
+			default:
+				err = errors.Errorf("unknown action %q", action)
+			}
+		case http.MethodDelete:
+			err = subs.Cancel(id)
+		default:
+			writeError(w, http.StatusMethodNotAllowed, errors.New("method not allowed"))
+			return
+		}
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, subs.List())
+	})
+}
+
+func addSubscription(subs SubscriptionManager, r *http.Request) error {
+	var req SubscriptionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return errors.Wrap(err, "decoding request")
+	}
+	id, err := parseChannelID(req.Channel)
+	if err != nil {
+		return err
+	}
+	amount, ok := new(big.Int).SetString(req.Amount, 10)
+	if !ok {
+		return errors.Errorf("invalid amount %q", req.Amount)
+	}
+	interval, err := time.ParseDuration(req.Interval)
+	if err != nil {
+		return errors.Wrap(err, "parsing interval")
+	}
+	var end time.Time
+	if req.End != "" {
+		if end, err = time.Parse(time.RFC3339, req.End); err != nil {
+			return errors.Wrap(err, "parsing end date")
+		}
+	}
+	_, err = subs.Add(id, amount, interval, end)
+	return err
+}
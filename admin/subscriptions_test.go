@@ -0,0 +1,98 @@
+// Copyright (c) 2020 - for information on the respective copyright owner
+// see the NOTICE file and/or the repository at
+// https://github.com/hyperledger-labs/perun-node
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package admin_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"perun.network/go-perun/channel"
+
+	"github.com/hyperledger-labs/perun-node/admin"
+	"github.com/hyperledger-labs/perun-node/clock"
+	"github.com/hyperledger-labs/perun-node/payment"
+)
+
+func Test_SubscriptionsHandler(t *testing.T) {
+	const channelID = "0102030405060708091011121314151617181920212223242526272829303132"
+	newHandler := func(t *testing.T) http.Handler {
+		clk, err := clock.New("UTC")
+		require.NoError(t, err)
+		lookup := func(channel.ID) (payment.Channel, error) { return nil, assert.AnError }
+		return admin.SubscriptionsHandler(payment.NewSubscriptions(clk, lookup, nil, nil))
+	}
+	decode := func(t *testing.T, rec *httptest.ResponseRecorder) []map[string]interface{} {
+		var subs []map[string]interface{}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &subs))
+		return subs
+	}
+	do := func(handler http.Handler, method, target, body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(method, target, strings.NewReader(body)))
+		return rec
+	}
+
+	t.Run("happy_add_pause_resume_cancel", func(t *testing.T) {
+		handler := newHandler(t)
+		end := time.Now().Add(24 * time.Hour).Format(time.RFC3339)
+		rec := serve(handler, http.MethodPost,
+			`{"channel": "`+channelID+`", "amount": "10", "interval": "1h", "end": "`+end+`"}`)
+		require.Equal(t, http.StatusOK, rec.Code)
+		subs := decode(t, rec)
+		require.Len(t, subs, 1)
+		assert.Equal(t, channelID, subs[0]["channel"])
+		assert.Equal(t, "1h0m0s", subs[0]["interval"])
+		assert.EqualValues(t, 10, subs[0]["amount"])
+		id, _ := subs[0]["id"].(string)
+
+		rec = do(handler, http.MethodPut, "/?action=pause&id="+id, "")
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, string(payment.SubscriptionPaused), decode(t, rec)[0]["status"])
+		rec = do(handler, http.MethodPut, "/?action=resume&id="+id, "")
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, string(payment.SubscriptionActive), decode(t, rec)[0]["status"])
+		rec = do(handler, http.MethodDelete, "/?id="+id, "")
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, string(payment.SubscriptionCanceled), decode(t, rec)[0]["status"])
+	})
+
+	t.Run("err_invalid_request", func(t *testing.T) {
+		for _, body := range []string{
+			`invalid`,
+			`{"channel": "01", "amount": "10", "interval": "1h"}`,
+			`{"channel": "` + channelID + `", "amount": "ten", "interval": "1h"}`,
+			`{"channel": "` + channelID + `", "amount": "10", "interval": "hourly"}`,
+			`{"channel": "` + channelID + `", "amount": "10", "interval": "1h", "end": "tomorrow"}`,
+		} {
+			assert.Equal(t, http.StatusBadRequest, serve(newHandler(t), http.MethodPost, body).Code, body)
+		}
+	})
+
+	t.Run("err_unknown_action", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadRequest, do(newHandler(t), http.MethodPut, "/?action=stop&id=x", "").Code)
+	})
+
+	t.Run("err_unknown_subscription", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadRequest, do(newHandler(t), http.MethodDelete, "/?id=x", "").Code)
+	})
+}
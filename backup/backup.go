@@ -0,0 +1,285 @@
+// Copyright (c) 2020 - for information on the respective copyright owner
+// see the NOTICE file and/or the repository at
+// https://github.com/hyperledger-labs/perun-node
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backup
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/scrypt"
+)
+
+// ManifestName is the name of the manifest file in the archive.
+const ManifestName = "MANIFEST.sha256"
+
+const (
+	magic     = "PERUNBAK"
+	version   = 1
+	saltLen   = 16
+	headerLen = len(magic) + 1 + saltLen
+
+	// Parameters for deriving the encryption key using scrypt.
+	scryptN = 1 << 15
+	scryptR = 8
+	scryptP = 1
+	keyLen  = 32
+)
+
+// Entry maps a file or a directory on the disk to a name in the archive.
+// For directories, all regular files inside it are added under the name, preserving the directory structure.
+type Entry struct {
+	Name string
+	Path string
+}
+
+// Create writes the files in the entries as an encrypted archive to the writer.
+//
+// The files should not be modified while the archive is being created. So, the node using the files
+// should be stopped before creating a backup.
+func Create(w io.Writer, passphrase string, entries ...Entry) error {
+	var plain bytes.Buffer
+	if err := writeArchive(&plain, entries); err != nil {
+		return err
+	}
+
+	salt := make([]byte, saltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return errors.Wrap(err, "generating salt")
+	}
+	header := append(append([]byte(magic), version), salt...)
+	aead, err := newAEAD(passphrase, salt)
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err = rand.Read(nonce); err != nil {
+		return errors.Wrap(err, "generating nonce")
+	}
+
+	ciphertext := aead.Seal(nil, nonce, plain.Bytes(), header)
+	for _, data := range [][]byte{header, nonce, ciphertext} {
+		if _, err = w.Write(data); err != nil {
+			return errors.Wrap(err, "writing archive")
+		}
+	}
+	return nil
+}
+
+// Restore decrypts the archive read from the reader, verifies the checksums of all files against the manifest
+// and extracts them into the destination directory. Files are written only if all the checks succeed.
+//
+// It returns the names of the restored files (including the manifest).
+func Restore(r io.Reader, passphrase, destDir string) ([]string, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, errors.Wrap(err, "reading archive")
+	}
+	if len(data) < headerLen || string(data[:len(magic)]) != magic {
+		return nil, errors.New("not a backup archive")
+	}
+	if data[len(magic)] != version {
+		return nil, errors.Errorf("unsupported archive version %d", data[len(magic)])
+	}
+	header, salt := data[:headerLen], data[len(magic)+1:headerLen]
+	aead, err := newAEAD(passphrase, salt)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < headerLen+aead.NonceSize() {
+		return nil, errors.New("archive is truncated")
+	}
+	nonce, ciphertext := data[headerLen:headerLen+aead.NonceSize()], data[headerLen+aead.NonceSize():]
+	plain, err := aead.Open(nil, nonce, ciphertext, header)
+	if err != nil {
+		return nil, errors.New("decrypting archive: wrong passphrase or archive is corrupted")
+	}
+
+	names, files, err := readArchive(bytes.NewReader(plain))
+	if err != nil {
+		return nil, err
+	}
+	if err = verifyManifest(names, files); err != nil {
+		return nil, err
+	}
+	for _, name := range names {
+		filePath := filepath.Join(destDir, filepath.FromSlash(name))
+		if err = os.MkdirAll(filepath.Dir(filePath), 0o700); err != nil {
+			return nil, errors.Wrap(err, "creating directory")
+		}
+		if err = ioutil.WriteFile(filePath, files[name], 0o600); err != nil {
+			return nil, errors.Wrap(err, "writing file")
+		}
+	}
+	return names, nil
+}
+
+func newAEAD(passphrase string, salt []byte) (cipher.AEAD, error) {
+	key, err := scrypt.Key([]byte(passphrase), salt, scryptN, scryptR, scryptP, keyLen)
+	if err != nil {
+		return nil, errors.Wrap(err, "deriving key from passphrase")
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, errors.Wrap(err, "initializing cipher")
+	}
+	aead, err := cipher.NewGCM(block)
+	return aead, errors.Wrap(err, "initializing cipher")
+}
+
+// writeArchive writes the files in the entries and the manifest as a gzip compressed tar archive.
+func writeArchive(w io.Writer, entries []Entry) error {
+	gw := gzip.NewWriter(w)
+	tw := tar.NewWriter(gw)
+	var manifest bytes.Buffer
+
+	for _, entry := range entries {
+		err := filepath.Walk(entry.Path, func(filePath string, info os.FileInfo, err error) error {
+			if err != nil || !info.Mode().IsRegular() {
+				return err
+			}
+			rel, err := filepath.Rel(entry.Path, filePath)
+			if err != nil {
+				return err
+			}
+			name := path.Join(entry.Name, filepath.ToSlash(rel))
+			content, err := ioutil.ReadFile(filepath.Clean(filePath))
+			if err != nil {
+				return err
+			}
+			if err = writeFile(tw, name, content); err != nil {
+				return err
+			}
+			fmt.Fprintf(&manifest, "%x  %s\n", sha256.Sum256(content), name)
+			return nil
+		})
+		if err != nil {
+			return errors.Wrap(err, "adding "+entry.Path)
+		}
+	}
+	if err := writeFile(tw, ManifestName, manifest.Bytes()); err != nil {
+		return errors.Wrap(err, "adding manifest")
+	}
+	if err := tw.Close(); err != nil {
+		return errors.Wrap(err, "writing archive")
+	}
+	return errors.Wrap(gw.Close(), "writing archive")
+}
+
+func writeFile(tw *tar.Writer, name string, content []byte) error {
+	if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0o600, Size: int64(len(content))}); err != nil {
+		return err
+	}
+	_, err := tw.Write(content)
+	return err
+}
+
+// readArchive reads the files from a gzip compressed tar archive. It returns the names of files in the order
+// they are stored and a map of their contents.
+func readArchive(r io.Reader) ([]string, map[string][]byte, error) {
+	gr, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "reading archive")
+	}
+	tr := tar.NewReader(gr)
+	var names []string
+	files := make(map[string][]byte)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return names, files, nil
+		}
+		if err != nil {
+			return nil, nil, errors.Wrap(err, "reading archive")
+		}
+		if !isValidName(hdr.Name) {
+			return nil, nil, errors.Errorf("invalid file name %q in archive", hdr.Name)
+		}
+		if _, ok := files[hdr.Name]; ok {
+			return nil, nil, errors.Errorf("duplicate file %q in archive", hdr.Name)
+		}
+		content, err := ioutil.ReadAll(tr)
+		if err != nil {
+			return nil, nil, errors.Wrap(err, "reading archive")
+		}
+		names = append(names, hdr.Name)
+		files[hdr.Name] = content
+	}
+}
+
+// isValidName checks if the name is a relative path that does not point outside the destination directory.
+func isValidName(name string) bool {
+	return name != "" && !path.IsAbs(name) && path.Clean(name) == name && name != ".." &&
+		!strings.HasPrefix(name, "../")
+}
+
+// verifyManifest checks if each file in the archive (except manifest) is listed in the manifest with a
+// matching checksum and that each file listed in the manifest is present in the archive.
+func verifyManifest(names []string, files map[string][]byte) error {
+	manifest, ok := files[ManifestName]
+	if !ok {
+		return errors.New("manifest not found in archive")
+	}
+	checksums := make(map[string]string)
+	scanner := bufio.NewScanner(bytes.NewReader(manifest))
+	for scanner.Scan() {
+		fields := strings.SplitN(scanner.Text(), "  ", 2)
+		if len(fields) != 2 {
+			return errors.Errorf("invalid line in manifest: %q", scanner.Text())
+		}
+		checksums[fields[1]] = fields[0]
+	}
+
+	for _, name := range names {
+		if name == ManifestName {
+			continue
+		}
+		checksum, ok := checksums[name]
+		if !ok {
+			return errors.Errorf("file %q not listed in manifest", name)
+		}
+		sum := sha256.Sum256(files[name])
+		if checksum != hex.EncodeToString(sum[:]) {
+			return errors.Errorf("checksum mismatch for file %q", name)
+		}
+		delete(checksums, name)
+	}
+	if len(checksums) != 0 {
+		missing := make([]string, 0, len(checksums))
+		for name := range checksums {
+			missing = append(missing, name)
+		}
+		sort.Strings(missing)
+		return errors.Errorf("files listed in manifest not found in archive: %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
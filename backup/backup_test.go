@@ -0,0 +1,125 @@
+// Copyright (c) 2020 - for information on the respective copyright owner
+// see the NOTICE file and/or the repository at
+// https://github.com/hyperledger-labs/perun-node
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backup_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger-labs/perun-node/backup"
+)
+
+const passphrase = "backup-passphrase"
+
+func Test_Create_Restore(t *testing.T) {
+	srcDir := tempDir(t)
+	writeTestFile(t, filepath.Join(srcDir, "node.yaml"), "loglevel: debug\n")
+	writeTestFile(t, filepath.Join(srcDir, "keystore", "key-1"), "key-1")
+	writeTestFile(t, filepath.Join(srcDir, "keystore", "sub", "key-2"), "key-2")
+	entries := []backup.Entry{
+		{Name: "config/node.yaml", Path: filepath.Join(srcDir, "node.yaml")},
+		{Name: "keystore", Path: filepath.Join(srcDir, "keystore")},
+	}
+	archive := createArchive(t, entries...)
+
+	t.Run("happy", func(t *testing.T) {
+		destDir := tempDir(t)
+		names, err := backup.Restore(bytes.NewReader(archive), passphrase, destDir)
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []string{
+			"config/node.yaml", "keystore/key-1", "keystore/sub/key-2", backup.ManifestName,
+		}, names)
+
+		assertFileContent(t, filepath.Join(destDir, "config", "node.yaml"), "loglevel: debug\n")
+		assertFileContent(t, filepath.Join(destDir, "keystore", "key-1"), "key-1")
+		assertFileContent(t, filepath.Join(destDir, "keystore", "sub", "key-2"), "key-2")
+	})
+
+	t.Run("err_wrong_passphrase", func(t *testing.T) {
+		destDir := tempDir(t)
+		_, err := backup.Restore(bytes.NewReader(archive), "wrong-passphrase", destDir)
+		require.Error(t, err)
+		assertDirEmpty(t, destDir)
+	})
+
+	t.Run("err_tampered", func(t *testing.T) {
+		tampered := append([]byte{}, archive...)
+		tampered[len(tampered)-1] ^= 0xff
+
+		destDir := tempDir(t)
+		_, err := backup.Restore(bytes.NewReader(tampered), passphrase, destDir)
+		require.Error(t, err)
+		assertDirEmpty(t, destDir)
+	})
+
+	t.Run("err_truncated", func(t *testing.T) {
+		_, err := backup.Restore(bytes.NewReader(archive[:20]), passphrase, tempDir(t))
+		assert.Error(t, err)
+	})
+
+	t.Run("err_not_archive", func(t *testing.T) {
+		_, err := backup.Restore(bytes.NewReader([]byte("not an archive")), passphrase, tempDir(t))
+		assert.Error(t, err)
+	})
+}
+
+func Test_Create(t *testing.T) {
+	t.Run("err_missing_path", func(t *testing.T) {
+		entry := backup.Entry{Name: "db", Path: filepath.Join(tempDir(t), "missing")}
+		assert.Error(t, backup.Create(&bytes.Buffer{}, passphrase, entry))
+	})
+}
+
+func createArchive(t *testing.T, entries ...backup.Entry) []byte {
+	var buf bytes.Buffer
+	require.NoError(t, backup.Create(&buf, passphrase, entries...))
+	return buf.Bytes()
+}
+
+func writeTestFile(t *testing.T, path, content string) {
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o700))
+	require.NoError(t, ioutil.WriteFile(path, []byte(content), 0o600))
+}
+
+func assertFileContent(t *testing.T, path, want string) {
+	content, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, want, string(content))
+}
+
+func assertDirEmpty(t *testing.T, dir string) {
+	files, err := ioutil.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Empty(t, files)
+}
+
+func tempDir(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "")
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		if err = os.RemoveAll(dir); err != nil {
+			t.Log("Error in test cleanup: removing dir - " + dir)
+		}
+	})
+	return dir
+}
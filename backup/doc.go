@@ -0,0 +1,25 @@
+// Copyright (c) 2020 - for information on the respective copyright owner
+// see the NOTICE file and/or the repository at
+// https://github.com/hyperledger-labs/perun-node
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package backup implements encrypted archives for backing up and restoring
+// the data of a node: keystores, persistence database, contacts and config.
+//
+// The files are packed into a gzip compressed tar archive along with a
+// manifest containing the SHA-256 checksum of each file. The archive is
+// then encrypted with AES-256-GCM, using a key derived from the passphrase
+// with scrypt. On restore, the authentication tag of the ciphertext and the
+// checksums of all the files are verified before any file is written.
+package backup
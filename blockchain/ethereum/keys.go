@@ -0,0 +1,49 @@
+// Copyright (c) 2020 - for information on the respective copyright owner
+// see the NOTICE file and/or the repository at
+// https://github.com/hyperledger-labs/perun-node
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ethereum
+
+import (
+	"encoding/hex"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/pkg/errors"
+
+	"github.com/hyperledger-labs/perun-node/blockchain/ethereum/internal"
+)
+
+// NewAccount generates a new key and stores it in the keystore at the given path, encrypted with the password.
+// The keystore directory is created if it does not exist.
+//
+// It returns the address of the account and the hex encoded private key. The private key is returned only for
+// the purpose of pre-funding the account on development chains (such as ganache-cli), it should not be stored
+// or logged otherwise.
+//
+// The function signature uses only types from std lib. This enables the function to be loaded as symbol
+// without importing this package when it is compiled as plugin.
+func NewAccount(keystorePath, password string) (addr, privateKey string, _ error) {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		return "", "", errors.Wrap(err, "generating key")
+	}
+	ks := keystore.NewKeyStore(keystorePath, internal.StandardScryptN, internal.StandardScryptP)
+	acc, err := ks.ImportECDSA(key, password)
+	if err != nil {
+		return "", "", errors.Wrap(err, "storing key in keystore")
+	}
+	return acc.Address.Hex(), "0x" + hex.EncodeToString(crypto.FromECDSA(key)), nil
+}
@@ -0,0 +1,81 @@
+// Copyright (c) 2020 - for information on the respective copyright owner
+// see the NOTICE file and/or the repository at
+// https://github.com/hyperledger-labs/perun-node
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"bytes"
+	"sort"
+	"strings"
+
+	"perun.network/go-perun/channel"
+	"perun.network/go-perun/client"
+	"perun.network/go-perun/wire"
+)
+
+// ChannelFilter selects the channels returned by Client.Channels. Zero value selects all open channels.
+type ChannelFilter struct {
+	// Off-chain address of a peer in the channel, empty for any peer.
+	Peer string
+	// Phases of the channel, empty for any phase.
+	Phases []channel.Phase
+}
+
+// listedChannel is the part of the channel api in go-perun used for filtering channels.
+type listedChannel interface {
+	Peers() []wire.Address
+	Phase() channel.Phase
+}
+
+// matches returns true if the channel is selected by the filter.
+func (f ChannelFilter) matches(ch listedChannel) bool {
+	if f.Peer != "" {
+		found := false
+		for _, p := range ch.Peers() {
+			found = found || strings.EqualFold(p.String(), f.Peer)
+		}
+		if !found {
+			return false
+		}
+	}
+	if len(f.Phases) == 0 {
+		return true
+	}
+	phase := ch.Phase()
+	for _, p := range f.Phases {
+		if p == phase {
+			return true
+		}
+	}
+	return false
+}
+
+// Channels returns the open channels of the client selected by the filter, sorted by their ID. This includes
+// the channels restored on start. A channel can be retrieved by its ID using Channel.
+func (c *Client) Channels(filter ChannelFilter) []*client.Channel {
+	open := c.limiter.openChannels()
+	chs := make([]*client.Channel, 0, len(open))
+	for _, ch := range open {
+		if filter.matches(ch) {
+			chs = append(chs, ch)
+		}
+	}
+	sort.Slice(chs, func(i, j int) bool {
+		idI, idJ := chs[i].ID(), chs[j].ID()
+		return bytes.Compare(idI[:], idJ[:]) < 0
+	})
+	return chs
+}
@@ -0,0 +1,58 @@
+// Copyright (c) 2020 - for information on the respective copyright owner
+// see the NOTICE file and/or the repository at
+// https://github.com/hyperledger-labs/perun-node
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"perun.network/go-perun/channel"
+	"perun.network/go-perun/pkg/test"
+	"perun.network/go-perun/wire"
+
+	"github.com/hyperledger-labs/perun-node/blockchain/ethereum/ethereumtest"
+)
+
+type fakeListedChannel struct {
+	peers []wire.Address
+	phase channel.Phase
+}
+
+func (ch fakeListedChannel) Peers() []wire.Address { return ch.peers }
+
+func (ch fakeListedChannel) Phase() channel.Phase { return ch.phase }
+
+func Test_ChannelFilter_matches(t *testing.T) {
+	rng := test.Prng(t)
+	alice, bob := ethereumtest.NewRandomAddress(rng), ethereumtest.NewRandomAddress(rng)
+	ch := fakeListedChannel{peers: []wire.Address{alice}, phase: channel.Acting}
+
+	t.Run("happy_all", func(t *testing.T) {
+		assert.True(t, ChannelFilter{}.matches(ch))
+	})
+	t.Run("happy_peer", func(t *testing.T) {
+		assert.True(t, ChannelFilter{Peer: alice.String()}.matches(ch))
+		assert.True(t, ChannelFilter{Peer: strings.ToLower(alice.String())}.matches(ch))
+		assert.False(t, ChannelFilter{Peer: bob.String()}.matches(ch))
+	})
+	t.Run("happy_phases", func(t *testing.T) {
+		assert.True(t, ChannelFilter{Phases: []channel.Phase{channel.Signing, channel.Acting}}.matches(ch))
+		assert.False(t, ChannelFilter{Phases: []channel.Phase{channel.Registered}}.matches(ch))
+		assert.False(t, ChannelFilter{Peer: bob.String(), Phases: []channel.Phase{channel.Acting}}.matches(ch))
+	})
+}
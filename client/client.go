@@ -18,18 +18,23 @@ package client
 
 import (
 	"context"
+	"math/big"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/pkg/errors"
 	"perun.network/go-perun/channel"
 	"perun.network/go-perun/channel/persistence/keyvalue"
 	"perun.network/go-perun/client"
+	"perun.network/go-perun/pkg/sortedkv"
 	"perun.network/go-perun/pkg/sortedkv/leveldb"
 	"perun.network/go-perun/wire/net"
 
 	"github.com/hyperledger-labs/perun-node"
 	"github.com/hyperledger-labs/perun-node/blockchain/ethereum"
+	"github.com/hyperledger-labs/perun-node/payment"
+	"github.com/hyperledger-labs/perun-node/persistence"
 )
 
 // Client is a wrapper type around the state channel client implementation from go-perun.
@@ -39,8 +44,52 @@ type Client struct {
 	perun.WireBus
 
 	wg *sync.WaitGroup
+	// Listeners for incoming off-chain connections, drained on shutdown if they support it.
+	listeners []net.Listener
+
+	// Set to 1 when the client is shutting down. Should be accessed atomically.
+	draining int32
+	// Number of incoming updates currently being handled. Should be accessed atomically.
+	updatesInProgress int32
+	// Set to 1 when the client is in maintenance mode. Should be accessed atomically.
+	maintenance int32
+
+	limiter   limiter
+	timeCheck func() error
+	diskCheck func() error
+	onPayment func(payment.Received) *payment.Receipt
+
+	checkInvariants bool
+	invariants      invariants
+	statusSubs      statusSubs
+	idle            idleTracker
+	watchdog        watchdog
+	closing         *closingModes
+	db              sortedkv.Database
 }
 
+const (
+	// responseTimeout is the timeout used when responding to incoming proposals and updates.
+	responseTimeout = 10 * time.Second
+
+	// drainPollInterval is the interval at which the count of in-progress updates is checked during shutdown.
+	drainPollInterval = 10 * time.Millisecond
+)
+
+// ErrMaintenanceMode is returned when opening a new channel is refused because the client is in
+// maintenance mode.
+var ErrMaintenanceMode = errors.New("node is in maintenance mode")
+
+// ErrShuttingDown is returned when opening a new channel is refused because the client is shutting down.
+var ErrShuttingDown = errors.New("node is shutting down")
+
+// ErrProposalsNotSupported is the reason for rejecting the incoming channel proposals that pass all the checks,
+// as accepting them is not implemented yet.
+var ErrProposalsNotSupported = errors.New("incoming channel proposals are not supported by this node")
+
+// ErrChannelNotFound is returned when there is no open channel with the given ID.
+var ErrChannelNotFound = errors.New("channel not found")
+
 // NewEthereumPaymentClient initializes a two party, ethereum payment channel client for the given user.
 // It establishes a connection to the blockchain and verifies the integrity of contracts at the given address.
 // It uses the comm backend to initialize adapters for off-chain communication network.
@@ -54,31 +103,116 @@ func NewEthereumPaymentClient(cfg Config, user perun.User, comm perun.CommBacken
 		return nil, errors.WithMessage(err, "off-chain account")
 	}
 	msgBus := net.NewBus(offChainAcc, comm.NewDialer())
-
-	c, err := client.New(offChainAcc.Address(), msgBus, funder, adjudicator, user.OffChain.Wallet)
-	if err != nil {
+	var c *client.Client
+	var db sortedkv.Database
+	// Release the resources acquired so far if any of the steps below fails, all of them assign their error to err.
+	defer func() {
+		if err != nil {
+			closeOnError(c, msgBus, db)
+		}
+	}()
+	bus := receiptBus{Bus: msgBus, onReceipt: cfg.OnReceipt}
+	if c, err = client.New(offChainAcc.Address(), bus, funder, adjudicator, user.OffChain.Wallet); err != nil {
 		return nil, errors.Wrap(err, "initializing state channel client")
 	}
-	if err = loadPersister(c, cfg.DatabaseDir, cfg.PeerReconnTimeout); err != nil {
+	if db, err = openDatabase(cfg.DatabaseDir); err != nil {
 		return nil, err
 	}
-
 	client := &Client{
 		ChannelClient: c,
 		WireBus:       msgBus,
+		db:            db,
 		wg:            &sync.WaitGroup{},
+		timeCheck:     cfg.TimeCheck,
+		diskCheck:     cfg.DiskCheck,
+		onPayment:     cfg.OnPayment,
+
+		checkInvariants: cfg.CheckInvariants,
+	}
+	client.limiter.limits = cfg.Limits
+	client.limiter.self = offChainAcc.Address().String()
+	client.idle.policy, client.idle.now = cfg.IdlePolicy, cfg.Now
+	// Registered before restoring, so that the restored channels are also counted for limits and checked
+	// for invariants.
+	c.OnNewChannel(client.onNewChannel)
+	if client.closing, err = loadClosingModes(client.db, cfg.ClosingMode); err != nil {
+		return nil, err
+	}
+	if err = loadPersister(c, client.db, cfg.PeerReconnTimeout); err != nil {
+		return nil, err
 	}
 
-	listener, err := comm.NewListener(user.CommAddr)
+	listeners, err := newListeners(comm, user)
 	if err != nil {
 		return nil, err
 	}
-	client.runAsGoRoutine(func() { client.Handle(&ProposalHandler{}, &UpdateHandler{}) })
-	client.runAsGoRoutine(func() { msgBus.Listen(listener) })
+	client.runAsGoRoutine(func() { client.Handle(&ProposalHandler{client: client}, &UpdateHandler{client: client}) })
+	client.listeners = listeners
+	for _, listener := range listeners {
+		listener := listener
+		client.runAsGoRoutine(func() { msgBus.Listen(listener) })
+	}
 
 	return client, nil
 }
 
+// closeOnError closes the channel client, message bus and database (those that were initialized) when initializing
+// the client fails. Errors in closing are ignored, as the error in initializing the client is returned.
+func closeOnError(c *client.Client, msgBus *net.Bus, db sortedkv.Database) {
+	if c != nil {
+		c.Close() // nolint: errcheck, gosec  // error in initializing the client is returned.
+	}
+	msgBus.Close() // nolint: errcheck, gosec  // error in initializing the client is returned.
+	if db != nil {
+		db.Close() // nolint: errcheck, gosec  // error in initializing the client is returned.
+	}
+}
+
+// newListeners returns a listener for each of the listen addresses of the user (or its comm address, if there
+// are none). If any of them cannot be created, the ones already created are closed.
+func newListeners(comm perun.CommBackend, user perun.User) ([]net.Listener, error) {
+	addrs := user.ListenAddrs
+	if len(addrs) == 0 {
+		addrs = []string{user.CommAddr}
+	}
+	listeners := make([]net.Listener, 0, len(addrs))
+	for _, addr := range addrs {
+		listener, err := comm.NewListener(addr)
+		if err != nil {
+			for _, l := range listeners {
+				l.Close() // nolint: errcheck, gosec  // error in creating the listener is returned.
+			}
+			return nil, errors.WithMessage(err, addr)
+		}
+		listeners = append(listeners, listener)
+	}
+	return listeners, nil
+}
+
+// onNewChannel tracks the channel for limits, starts watching it for status changes and for checking the
+// invariants (if enabled), and starts the watcher for disputes on it.
+func (c *Client) onNewChannel(ch *client.Channel) {
+	c.limiter.addChannel(ch)
+	go c.runWatcher(ch)
+	// The states are sent while the channel is locked, so the subscription is read until the channel is closed.
+	updates := make(chan *channel.State, 1)
+	ch.SubUpdates(updates)
+	go c.watchChannel(ch, updates)
+}
+
+// Violations returns the violations of protocol invariants detected in the channels, if checking
+// invariants is enabled. Channels with violations are halted: incoming updates on them are rejected and
+// CheckHalted returns an error for them.
+func (c *Client) Violations() []Violation {
+	return c.invariants.violations()
+}
+
+// CheckHalted returns an ErrChannelHalted if the channel is halted due to a violation of protocol invariants.
+// It should be called before making payments on the channel.
+func (c *Client) CheckHalted(id channel.ID) error {
+	return c.invariants.check(id)
+}
+
 // Close closes the client and waits until the listener and handler go routines return.
 //
 // Close depends on the following mechanisms implemented in client.Close and bus.Close to signal the go-routines:
@@ -95,6 +229,130 @@ func (c *Client) Close() error {
 	return nil
 }
 
+// Database returns the persistence database of the client, it can be used for persisting other data of the node
+// under its own key prefix.
+func (c *Client) Database() sortedkv.Database {
+	return c.db
+}
+
+// Shutdown gracefully shuts down the client.
+//
+// It stops accepting new channels (incoming and outgoing proposals are refused) and new off-chain connections, waits
+// for the pending connections to complete the handshake (for listeners that support draining) and for the
+// incoming updates that are being handled to complete, until the context expires. Then it closes the
+// client, which also shuts down the listener. Since the channel states are persisted continuously, no
+// additional step is required for persisting them.
+//
+// Client is closed even if the context expires before in-progress updates complete. In this case, an
+// error is returned after closing the client.
+func (c *Client) Shutdown(ctx context.Context) error {
+	atomic.StoreInt32(&c.draining, 1)
+	drainErr := c.drainListeners(ctx)
+	if err := c.waitForUpdates(ctx); err != nil && drainErr == nil {
+		drainErr = err
+	}
+	if err := c.Close(); err != nil {
+		return err
+	}
+	return drainErr
+}
+
+// drainer is implemented by listeners that can stop accepting new connections and wait for the pending
+// ones to complete the handshake, such as the tcp listener.
+type drainer interface {
+	Drain(ctx context.Context) error
+}
+
+// drainListeners drains the listeners that support it. Others are shut down when the client is closed.
+func (c *Client) drainListeners(ctx context.Context) error {
+	var err error
+	for _, l := range c.listeners {
+		if d, ok := l.(drainer); ok {
+			if drainErr := d.Drain(ctx); drainErr != nil && err == nil {
+				err = errors.WithMessage(drainErr, "draining listener")
+			}
+		}
+	}
+	return err
+}
+
+// ProposeChannel proposes a new channel, if it can be opened without exceeding the limits configured
+// for the client. Else, an ErrLimitExceeded is returned. The proposal is also refused if the client is
+// shutting down, in maintenance mode or if the time or disk check configured for the client fails.
+func (c *Client) ProposeChannel(ctx context.Context, req *client.ChannelProposal) (*client.Channel, error) {
+	if c.isDraining() {
+		return nil, ErrShuttingDown
+	}
+	if c.InMaintenance() {
+		return nil, ErrMaintenanceMode
+	}
+	if err := c.checkTime(); err != nil {
+		return nil, errors.WithMessage(err, "refusing to propose channel")
+	}
+	if err := c.checkDisk(); err != nil {
+		return nil, errors.WithMessage(err, "refusing to propose channel")
+	}
+	if err := c.limiter.acquireProposal(req.PeerAddrs); err != nil {
+		return nil, err
+	}
+	defer c.limiter.releaseProposal()
+	return c.ChannelClient.ProposeChannel(ctx, req)
+}
+
+// SetMaintenance enables or disables the maintenance mode.
+//
+// In maintenance mode, opening new channels is refused: outgoing proposals fail with ErrMaintenanceMode and
+// incoming proposals are rejected. Updates, disputes and closing of the existing channels continue to be
+// handled, so that the node can be maintained without risking the funds in open channels.
+func (c *Client) SetMaintenance(enabled bool) {
+	var value int32
+	if enabled {
+		value = 1
+	}
+	atomic.StoreInt32(&c.maintenance, value)
+}
+
+// Peers returns the off-chain addresses of the peers with which the client has open channels, in sorted order.
+func (c *Client) Peers() []string {
+	return c.limiter.peers()
+}
+
+// InMaintenance returns true if the client is in maintenance mode.
+func (c *Client) InMaintenance() bool {
+	return atomic.LoadInt32(&c.maintenance) == 1
+}
+
+func (c *Client) checkTime() error {
+	if c.timeCheck == nil {
+		return nil
+	}
+	return c.timeCheck()
+}
+
+func (c *Client) checkDisk() error {
+	if c.diskCheck == nil {
+		return nil
+	}
+	return c.diskCheck()
+}
+
+func (c *Client) isDraining() bool {
+	return atomic.LoadInt32(&c.draining) == 1
+}
+
+func (c *Client) waitForUpdates(ctx context.Context) error {
+	ticker := time.NewTicker(drainPollInterval)
+	defer ticker.Stop()
+	for atomic.LoadInt32(&c.updatesInProgress) > 0 {
+		select {
+		case <-ctx.Done():
+			return errors.Wrap(ctx.Err(), "waiting for in-progress updates to complete")
+		case <-ticker.C:
+		}
+	}
+	return nil
+}
+
 func connectToChain(cfg ChainConfig, cred perun.Credential) (channel.Funder, channel.Adjudicator, error) {
 	walletBackend := ethereum.NewWalletBackend()
 	assetAddr, err := walletBackend.ParseAddr(cfg.Asset)
@@ -114,11 +372,19 @@ func connectToChain(cfg ChainConfig, cred perun.Credential) (channel.Funder, cha
 	return chain.NewFunder(assetAddr), chain.NewAdjudicator(adjudicatorAddr, cred.Addr), err
 }
 
-func loadPersister(c *client.Client, dbPath string, reconnTimeout time.Duration) error {
+// openDatabase migrates the persistence database at the path to the latest schema version and opens it.
+func openDatabase(dbPath string) (sortedkv.Database, error) {
+	if err := persistence.Migrate(dbPath, persistence.Migrations); err != nil {
+		return nil, errors.WithMessage(err, "migrating persistence database")
+	}
 	db, err := leveldb.LoadDatabase(dbPath)
 	if err != nil {
-		return errors.Wrap(err, "initializing persistence database in dir - "+dbPath)
+		return nil, errors.Wrap(err, "initializing persistence database in dir - "+dbPath)
 	}
+	return db, nil
+}
+
+func loadPersister(c *client.Client, db sortedkv.Database, reconnTimeout time.Duration) error {
 	pr := keyvalue.NewPersistRestorer(db)
 	c.EnablePersistence(pr)
 	ctx, cancel := context.WithTimeout(context.Background(), reconnTimeout)
@@ -135,23 +401,144 @@ func (c *Client) runAsGoRoutine(f func()) {
 }
 
 // ProposalHandler implements the handler for incoming channel proposals.
-type ProposalHandler struct{}
+type ProposalHandler struct {
+	client *Client
+}
 
 // HandleProposal implements the client.ProposalHandler interface defined in go-perun.
 // This method is called on every incoming channel proposal.
-// TODO: (mano) Implement an accept all handler until user api components are implemented.
-// TODO: (mano) Replace with proper implementation after user api components are implemented.
-func (ph *ProposalHandler) HandleProposal(_ *client.ChannelProposal, _ *client.ProposalResponder) {
-	panic("proposalHandler.HandleProposal not implemented")
+//
+// Proposals are rejected when the client is shutting down or in maintenance mode, if accepting it would
+// exceed the limits configured for the client or if the time or disk check fails. As accepting proposals is not
+// implemented yet, the proposals passing these checks are rejected with ErrProposalsNotSupported. They still
+// count as pending until rejected, so that the limit on pending proposals also bounds the responses in progress.
+func (ph *ProposalHandler) HandleProposal(proposal *client.ChannelProposal, responder *client.ProposalResponder) {
+	if ph.client.isDraining() {
+		ph.reject(responder, ErrShuttingDown.Error())
+		return
+	}
+	if ph.client.InMaintenance() {
+		ph.reject(responder, ErrMaintenanceMode.Error())
+		return
+	}
+	if err := ph.client.checkTime(); err != nil {
+		ph.reject(responder, err.Error())
+		return
+	}
+	if err := ph.client.checkDisk(); err != nil {
+		ph.reject(responder, err.Error())
+		return
+	}
+	if err := ph.client.limiter.acquireProposal(proposal.PeerAddrs); err != nil {
+		ph.reject(responder, err.Error())
+		return
+	}
+	defer ph.client.limiter.releaseProposal()
+	ph.reject(responder, ErrProposalsNotSupported.Error())
+}
+
+func (ph *ProposalHandler) reject(responder *client.ProposalResponder, reason string) {
+	ctx, cancel := context.WithTimeout(context.Background(), responseTimeout)
+	defer cancel()
+	if err := responder.Reject(ctx, reason); err != nil {
+		ph.client.Log().Error("Rejecting channel proposal: ", err)
+	}
 }
 
 // UpdateHandler implements the handler for incoming state updates.
-type UpdateHandler struct{}
+type UpdateHandler struct {
+	client *Client
+}
 
 // HandleUpdate implements the UpdateHandler interface.
 // This method is called on every incoming state update for any channel managed by this client.
-// TODO: (mano) Implement an accept all handler until user api components are implemented.
-// TODO: (mano) Replace with proper implementation after user api components are implemented.
-func (uh *UpdateHandler) HandleUpdate(_ client.ChannelUpdate, _ *client.UpdateResponder) {
-	panic("updateHandler.HandleUpdate not implemented")
+//
+// Updates are handled even when the client is shutting down and the client waits for them to
+// complete before closing. Updates are rejected if the disk check fails, as accepting them requires
+// the new state to be persisted.
+//
+// Only payments to this client (see payment.ValidateIncoming) are accepted, all other updates are rejected.
+func (uh *UpdateHandler) HandleUpdate(up client.ChannelUpdate, responder *client.UpdateResponder) {
+	atomic.AddInt32(&uh.client.updatesInProgress, 1)
+	defer atomic.AddInt32(&uh.client.updatesInProgress, -1)
+
+	ctx, cancel := context.WithTimeout(context.Background(), responseTimeout)
+	defer cancel()
+	if up.State.IsFinal {
+		uh.handleFinal(ctx, up, responder)
+		return
+	}
+	amount, err := uh.validateUpdate(up)
+	if err != nil {
+		if rejectErr := responder.Reject(ctx, err.Error()); rejectErr != nil {
+			uh.client.Log().Error("Rejecting channel update: ", rejectErr)
+		}
+		return
+	}
+	if err = responder.Accept(ctx); err != nil {
+		uh.client.Log().Error("Accepting channel update: ", err)
+		return
+	}
+	if uh.client.onPayment == nil {
+		return
+	}
+	receipt := uh.client.onPayment(payment.Received{
+		Channel: up.State.ID,
+		Asset:   payment.Asset(up.State),
+		Amount:  amount,
+		Version: up.State.Version,
+	})
+	if receipt != nil {
+		uh.client.runAsGoRoutine(func() { uh.client.sendReceipt(up.State.ID, *receipt) })
+	}
+}
+
+// handleFinal handles the request from the peer to finalize the channel, as per the closing mode of the channel.
+// If it is accepted, the channel is settled right after.
+func (uh *UpdateHandler) handleFinal(ctx context.Context, up client.ChannelUpdate, responder *client.UpdateResponder) {
+	ch, err := uh.validateFinal(up)
+	if err != nil {
+		if rejectErr := responder.Reject(ctx, err.Error()); rejectErr != nil {
+			uh.client.Log().Error("Rejecting final channel update: ", rejectErr)
+		}
+		return
+	}
+	if err = responder.Accept(ctx); err != nil {
+		uh.client.Log().Error("Accepting final channel update: ", err)
+		return
+	}
+	go uh.client.settleFinal(ch)
+}
+
+// validateFinal checks if the final update can be accepted and returns the channel.
+func (uh *UpdateHandler) validateFinal(up client.ChannelUpdate) (*client.Channel, error) {
+	if uh.client.closing.get(up.State.ID) != ClosingModeAuto {
+		return nil, errors.New("closing mode of the channel is manual, cooperative close is not accepted")
+	}
+	if err := uh.client.checkDisk(); err != nil {
+		return nil, err
+	}
+	if err := uh.client.CheckHalted(up.State.ID); err != nil {
+		return nil, err
+	}
+	ch, err := uh.client.Channel(up.State.ID)
+	if err != nil {
+		return nil, err
+	}
+	return ch, checkFinal(ch.State(), up.State)
+}
+
+// validateUpdate checks if the update can be accepted and returns the amount received in it.
+func (uh *UpdateHandler) validateUpdate(up client.ChannelUpdate) (*big.Int, error) {
+	if err := uh.client.checkDisk(); err != nil {
+		return nil, err
+	}
+	if err := uh.client.CheckHalted(up.State.ID); err != nil {
+		return nil, err
+	}
+	ch, err := uh.client.Channel(up.State.ID)
+	if err != nil {
+		return nil, err
+	}
+	return payment.ValidateIncoming(ch.State(), up.State, ch.Idx())
 }
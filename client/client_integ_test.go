@@ -19,6 +19,7 @@
 package client_test
 
 import (
+	"context"
 	"errors"
 	"io/ioutil"
 	"math/rand"
@@ -70,6 +71,15 @@ func Test_Integ_NewEthereumPaymentClient(t *testing.T) {
 		assert.NoError(t, client.Close())
 	})
 
+	t.Run("happy_shutdown", func(t *testing.T) {
+		cfg.DatabaseDir = newDatabaseDir(t) // start with empty persistence dir each time.
+		client, err := client.NewEthereumPaymentClient(cfg, user, tcp.NewTCPBackend(5*time.Second))
+		require.NoError(t, err)
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		assert.NoError(t, client.Shutdown(ctx))
+	})
+
 	t.Run("err_invalid_listener", func(t *testing.T) {
 		invalidCfg := cfg
 		invalidCfg.DatabaseDir = newDatabaseDir(t) // start with empty persistence dir each time.
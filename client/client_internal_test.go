@@ -0,0 +1,81 @@
+// Copyright (c) 2020 - for information on the respective copyright owner
+// see the NOTICE file and/or the repository at
+// https://github.com/hyperledger-labs/perun-node
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"fmt"
+	stdnet "net"
+	"testing"
+	"time"
+
+	"github.com/phayes/freeport"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"perun.network/go-perun/wire/net"
+
+	"github.com/hyperledger-labs/perun-node"
+	"github.com/hyperledger-labs/perun-node/comm/tcp"
+)
+
+func Test_newListeners(t *testing.T) {
+	comm := tcp.NewTCPBackend(time.Second)
+	newAddr := func(t *testing.T) string {
+		port, err := freeport.GetFreePort()
+		require.NoError(t, err)
+		return fmt.Sprintf("127.0.0.1:%d", port)
+	}
+
+	t.Run("happy", func(t *testing.T) {
+		listeners, err := newListeners(comm, perun.User{ListenAddrs: []string{newAddr(t), newAddr(t)}})
+		require.NoError(t, err)
+		assert.Len(t, listeners, 2)
+		for _, l := range listeners {
+			assert.NoError(t, l.Close())
+		}
+	})
+
+	t.Run("happy_comm_addr", func(t *testing.T) {
+		listeners, err := newListeners(comm, perun.User{Peer: perun.Peer{CommAddr: newAddr(t)}})
+		require.NoError(t, err)
+		require.Len(t, listeners, 1)
+		assert.NoError(t, listeners[0].Close())
+	})
+
+	t.Run("err_invalid_addr", func(t *testing.T) {
+		addr := newAddr(t)
+		_, err := newListeners(comm, perun.User{ListenAddrs: []string{addr, "invalid-addr"}})
+		require.Error(t, err)
+
+		// Listener created for the first address should have been closed.
+		listener, err := comm.NewListener(addr)
+		require.NoError(t, err)
+		assert.NoError(t, listener.Close())
+	})
+}
+
+func Test_Client_drainListeners(t *testing.T) {
+	listener, err := tcp.NewTCPBackend(time.Second).NewListener("127.0.0.1:0")
+	require.NoError(t, err)
+	addr := listener.(*tcp.Listener).Addr().String()
+	c := &Client{listeners: []net.Listener{listener}}
+
+	require.NoError(t, c.drainListeners(context.Background()))
+	_, err = stdnet.Dial("tcp", addr)
+	assert.Error(t, err, "listener should not accept new connections after draining")
+	assert.NoError(t, listener.Close())
+}
@@ -17,10 +17,12 @@
 package client_test
 
 import (
+	"context"
 	"testing"
 
 	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
+	pclient "perun.network/go-perun/client"
 
 	"github.com/hyperledger-labs/perun-node"
 	"github.com/hyperledger-labs/perun-node/client"
@@ -31,6 +33,7 @@ func Test_ChannelClient_Interface(t *testing.T) {
 	assert.Implements(t, (*perun.ChannelClient)(nil), new(client.Client))
 }
 
+// nolint:dupl  // Close has the same error cases as Shutdown.
 func Test_Client_Close(t *testing.T) {
 	// happy path test is covered in integration test, as internal components of
 	// the client should be initialized.
@@ -60,3 +63,63 @@ func Test_Client_Close(t *testing.T) {
 		assert.Error(t, Client.Close())
 	})
 }
+
+// nolint:dupl  // Shutdown closes the client in the end and hence has the same error cases as Close.
+func Test_Client_Shutdown(t *testing.T) {
+	// happy path test is covered in integration test, as internal components of
+	// the client should be initialized.
+	t.Run("err_channelClient_Err", func(t *testing.T) {
+		chClient := &mocks.ChannelClient{}
+		msgBus := &mocks.WireBus{}
+		Client := client.Client{
+			ChannelClient: chClient,
+			WireBus:       msgBus,
+		}
+
+		chClient.On("Close").Return(errors.New("error for test"))
+		msgBus.On("Close").Return(nil)
+		assert.Error(t, Client.Shutdown(context.Background()))
+	})
+
+	t.Run("err_wireBus_Err", func(t *testing.T) {
+		chClient := &mocks.ChannelClient{}
+		msgBus := &mocks.WireBus{}
+		Client := client.Client{
+			ChannelClient: chClient,
+			WireBus:       msgBus,
+		}
+
+		chClient.On("Close").Return(nil)
+		msgBus.On("Close").Return(errors.New("error for test"))
+		assert.Error(t, Client.Shutdown(context.Background()))
+	})
+}
+
+func Test_Client_ProposeChannel(t *testing.T) {
+	// happy path test is covered in integration test, as internal components of
+	// the client should be initialized.
+	t.Run("err_maintenance_mode", func(t *testing.T) {
+		chClient := &mocks.ChannelClient{}
+		Client := client.Client{ChannelClient: chClient}
+		Client.SetMaintenance(true)
+		assert.True(t, Client.InMaintenance())
+
+		_, err := Client.ProposeChannel(context.Background(), &pclient.ChannelProposal{})
+		assert.Equal(t, client.ErrMaintenanceMode, err)
+		chClient.AssertNotCalled(t, "ProposeChannel")
+	})
+
+	t.Run("err_shutting_down", func(t *testing.T) {
+		chClient := &mocks.ChannelClient{}
+		msgBus := &mocks.WireBus{}
+		Client := client.Client{ChannelClient: chClient, WireBus: msgBus}
+		// Closing fails, as internal components of the client are not initialized. Proposals are refused anyway.
+		chClient.On("Close").Return(errors.New("error for test"))
+		msgBus.On("Close").Return(nil)
+		assert.Error(t, Client.Shutdown(context.Background()))
+
+		_, err := Client.ProposeChannel(context.Background(), &pclient.ChannelProposal{})
+		assert.Equal(t, client.ErrShuttingDown, err)
+		chClient.AssertNotCalled(t, "ProposeChannel")
+	})
+}
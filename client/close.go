@@ -0,0 +1,88 @@
+// Copyright (c) 2020 - for information on the respective copyright owner
+// see the NOTICE file and/or the repository at
+// https://github.com/hyperledger-labs/perun-node
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"sync"
+
+	"github.com/pkg/errors"
+	"perun.network/go-perun/channel"
+)
+
+// closableChannel is the part of the channel api in go-perun used for closing a channel.
+type closableChannel interface {
+	ID() channel.ID
+	UpdateBy(ctx context.Context, update func(*channel.State)) error
+	Settle(ctx context.Context) error
+	Close() error
+}
+
+// CloseAll closes all the open channels of the client and returns the error (nil if successful) for
+// each channel. Up to workers channels are closed at the same time, a failure in closing one channel
+// does not affect the others.
+//
+// Each channel is first finalized by an off-chain update with the peer. If that fails (for example,
+// because the peer is not reachable), the latest state is registered on the blockchain and the funds
+// are withdrawn after the challenge duration.
+func (c *Client) CloseAll(ctx context.Context, workers int) map[channel.ID]error {
+	open := c.limiter.openChannels()
+	chs := make([]closableChannel, len(open))
+	for i := range open {
+		chs[i] = open[i]
+	}
+	return closeChannels(ctx, chs, workers)
+}
+
+// closeChannels closes the channels using a pool of workers and returns the error for each channel.
+func closeChannels(ctx context.Context, chs []closableChannel, workers int) map[channel.ID]error {
+	if workers < 1 {
+		workers = 1
+	}
+	queue := make(chan closableChannel)
+	var mutex sync.Mutex
+	results := make(map[channel.ID]error, len(chs))
+
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go func() {
+			defer wg.Done()
+			for ch := range queue {
+				err := closeChannel(ctx, ch)
+				mutex.Lock()
+				results[ch.ID()] = err
+				mutex.Unlock()
+			}
+		}()
+	}
+	for _, ch := range chs {
+		queue <- ch
+	}
+	close(queue)
+	wg.Wait()
+	return results
+}
+
+func closeChannel(ctx context.Context, ch closableChannel) error {
+	// Error in finalizing is ignored, as the channel can be settled with the latest state.
+	ch.UpdateBy(ctx, func(state *channel.State) { state.IsFinal = true }) // nolint: errcheck, gosec
+	if err := ch.Settle(ctx); err != nil {
+		return errors.WithMessage(err, "settling channel")
+	}
+	return errors.Wrap(ch.Close(), "closing channel")
+}
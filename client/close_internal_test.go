@@ -0,0 +1,119 @@
+// Copyright (c) 2020 - for information on the respective copyright owner
+// see the NOTICE file and/or the repository at
+// https://github.com/hyperledger-labs/perun-node
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"perun.network/go-perun/channel"
+)
+
+type fakeChannel struct {
+	id        channel.ID
+	updateErr error
+	settleErr error
+
+	active, maxActive *int32 // Number of channels being settled, shared by all channels in a test.
+	final, closed     bool
+}
+
+func (ch *fakeChannel) ID() channel.ID { return ch.id }
+
+func (ch *fakeChannel) UpdateBy(_ context.Context, update func(*channel.State)) error {
+	if ch.updateErr != nil {
+		return ch.updateErr
+	}
+	state := &channel.State{}
+	update(state)
+	ch.final = state.IsFinal
+	return nil
+}
+
+func (ch *fakeChannel) Settle(context.Context) error {
+	active := atomic.AddInt32(ch.active, 1)
+	defer atomic.AddInt32(ch.active, -1)
+	for {
+		max := atomic.LoadInt32(ch.maxActive)
+		if active <= max || atomic.CompareAndSwapInt32(ch.maxActive, max, active) {
+			break
+		}
+	}
+	time.Sleep(10 * time.Millisecond)
+	return ch.settleErr
+}
+
+func (ch *fakeChannel) Close() error {
+	ch.closed = true
+	return nil
+}
+
+func Test_closeChannels(t *testing.T) {
+	setup := func(n int) []*fakeChannel {
+		var active, maxActive int32
+		chs := make([]*fakeChannel, n)
+		for i := range chs {
+			chs[i] = &fakeChannel{id: channel.ID{byte(i)}, active: &active, maxActive: &maxActive}
+		}
+		return chs
+	}
+	closeAll := func(chs []*fakeChannel, workers int) map[channel.ID]error {
+		closables := make([]closableChannel, len(chs))
+		for i := range chs {
+			closables[i] = chs[i]
+		}
+		return closeChannels(context.Background(), closables, workers)
+	}
+
+	t.Run("happy", func(t *testing.T) {
+		chs := setup(10)
+		results := closeAll(chs, 3)
+		require.Len(t, results, 10)
+		for _, ch := range chs {
+			assert.NoError(t, results[ch.id])
+			assert.True(t, ch.final)
+			assert.True(t, ch.closed)
+		}
+		assert.LessOrEqual(t, atomic.LoadInt32(chs[0].maxActive), int32(3), "more channels closed than workers")
+	})
+
+	t.Run("happy_not_finalized", func(t *testing.T) {
+		chs := setup(1)
+		chs[0].updateErr = assert.AnError
+		results := closeAll(chs, 1)
+		assert.NoError(t, results[chs[0].id])
+		assert.False(t, chs[0].final)
+		assert.True(t, chs[0].closed)
+	})
+
+	t.Run("err_isolated", func(t *testing.T) {
+		chs := setup(4)
+		chs[1].settleErr = assert.AnError
+		results := closeAll(chs, 2)
+		require.Len(t, results, 4)
+		assert.Error(t, results[chs[1].id])
+		assert.False(t, chs[1].closed)
+		for _, i := range []int{0, 2, 3} {
+			assert.NoError(t, results[chs[i].id])
+			assert.True(t, chs[i].closed)
+		}
+	})
+}
@@ -0,0 +1,207 @@
+// Copyright (c) 2020 - for information on the respective copyright owner
+// see the NOTICE file and/or the repository at
+// https://github.com/hyperledger-labs/perun-node
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"encoding/hex"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+	"perun.network/go-perun/channel"
+	"perun.network/go-perun/client"
+	"perun.network/go-perun/pkg/sortedkv"
+)
+
+// ClosingMode determines how the requests from the peer to close a channel cooperatively are handled.
+type ClosingMode string
+
+// Closing modes of a channel.
+const (
+	// ClosingModeManual rejects the requests from the peer to finalize the channel. The channel is closed only
+	// when the node operator closes it (or by the idle policy), else the peer has to settle it on the blockchain
+	// with the latest state, which takes the challenge duration.
+	ClosingModeManual ClosingMode = "manual"
+	// ClosingModeAuto accepts the requests from the peer to finalize the channel without changing the balances
+	// and settles the channel immediately after.
+	ClosingModeAuto ClosingMode = "auto"
+)
+
+// closingModeKeyPrefix is the prefix of the keys in the persistence database, under which the closing modes
+// set for the channels are stored.
+const closingModeKeyPrefix = "perun-node:closing-mode:"
+
+// ParseClosingMode parses the closing mode. Empty string is parsed as ClosingModeManual.
+func ParseClosingMode(s string) (ClosingMode, error) {
+	switch mode := ClosingMode(s); mode {
+	case "":
+		return ClosingModeManual, nil
+	case ClosingModeManual, ClosingModeAuto:
+		return mode, nil
+	}
+	return "", errors.Errorf("invalid closing mode %q, should be %s or %s", s, ClosingModeManual, ClosingModeAuto)
+}
+
+// ChannelClosingMode is the closing mode of an open channel.
+type ChannelClosingMode struct {
+	Channel string      `json:"channel"` // ID of the channel as hex string.
+	Mode    ClosingMode `json:"mode"`
+}
+
+// closingModes holds the closing modes set for the channels, overriding the default mode. They are stored in
+// the persistence database, so that they are retained across restarts.
+type closingModes struct {
+	db sortedkv.Database
+
+	mutex       sync.RWMutex
+	defaultMode ClosingMode
+	overrides   map[channel.ID]ClosingMode
+}
+
+// loadClosingModes loads the closing modes set for the channels from the database.
+func loadClosingModes(db sortedkv.Database, defaultMode ClosingMode) (*closingModes, error) {
+	mode, err := ParseClosingMode(string(defaultMode))
+	if err != nil {
+		return nil, err
+	}
+	m := &closingModes{db: db, defaultMode: mode, overrides: make(map[channel.ID]ClosingMode)}
+	it := db.NewIteratorWithPrefix(closingModeKeyPrefix)
+	defer it.Close() // nolint: errcheck  // iterator is used only for reading.
+	for it.Next() {
+		var id channel.ID
+		b, decodeErr := hex.DecodeString(strings.TrimPrefix(it.Key(), closingModeKeyPrefix))
+		if decodeErr != nil || len(b) != len(id) {
+			return nil, errors.Errorf("invalid key %q for closing mode in database", it.Key())
+		}
+		copy(id[:], b)
+		if m.overrides[id], err = ParseClosingMode(it.Value()); err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+func (m *closingModes) get(id channel.ID) ClosingMode {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	if mode, ok := m.overrides[id]; ok {
+		return mode
+	}
+	return m.defaultMode
+}
+
+func (m *closingModes) set(id channel.ID, mode ClosingMode) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	if err := m.db.Put(closingModeKeyPrefix+hex.EncodeToString(id[:]), string(mode)); err != nil {
+		return errors.WithMessage(err, "storing closing mode")
+	}
+	m.overrides[id] = mode
+	return nil
+}
+
+func (m *closingModes) setDefault(mode ClosingMode) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.defaultMode = mode
+}
+
+// remove removes the closing mode set for the channel, once it is closed.
+func (m *closingModes) remove(id channel.ID) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	if _, ok := m.overrides[id]; !ok {
+		return nil
+	}
+	delete(m.overrides, id)
+	return errors.WithMessage(m.db.Delete(closingModeKeyPrefix+hex.EncodeToString(id[:])), "removing closing mode")
+}
+
+// removeClosingMode removes the closing mode set for the channel, once it is closed. Errors are logged.
+func (c *Client) removeClosingMode(id channel.ID) {
+	if c.closing == nil {
+		return
+	}
+	if err := c.closing.remove(id); err != nil {
+		c.Log().Errorf("Channel %x: %v", id, err)
+	}
+}
+
+// ClosingModes returns the closing mode of each open channel, sorted by channel ID.
+func (c *Client) ClosingModes() []ChannelClosingMode {
+	open := c.limiter.openChannels()
+	modes := make([]ChannelClosingMode, len(open))
+	for i, ch := range open {
+		id := ch.ID()
+		modes[i] = ChannelClosingMode{Channel: hex.EncodeToString(id[:]), Mode: c.closing.get(id)}
+	}
+	sort.Slice(modes, func(i, j int) bool { return modes[i].Channel < modes[j].Channel })
+	return modes
+}
+
+// SetClosingMode sets the closing mode of the open channel, overriding the default mode in the config. It is
+// stored in the persistence database. ErrChannelNotFound is returned if there is no open channel with the ID.
+func (c *Client) SetClosingMode(id channel.ID, mode ClosingMode) error {
+	mode, err := ParseClosingMode(string(mode))
+	if err != nil {
+		return err
+	}
+	if ch, chErr := c.Channel(id); chErr != nil || ch.IsClosed() {
+		return ErrChannelNotFound
+	}
+	return c.closing.set(id, mode)
+}
+
+// SetDefaultClosingMode sets the closing mode of the channels for which no mode is set explicitly. It is not
+// stored, as the default is taken from the config.
+func (c *Client) SetDefaultClosingMode(mode ClosingMode) error {
+	mode, err := ParseClosingMode(string(mode))
+	if err != nil {
+		return err
+	}
+	c.closing.setDefault(mode)
+	return nil
+}
+
+// checkFinal checks if the next state only finalizes the channel with the current state, without changing
+// the balances or anything else.
+func checkFinal(current, next *channel.State) error {
+	if current.IsFinal {
+		return errors.New("channel is already finalized")
+	}
+	expected := current.Clone()
+	expected.IsFinal = true
+	expected.Version++
+	return errors.WithMessage(expected.Equal(next), "state should not change other than finalizing it")
+}
+
+// settleFinal settles and closes the channel, after its final state was accepted. Errors are logged, the
+// channel can still be closed by the node operator.
+func (c *Client) settleFinal(ch *client.Channel) {
+	id := ch.ID()
+	if err := ch.Settle(context.Background()); err != nil {
+		c.Log().Errorf("Settling channel %x closed by peer: %v", id, err)
+		return
+	}
+	if err := ch.Close(); err != nil {
+		c.Log().Errorf("Closing channel %x closed by peer: %v", id, err)
+		return
+	}
+	c.Log().Infof("Settled channel %x closed by peer", id)
+}
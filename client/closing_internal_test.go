@@ -0,0 +1,121 @@
+// Copyright (c) 2020 - for information on the respective copyright owner
+// see the NOTICE file and/or the repository at
+// https://github.com/hyperledger-labs/perun-node
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"encoding/hex"
+	"math/big"
+	"testing"
+
+	"github.com/hyperledger-labs/perun-node/blockchain/ethereum/ethereumtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"perun.network/go-perun/channel"
+	"perun.network/go-perun/pkg/sortedkv/memorydb"
+	"perun.network/go-perun/pkg/test"
+)
+
+func Test_ParseClosingMode(t *testing.T) {
+	for input, want := range map[string]ClosingMode{
+		"":       ClosingModeManual,
+		"manual": ClosingModeManual,
+		"auto":   ClosingModeAuto,
+	} {
+		got, err := ParseClosingMode(input)
+		require.NoError(t, err, input)
+		assert.Equal(t, want, got)
+	}
+	_, err := ParseClosingMode("immediate")
+	assert.Error(t, err)
+}
+
+func Test_closingModes(t *testing.T) {
+	t.Run("happy_persisted", func(t *testing.T) {
+		db := memorydb.NewDatabase()
+		modes, err := loadClosingModes(db, "")
+		require.NoError(t, err)
+		assert.Equal(t, ClosingModeManual, modes.get(channel.ID{1}))
+		require.NoError(t, modes.set(channel.ID{1}, ClosingModeAuto))
+		require.NoError(t, modes.set(channel.ID{2}, ClosingModeAuto))
+		require.NoError(t, modes.remove(channel.ID{2}))
+		require.NoError(t, modes.remove(channel.ID{3}))
+
+		reloaded, err := loadClosingModes(db, ClosingModeManual)
+		require.NoError(t, err)
+		assert.Equal(t, ClosingModeAuto, reloaded.get(channel.ID{1}))
+		assert.Equal(t, ClosingModeManual, reloaded.get(channel.ID{2}))
+	})
+	t.Run("happy_default_auto", func(t *testing.T) {
+		modes, err := loadClosingModes(memorydb.NewDatabase(), ClosingModeAuto)
+		require.NoError(t, err)
+		assert.Equal(t, ClosingModeAuto, modes.get(channel.ID{1}))
+	})
+	t.Run("happy_set_default", func(t *testing.T) {
+		modes, err := loadClosingModes(memorydb.NewDatabase(), ClosingModeManual)
+		require.NoError(t, err)
+		require.NoError(t, modes.set(channel.ID{1}, ClosingModeManual))
+		c := &Client{closing: modes}
+
+		require.NoError(t, c.SetDefaultClosingMode(ClosingModeAuto))
+		assert.Equal(t, ClosingModeAuto, modes.get(channel.ID{2}))
+		assert.Equal(t, ClosingModeManual, modes.get(channel.ID{1}), "override should be retained")
+		assert.Error(t, c.SetDefaultClosingMode("immediate"))
+		assert.Equal(t, ClosingModeAuto, modes.get(channel.ID{2}))
+	})
+	t.Run("err_invalid", func(t *testing.T) {
+		_, err := loadClosingModes(memorydb.NewDatabase(), "immediate")
+		assert.Error(t, err)
+
+		db := memorydb.NewDatabase()
+		id := channel.ID{1}
+		require.NoError(t, db.Put(closingModeKeyPrefix+hex.EncodeToString(id[:]), "immediate"))
+		_, err = loadClosingModes(db, "")
+		assert.Error(t, err)
+	})
+}
+
+func Test_checkFinal(t *testing.T) {
+	rng := test.Prng(t)
+	current := &channel.State{
+		ID:      channel.ID{1},
+		Version: 5,
+		App:     channel.NewMockApp(ethereumtest.NewRandomAddress(rng)),
+		Data:    channel.NewMockOp(channel.OpValid),
+		Allocation: channel.Allocation{
+			Assets:   []channel.Asset{nil},
+			Balances: [][]channel.Bal{{big.NewInt(10), big.NewInt(10)}},
+		},
+	}
+	final := func() *channel.State {
+		next := current.Clone()
+		next.IsFinal = true
+		next.Version++
+		return next
+	}
+
+	t.Run("happy", func(t *testing.T) {
+		assert.NoError(t, checkFinal(current, final()))
+	})
+	t.Run("err_balances_changed", func(t *testing.T) {
+		next := final()
+		next.Balances[0][0] = big.NewInt(5)
+		assert.Error(t, checkFinal(current, next))
+	})
+	t.Run("err_already_final", func(t *testing.T) {
+		assert.Error(t, checkFinal(final(), final()))
+	})
+}
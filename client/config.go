@@ -16,7 +16,11 @@
 
 package client
 
-import "time"
+import (
+	"time"
+
+	"github.com/hyperledger-labs/perun-node/payment"
+)
 
 // Config represents the configuration parameters for state channel client.
 type Config struct {
@@ -27,6 +31,32 @@ type Config struct {
 	// Timeout for re-establishing all open channels (if any) that was persisted during the
 	// previous running instance of the node.
 	PeerReconnTimeout time.Duration
+
+	// Limits on the resources used by the client.
+	Limits Limits
+
+	// TimeCheck (if not nil) is called before timeout-sensitive actions such as proposing or accepting
+	// a channel. If it returns an error, the action is refused.
+	TimeCheck func() error
+	// DiskCheck (if not nil) is called before actions that require new data to be persisted, such as
+	// proposing or accepting a channel and accepting an update. If it returns an error, the action is refused.
+	DiskCheck func() error
+	// OnPayment (if not nil) is called after a payment received on a channel is accepted. If it returns a
+	// receipt for the payment, the receipt is sent to the payer.
+	OnPayment func(payment.Received) *payment.Receipt
+	// OnReceipt (if not nil) is called with the receipts sent by the peers for the payments made to them.
+	OnReceipt func(payment.Receipt)
+	// If true, the protocol invariants are checked on each new state of the channels and the channels
+	// violating them are halted. See Client.Violations.
+	CheckInvariants bool
+	// Policy for closing the channels that have not been updated for a while, see Client.CloseIdle. It can be
+	// overridden for each channel using Client.SetIdlePolicy.
+	IdlePolicy IdlePolicy
+	// Default closing mode of the channels, ClosingModeManual if empty. It can be overridden for each channel
+	// using Client.SetClosingMode.
+	ClosingMode ClosingMode
+	// Now (if not nil) is used as the source of time for the idle policy. Defaults to time.Now.
+	Now func() time.Time
 }
 
 // ChainConfig represents the configuration parameters for connecting to blockchain.
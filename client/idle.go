@@ -0,0 +1,184 @@
+// Copyright (c) 2020 - for information on the respective copyright owner
+// see the NOTICE file and/or the repository at
+// https://github.com/hyperledger-labs/perun-node
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"bytes"
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"perun.network/go-perun/channel"
+)
+
+// IdlePolicy is the policy for closing the channels that have not been updated for a while.
+type IdlePolicy struct {
+	// Duration without updates after which the channel is closed. Zero means the channel is not closed when idle.
+	Timeout time.Duration
+	// Duration before the deadline from which a warning is logged, so that the channel can be kept open by
+	// updating it. Zero means no warning.
+	WarnBefore time.Duration
+}
+
+// IdleChannel is the idle status of an open channel.
+type IdleChannel struct {
+	Channel    channel.ID
+	LastUpdate time.Time
+	Policy     IdlePolicy
+	// Time at which the channel is closed if not updated, zero if it is not closed when idle.
+	Deadline time.Time
+}
+
+// idleTracker tracks the time of last update on the open channels, for enforcing the idle policy on them.
+// Channels restored on start are considered to be updated at the time they are restored.
+type idleTracker struct {
+	now    func() time.Time
+	policy IdlePolicy // Default policy, used for channels without an override.
+
+	mutex      sync.Mutex
+	overrides  map[channel.ID]IdlePolicy
+	lastUpdate map[channel.ID]time.Time
+	warned     map[channel.ID]bool
+}
+
+func (t *idleTracker) currentTime() time.Time {
+	if t.now == nil {
+		return time.Now()
+	}
+	return t.now()
+}
+
+// touch records an update on the channel.
+func (t *idleTracker) touch(id channel.ID) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	if t.lastUpdate == nil {
+		t.lastUpdate = make(map[channel.ID]time.Time)
+		t.overrides = make(map[channel.ID]IdlePolicy)
+		t.warned = make(map[channel.ID]bool)
+	}
+	t.lastUpdate[id] = t.currentTime()
+	delete(t.warned, id)
+}
+
+// remove stops tracking the channel, once it is closed.
+func (t *idleTracker) remove(id channel.ID) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	delete(t.lastUpdate, id)
+	delete(t.overrides, id)
+	delete(t.warned, id)
+}
+
+// setPolicy overrides the default policy for the channel.
+func (t *idleTracker) setPolicy(id channel.ID, policy IdlePolicy) error {
+	if policy.Timeout < 0 || policy.WarnBefore < 0 {
+		return errors.New("durations in idle policy must not be negative")
+	}
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	if _, ok := t.lastUpdate[id]; !ok {
+		return ErrChannelNotFound
+	}
+	t.overrides[id] = policy
+	delete(t.warned, id)
+	return nil
+}
+
+// channels returns the idle status of the tracked channels, sorted by their ID. It should be called with
+// mutex locked.
+func (t *idleTracker) channels() []IdleChannel {
+	chs := make([]IdleChannel, 0, len(t.lastUpdate))
+	for id, lastUpdate := range t.lastUpdate {
+		policy, ok := t.overrides[id]
+		if !ok {
+			policy = t.policy
+		}
+		ch := IdleChannel{Channel: id, LastUpdate: lastUpdate, Policy: policy}
+		if policy.Timeout > 0 {
+			ch.Deadline = lastUpdate.Add(policy.Timeout)
+		}
+		chs = append(chs, ch)
+	}
+	sort.Slice(chs, func(i, j int) bool { return bytes.Compare(chs[i].Channel[:], chs[j].Channel[:]) < 0 })
+	return chs
+}
+
+// status returns the idle status of the open channels, sorted by their ID.
+func (t *idleTracker) status() []IdleChannel {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	return t.channels()
+}
+
+// due returns the channels that are past their deadline and those that are within the warning period.
+// A channel is returned for warning only once after each update.
+func (t *idleTracker) due() (expired []channel.ID, warn []IdleChannel) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	now := t.currentTime()
+	for _, ch := range t.channels() {
+		switch {
+		case ch.Deadline.IsZero():
+		case !now.Before(ch.Deadline):
+			expired = append(expired, ch.Channel)
+		case !t.warned[ch.Channel] && ch.Policy.WarnBefore > 0 && !now.Before(ch.Deadline.Add(-ch.Policy.WarnBefore)):
+			t.warned[ch.Channel] = true
+			warn = append(warn, ch)
+		}
+	}
+	return expired, warn
+}
+
+// IdleChannels returns the idle status of the open channels, sorted by their ID.
+func (c *Client) IdleChannels() []IdleChannel {
+	return c.idle.status()
+}
+
+// SetIdlePolicy sets the idle policy for the open channel, overriding the default policy in the config. It returns
+// ErrChannelNotFound if there is no open channel with the ID.
+func (c *Client) SetIdlePolicy(id channel.ID, policy IdlePolicy) error {
+	return c.idle.setPolicy(id, policy)
+}
+
+// CloseIdle closes the channels that have not been updated within the timeout of their idle policy and
+// returns the error (nil if successful) for each of them. Up to workers channels are closed at the same
+// time, see CloseAll for details. For the channels that will be closed within their warning period, a
+// warning is logged.
+func (c *Client) CloseIdle(ctx context.Context, workers int) map[channel.ID]error {
+	expired, warn := c.idle.due()
+	for _, ch := range warn {
+		c.Log().Warnf("Channel %x has not been updated since %v, it will be closed at %v unless updated",
+			ch.Channel, ch.LastUpdate, ch.Deadline)
+	}
+	if len(expired) == 0 {
+		return map[channel.ID]error{}
+	}
+	isExpired := make(map[channel.ID]bool, len(expired))
+	for _, id := range expired {
+		isExpired[id] = true
+	}
+	var chs []closableChannel
+	for _, ch := range c.limiter.openChannels() {
+		if isExpired[ch.ID()] {
+			chs = append(chs, ch)
+		}
+	}
+	return closeChannels(ctx, chs, workers)
+}
@@ -0,0 +1,77 @@
+// Copyright (c) 2020 - for information on the respective copyright owner
+// see the NOTICE file and/or the repository at
+// https://github.com/hyperledger-labs/perun-node
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"perun.network/go-perun/channel"
+)
+
+func Test_idleTracker(t *testing.T) {
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	// newTracker returns a tracker with the given default policy, tracking the channels {1} and {2} updated at
+	// start, along with a function to set its current time.
+	newTracker := func(policy IdlePolicy) (*idleTracker, func(time.Time)) {
+		now := start
+		tracker := &idleTracker{policy: policy, now: func() time.Time { return now }}
+		tracker.touch(channel.ID{1})
+		tracker.touch(channel.ID{2})
+		return tracker, func(t time.Time) { now = t }
+	}
+	policy := IdlePolicy{Timeout: time.Hour, WarnBefore: 10 * time.Minute}
+
+	t.Run("happy_status", func(t *testing.T) {
+		tracker, _ := newTracker(policy)
+		require.NoError(t, tracker.setPolicy(channel.ID{2}, IdlePolicy{}))
+		chs := tracker.status()
+		require.Len(t, chs, 2)
+		assert.Equal(t, IdleChannel{Channel: channel.ID{1}, LastUpdate: start, Policy: policy,
+			Deadline: start.Add(time.Hour)}, chs[0])
+		assert.True(t, chs[1].Deadline.IsZero())
+
+		tracker.remove(channel.ID{1})
+		assert.Len(t, tracker.status(), 1)
+	})
+	t.Run("happy_due", func(t *testing.T) {
+		tracker, setNow := newTracker(policy)
+		setNow(start.Add(30 * time.Minute))
+		expired, warn := tracker.due()
+		assert.Empty(t, expired)
+		assert.Empty(t, warn)
+
+		setNow(start.Add(55 * time.Minute))
+		_, warn = tracker.due()
+		assert.Len(t, warn, 2)
+		_, warn = tracker.due()
+		assert.Empty(t, warn, "warning should be returned only once")
+
+		tracker.touch(channel.ID{2})
+		setNow(start.Add(time.Hour))
+		expired, warn = tracker.due()
+		assert.Equal(t, []channel.ID{{1}}, expired)
+		assert.Empty(t, warn)
+	})
+	t.Run("err_set_policy", func(t *testing.T) {
+		tracker, _ := newTracker(policy)
+		assert.Equal(t, ErrChannelNotFound, tracker.setPolicy(channel.ID{3}, policy))
+		assert.Error(t, tracker.setPolicy(channel.ID{1}, IdlePolicy{Timeout: -time.Hour}))
+	})
+}
@@ -0,0 +1,138 @@
+// Copyright (c) 2020 - for information on the respective copyright owner
+// see the NOTICE file and/or the repository at
+// https://github.com/hyperledger-labs/perun-node
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"perun.network/go-perun/channel"
+
+	"github.com/hyperledger-labs/perun-node/log"
+)
+
+// Violation is a violation of a protocol invariant, detected in the successive states of a channel.
+type Violation struct {
+	Channel string    `json:"channel"` // ID of the channel as hex string.
+	Version uint64    `json:"version"` // Version of the state that violated the invariant.
+	Reason  string    `json:"reason"`
+	At      time.Time `json:"at"`
+}
+
+// ErrChannelHalted is returned for actions on a channel that is halted due to a violation of protocol invariants.
+type ErrChannelHalted struct {
+	Violation
+}
+
+func (e ErrChannelHalted) Error() string {
+	return fmt.Sprintf("channel halted due to invariant violation at version %d: %s", e.Version, e.Reason)
+}
+
+// invariants checks the protocol invariants on the successive states of channels, independent of the checks
+// in the go-perun state machine, for detecting bugs in it before funds are at risk. Channels that violate
+// an invariant are halted: all further updates on them are refused by the node.
+type invariants struct {
+	mutex  sync.Mutex
+	halted map[channel.ID]Violation
+}
+
+// checkUpdate checks the invariants on the transition from the previous to the next state of the channel and
+// halts the channel if any of them is violated. As there is nothing to compare the first received state with,
+// the transition to it (prev is nil) is not checked.
+func (inv *invariants) checkUpdate(prev, next *channel.State, logger log.Logger) {
+	if prev == nil {
+		return
+	}
+	if err := checkTransition(prev, next); err != nil {
+		logger.Errorf("Halting channel %x, invariant violated in transition from version %d to %d: %v",
+			next.ID, prev.Version, next.Version, err)
+		inv.halt(next.ID, next.Version, err)
+	}
+}
+
+func (inv *invariants) halt(id channel.ID, version uint64, reason error) {
+	inv.mutex.Lock()
+	defer inv.mutex.Unlock()
+	if inv.halted == nil {
+		inv.halted = make(map[channel.ID]Violation)
+	}
+	if _, ok := inv.halted[id]; ok {
+		return
+	}
+	inv.halted[id] = Violation{
+		Channel: hex.EncodeToString(id[:]),
+		Version: version,
+		Reason:  reason.Error(),
+		At:      time.Now(),
+	}
+}
+
+// check returns an ErrChannelHalted if the channel is halted.
+func (inv *invariants) check(id channel.ID) error {
+	inv.mutex.Lock()
+	defer inv.mutex.Unlock()
+	if v, ok := inv.halted[id]; ok {
+		return ErrChannelHalted{Violation: v}
+	}
+	return nil
+}
+
+// violations returns the violations of all halted channels, in the order of detection.
+func (inv *invariants) violations() []Violation {
+	inv.mutex.Lock()
+	defer inv.mutex.Unlock()
+	violations := make([]Violation, 0, len(inv.halted))
+	for _, v := range inv.halted {
+		violations = append(violations, v)
+	}
+	sort.Slice(violations, func(i, j int) bool { return violations[i].At.Before(violations[j].At) })
+	return violations
+}
+
+// checkTransition checks the invariants on the transition of a channel from prev to next state.
+func checkTransition(prev, next *channel.State) error {
+	switch {
+	case next.ID != prev.ID:
+		return errors.New("channel ID changed")
+	case prev.IsFinal:
+		return errors.New("final state was updated")
+	case next.Version != prev.Version+1:
+		return errors.Errorf("version changed from %d to %d, instead of increasing by one", prev.Version, next.Version)
+	case len(next.Assets) != len(prev.Assets):
+		return errors.Errorf("number of assets changed from %d to %d", len(prev.Assets), len(next.Assets))
+	case len(next.Balances) != len(next.Assets):
+		return errors.Errorf("balances for %d assets, instead of %d", len(next.Balances), len(next.Assets))
+	}
+	prevSum, nextSum := prev.Allocation.Sum(), next.Allocation.Sum()
+	for i := range prevSum {
+		if prevSum[i].Cmp(nextSum[i]) != 0 {
+			return errors.Errorf("total balance of asset %d changed from %v to %v", i, prevSum[i], nextSum[i])
+		}
+	}
+	for i := range next.Balances {
+		for j, bal := range next.Balances[i] {
+			if bal.Sign() < 0 {
+				return errors.Errorf("negative balance %v of participant %d for asset %d", bal, j, i)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+// Copyright (c) 2020 - for information on the respective copyright owner
+// see the NOTICE file and/or the repository at
+// https://github.com/hyperledger-labs/perun-node
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"perun.network/go-perun/channel"
+)
+
+func Test_checkTransition(t *testing.T) {
+	newState := func(version uint64, bals ...int64) *channel.State {
+		balances := make([]channel.Bal, len(bals))
+		for i := range bals {
+			balances[i] = big.NewInt(bals[i])
+		}
+		return &channel.State{
+			ID:      channel.ID{1},
+			Version: version,
+			Allocation: channel.Allocation{
+				Assets:   make([]channel.Asset, 1),
+				Balances: [][]channel.Bal{balances},
+			},
+		}
+	}
+
+	t.Run("happy", func(t *testing.T) {
+		assert.NoError(t, checkTransition(newState(1, 10, 10), newState(2, 5, 15)))
+	})
+
+	tests := map[string]func(prev, next *channel.State){
+		"err_channel_id":        func(_, next *channel.State) { next.ID = channel.ID{2} },
+		"err_final":             func(prev, _ *channel.State) { prev.IsFinal = true },
+		"err_version_same":      func(_, next *channel.State) { next.Version = 1 },
+		"err_version_skipped":   func(_, next *channel.State) { next.Version = 3 },
+		"err_assets":            func(_, next *channel.State) { next.Assets = append(next.Assets, nil) },
+		"err_balances":          func(_, next *channel.State) { next.Balances = append(next.Balances, nil) },
+		"err_balance_increased": func(_, next *channel.State) { next.Balances[0][1] = big.NewInt(16) },
+		"err_balance_negative": func(_, next *channel.State) {
+			next.Balances[0] = []channel.Bal{big.NewInt(-1), big.NewInt(21)}
+		},
+		"err_balance_decreased": func(_, next *channel.State) { next.Balances[0][0] = big.NewInt(4) },
+	}
+	for name, modify := range tests {
+		modify := modify
+		t.Run(name, func(t *testing.T) {
+			prev, next := newState(1, 10, 10), newState(2, 5, 15)
+			modify(prev, next)
+			assert.Error(t, checkTransition(prev, next))
+		})
+	}
+}
+
+func Test_invariants(t *testing.T) {
+	var inv invariants
+	assert.NoError(t, inv.check(channel.ID{1}))
+	assert.Empty(t, inv.violations())
+
+	inv.halt(channel.ID{1}, 5, assert.AnError)
+	inv.halt(channel.ID{1}, 6, assert.AnError)
+	err := inv.check(channel.ID{1})
+	require.IsType(t, ErrChannelHalted{}, err)
+	assert.EqualValues(t, 5, err.(ErrChannelHalted).Version)
+	assert.NoError(t, inv.check(channel.ID{2}))
+	violations := inv.violations()
+	require.Len(t, violations, 1)
+	assert.EqualValues(t, 5, violations[0].Version, "first violation should be retained")
+	assert.Equal(t, assert.AnError.Error(), violations[0].Reason)
+}
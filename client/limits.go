@@ -0,0 +1,148 @@
+// Copyright (c) 2020 - for information on the respective copyright owner
+// see the NOTICE file and/or the repository at
+// https://github.com/hyperledger-labs/perun-node
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"perun.network/go-perun/channel"
+	"perun.network/go-perun/client"
+	"perun.network/go-perun/wire"
+)
+
+// Limits represents the limits on resources used by the client. Zero value for a limit means there is no limit.
+type Limits struct {
+	// Maximum number of open channels.
+	MaxOpenChannels int
+	// Maximum number of distinct peers with which the client has open channels.
+	MaxPeers int
+	// Maximum number of channel proposals (incoming and outgoing) being processed at the same time.
+	MaxPendingProposals int
+}
+
+// Names of the resources used in ErrLimitExceeded.
+const (
+	ResourceOpenChannels     = "open channels"
+	ResourcePeers            = "peers"
+	ResourcePendingProposals = "pending proposals"
+)
+
+// ErrLimitExceeded is returned when an operation cannot be completed without exceeding the limit on a resource.
+type ErrLimitExceeded struct {
+	Resource string
+	Max      int
+}
+
+func (e ErrLimitExceeded) Error() string {
+	return fmt.Sprintf("limit exceeded for %s: max %d", e.Resource, e.Max)
+}
+
+// limiter tracks the usage of resources and enforces the limits on them. Zero value has no limits.
+type limiter struct {
+	limits Limits
+	self   string // Off-chain address of the client, it is not counted as peer.
+
+	mutex            sync.Mutex
+	channels         map[channel.ID]*client.Channel
+	pendingProposals int
+}
+
+// addChannel tracks the channel for counting the open channels and peers.
+// It should be registered as the new channel handler on the client, so that restored channels are also tracked.
+func (l *limiter) addChannel(ch *client.Channel) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	if l.channels == nil {
+		l.channels = make(map[channel.ID]*client.Channel)
+	}
+	l.channels[ch.ID()] = ch
+}
+
+// acquireProposal checks if a new channel with the given peers can be opened without exceeding the limits.
+// If so, it reserves a slot for pending proposal, which should be released using releaseProposal once
+// the proposal is processed. Else, an ErrLimitExceeded is returned.
+func (l *limiter) acquireProposal(peers []wire.Address) error {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if l.limits.MaxPendingProposals != 0 && l.pendingProposals >= l.limits.MaxPendingProposals {
+		return ErrLimitExceeded{Resource: ResourcePendingProposals, Max: l.limits.MaxPendingProposals}
+	}
+	openChannels, peerSet := l.usage()
+	if l.limits.MaxOpenChannels != 0 && openChannels+l.pendingProposals >= l.limits.MaxOpenChannels {
+		return ErrLimitExceeded{Resource: ResourceOpenChannels, Max: l.limits.MaxOpenChannels}
+	}
+	for _, p := range peers {
+		peerSet[p.String()] = struct{}{}
+	}
+	delete(peerSet, l.self)
+	if l.limits.MaxPeers != 0 && len(peerSet) > l.limits.MaxPeers {
+		return ErrLimitExceeded{Resource: ResourcePeers, Max: l.limits.MaxPeers}
+	}
+	l.pendingProposals++
+	return nil
+}
+
+func (l *limiter) releaseProposal() {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.pendingProposals--
+}
+
+// peers returns the off-chain addresses of the peers with which the client has open channels.
+func (l *limiter) peers() []string {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	_, peerSet := l.usage()
+	addrs := make([]string, 0, len(peerSet))
+	for addr := range peerSet {
+		addrs = append(addrs, addr)
+	}
+	sort.Strings(addrs)
+	return addrs
+}
+
+// openChannels returns the channels that are open.
+func (l *limiter) openChannels() []*client.Channel {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.usage()
+	chs := make([]*client.Channel, 0, len(l.channels))
+	for _, ch := range l.channels {
+		chs = append(chs, ch)
+	}
+	return chs
+}
+
+// usage returns the number of open channels and the set of peers in them. Closed channels are removed
+// from tracking. It should be called with mutex locked.
+func (l *limiter) usage() (openChannels int, peerSet map[string]struct{}) {
+	peerSet = make(map[string]struct{})
+	for id, ch := range l.channels {
+		if ch.IsClosed() {
+			delete(l.channels, id)
+			continue
+		}
+		for _, p := range ch.Peers() {
+			peerSet[p.String()] = struct{}{}
+		}
+	}
+	delete(peerSet, l.self)
+	return len(l.channels), peerSet
+}
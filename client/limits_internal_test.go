@@ -0,0 +1,68 @@
+// Copyright (c) 2020 - for information on the respective copyright owner
+// see the NOTICE file and/or the repository at
+// https://github.com/hyperledger-labs/perun-node
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"perun.network/go-perun/pkg/test"
+	"perun.network/go-perun/wire"
+
+	"github.com/hyperledger-labs/perun-node/blockchain/ethereum/ethereumtest"
+)
+
+func Test_limiter_acquireProposal(t *testing.T) {
+	rng := test.Prng(t)
+	self := ethereumtest.NewRandomAddress(rng)
+	peer1, peer2 := ethereumtest.NewRandomAddress(rng), ethereumtest.NewRandomAddress(rng)
+
+	t.Run("happy_no_limits", func(t *testing.T) {
+		l := limiter{self: self.String()}
+		for i := 0; i < 10; i++ {
+			require.NoError(t, l.acquireProposal([]wire.Address{self, peer1}))
+		}
+	})
+
+	t.Run("happy_release", func(t *testing.T) {
+		l := limiter{limits: Limits{MaxPendingProposals: 1}, self: self.String()}
+		require.NoError(t, l.acquireProposal([]wire.Address{self, peer1}))
+		l.releaseProposal()
+		assert.NoError(t, l.acquireProposal([]wire.Address{self, peer1}))
+	})
+
+	t.Run("err_pending_proposals", func(t *testing.T) {
+		l := limiter{limits: Limits{MaxPendingProposals: 1}, self: self.String()}
+		require.NoError(t, l.acquireProposal([]wire.Address{self, peer1}))
+		err := l.acquireProposal([]wire.Address{self, peer2})
+		assert.Equal(t, ErrLimitExceeded{Resource: ResourcePendingProposals, Max: 1}, err)
+	})
+
+	t.Run("err_open_channels", func(t *testing.T) {
+		l := limiter{limits: Limits{MaxOpenChannels: 1}, self: self.String()}
+		require.NoError(t, l.acquireProposal([]wire.Address{self, peer1}))
+		err := l.acquireProposal([]wire.Address{self, peer2})
+		assert.Equal(t, ErrLimitExceeded{Resource: ResourceOpenChannels, Max: 1}, err)
+	})
+
+	t.Run("err_peers", func(t *testing.T) {
+		l := limiter{limits: Limits{MaxPeers: 1}, self: self.String()}
+		err := l.acquireProposal([]wire.Address{self, peer1, peer2})
+		assert.Equal(t, ErrLimitExceeded{Resource: ResourcePeers, Max: 1}, err)
+	})
+}
@@ -0,0 +1,73 @@
+// Copyright (c) 2020 - for information on the respective copyright owner
+// see the NOTICE file and/or the repository at
+// https://github.com/hyperledger-labs/perun-node
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+
+	"perun.network/go-perun/channel"
+	"perun.network/go-perun/wire"
+
+	"github.com/hyperledger-labs/perun-node/payment"
+)
+
+// receiptBus wraps the message bus used by the go-perun client, so that the receipts sent by the peers are passed
+// to the handler instead of the go-perun client, which does not know the message type.
+type receiptBus struct {
+	wire.Bus
+	onReceipt func(payment.Receipt)
+}
+
+func (b receiptBus) SubscribeClient(c wire.Consumer, addr wire.Address) error {
+	return b.Bus.SubscribeClient(receiptConsumer{Consumer: c, onReceipt: b.onReceipt}, addr)
+}
+
+type receiptConsumer struct {
+	wire.Consumer
+	onReceipt func(payment.Receipt)
+}
+
+func (c receiptConsumer) Put(e *wire.Envelope) {
+	msg, ok := e.Msg.(*payment.ReceiptMsg)
+	if !ok {
+		c.Consumer.Put(e)
+		return
+	}
+	if c.onReceipt != nil {
+		c.onReceipt(msg.Receipt)
+	}
+}
+
+// sendReceipt sends the receipt for a payment received on the channel to the peer in the channel.
+func (c *Client) sendReceipt(id channel.ID, receipt payment.Receipt) {
+	ch, err := c.Channel(id)
+	if err != nil {
+		c.Log().Error("Sending receipt: ", err)
+		return
+	}
+	peers := ch.Peers()
+	ctx, cancel := context.WithTimeout(context.Background(), responseTimeout)
+	defer cancel()
+	err = c.Publish(ctx, &wire.Envelope{
+		Sender:    peers[ch.Idx()],
+		Recipient: peers[1-ch.Idx()],
+		Msg:       &payment.ReceiptMsg{Receipt: receipt},
+	})
+	if err != nil {
+		c.Log().Error("Sending receipt: ", err)
+	}
+}
@@ -0,0 +1,58 @@
+// Copyright (c) 2020 - for information on the respective copyright owner
+// see the NOTICE file and/or the repository at
+// https://github.com/hyperledger-labs/perun-node
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"perun.network/go-perun/pkg/test"
+	"perun.network/go-perun/wire"
+
+	"github.com/hyperledger-labs/perun-node/blockchain/ethereum/ethereumtest"
+	"github.com/hyperledger-labs/perun-node/payment"
+)
+
+func Test_receiptBus(t *testing.T) {
+	rng := test.Prng(t)
+	self, peer := ethereumtest.NewRandomAddress(rng), ethereumtest.NewRandomAddress(rng)
+	receipts := make(chan payment.Receipt, 1)
+	bus := receiptBus{Bus: wire.NewLocalBus(), onReceipt: func(r payment.Receipt) { receipts <- r }}
+
+	relay := wire.NewRelay()
+	defer relay.Close() // nolint: errcheck  // test cleanup.
+	others := wire.NewReceiver()
+	require.NoError(t, relay.Subscribe(others, func(*wire.Envelope) bool { return true }))
+	require.NoError(t, bus.SubscribeClient(relay, self))
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	receipt := payment.Receipt{InvoiceID: payment.InvoiceID{1}, Amount: big.NewInt(5)}
+	require.NoError(t, bus.Publish(ctx, &wire.Envelope{
+		Sender: peer, Recipient: self, Msg: &payment.ReceiptMsg{Receipt: receipt},
+	}))
+	require.NoError(t, bus.Publish(ctx, &wire.Envelope{Sender: peer, Recipient: self, Msg: wire.NewPingMsg()}))
+
+	assert.Equal(t, receipt, <-receipts)
+	env, err := others.Next(ctx)
+	require.NoError(t, err)
+	assert.IsType(t, &wire.PingMsg{}, env.Msg, "other messages should be passed to the client")
+}
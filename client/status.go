@@ -0,0 +1,125 @@
+// Copyright (c) 2020 - for information on the respective copyright owner
+// see the NOTICE file and/or the repository at
+// https://github.com/hyperledger-labs/perun-node
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"perun.network/go-perun/channel"
+)
+
+// ChannelStatus is the status of a channel in its lifecycle, as reported to the status subscribers.
+type ChannelStatus string
+
+// Statuses of a channel, in the order of its lifecycle.
+const (
+	// StatusOpen means the channel is set up (or restored) and can be updated.
+	StatusOpen ChannelStatus = "open"
+	// StatusFinal means the final state of the channel is agreed, no further updates are possible.
+	StatusFinal ChannelStatus = "final"
+	// StatusClosed means the channel is closed in the client, after it was settled.
+	StatusClosed ChannelStatus = "closed"
+)
+
+// StatusEvent reports a change in the status of a channel.
+type StatusEvent struct {
+	Channel channel.ID
+	Old     ChannelStatus // Empty for a new channel.
+	New     ChannelStatus
+	At      time.Time
+}
+
+// statusSubs holds the callbacks subscribed to status changes of the channels.
+type statusSubs struct {
+	mutex sync.RWMutex
+	subs  map[int]func(StatusEvent)
+	next  int
+}
+
+// SubscribeStatus registers the callback to be called on each change in the status of any channel of the client,
+// including the new and restored channels. Events of a channel are reported in order. The callback must not
+// block, as it is called on the go-routine watching the channel. The returned function cancels the subscription.
+func (c *Client) SubscribeStatus(callback func(StatusEvent)) (unsubscribe func()) {
+	return c.statusSubs.add(callback)
+}
+
+func (s *statusSubs) add(callback func(StatusEvent)) func() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.subs == nil {
+		s.subs = make(map[int]func(StatusEvent))
+	}
+	id := s.next
+	s.next++
+	s.subs[id] = callback
+	return func() {
+		s.mutex.Lock()
+		defer s.mutex.Unlock()
+		delete(s.subs, id)
+	}
+}
+
+func (s *statusSubs) publish(e StatusEvent) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	for _, callback := range s.subs {
+		callback(e)
+	}
+}
+
+// watchedChannel is the part of the channel api in go-perun used for watching a channel.
+type watchedChannel interface {
+	ID() channel.ID
+	State() *channel.State
+	Ctx() context.Context
+}
+
+// watchChannel reports the status changes of the channel to the subscribers, tracks its updates for the idle
+// policy and checks the invariants (if enabled) on each new state received on the updates subscription, until
+// the channel is closed.
+func (c *Client) watchChannel(ch watchedChannel, updates <-chan *channel.State) {
+	id := ch.ID()
+	c.idle.touch(id)
+	status := StatusOpen
+	if ch.State().IsFinal {
+		status = StatusFinal
+	}
+	c.statusSubs.publish(StatusEvent{Channel: id, New: status, At: time.Now()})
+
+	var prev *channel.State
+	for {
+		select {
+		case <-ch.Ctx().Done():
+			c.idle.remove(id)
+			c.removeClosingMode(id)
+			c.statusSubs.publish(StatusEvent{Channel: id, Old: status, New: StatusClosed, At: time.Now()})
+			return
+		case next := <-updates:
+			c.idle.touch(id)
+			if c.checkInvariants {
+				c.invariants.checkUpdate(prev, next, c.Log())
+				prev = next.Clone()
+			}
+			if next.IsFinal && status != StatusFinal {
+				c.statusSubs.publish(StatusEvent{Channel: id, Old: status, New: StatusFinal, At: time.Now()})
+				status = StatusFinal
+			}
+		}
+	}
+}
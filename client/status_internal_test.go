@@ -0,0 +1,109 @@
+// Copyright (c) 2020 - for information on the respective copyright owner
+// see the NOTICE file and/or the repository at
+// https://github.com/hyperledger-labs/perun-node
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"perun.network/go-perun/channel"
+)
+
+type fakeWatchedChannel struct {
+	id    channel.ID
+	state *channel.State
+	ctx   context.Context
+}
+
+func (ch fakeWatchedChannel) ID() channel.ID { return ch.id }
+
+func (ch fakeWatchedChannel) State() *channel.State { return ch.state }
+
+func (ch fakeWatchedChannel) Ctx() context.Context { return ch.ctx }
+
+// recorder records the status events received by a subscriber.
+type recorder struct {
+	mutex  sync.Mutex
+	events []StatusEvent
+}
+
+func (r *recorder) record(e StatusEvent) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.events = append(r.events, e)
+}
+
+func (r *recorder) statuses() [][2]ChannelStatus {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	statuses := make([][2]ChannelStatus, len(r.events))
+	for i, e := range r.events {
+		statuses[i] = [2]ChannelStatus{e.Old, e.New}
+	}
+	return statuses
+}
+
+func Test_Client_watchChannel(t *testing.T) {
+	// watch starts watching a new channel with the given initial state and returns the updates subscription,
+	// a function to close the channel and wait until the watcher returns and the recorded events.
+	watch := func(c *Client, state *channel.State) (chan<- *channel.State, func(), *recorder) {
+		rec := &recorder{}
+		c.SubscribeStatus(rec.record)
+		ctx, cancel := context.WithCancel(context.Background())
+		updates := make(chan *channel.State, 1)
+		done := make(chan struct{})
+		go func() {
+			c.watchChannel(fakeWatchedChannel{id: state.ID, state: state, ctx: ctx}, updates)
+			close(done)
+		}()
+		return updates, func() { cancel(); <-done }, rec
+	}
+
+	t.Run("happy_lifecycle", func(t *testing.T) {
+		updates, closeChannel, rec := watch(&Client{}, &channel.State{})
+		updates <- &channel.State{Version: 1}
+		updates <- &channel.State{Version: 2, IsFinal: true}
+		updates <- &channel.State{Version: 2, IsFinal: true}
+		require.Eventually(t, func() bool { return len(rec.statuses()) == 2 }, time.Second, 10*time.Millisecond)
+		closeChannel()
+
+		assert.Equal(t, [][2]ChannelStatus{
+			{"", StatusOpen},
+			{StatusOpen, StatusFinal},
+			{StatusFinal, StatusClosed},
+		}, rec.statuses())
+	})
+	t.Run("happy_restored_final", func(t *testing.T) {
+		_, closeChannel, rec := watch(&Client{}, &channel.State{IsFinal: true})
+		closeChannel()
+		assert.Equal(t, [][2]ChannelStatus{{"", StatusFinal}, {StatusFinal, StatusClosed}}, rec.statuses())
+	})
+	t.Run("happy_unsubscribe", func(t *testing.T) {
+		c := &Client{}
+		unsubscribed := &recorder{}
+		unsubscribe := c.SubscribeStatus(unsubscribed.record)
+		unsubscribe()
+		_, closeChannel, rec := watch(c, &channel.State{})
+		closeChannel()
+		assert.Len(t, rec.statuses(), 2)
+		assert.Empty(t, unsubscribed.statuses())
+	})
+}
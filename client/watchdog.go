@@ -0,0 +1,127 @@
+// Copyright (c) 2020 - for information on the respective copyright owner
+// see the NOTICE file and/or the repository at
+// https://github.com/hyperledger-labs/perun-node
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"encoding/hex"
+	"sort"
+	"sync"
+	"time"
+
+	"perun.network/go-perun/channel"
+)
+
+const (
+	// Backoff before restarting a failed watcher, doubled on each consecutive failure up to the max.
+	watcherMinBackoff = time.Second
+	watcherMaxBackoff = time.Minute
+)
+
+// WatcherStatus is the status of the watcher of an open channel, that responds to the disputes registered on
+// the blockchain with the latest state and settles the channel.
+type WatcherStatus struct {
+	Channel     string    `json:"channel"` // ID of the channel as hex string.
+	Failures    int       `json:"failures"`
+	LastError   string    `json:"lastError,omitempty"`
+	LastErrorAt time.Time `json:"lastErrorAt,omitempty"`
+}
+
+// disputableChannel is the part of the channel api in go-perun used for watching the disputes on a channel.
+type disputableChannel interface {
+	ID() channel.ID
+	Watch() error
+	Ctx() context.Context
+}
+
+// watchdog tracks the watchers of the open channels.
+type watchdog struct {
+	minBackoff time.Duration // Defaults to watcherMinBackoff, if zero.
+
+	mutex    sync.Mutex
+	watchers map[channel.ID]*WatcherStatus
+}
+
+// runWatcher runs the watcher of the channel until the channel is closed or a dispute on it is resolved. When
+// the watcher fails (for example, because the connection to the blockchain was lost), the error is logged and
+// the watcher is restarted after a backoff, as disputes must not go unanswered.
+func (c *Client) runWatcher(ch disputableChannel) {
+	id := ch.ID()
+	c.watchdog.add(id)
+	defer c.watchdog.remove(id)
+
+	backoff := c.watchdog.minBackoff
+	if backoff == 0 {
+		backoff = watcherMinBackoff
+	}
+	for {
+		err := ch.Watch()
+		if err == nil || ch.Ctx().Err() != nil {
+			return
+		}
+		c.Log().Errorf("Watcher for channel %x failed, restarting in %v: %v", id, backoff, err)
+		c.watchdog.failed(id, err)
+		select {
+		case <-ch.Ctx().Done():
+			return
+		case <-time.After(backoff):
+		}
+		if backoff *= 2; backoff > watcherMaxBackoff {
+			backoff = watcherMaxBackoff
+		}
+	}
+}
+
+// Watchers returns the status of the watchers of the open channels, sorted by channel ID.
+func (c *Client) Watchers() []WatcherStatus {
+	return c.watchdog.status()
+}
+
+func (w *watchdog) add(id channel.ID) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	if w.watchers == nil {
+		w.watchers = make(map[channel.ID]*WatcherStatus)
+	}
+	w.watchers[id] = &WatcherStatus{Channel: hex.EncodeToString(id[:])}
+}
+
+func (w *watchdog) remove(id channel.ID) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	delete(w.watchers, id)
+}
+
+func (w *watchdog) failed(id channel.ID, err error) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	if s, ok := w.watchers[id]; ok {
+		s.Failures++
+		s.LastError, s.LastErrorAt = err.Error(), time.Now()
+	}
+}
+
+func (w *watchdog) status() []WatcherStatus {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	statuses := make([]WatcherStatus, 0, len(w.watchers))
+	for _, s := range w.watchers {
+		statuses = append(statuses, *s)
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Channel < statuses[j].Channel })
+	return statuses
+}
@@ -0,0 +1,88 @@
+// Copyright (c) 2020 - for information on the respective copyright owner
+// see the NOTICE file and/or the repository at
+// https://github.com/hyperledger-labs/perun-node
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"encoding/hex"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"perun.network/go-perun/channel"
+	"perun.network/go-perun/log"
+
+	"github.com/hyperledger-labs/perun-node/internal/mocks"
+)
+
+type fakeDisputableChannel struct {
+	id      channel.ID
+	ctx     context.Context
+	watches int32
+	// watch is called on each Watch call, with the count of calls so far.
+	watch func(n int32) error
+}
+
+func (ch *fakeDisputableChannel) ID() channel.ID { return ch.id }
+
+func (ch *fakeDisputableChannel) Watch() error { return ch.watch(atomic.AddInt32(&ch.watches, 1)) }
+
+func (ch *fakeDisputableChannel) Ctx() context.Context { return ch.ctx }
+
+func Test_Client_runWatcher(t *testing.T) {
+	newClient := func() *Client {
+		chClient := &mocks.ChannelClient{}
+		chClient.On("Log").Return(log.Get())
+		return &Client{ChannelClient: chClient, watchdog: watchdog{minBackoff: time.Millisecond}}
+	}
+
+	t.Run("happy_restart", func(t *testing.T) {
+		c := newClient()
+		ch := &fakeDisputableChannel{id: channel.ID{1}, ctx: context.Background(), watch: func(n int32) error {
+			if n < 3 {
+				return assert.AnError
+			}
+			return nil
+		}}
+		c.runWatcher(ch)
+		assert.Equal(t, int32(3), atomic.LoadInt32(&ch.watches))
+		assert.Empty(t, c.Watchers(), "watcher should be removed once it returns")
+	})
+	t.Run("happy_status", func(t *testing.T) {
+		c := newClient()
+		ctx, cancel := context.WithCancel(context.Background())
+		ch := &fakeDisputableChannel{id: channel.ID{1}, ctx: ctx, watch: func(int32) error { return assert.AnError }}
+		done := make(chan struct{})
+		go func() {
+			c.runWatcher(ch)
+			close(done)
+		}()
+		require.Eventually(t, func() bool {
+			watchers := c.Watchers()
+			return len(watchers) == 1 && watchers[0].Failures > 1
+		}, time.Second, time.Millisecond)
+		watchers := c.Watchers()
+		assert.Equal(t, hex.EncodeToString(ch.id[:]), watchers[0].Channel)
+		assert.Equal(t, assert.AnError.Error(), watchers[0].LastError)
+
+		cancel()
+		<-done
+		assert.Empty(t, c.Watchers())
+	})
+}
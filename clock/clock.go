@@ -0,0 +1,52 @@
+// Copyright (c) 2020 - for information on the respective copyright owner
+// see the NOTICE file and/or the repository at
+// https://github.com/hyperledger-labs/perun-node
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clock
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Clock provides the current time.
+type Clock interface {
+	Now() time.Time
+}
+
+// zonedClock is a clock based on the system time, that returns time in the configured location.
+type zonedClock struct {
+	location *time.Location
+}
+
+// New returns a clock based on the system time that returns the time in the given timezone.
+// The timezone should be a name in the IANA time zone database (such as "Europe/Berlin"), "UTC" or
+// "Local". If it is empty, local timezone is used.
+func New(timezone string) (Clock, error) {
+	if timezone == "" {
+		return zonedClock{location: time.Local}, nil
+	}
+	location, err := time.LoadLocation(timezone)
+	if err != nil {
+		return nil, errors.Wrap(err, "loading timezone")
+	}
+	return zonedClock{location: location}, nil
+}
+
+// Now returns the current time in the location of the clock.
+func (c zonedClock) Now() time.Time {
+	return time.Now().In(c.location)
+}
@@ -0,0 +1,47 @@
+// Copyright (c) 2020 - for information on the respective copyright owner
+// see the NOTICE file and/or the repository at
+// https://github.com/hyperledger-labs/perun-node
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clock_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger-labs/perun-node/clock"
+)
+
+func Test_New(t *testing.T) {
+	t.Run("happy", func(t *testing.T) {
+		c, err := clock.New("UTC")
+		require.NoError(t, err)
+		assert.Equal(t, time.UTC, c.Now().Location())
+		assert.WithinDuration(t, time.Now(), c.Now(), time.Second)
+	})
+
+	t.Run("happy_local", func(t *testing.T) {
+		c, err := clock.New("")
+		require.NoError(t, err)
+		assert.Equal(t, time.Local, c.Now().Location())
+	})
+
+	t.Run("err_unknown_timezone", func(t *testing.T) {
+		_, err := clock.New("Unknown/Timezone")
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,25 @@
+// Copyright (c) 2020 - for information on the respective copyright owner
+// see the NOTICE file and/or the repository at
+// https://github.com/hyperledger-labs/perun-node
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package clock provides the clock used by the components of the node and
+// detection of skew in the local clock.
+//
+// Skew is detected by querying the time from an NTP server using the simple
+// network time protocol (SNTP, RFC 4330). When the offset of local clock
+// exceeds the configured threshold, a warning is logged and the monitor
+// reports an error, that can be used to refuse actions sensitive to
+// timeouts (such as opening channels with a challenge duration).
+package clock
@@ -0,0 +1,107 @@
+// Copyright (c) 2020 - for information on the respective copyright owner
+// see the NOTICE file and/or the repository at
+// https://github.com/hyperledger-labs/perun-node
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clock
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/hyperledger-labs/perun-node/log"
+)
+
+// ErrClockSkew is returned by SkewMonitor.Err when the offset of the local clock exceeds the threshold.
+type ErrClockSkew struct {
+	Offset    time.Duration
+	Threshold time.Duration
+}
+
+func (e ErrClockSkew) Error() string {
+	return fmt.Sprintf("local clock is off by %v, exceeds the threshold %v", e.Offset, e.Threshold)
+}
+
+// SkewMonitor periodically checks the offset of the clock against an NTP server.
+type SkewMonitor struct {
+	log.Logger
+
+	clock     Clock
+	server    string
+	threshold time.Duration
+
+	mutex  sync.RWMutex
+	offset time.Duration
+}
+
+// NewSkewMonitor returns a monitor that checks the offset of the clock using the NTP server
+// and reports skew if the absolute value of offset exceeds the threshold.
+func NewSkewMonitor(clock Clock, server string, threshold time.Duration) *SkewMonitor {
+	return &SkewMonitor{
+		Logger:    log.NewLoggerWithField("component", "clock"),
+		clock:     clock,
+		server:    server,
+		threshold: threshold,
+	}
+}
+
+// Check queries the offset of the clock from the NTP server and stores it. A warning is logged
+// if the offset exceeds the threshold. If the query fails, the previous offset is retained.
+func (m *SkewMonitor) Check(ctx context.Context) (time.Duration, error) {
+	offset, err := QueryOffset(ctx, m.server, m.clock)
+	if err != nil {
+		return 0, err
+	}
+	m.mutex.Lock()
+	m.offset = offset
+	m.mutex.Unlock()
+
+	if exceeds(offset, m.threshold) {
+		m.Warn(ErrClockSkew{Offset: offset, Threshold: m.threshold}.Error(), ", sync the clock using NTP.")
+	}
+	return offset, nil
+}
+
+// Run checks the offset at the given interval, until the context is canceled.
+// Errors in querying the NTP server are logged.
+func (m *SkewMonitor) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if _, err := m.Check(ctx); err != nil && ctx.Err() == nil {
+			m.Error("Checking clock skew: ", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Err returns an ErrClockSkew if the last checked offset exceeds the threshold, else nil.
+func (m *SkewMonitor) Err() error {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	if exceeds(m.offset, m.threshold) {
+		return ErrClockSkew{Offset: m.offset, Threshold: m.threshold}
+	}
+	return nil
+}
+
+func exceeds(offset, threshold time.Duration) bool {
+	return offset > threshold || offset < -threshold
+}
@@ -0,0 +1,124 @@
+// Copyright (c) 2020 - for information on the respective copyright owner
+// see the NOTICE file and/or the repository at
+// https://github.com/hyperledger-labs/perun-node
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clock
+
+import (
+	"context"
+	"encoding/binary"
+	"net"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// defaultNTPPort is used when the port is not specified in the server address.
+	defaultNTPPort = "123"
+	// defaultQueryTimeout is used when the context has no deadline.
+	defaultQueryTimeout = 5 * time.Second
+
+	sntpPacketLen = 48
+	// First byte of the request: leap indicator 0, version 4, mode 3 (client).
+	sntpRequestHeader = 0x23
+	// Mode 4 (server) in the first byte of the response.
+	sntpModeServer = 4
+	// Leap indicator 3 (in the upper two bits of the first byte) means the clock of the server is not synchronized.
+	sntpLeapNotSync = 3
+	// Stratum 16 or higher means the server is not synchronized.
+	sntpMaxStratum = 15
+
+	// Seconds between the NTP epoch (1900) and the unix epoch (1970).
+	ntpEpochOffset = 2208988800
+)
+
+// QueryOffset queries the time from the NTP server and returns the offset of the given clock relative
+// to the server. Positive value means the clock is behind the server.
+func QueryOffset(ctx context.Context, server string, clock Clock) (time.Duration, error) {
+	if _, _, err := net.SplitHostPort(server); err != nil {
+		server = net.JoinHostPort(server, defaultNTPPort)
+	}
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "udp", server)
+	if err != nil {
+		return 0, errors.Wrap(err, "connecting to ntp server")
+	}
+	defer conn.Close() // nolint: errcheck  // connection is used only for the query.
+
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(defaultQueryTimeout)
+	}
+	if err = conn.SetDeadline(deadline); err != nil {
+		return 0, errors.Wrap(err, "setting deadline")
+	}
+
+	request := make([]byte, sntpPacketLen)
+	request[0] = sntpRequestHeader
+	sent := clock.Now()
+	binary.BigEndian.PutUint64(request[40:], toNTPTime(sent))
+	if _, err = conn.Write(request); err != nil {
+		return 0, errors.Wrap(err, "sending request to ntp server")
+	}
+	response := make([]byte, sntpPacketLen)
+	n, err := conn.Read(response)
+	received := clock.Now()
+	if err != nil {
+		return 0, errors.Wrap(err, "reading response from ntp server")
+	}
+	if err = validateResponse(request, response[:n]); err != nil {
+		return 0, err
+	}
+
+	serverReceived := fromNTPTime(binary.BigEndian.Uint64(response[32:]))
+	serverSent := fromNTPTime(binary.BigEndian.Uint64(response[40:]))
+	return (serverReceived.Sub(sent) + serverSent.Sub(received)) / 2, nil
+}
+
+// validateResponse checks if the response is a reply from a synchronized server to the request. The origin timestamp
+// of the response should match the transmit timestamp of the request, else it is a stale or a spoofed response.
+func validateResponse(request, response []byte) error {
+	if len(response) < sntpPacketLen || response[0]&0x07 != sntpModeServer {
+		return errors.New("invalid response from ntp server")
+	}
+	if binary.BigEndian.Uint64(response[24:]) != binary.BigEndian.Uint64(request[40:]) {
+		return errors.New("origin timestamp of ntp response does not match the request")
+	}
+	if response[1] == 0 {
+		return errors.New("ntp server sent kiss-of-death response")
+	}
+	if response[0]>>6 == sntpLeapNotSync || response[1] > sntpMaxStratum {
+		return errors.New("ntp server is not synchronized")
+	}
+	if binary.BigEndian.Uint64(response[40:]) == 0 {
+		return errors.New("transmit timestamp of ntp response is zero")
+	}
+	return nil
+}
+
+// toNTPTime converts the time to NTP timestamp format: seconds since 1900 in the upper 32 bits
+// and fraction of second in the lower 32 bits.
+func toNTPTime(t time.Time) uint64 {
+	secs := uint64(t.Unix() + ntpEpochOffset)
+	frac := uint64(t.Nanosecond()) << 32 / uint64(time.Second)
+	return secs<<32 | frac
+}
+
+func fromNTPTime(ntpTime uint64) time.Time {
+	secs := int64(ntpTime>>32) - ntpEpochOffset
+	nanos := int64((ntpTime & 0xffffffff) * uint64(time.Second) >> 32)
+	return time.Unix(secs, nanos)
+}
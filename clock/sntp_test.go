@@ -0,0 +1,127 @@
+// Copyright (c) 2020 - for information on the respective copyright owner
+// see the NOTICE file and/or the repository at
+// https://github.com/hyperledger-labs/perun-node
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clock_test
+
+import (
+	"context"
+	"encoding/binary"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger-labs/perun-node/clock"
+)
+
+const ntpEpochOffset = 2208988800
+
+func Test_QueryOffset(t *testing.T) {
+	c, err := clock.New("UTC")
+	require.NoError(t, err)
+
+	t.Run("happy", func(t *testing.T) {
+		server := newNTPServer(t, time.Minute, nil)
+		offset, err := clock.QueryOffset(context.Background(), server, c)
+		require.NoError(t, err)
+		assert.InDelta(t, float64(time.Minute), float64(offset), float64(time.Second))
+	})
+
+	t.Run("err_kiss_of_death", func(t *testing.T) {
+		server := newNTPServer(t, 0, func(response []byte) { response[1] = 0 })
+		_, err := clock.QueryOffset(context.Background(), server, c)
+		assert.Error(t, err)
+	})
+
+	t.Run("err_origin_mismatch", func(t *testing.T) {
+		server := newNTPServer(t, 0, func(response []byte) { response[31]++ })
+		_, err := clock.QueryOffset(context.Background(), server, c)
+		assert.Error(t, err)
+	})
+
+	t.Run("err_leap_not_synchronized", func(t *testing.T) {
+		server := newNTPServer(t, 0, func(response []byte) { response[0] |= 0xc0 })
+		_, err := clock.QueryOffset(context.Background(), server, c)
+		assert.Error(t, err)
+	})
+
+	t.Run("err_stratum_not_synchronized", func(t *testing.T) {
+		server := newNTPServer(t, 0, func(response []byte) { response[1] = 16 })
+		_, err := clock.QueryOffset(context.Background(), server, c)
+		assert.Error(t, err)
+	})
+
+	t.Run("err_timeout", func(t *testing.T) {
+		conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+		require.NoError(t, err)
+		t.Cleanup(func() { conn.Close() }) // nolint: errcheck, gosec  // test cleanup.
+
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+		_, err = clock.QueryOffset(ctx, conn.LocalAddr().String(), c)
+		assert.Error(t, err)
+	})
+}
+
+func Test_SkewMonitor(t *testing.T) {
+	c, err := clock.New("UTC")
+	require.NoError(t, err)
+
+	t.Run("happy_within_threshold", func(t *testing.T) {
+		monitor := clock.NewSkewMonitor(c, newNTPServer(t, 0, nil), time.Second)
+		_, err := monitor.Check(context.Background())
+		require.NoError(t, err)
+		assert.NoError(t, monitor.Err())
+	})
+
+	t.Run("happy_exceeds_threshold", func(t *testing.T) {
+		monitor := clock.NewSkewMonitor(c, newNTPServer(t, -time.Minute, nil), time.Second)
+		_, err := monitor.Check(context.Background())
+		require.NoError(t, err)
+		assert.IsType(t, clock.ErrClockSkew{}, monitor.Err())
+	})
+}
+
+// newNTPServer starts a server that responds to one SNTP request with the time offset by the given duration
+// and returns its address. If tamper is not nil, it is applied to the response before sending it.
+func newNTPServer(t *testing.T, offset time.Duration, tamper func(response []byte)) string {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() }) // nolint: errcheck, gosec  // test cleanup.
+
+	go func() {
+		request := make([]byte, 48)
+		_, addr, readErr := conn.ReadFrom(request)
+		if readErr != nil {
+			return
+		}
+		response := make([]byte, 48)
+		response[0] = 0x24 // Version 4, mode 4 (server).
+		response[1] = 1    // Stratum 1 (primary server).
+		copy(response[24:32], request[40:48])
+		now := time.Now().Add(offset)
+		ntpTime := uint64(now.Unix()+ntpEpochOffset)<<32 | uint64(now.Nanosecond())<<32/uint64(time.Second)
+		binary.BigEndian.PutUint64(response[32:], ntpTime)
+		binary.BigEndian.PutUint64(response[40:], ntpTime)
+		if tamper != nil {
+			tamper(response)
+		}
+		conn.WriteTo(response, addr) // nolint: errcheck, gosec  // error is detected by the client.
+	}()
+	return conn.LocalAddr().String()
+}
@@ -0,0 +1,227 @@
+// Copyright (c) 2020 - for information on the respective copyright owner
+// see the NOTICE file and/or the repository at
+// https://github.com/hyperledger-labs/perun-node
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+
+	"github.com/hyperledger-labs/perun-node/backup"
+	"github.com/hyperledger-labs/perun-node/node"
+)
+
+// passphraseEnv is the environment variable used for reading the backup passphrase,
+// when a passphrase file is not specified.
+const passphraseEnv = "PERUN_BACKUP_PASSPHRASE"
+
+// Names of the files and directories in the backup archive.
+const (
+	backupConfig           = "node.yaml"
+	backupContacts         = "contacts.yaml"
+	backupDatabase         = "db"
+	backupOnChainKeystore  = "keystore/onchain"
+	backupOffChainKeystore = "keystore/offchain"
+	backupTLSCert          = "tls/cert.pem"
+	backupTLSKey           = "tls/key.pem"
+	backupTLSCA            = "tls/ca.pem"
+	backupSignerToken      = "signer.token"
+	backupManifest         = "manifest.json"
+)
+
+// referencedFiles returns the other files referenced in the config, keyed by their names in the archive. They are
+// backed up if they exist.
+func referencedFiles(cfg *node.Config) map[string]*string {
+	return map[string]*string{
+		backupTLSCert:     &cfg.CommTLSCertFile,
+		backupTLSKey:      &cfg.CommTLSKeyFile,
+		backupTLSCA:       &cfg.CommTLSCAFile,
+		backupSignerToken: &cfg.SignerTokenFile,
+		backupManifest:    &cfg.ManifestFile,
+	}
+}
+
+// runBackup creates an encrypted backup of the config, contacts, keystores and the persistence database of
+// the node. The node should be stopped before taking a backup.
+func runBackup(args []string) error {
+	flags := flag.NewFlagSet("backup", flag.ExitOnError)
+	configFile := flags.String("config", "node.yaml", "Path to the node config file.")
+	out := flags.String("out", "perunnode.backup", "Path to the backup archive to be created.")
+	passphraseFile := flags.String("passphrase-file", "", "Path to the file containing the backup passphrase. "+
+		"If empty, it is read from "+passphraseEnv+".")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	passphrase, err := readPassphrase(*passphraseFile, passphraseEnv)
+	if err != nil {
+		return err
+	}
+	cfg, err := node.ParseConfig(*configFile)
+	if err != nil {
+		return err
+	}
+
+	entries := []backup.Entry{
+		{Name: backupConfig, Path: *configFile},
+		{Name: backupContacts, Path: cfg.ContactsFile},
+		{Name: backupOnChainKeystore, Path: cfg.User.OnChainWallet.KeystorePath},
+		{Name: backupOffChainKeystore, Path: cfg.User.OffChainWallet.KeystorePath},
+	}
+	// Database directory is created only when the node is started for the first time and the manifest file
+	// only when a manifest is staged.
+	if _, err = os.Stat(cfg.DatabaseDir); err == nil {
+		entries = append(entries, backup.Entry{Name: backupDatabase, Path: cfg.DatabaseDir})
+	}
+	for name, path := range referencedFiles(&cfg) {
+		if _, err = os.Stat(*path); *path != "" && err == nil {
+			entries = append(entries, backup.Entry{Name: name, Path: *path})
+		}
+	}
+
+	f, err := os.OpenFile(filepath.Clean(*out), os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+	if err != nil {
+		return errors.Wrap(err, "creating backup archive")
+	}
+	if err = backup.Create(f, passphrase, entries...); err != nil {
+		f.Close()       // nolint: errcheck, gosec  // archive is removed anyway.
+		os.Remove(*out) // nolint: errcheck, gosec  // nothing to do if removal fails.
+		return err
+	}
+	if err = f.Close(); err != nil {
+		return errors.Wrap(err, "writing backup archive")
+	}
+	fmt.Println("Backup written to", *out)
+	return nil
+}
+
+// runRestore restores the backup into a directory and updates the paths in the restored config to point to
+// the restored files. It then checks the consistency of the restored data against the blockchain.
+func runRestore(args []string) error {
+	flags := flag.NewFlagSet("restore", flag.ExitOnError)
+	in := flags.String("in", "perunnode.backup", "Path to the backup archive.")
+	dir := flags.String("dir", "perunnode", "Directory to restore the backup into. Should not already exist.")
+	passphraseFile := flags.String("passphrase-file", "", "Path to the file containing the backup passphrase. "+
+		"If empty, it is read from "+passphraseEnv+".")
+	skipCheck := flags.Bool("skip-check", false, "Skip checking the restored data against the blockchain.")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	passphrase, err := readPassphrase(*passphraseFile, passphraseEnv)
+	if err != nil {
+		return err
+	}
+	if _, err = os.Stat(*dir); !os.IsNotExist(err) {
+		return errors.Errorf("restore directory %s already exists", *dir)
+	}
+	f, err := os.Open(filepath.Clean(*in))
+	if err != nil {
+		return errors.Wrap(err, "opening backup archive")
+	}
+	defer f.Close() // nolint: errcheck  // file is only read.
+	restored, err := backup.Restore(f, passphrase, *dir)
+	if err != nil {
+		return err
+	}
+
+	cfg, err := updateRestoredConfig(*dir, restored)
+	if err != nil {
+		return err
+	}
+	fmt.Println("Backup restored to", *dir)
+	if *skipCheck {
+		return nil
+	}
+	if err = node.Check(cfg); err != nil {
+		return errors.WithMessage(err, "checking restored data")
+	}
+	fmt.Println("Restored data is consistent with the blockchain")
+	return nil
+}
+
+// updateRestoredConfig updates the paths in the restored config file to point to the restored files.
+// The manifest file is always placed in the restore directory, as it is written by the node. Other referenced
+// files that were not in the backup are retained as they are and should exist.
+// Environment variable overrides are not applied, so that they are not persisted in the file.
+func updateRestoredConfig(dir string, restored []string) (node.Config, error) {
+	configFile := filepath.Join(dir, backupConfig)
+	data, err := ioutil.ReadFile(filepath.Clean(configFile))
+	if err != nil {
+		return node.Config{}, errors.Wrap(err, "reading restored config")
+	}
+	var cfg node.Config
+	if err = yaml.Unmarshal(data, &cfg); err != nil {
+		return node.Config{}, errors.Wrap(err, "parsing restored config")
+	}
+
+	cfg.ContactsFile = filepath.Join(dir, backupContacts)
+	cfg.DatabaseDir = filepath.Join(dir, backupDatabase)
+	cfg.User.OnChainWallet.KeystorePath = filepath.Join(dir, filepath.FromSlash(backupOnChainKeystore))
+	cfg.User.OffChainWallet.KeystorePath = filepath.Join(dir, filepath.FromSlash(backupOffChainKeystore))
+	if err = updateReferencedFiles(&cfg, dir, restored); err != nil {
+		return node.Config{}, err
+	}
+	if err = writeYAML(configFile, cfg); err != nil {
+		return node.Config{}, errors.WithMessage(err, "updating restored config")
+	}
+	return node.ParseConfig(configFile)
+}
+
+// updateReferencedFiles updates the paths of the referenced files that were restored to point to the restore
+// directory. It returns an error if any of the other referenced files (except the manifest) does not exist.
+func updateReferencedFiles(cfg *node.Config, dir string, restored []string) error {
+	isRestored := make(map[string]bool, len(restored))
+	for _, name := range restored {
+		isRestored[name] = true
+	}
+	for name, path := range referencedFiles(cfg) {
+		if *path == "" {
+			continue
+		}
+		if isRestored[name] || name == backupManifest {
+			*path = filepath.Join(dir, filepath.FromSlash(name))
+			continue
+		}
+		if _, err := os.Stat(*path); err != nil {
+			return errors.Wrapf(err, "file %s in restored config is not in the backup", *path)
+		}
+	}
+	return nil
+}
+
+// readPassphrase reads the passphrase from the file, if specified, else from the environment variable.
+func readPassphrase(file, env string) (string, error) {
+	if file == "" {
+		passphrase := os.Getenv(env)
+		if passphrase == "" {
+			return "", errors.New("passphrase file not specified and " + env + " is not set")
+		}
+		return passphrase, nil
+	}
+	data, err := ioutil.ReadFile(filepath.Clean(file))
+	if err != nil {
+		return "", errors.Wrap(err, "reading passphrase file")
+	}
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
@@ -0,0 +1,88 @@
+// Copyright (c) 2020 - for information on the respective copyright owner
+// see the NOTICE file and/or the repository at
+// https://github.com/hyperledger-labs/perun-node
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+
+	"github.com/hyperledger-labs/perun-node/node"
+)
+
+// adminRequestTimeout is the timeout for requests to the admin API of a running node.
+const adminRequestTimeout = 10 * time.Second
+
+// runConfig prints the effective configuration with the secrets masked, as yaml.
+//
+// If the address of the admin API is given, the configuration in effect on the running node (including the
+// runtime state changed via the admin API) is printed. Else, it is derived from the config file, environment
+// variables, network presets and defaults.
+func runConfig(args []string) error {
+	flags := flag.NewFlagSet("config", flag.ExitOnError)
+	configFile := flags.String("config", "node.yaml", "Path to the node config file.")
+	adminAddr := flags.String("admin", "", "Address of the admin API of a running node. If empty, "+
+		"the config file is used.")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	var effectiveConfig interface{}
+	if *adminAddr != "" {
+		var err error
+		if effectiveConfig, err = fetchEffectiveConfig(*adminAddr); err != nil {
+			return err
+		}
+	} else {
+		cfg, err := node.ParseConfig(*configFile)
+		if err != nil {
+			return err
+		}
+		effectiveConfig = node.MaskSecrets(cfg)
+	}
+
+	data, err := yaml.Marshal(effectiveConfig)
+	if err != nil {
+		return errors.Wrap(err, "encoding config")
+	}
+	fmt.Print(string(data))
+	return nil
+}
+
+// fetchEffectiveConfig fetches the effective configuration from the admin API of a running node.
+func fetchEffectiveConfig(adminAddr string) (interface{}, error) {
+	client := http.Client{Timeout: adminRequestTimeout}
+	resp, err := client.Get("http://" + adminAddr + "/config")
+	if err != nil {
+		return nil, errors.Wrap(err, "requesting config from admin api")
+	}
+	defer resp.Body.Close() // nolint: errcheck  // nothing to do if closing the body fails.
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("requesting config from admin api: %s", resp.Status)
+	}
+	var cfg map[string]interface{}
+	if err = json.NewDecoder(resp.Body).Decode(&cfg); err != nil {
+		return nil, errors.Wrap(err, "decoding config")
+	}
+	return cfg, nil
+}
@@ -0,0 +1,79 @@
+// Copyright (c) 2020 - for information on the respective copyright owner
+// see the NOTICE file and/or the repository at
+// https://github.com/hyperledger-labs/perun-node
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/hyperledger-labs/perun-node/contracts"
+	"github.com/hyperledger-labs/perun-node/node"
+)
+
+// fetchManifestTimeout is the timeout for fetching the contract manifest.
+const fetchManifestTimeout = 30 * time.Second
+
+// runUpdateContracts fetches the signed contract manifest from the URL in the config, verifies it, validates
+// the contracts in it on the chain and stages it. The staged contracts are used when the node is started next.
+func runUpdateContracts(args []string) error {
+	flags := flag.NewFlagSet("update-contracts", flag.ExitOnError)
+	configFile := flags.String("config", "node.yaml", "Path to the node config file.")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	cfg, err := node.ParseConfig(*configFile)
+	if err != nil {
+		return err
+	}
+	if cfg.ManifestURL == "" || cfg.ManifestPubKey == "" || cfg.ManifestFile == "" {
+		return errors.New("manifesturl, manifestpubkey and manifestfile should be set in the config")
+	}
+	key, err := contracts.ParsePublicKey(cfg.ManifestPubKey)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), fetchManifestTimeout)
+	defer cancel()
+	data, err := contracts.Fetch(ctx, cfg.ManifestURL)
+	if err != nil {
+		return err
+	}
+	m, err := contracts.Verify(data, key)
+	if err != nil {
+		return err
+	}
+	if cfg.Network != "" && m.Network != cfg.Network {
+		return errors.Errorf("manifest is for network %q, node is configured for %q", m.Network, cfg.Network)
+	}
+
+	cfg.Adjudicator, cfg.Asset = m.Adjudicator, m.Asset
+	if err = node.Check(cfg); err != nil {
+		return errors.WithMessage(err, "validating contracts in manifest")
+	}
+	if _, err = contracts.Stage(cfg.ManifestFile, data, key); err != nil {
+		return err
+	}
+	fmt.Printf("Staged contract manifest version %d (adjudicator %s, asset %s).\n", m.Version, m.Adjudicator, m.Asset)
+	fmt.Println("The contracts will be used when the node is started next.")
+	return nil
+}
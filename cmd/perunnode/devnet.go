@@ -0,0 +1,320 @@
+// Copyright (c) 2020 - for information on the respective copyright owner
+// see the NOTICE file and/or the repository at
+// https://github.com/hyperledger-labs/perun-node
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+
+	"github.com/hyperledger-labs/perun-node"
+	"github.com/hyperledger-labs/perun-node/blockchain/ethereum"
+	"github.com/hyperledger-labs/perun-node/node"
+	"github.com/hyperledger-labs/perun-node/session"
+)
+
+const (
+	// devnetBalance is the balance (in wei) with which the on-chain account of each devnet node is funded.
+	devnetBalance = "100000000000000000000"
+
+	// devnetChainTimeout is the duration to wait for the blockchain node to start accepting connections.
+	devnetChainTimeout = 30 * time.Second
+)
+
+// devnetNode holds the data of a node in the devnet.
+type devnetNode struct {
+	cfg        node.Config
+	dir        string
+	onChainKey string
+	cmd        *exec.Cmd
+	exited     chan struct{} // Closed when the node process exits.
+}
+
+// runDevnet sets up and runs a local network of perun nodes for development and testing.
+//
+// It generates the accounts for each node, starts a ganache-cli node with on-chain accounts pre-funded,
+// deploys the contracts and writes the config and contacts (containing all other nodes) file for each node.
+// Each node is then started as a sub process of this command, using the "run" command.
+//
+// Devnet runs until it receives SIGINT or SIGTERM, after which the nodes are shutdown and ganache-cli is stopped.
+// The generated files are retained in the devnet directory, so that the nodes can be started individually.
+func runDevnet(args []string) error {
+	flags := flag.NewFlagSet("devnet", flag.ExitOnError)
+	nodesCount := flags.Int("nodes", 2, "Number of nodes in the devnet.")
+	dir := flags.String("dir", "devnet", "Directory for the files generated for devnet. Should not already exist.")
+	ganache := flags.String("ganache", "ganache-cli", "Path to the ganache-cli executable.")
+	chainPort := flags.Int("chainport", 8545, "Port at which ganache-cli listens for connections.")
+	commPort := flags.Int("commport", 5750, "Port for off-chain communication of first node, incremented for others.")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *nodesCount < 1 {
+		return errors.New("number of nodes should be at least 1")
+	}
+
+	if _, err := os.Stat(*dir); !os.IsNotExist(err) {
+		return errors.Errorf("devnet directory %s already exists", *dir)
+	}
+	nodes, err := newDevnetNodes(*dir, *nodesCount, *chainPort, *commPort)
+	if err != nil {
+		return err
+	}
+
+	chainURL := nodes[0].cfg.ChainURL
+	ganacheCmd, err := startGanache(*ganache, *dir, *chainPort, nodes)
+	if err != nil {
+		return err
+	}
+	defer stopProcess(ganacheCmd)
+
+	if err = deployContracts(chainURL, nodes); err != nil {
+		return err
+	}
+	if err = writeDevnetFiles(nodes); err != nil {
+		return err
+	}
+
+	exited := make(chan error, len(nodes))
+	for i := range nodes {
+		if err = startNode(nodes[i], exited); err != nil {
+			stopNodes(nodes)
+			return err
+		}
+	}
+	printDevnetSummary(chainURL, nodes)
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(signals)
+	select {
+	case <-signals:
+	case err = <-exited:
+	}
+	fmt.Println("Shutting down devnet")
+	stopNodes(nodes)
+	return err
+}
+
+// newDevnetNodes generates the accounts and the config for each node in the devnet.
+// Same keystore is used for the on-chain and off-chain accounts of a node.
+func newDevnetNodes(dir string, count, chainPort, commPort int) ([]*devnetNode, error) {
+	nodes := make([]*devnetNode, count)
+	for i := range nodes {
+		alias := "node" + strconv.Itoa(i+1)
+		nodeDir := filepath.Join(dir, alias)
+		keystore := filepath.Join(nodeDir, "keystore")
+
+		onChainAddr, onChainKey, err := ethereum.NewAccount(keystore, "")
+		if err != nil {
+			return nil, errors.WithMessage(err, "generating on-chain account for "+alias)
+		}
+		offChainAddr, _, err := ethereum.NewAccount(keystore, "")
+		if err != nil {
+			return nil, errors.WithMessage(err, "generating off-chain account for "+alias)
+		}
+
+		nodes[i] = &devnetNode{
+			dir:        nodeDir,
+			onChainKey: onChainKey,
+			cfg: node.Config{
+				LogLevel:          "info",
+				LogFile:           filepath.Join(nodeDir, "node.log"),
+				ChainURL:          "ws://127.0.0.1:" + strconv.Itoa(chainPort),
+				ChainConnTimeout:  10 * time.Second,
+				DatabaseDir:       filepath.Join(nodeDir, "db"),
+				PeerReconnTimeout: 20 * time.Second,
+				CommDialerTimeout: 10 * time.Second,
+				ContactsFile:      filepath.Join(nodeDir, "contacts.yaml"),
+				User: session.UserConfig{
+					Alias:          alias,
+					OnChainAddr:    onChainAddr,
+					OnChainWallet:  session.WalletConfig{KeystorePath: keystore},
+					PartAddrs:      []string{offChainAddr},
+					OffChainAddr:   offChainAddr,
+					OffChainWallet: session.WalletConfig{KeystorePath: keystore},
+					CommAddr:       "127.0.0.1:" + strconv.Itoa(commPort+i),
+					CommType:       "tcp",
+				},
+			},
+		}
+	}
+	return nodes, nil
+}
+
+// startGanache starts a ganache-cli node with the on-chain accounts of all nodes funded and waits
+// until it starts accepting connections. The output of ganache-cli is written to a log file in the devnet directory.
+func startGanache(ganache, dir string, port int, nodes []*devnetNode) (*exec.Cmd, error) {
+	args := []string{"--port", strconv.Itoa(port)}
+	for _, n := range nodes {
+		args = append(args, fmt.Sprintf("--account=%s,%s", n.onChainKey, devnetBalance))
+	}
+	logFile, err := os.Create(filepath.Join(dir, "ganache.log"))
+	if err != nil {
+		return nil, errors.Wrap(err, "creating log file for ganache-cli")
+	}
+	defer logFile.Close() // nolint: errcheck  // file is retained by the child process after start.
+
+	cmd := exec.Command(ganache, args...)
+	cmd.Stdout = logFile
+	cmd.Stderr = logFile
+	if err = cmd.Start(); err != nil {
+		return nil, errors.Wrap(err, "starting ganache-cli")
+	}
+	return cmd, nil
+}
+
+// deployContracts deploys the adjudicator and asset contracts using the on-chain account of the first node and
+// sets the contract addresses in the config of all nodes. It retries connecting to the blockchain node until
+// devnetChainTimeout, as the node might still be starting.
+func deployContracts(chainURL string, nodes []*devnetNode) error {
+	userCfg := nodes[0].cfg.User
+	addr, err := ethereum.NewWalletBackend().ParseAddr(userCfg.OnChainAddr)
+	if err != nil {
+		return errors.WithMessage(err, "parsing on-chain address")
+	}
+	cred := perun.Credential{
+		Addr:     addr,
+		Keystore: userCfg.OnChainWallet.KeystorePath,
+		Password: userCfg.OnChainWallet.Password,
+	}
+
+	var chain perun.ChainBackend
+	deadline := time.Now().Add(devnetChainTimeout)
+	for {
+		if chain, err = ethereum.NewChainBackend(chainURL, time.Second, cred); err == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			return errors.WithMessage(err, "connecting to ganache-cli")
+		}
+		time.Sleep(500 * time.Millisecond)
+	}
+
+	adjudicator, err := chain.DeployAdjudicator()
+	if err != nil {
+		return errors.WithMessage(err, "deploying adjudicator")
+	}
+	asset, err := chain.DeployAsset(adjudicator)
+	if err != nil {
+		return errors.WithMessage(err, "deploying asset")
+	}
+	for _, n := range nodes {
+		n.cfg.Adjudicator = adjudicator.String()
+		n.cfg.Asset = asset.String()
+	}
+	return nil
+}
+
+// writeDevnetFiles writes the config file and the contacts file for each node.
+// Contacts file of each node has entries for all the other nodes in the devnet.
+func writeDevnetFiles(nodes []*devnetNode) error {
+	for _, n := range nodes {
+		contacts := make(map[string]perun.Peer, len(nodes)-1)
+		for _, peer := range nodes {
+			if peer == n {
+				continue
+			}
+			contacts[peer.cfg.User.Alias] = perun.Peer{
+				Alias:              peer.cfg.User.Alias,
+				OffChainAddrString: peer.cfg.User.OffChainAddr,
+				CommAddr:           peer.cfg.User.CommAddr,
+				CommType:           peer.cfg.User.CommType,
+			}
+		}
+		if err := writeYAML(n.cfg.ContactsFile, contacts); err != nil {
+			return errors.WithMessage(err, "writing contacts file")
+		}
+		if err := writeYAML(n.configFile(), n.cfg); err != nil {
+			return errors.WithMessage(err, "writing config file")
+		}
+	}
+	return nil
+}
+
+// startNode starts the node as a sub process using the "run" command of this executable.
+// When the process exits, an error is sent on the exited channel.
+func startNode(n *devnetNode, exited chan<- error) error {
+	executable, err := os.Executable()
+	if err != nil {
+		return errors.Wrap(err, "locating perunnode executable")
+	}
+	n.cmd = exec.Command(executable, "run", "-config", n.configFile())
+	n.cmd.Stderr = os.Stderr
+	if err = n.cmd.Start(); err != nil {
+		return errors.Wrap(err, "starting "+n.cfg.User.Alias)
+	}
+	n.exited = make(chan struct{})
+	go func() {
+		err := n.cmd.Wait()
+		close(n.exited)
+		exited <- errors.Errorf("%s exited: %v", n.cfg.User.Alias, err)
+	}()
+	return nil
+}
+
+// stopNodes sends SIGTERM to the running nodes, so that they shutdown gracefully, and waits for them to exit.
+func stopNodes(nodes []*devnetNode) {
+	for _, n := range nodes {
+		if n.exited != nil {
+			n.cmd.Process.Signal(syscall.SIGTERM) // nolint: errcheck  // process might have already exited.
+		}
+	}
+	for _, n := range nodes {
+		if n.exited != nil {
+			<-n.exited
+		}
+	}
+}
+
+// stopProcess kills the process and waits for it to exit.
+func stopProcess(cmd *exec.Cmd) {
+	cmd.Process.Kill() // nolint: errcheck  // process might have already exited.
+	cmd.Wait()         // nolint: errcheck  // error is expected, as the process was killed.
+}
+
+func printDevnetSummary(chainURL string, nodes []*devnetNode) {
+	fmt.Printf("Devnet is running. Press Ctrl+C to stop.\n\n")
+	fmt.Printf("Chain URL:   %s\nAdjudicator: %s\nAsset:       %s\n\n",
+		chainURL, nodes[0].cfg.Adjudicator, nodes[0].cfg.Asset)
+	fmt.Printf("%-8s %-44s %-17s %s\n", "Alias", "Off-chain address", "Comm address", "Config file")
+	for _, n := range nodes {
+		user := n.cfg.User
+		fmt.Printf("%-8s %-44s %-17s %s\n", user.Alias, user.OffChainAddr, user.CommAddr, n.configFile())
+	}
+}
+
+func (n *devnetNode) configFile() string {
+	return filepath.Join(n.dir, "node.yaml")
+}
+
+func writeYAML(file string, v interface{}) error {
+	data, err := yaml.Marshal(v)
+	if err != nil {
+		return errors.Wrap(err, "encoding yaml")
+	}
+	return errors.Wrap(ioutil.WriteFile(file, data, 0o600), "writing file")
+}
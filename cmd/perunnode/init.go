@@ -0,0 +1,222 @@
+// Copyright (c) 2020 - for information on the respective copyright owner
+// see the NOTICE file and/or the repository at
+// https://github.com/hyperledger-labs/perun-node
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/hyperledger-labs/perun-node"
+	"github.com/hyperledger-labs/perun-node/blockchain/ethereum"
+	"github.com/hyperledger-labs/perun-node/comm/tcp"
+	"github.com/hyperledger-labs/perun-node/node"
+	"github.com/hyperledger-labs/perun-node/session"
+)
+
+// initParams are the parameters for initializing a node, that can be set using flags or prompts.
+type initParams struct {
+	network, alias, chainURL, adjudicator, asset, commAddr string
+}
+
+// runInit initializes the files for a new node: it generates the on-chain and off-chain accounts, writes
+// the config and an empty contacts file, and then runs a self-check to verify that the node can be started.
+// Finally, it prints the details of the node that should be shared with peers for adding it to their contacts.
+//
+// Parameters not set using flags are prompted for, unless interactive mode is disabled.
+func runInit(args []string) error {
+	flags := flag.NewFlagSet("init", flag.ExitOnError)
+	dir := flags.String("dir", ".", "Directory for the files of the node.")
+	passwordFile := flags.String("password-file", "", "Path to the file containing the password for keystore. "+
+		"If empty, keys are encrypted with an empty password.")
+	interactive := flags.Bool("interactive", true, "Prompt for the parameters not set using flags.")
+	skipCheck := flags.Bool("skip-check", false, "Skip the self-check of chain connection and listener.")
+	params := initParams{}
+	flags.StringVar(&params.network, "network", node.NetworkDev, "Network preset (mainnet, testnet or dev).")
+	flags.StringVar(&params.alias, "alias", "alice", "Alias of the user.")
+	flags.StringVar(&params.chainURL, "chainurl", "", "URL of the blockchain node. Preset value is used if empty.")
+	flags.StringVar(&params.adjudicator, "adjudicator", "", "Adjudicator address. Preset value is used if empty.")
+	flags.StringVar(&params.asset, "asset", "", "Asset address. Preset value is used if empty.")
+	flags.StringVar(&params.commAddr, "commaddr", "127.0.0.1:5751", "Address for off-chain communication.")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *interactive {
+		promptParams(flags, &params, os.Stdin)
+	}
+
+	password := ""
+	if *passwordFile != "" {
+		var err error
+		if password, err = readPassphrase(*passwordFile, passphraseEnv); err != nil {
+			return err
+		}
+	}
+	configFile := filepath.Join(*dir, "node.yaml")
+	if _, err := os.Stat(configFile); !os.IsNotExist(err) {
+		return errors.Errorf("config file %s already exists", configFile)
+	}
+	cfg, err := initNodeFiles(*dir, configFile, password, params)
+	if err != nil {
+		return err
+	}
+	fmt.Println("Config written to", configFile)
+	if *passwordFile != "" {
+		fmt.Println("Set PERUN_USER_ONCHAINWALLET_PASSWORD_FILE and PERUN_USER_OFFCHAINWALLET_PASSWORD_FILE to",
+			*passwordFile, "when running the node.")
+	}
+
+	if !*skipCheck {
+		if err = selfCheck(cfg); err != nil {
+			return err
+		}
+	}
+	printPeerDetails(cfg.User)
+	return nil
+}
+
+// promptParams prompts for the parameters that were not set using flags. Default value is used if the
+// input is empty or if the reader is closed.
+func promptParams(flags *flag.FlagSet, params *initParams, r io.Reader) {
+	setFlags := make(map[string]bool)
+	flags.Visit(func(f *flag.Flag) { setFlags[f.Name] = true })
+	reader := bufio.NewReader(r)
+	prompt := func(name, label string, value *string) {
+		if setFlags[name] {
+			return
+		}
+		fmt.Printf("%s [%s]: ", label, *value)
+		input, _ := reader.ReadString('\n') // nolint: errcheck  // partial input is used, default if empty.
+		if input = strings.TrimSpace(input); input != "" {
+			*value = input
+		}
+	}
+
+	prompt("network", "Network (mainnet, testnet or dev)", &params.network)
+	prompt("alias", "Alias", &params.alias)
+	prompt("chainurl", "Blockchain node URL (empty for preset value)", &params.chainURL)
+	prompt("adjudicator", "Adjudicator address (empty for preset value)", &params.adjudicator)
+	prompt("asset", "Asset address (empty for preset value)", &params.asset)
+	prompt("commaddr", "Address for off-chain communication", &params.commAddr)
+}
+
+// initNodeFiles generates the accounts and writes the config and an empty contacts file. The parameters are
+// validated before generating the accounts, so that no keys are left behind if they are invalid.
+// It returns the config parsed from the written file, so that the file is validated.
+//
+// The password is not written to the config file. It should be passed to the node using the
+// environment variables for wallet passwords (or the corresponding _FILE variables).
+func initNodeFiles(dir, configFile, password string, params initParams) (node.Config, error) {
+	keystore := filepath.Join(dir, "keystore")
+	cfg := node.Config{
+		Network:      params.network,
+		ChainURL:     params.chainURL,
+		Adjudicator:  params.adjudicator,
+		Asset:        params.asset,
+		DatabaseDir:  filepath.Join(dir, "db"),
+		ContactsFile: filepath.Join(dir, "contacts.yaml"),
+		User: session.UserConfig{
+			Alias:          params.alias,
+			OnChainWallet:  session.WalletConfig{KeystorePath: keystore},
+			OffChainWallet: session.WalletConfig{KeystorePath: keystore},
+			CommAddr:       params.commAddr,
+			CommType:       "tcp",
+		},
+	}
+	if err := validateInitConfig(&cfg); err != nil {
+		return node.Config{}, err
+	}
+
+	onChainAddr, _, err := ethereum.NewAccount(keystore, password)
+	if err != nil {
+		return node.Config{}, errors.WithMessage(err, "generating on-chain account")
+	}
+	offChainAddr, _, err := ethereum.NewAccount(keystore, password)
+	if err != nil {
+		return node.Config{}, errors.WithMessage(err, "generating off-chain account")
+	}
+	fmt.Println("Generated accounts in keystore", keystore)
+	cfg.User.OnChainAddr = onChainAddr
+	cfg.User.PartAddrs = []string{offChainAddr}
+	cfg.User.OffChainAddr = offChainAddr
+
+	if err = writeYAML(cfg.ContactsFile, map[string]perun.Peer{}); err != nil {
+		return node.Config{}, errors.WithMessage(err, "writing contacts file")
+	}
+	if err = writeYAML(configFile, cfg); err != nil {
+		return node.Config{}, errors.WithMessage(err, "writing config file")
+	}
+	if cfg, err = node.ParseConfig(configFile); err != nil {
+		return node.Config{}, err
+	}
+	if password != "" {
+		cfg.User.OnChainWallet.Password = password
+		cfg.User.OffChainWallet.Password = password
+	}
+	return cfg, nil
+}
+
+// validateInitConfig applies the network preset to the config and checks the contract addresses and the
+// address for off-chain communication.
+func validateInitConfig(cfg *node.Config) error {
+	if err := node.ApplyPreset(cfg); err != nil {
+		return err
+	}
+	backend := ethereum.NewWalletBackend()
+	if _, err := backend.ParseAddr(cfg.Adjudicator); err != nil {
+		return errors.WithMessage(err, "invalid adjudicator address")
+	}
+	if _, err := backend.ParseAddr(cfg.Asset); err != nil {
+		return errors.WithMessage(err, "invalid asset address")
+	}
+	if _, _, err := net.SplitHostPort(cfg.User.CommAddr); err != nil {
+		return errors.Wrap(err, "invalid address for off-chain communication")
+	}
+	return nil
+}
+
+// selfCheck checks if the accounts can be unlocked, the blockchain node is reachable with valid contracts
+// deployed and the listener for off-chain communication can be started and connected to.
+func selfCheck(cfg node.Config) error {
+	if err := node.Check(cfg); err != nil {
+		return errors.WithMessage(err, "self-check of chain connection failed, update the config and retry")
+	}
+	fmt.Println("Self-check: chain connection and contracts OK")
+	if err := tcp.NewTCPBackend(cfg.CommDialerTimeout).CheckListener(cfg.User.CommAddr); err != nil {
+		return errors.WithMessage(err, "self-check of listener failed, update the config and retry")
+	}
+	fmt.Println("Self-check: listener at", cfg.User.CommAddr, "OK")
+	return nil
+}
+
+// printPeerDetails prints the details of the user in the format of contacts file, so that it can be shared
+// with the peers for adding to their contacts.
+func printPeerDetails(user session.UserConfig) {
+	fmt.Printf("\nShare the following details with your peers for adding this node to their contacts:\n\n")
+	fmt.Printf("%s:\n", user.Alias)
+	fmt.Printf("    alias: %s\n", user.Alias)
+	fmt.Printf("    offchain_address: %s\n", user.OffChainAddr)
+	fmt.Printf("    comm_address: %s\n", user.CommAddr)
+	fmt.Printf("    comm_type: %s\n", user.CommType)
+}
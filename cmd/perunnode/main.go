@@ -0,0 +1,76 @@
+// Copyright (c) 2020 - for information on the respective copyright owner
+// see the NOTICE file and/or the repository at
+// https://github.com/hyperledger-labs/perun-node
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+)
+
+// command represents a sub command of perunnode.
+type command struct {
+	summary string
+	run     func(args []string) error
+}
+
+var commands = map[string]command{
+	"backup":           {summary: "Create an encrypted backup of the node data.", run: runBackup},
+	"config":           {summary: "Print the effective config with secrets masked.", run: runConfig},
+	"devnet":           {summary: "Run a local network of nodes for development.", run: runDevnet},
+	"init":             {summary: "Initialize the keys and config for a new node.", run: runInit},
+	"restore":          {summary: "Restore the node data from a backup.", run: runRestore},
+	"run":              {summary: "Run the node.", run: runNode},
+	"service":          {summary: "Install, uninstall or show the status of the node as a service.", run: runService},
+	"signer":           {summary: "Run the signer holding the off-chain keys in a separate process.", run: runSigner},
+	"update-contracts": {summary: "Fetch, verify and stage the signed contract manifest.", run: runUpdateContracts},
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		printUsage()
+		os.Exit(2)
+	}
+	name := os.Args[1]
+	if name == "help" || name == "-h" || name == "--help" {
+		printUsage()
+		return
+	}
+
+	cmd, ok := commands[name]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "Unknown command %q.\n\n", name)
+		printUsage()
+		os.Exit(2)
+	}
+	if err := cmd.run(os.Args[2:]); err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		os.Exit(1)
+	}
+}
+
+func printUsage() {
+	fmt.Fprintf(os.Stderr, "Usage: perunnode <command> [flags]\n\nCommands:\n")
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(os.Stderr, "  %-18s %s\n", name, commands[name].summary)
+	}
+}
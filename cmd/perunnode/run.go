@@ -0,0 +1,162 @@
+// Copyright (c) 2020 - for information on the respective copyright owner
+// see the NOTICE file and/or the repository at
+// https://github.com/hyperledger-labs/perun-node
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"flag"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/pkg/errors"
+
+	"github.com/hyperledger-labs/perun-node/internal/daemon"
+	"github.com/hyperledger-labs/perun-node/internal/supervisor"
+	"github.com/hyperledger-labs/perun-node/log"
+	"github.com/hyperledger-labs/perun-node/node"
+)
+
+// runNode runs the node until it receives SIGINT or SIGTERM. Other signals handled by the node are:
+//
+// SIGHUP: Reload the config file and apply the changes in reloadable parameters. The config file is also
+// watched for changes and reloaded automatically.
+// SIGUSR1: Reopen the log file, to be used after the log file has been rotated.
+//
+// When started by systemd, the node notifies readiness and sends watchdog notifications (if enabled).
+func runNode(args []string) error {
+	flags := flag.NewFlagSet("run", flag.ExitOnError)
+	configFile := flags.String("config", "node.yaml", "Path to the node config file.")
+	pidFile := flags.String("pidfile", "", "Path to the file for writing the process ID. Not written if empty.")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if *pidFile != "" {
+		if err := daemon.WritePIDFile(*pidFile); err != nil {
+			return err
+		}
+		defer daemon.RemovePIDFile(*pidFile) // nolint: errcheck  // nothing to do if removal fails on exit.
+	}
+
+	cfg, err := node.ParseConfig(*configFile)
+	if err != nil {
+		return err
+	}
+	n, err := node.New(cfg)
+	if err != nil {
+		return err
+	}
+	reloader := node.NewReloader(*configFile, cfg)
+	reloader.OnReload(n.ReloadFeatures)
+	reloader.OnReload(n.ReloadAccess)
+	reloader.OnReload(n.ReloadClosingMode)
+	reloader.OnReload(n.ReloadConfig)
+	n.Info("Node started")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	sup := supervisor.New()
+	notify(n, daemon.StateReady)
+	superviseServices(ctx, sup, n, reloader)
+
+	handleSignals(n, reloader)
+
+	notify(n, daemon.StateStopping)
+	// Services are stopped before shutting down the node, so that they do not send updates (such as payments
+	// for subscriptions) while the client is draining or after it is closed.
+	cancel()
+	sup.Wait()
+	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), reloader.Config().ShutdownTimeout)
+	defer shutdownCancel()
+	return n.Shutdown(shutdownCtx)
+}
+
+// superviseServices runs the background services of the node under the supervisor, until the context is
+// cancelled. The services are restarted if they panic.
+func superviseServices(ctx context.Context, sup *supervisor.Supervisor, n *node.Node, reloader *node.Reloader) {
+	sup.Go(ctx, "config watcher", supervisor.Permanent, func(ctx context.Context) error {
+		reloader.Watch(ctx, node.ConfigWatchInterval)
+		return nil
+	})
+	sup.Go(ctx, "watchdog", supervisor.Transient, func(ctx context.Context) error {
+		return errors.WithMessage(daemon.RunWatchdog(ctx, nil), "sending watchdog notifications")
+	})
+	if n.SkewMonitor != nil {
+		sup.Go(ctx, "skew monitor", supervisor.Permanent, func(ctx context.Context) error {
+			n.SkewMonitor.Run(ctx, node.ClockCheckInterval)
+			return nil
+		})
+	}
+	if n.DiskMonitor != nil {
+		sup.Go(ctx, "disk monitor", supervisor.Permanent, func(ctx context.Context) error {
+			n.DiskMonitor.Run(ctx, node.DiskCheckInterval)
+			return nil
+		})
+	}
+	if n.Discovery != nil {
+		sup.Go(ctx, "discovery", supervisor.Permanent, func(ctx context.Context) error {
+			return n.Discovery.Run(ctx, node.DiscoveryInterval)
+		})
+	}
+	if n.Scheduler != nil {
+		sup.Go(ctx, "scheduler", supervisor.Permanent, func(ctx context.Context) error {
+			n.Scheduler.Run(ctx)
+			return nil
+		})
+	}
+	sup.Go(ctx, "subscriptions", supervisor.Permanent, func(ctx context.Context) error {
+		n.Subscriptions.Run(ctx, node.SubscriptionCheckInterval)
+		return nil
+	})
+	sup.Go(ctx, "streams", supervisor.Permanent, func(ctx context.Context) error {
+		n.Streams.Run(ctx)
+		return nil
+	})
+}
+
+// handleSignals handles the signals for reloading config and reopening log file.
+// It returns when a signal for shutting down the node is received.
+func handleSignals(n *node.Node, reloader *node.Reloader) {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP, syscall.SIGUSR1)
+	defer signal.Stop(signals)
+
+	for sig := range signals {
+		n.Info("Received signal ", sig)
+		switch sig {
+		case syscall.SIGHUP:
+			notify(n, daemon.StateReloading)
+			if err := reloader.Reload(); err != nil {
+				n.Error("Reloading config: ", err)
+			}
+			notify(n, daemon.StateReady)
+		case syscall.SIGUSR1:
+			if err := log.ReopenFile(); err != nil {
+				n.Error("Reopening log file: ", err)
+			}
+		default:
+			return
+		}
+	}
+}
+
+// notify sends the notification to the service manager and logs the error, if any.
+func notify(n *node.Node, state string) {
+	if _, err := daemon.Notify(state); err != nil {
+		n.Error("Sending notification to service manager: ", err)
+	}
+}
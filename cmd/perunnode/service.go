@@ -0,0 +1,122 @@
+// Copyright (c) 2020 - for information on the respective copyright owner
+// see the NOTICE file and/or the repository at
+// https://github.com/hyperledger-labs/perun-node
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+
+	"github.com/hyperledger-labs/perun-node/internal/service"
+	"github.com/hyperledger-labs/perun-node/node"
+)
+
+// runService installs, uninstalls or shows the status of the node as a service. The service manager is
+// systemd on linux and launchd on macOS.
+func runService(args []string) error {
+	usage := errors.New("usage: perunnode service install|uninstall|status [flags]")
+	if len(args) < 1 {
+		return usage
+	}
+	m, err := service.New()
+	if err != nil {
+		return err
+	}
+	switch args[0] {
+	case "install":
+		return installService(m, args[1:])
+	case "uninstall":
+		return uninstallService(m, args[1:])
+	case "status":
+		return serviceStatus(m, args[1:])
+	default:
+		return usage
+	}
+}
+
+// installService installs the node as a service that runs with the given config file and starts it.
+// The working directory of the service is the directory of the config file.
+func installService(m service.Manager, args []string) error {
+	flags := flag.NewFlagSet("service install", flag.ExitOnError)
+	name := flags.String("name", service.DefaultName, "Name of the service.")
+	configFile := flags.String("config", "node.yaml", "Path to the node config file.")
+	user := flags.String("user", "", "User to run the service as (systemd only). Defaults to root.")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if _, err := node.ParseConfig(*configFile); err != nil {
+		return err
+	}
+	absConfigFile, err := filepath.Abs(*configFile)
+	if err != nil {
+		return errors.Wrap(err, "resolving config file path")
+	}
+	executable, err := os.Executable()
+	if err != nil {
+		return errors.Wrap(err, "finding path of executable")
+	}
+	if executable, err = filepath.EvalSymlinks(executable); err != nil {
+		return errors.Wrap(err, "resolving path of executable")
+	}
+
+	err = m.Install(service.Config{
+		Name:        *name,
+		Description: "Perun node",
+		Executable:  executable,
+		Args:        []string{"run", "-config", absConfigFile},
+		WorkingDir:  filepath.Dir(absConfigFile),
+		User:        *user,
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Installed and started service %s.\n", *name)
+	return nil
+}
+
+func uninstallService(m service.Manager, args []string) error {
+	flags := flag.NewFlagSet("service uninstall", flag.ExitOnError)
+	name := flags.String("name", service.DefaultName, "Name of the service.")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if err := m.Uninstall(*name); err != nil {
+		return err
+	}
+	fmt.Printf("Stopped and uninstalled service %s.\n", *name)
+	return nil
+}
+
+func serviceStatus(m service.Manager, args []string) error {
+	flags := flag.NewFlagSet("service status", flag.ExitOnError)
+	name := flags.String("name", service.DefaultName, "Name of the service.")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	status, err := m.Status(*name)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Service %s: %s\n", *name, status)
+	return nil
+}
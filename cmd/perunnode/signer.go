@@ -0,0 +1,129 @@
+// Copyright (c) 2020 - for information on the respective copyright owner
+// see the NOTICE file and/or the repository at
+// https://github.com/hyperledger-labs/perun-node
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/pkg/errors"
+	"perun.network/go-perun/wallet"
+
+	"github.com/hyperledger-labs/perun-node/blockchain/ethereum"
+	"github.com/hyperledger-labs/perun-node/signer"
+)
+
+// signerPasswordEnv is the environment variable used for reading the keystore password of the signer,
+// when the password file is not specified.
+const signerPasswordEnv = "PERUN_SIGNER_PASSWORD"
+
+// runSigner runs the signer holding the off-chain keys, until it receives SIGINT or SIGTERM.
+//
+// The signer listens only on a unix socket, which (along with all files created by the signer) is
+// accessible only to the owner. It refuses to run as root, so that it can be run as a dedicated
+// unprivileged user. The token file is generated if it does not exist.
+func runSigner(args []string) error {
+	flags := flag.NewFlagSet("signer", flag.ExitOnError)
+	socketPath := flags.String("socket", "signer.sock", "Path of the unix socket to listen on.")
+	tokenFile := flags.String("token-file", "signer.token", "Path to the file containing the token "+
+		"for authenticating the node. Generated if it does not exist.")
+	keystore := flags.String("keystore", "", "Path to the keystore containing the off-chain keys.")
+	passwordFile := flags.String("password-file", "", "Path to the file containing the password for keystore. "+
+		"If empty, it is read from "+signerPasswordEnv+".")
+	addrs := flags.String("addrs", "", "Comma separated list of addresses (off-chain and participant) to unlock.")
+	allowRoot := flags.Bool("allow-root", false, "Allow running as root.")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if os.Geteuid() == 0 && !*allowRoot {
+		return errors.New("signer should not be run as root, use -allow-root to override")
+	}
+	syscall.Umask(0o077)
+
+	accs, err := unlockSignerAccounts(*keystore, *passwordFile, *addrs)
+	if err != nil {
+		return err
+	}
+	token, err := signerToken(*tokenFile)
+	if err != nil {
+		return err
+	}
+	s, err := signer.NewServer(token, accs...)
+	if err != nil {
+		return err
+	}
+	l, err := signer.Listen(*socketPath)
+	if err != nil {
+		return err
+	}
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+	go func() {
+		<-signals
+		s.Close() // nolint: errcheck, gosec  // Serve returns once the listener is closed.
+	}()
+	fmt.Printf("Signer listening on %s with %d accounts.\n", *socketPath, len(accs))
+	if err = s.Serve(l); err != nil {
+		s.Info("Signer stopped: ", err)
+	}
+	return nil
+}
+
+// unlockSignerAccounts unlocks the accounts for the given comma separated addresses in the keystore.
+func unlockSignerAccounts(keystore, passwordFile, addrs string) ([]wallet.Account, error) {
+	if keystore == "" || addrs == "" {
+		return nil, errors.New("keystore and addrs should be specified")
+	}
+	password, err := readPassphrase(passwordFile, signerPasswordEnv)
+	if err != nil {
+		return nil, err
+	}
+	wb := ethereum.NewWalletBackend()
+	w, err := wb.NewWallet(keystore, password)
+	if err != nil {
+		return nil, err
+	}
+	var accs []wallet.Account
+	for _, addrString := range strings.Split(addrs, ",") {
+		addr, parseErr := wb.ParseAddr(strings.TrimSpace(addrString))
+		if parseErr != nil {
+			return nil, parseErr
+		}
+		acc, unlockErr := wb.UnlockAccount(w, addr)
+		if unlockErr != nil {
+			return nil, unlockErr
+		}
+		accs = append(accs, acc)
+	}
+	return accs, nil
+}
+
+// signerToken reads the token from the file, generating it if the file does not exist.
+func signerToken(tokenFile string) ([]byte, error) {
+	if _, err := os.Stat(tokenFile); os.IsNotExist(err) {
+		if err = signer.GenerateTokenFile(tokenFile); err != nil {
+			return nil, err
+		}
+		fmt.Printf("Generated token file %s, set it as signertokenfile in the node config.\n", tokenFile)
+	}
+	return signer.ReadTokenFile(tokenFile)
+}
@@ -0,0 +1,81 @@
+// Copyright (c) 2020 - for information on the respective copyright owner
+// see the NOTICE file and/or the repository at
+// https://github.com/hyperledger-labs/perun-node
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package comm
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/hyperledger-labs/perun-node"
+)
+
+// Config is the configuration passed to the backend factories.
+type Config struct {
+	// Timeout to be used when dialing for new outgoing connections, zero for no timeout.
+	DialerTimeout time.Duration
+}
+
+// Factory returns a backend for off-chain communication using the given config.
+type Factory func(cfg Config) (perun.CommBackend, error)
+
+var (
+	mutex     sync.RWMutex
+	factories = make(map[string]Factory)
+)
+
+// Register registers the factory for the comm type. It is intended to be called from the init function
+// of the package implementing the backend.
+//
+// It panics if a factory is already registered for the comm type or if the factory is nil.
+func Register(commType string, factory Factory) {
+	if factory == nil {
+		panic("comm: nil factory for " + commType)
+	}
+	mutex.Lock()
+	defer mutex.Unlock()
+	if _, ok := factories[commType]; ok {
+		panic("comm: factory already registered for " + commType)
+	}
+	factories[commType] = factory
+}
+
+// New returns a backend for the comm type, using the registered factory.
+func New(commType string, cfg Config) (perun.CommBackend, error) {
+	mutex.RLock()
+	factory, ok := factories[commType]
+	mutex.RUnlock()
+	if !ok {
+		return nil, errors.Errorf("unsupported comm type %q", commType)
+	}
+	backend, err := factory(cfg)
+	return backend, errors.WithMessage(err, commType)
+}
+
+// Types returns the sorted list of registered comm types.
+func Types() []string {
+	mutex.RLock()
+	defer mutex.RUnlock()
+	types := make([]string, 0, len(factories))
+	for commType := range factories {
+		types = append(types, commType)
+	}
+	sort.Strings(types)
+	return types
+}
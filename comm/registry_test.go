@@ -0,0 +1,64 @@
+// Copyright (c) 2020 - for information on the respective copyright owner
+// see the NOTICE file and/or the repository at
+// https://github.com/hyperledger-labs/perun-node
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package comm_test
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger-labs/perun-node"
+	"github.com/hyperledger-labs/perun-node/comm"
+	"github.com/hyperledger-labs/perun-node/internal/mocks"
+)
+
+var (
+	backend      = &mocks.CommBackend{}
+	happyFactory = func(comm.Config) (perun.CommBackend, error) { return backend, nil }
+	errFactory   = func(comm.Config) (perun.CommBackend, error) { return nil, errors.New("error") }
+)
+
+func init() {
+	comm.Register("test-happy", happyFactory)
+	comm.Register("test-err", errFactory)
+}
+
+func Test_Registry(t *testing.T) {
+
+	t.Run("happy", func(t *testing.T) {
+		got, err := comm.New("test-happy", comm.Config{})
+		require.NoError(t, err)
+		assert.Equal(t, backend, got)
+		assert.Subset(t, comm.Types(), []string{"test-err", "test-happy"})
+	})
+	t.Run("err_unknown_type", func(t *testing.T) {
+		_, err := comm.New("test-unknown", comm.Config{})
+		assert.Error(t, err)
+	})
+	t.Run("err_factory", func(t *testing.T) {
+		_, err := comm.New("test-err", comm.Config{})
+		assert.Error(t, err)
+	})
+	t.Run("err_duplicate", func(t *testing.T) {
+		assert.Panics(t, func() { comm.Register("test-happy", happyFactory) })
+	})
+	t.Run("err_nil_factory", func(t *testing.T) {
+		assert.Panics(t, func() { comm.Register("test-nil", nil) })
+	})
+}
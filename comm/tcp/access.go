@@ -0,0 +1,213 @@
+// Copyright (c) 2020 - for information on the respective copyright owner
+// see the NOTICE file and/or the repository at
+// https://github.com/hyperledger-labs/perun-node
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tcp
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"net"
+	"sync"
+	"sync/atomic"
+
+	"github.com/pkg/errors"
+	"perun.network/go-perun/wallet"
+	"perun.network/go-perun/wire"
+	wirenet "perun.network/go-perun/wire/net"
+)
+
+// AccessRules are the rules for accepting incoming connections, based on the IP address of the peer and
+// its off-chain address. Deny rules take precedence over allow rules. If an allow list is empty, all the
+// IP addresses (or peers) that are not denied are accepted.
+//
+// The off-chain address sent by the peer when connecting is not authenticated, any peer can claim any
+// address. Hence, peer rules apply to the address in the common name of the TLS client certificate of the
+// peer, which should be verified using a CA (see NewTLSConfig), and the peer should send the same address.
+// If there are peer rules, connections without a verified client certificate are rejected. Without mutual
+// TLS, only the CIDR rules can be enforced.
+type AccessRules struct {
+	AllowCIDRs []string `json:"allowCIDRs" yaml:"allowcidrs"`
+	DenyCIDRs  []string `json:"denyCIDRs" yaml:"denycidrs"`
+	AllowPeers []string `json:"allowPeers" yaml:"allowpeers"` // Off-chain addresses.
+	DenyPeers  []string `json:"denyPeers" yaml:"denypeers"`   // Off-chain addresses.
+}
+
+// AccessList enforces the access rules on the incoming connections of the listeners. The rules can be
+// updated at runtime and apply to the connections accepted afterwards. Peer rules also apply to the
+// messages received afterwards on the existing incoming connections. It is safe for concurrent use.
+type AccessList struct {
+	parseAddr func(string) (wallet.Address, error)
+
+	mutex      sync.RWMutex
+	rules      AccessRules
+	allowNets  []*net.IPNet
+	denyNets   []*net.IPNet
+	allowPeers map[wallet.AddrKey]bool
+	denyPeers  map[wallet.AddrKey]bool
+}
+
+// NewAccessList returns an access list with the given rules. Off-chain addresses in the rules are parsed
+// using parseAddr.
+func NewAccessList(rules AccessRules, parseAddr func(string) (wallet.Address, error)) (*AccessList, error) {
+	a := &AccessList{parseAddr: parseAddr}
+	if err := a.SetRules(rules); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// Rules returns the current access rules.
+func (a *AccessList) Rules() AccessRules {
+	a.mutex.RLock()
+	defer a.mutex.RUnlock()
+	return a.rules
+}
+
+// SetRules replaces the access rules. If any of the rules is invalid, an error is returned and the
+// current rules are retained.
+func (a *AccessList) SetRules(rules AccessRules) error {
+	allowNets, err := parseCIDRs(rules.AllowCIDRs)
+	if err != nil {
+		return err
+	}
+	denyNets, err := parseCIDRs(rules.DenyCIDRs)
+	if err != nil {
+		return err
+	}
+	allowPeers, err := a.parsePeers(rules.AllowPeers)
+	if err != nil {
+		return err
+	}
+	denyPeers, err := a.parsePeers(rules.DenyPeers)
+	if err != nil {
+		return err
+	}
+
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	a.rules = rules
+	a.allowNets, a.denyNets = allowNets, denyNets
+	a.allowPeers, a.denyPeers = allowPeers, denyPeers
+	return nil
+}
+
+// allowsIP returns true if connections from the IP address are allowed. It is true for a nil list.
+func (a *AccessList) allowsIP(ip net.IP) bool {
+	if a == nil {
+		return true
+	}
+	a.mutex.RLock()
+	defer a.mutex.RUnlock()
+	if containsIP(a.denyNets, ip) {
+		return false
+	}
+	return len(a.allowNets) == 0 || containsIP(a.allowNets, ip)
+}
+
+// allowsPeer returns true if messages from the peer with the given off-chain address are allowed, on a
+// connection authenticated by the client certificate (nil if there is none). If there are peer rules, the
+// address should be the one in the certificate. It is true for a nil list.
+func (a *AccessList) allowsPeer(addr wallet.Address, cert *x509.Certificate) bool {
+	if a == nil {
+		return true
+	}
+	a.mutex.RLock()
+	defer a.mutex.RUnlock()
+	if len(a.allowPeers) == 0 && len(a.denyPeers) == 0 {
+		return true
+	}
+	if cert == nil {
+		return false
+	}
+	certAddr, err := a.parseAddr(cert.Subject.CommonName)
+	if err != nil || !certAddr.Equals(addr) {
+		return false
+	}
+	key := wallet.Key(certAddr)
+	if a.denyPeers[key] {
+		return false
+	}
+	return len(a.allowPeers) == 0 || a.allowPeers[key]
+}
+
+func (a *AccessList) parsePeers(peers []string) (map[wallet.AddrKey]bool, error) {
+	keys := make(map[wallet.AddrKey]bool, len(peers))
+	for _, peer := range peers {
+		addr, err := a.parseAddr(peer)
+		if err != nil {
+			return nil, errors.WithMessagef(err, "parsing peer address %s", peer)
+		}
+		keys[wallet.Key(addr)] = true
+	}
+	return keys, nil
+}
+
+func parseCIDRs(cidrs []string) ([]*net.IPNet, error) {
+	nets := make([]*net.IPNet, len(cidrs))
+	for i, cidr := range cidrs {
+		_, ipNet, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return nil, errors.Wrap(err, "parsing cidr")
+		}
+		nets[i] = ipNet
+	}
+	return nets, nil
+}
+
+func containsIP(nets []*net.IPNet, ip net.IP) bool {
+	for _, ipNet := range nets {
+		if ipNet.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// accessConn is an incoming connection, on which only the messages from the peers allowed by the access
+// list are received. As the first message is the address of the peer, connections from peers that are not
+// allowed are closed before they are registered by go-perun.
+type accessConn struct {
+	wirenet.Conn
+	tlsConn *tls.Conn // Underlying TLS connection, nil if TLS is not used.
+	access  *AccessList
+	stats   *ListenerStats // Should be accessed atomically.
+}
+
+func (c *accessConn) Recv() (*wire.Envelope, error) {
+	env, err := c.Conn.Recv()
+	if err != nil {
+		return nil, err
+	}
+	if !c.access.allowsPeer(env.Sender, c.clientCert()) {
+		atomic.AddUint64(&c.stats.RejectedPeer, 1)
+		c.Conn.Close() // nolint: errcheck, gosec  // connection is rejected, nothing to do if closing fails.
+		return nil, errors.Errorf("peer %v is not allowed by access list", env.Sender)
+	}
+	return env, nil
+}
+
+// clientCert returns the client certificate of the peer, if it was verified during the TLS handshake. As the
+// handshake completes before the first message is read, it should be called after receiving a message.
+func (c *accessConn) clientCert() *x509.Certificate {
+	if c.tlsConn == nil {
+		return nil
+	}
+	state := c.tlsConn.ConnectionState()
+	if len(state.VerifiedChains) == 0 || len(state.VerifiedChains[0]) == 0 {
+		return nil
+	}
+	return state.VerifiedChains[0][0]
+}
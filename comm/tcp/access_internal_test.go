@@ -0,0 +1,215 @@
+// Copyright (c) 2020 - for information on the respective copyright owner
+// see the NOTICE file and/or the repository at
+// https://github.com/hyperledger-labs/perun-node
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tcp
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"perun.network/go-perun/pkg/test"
+	"perun.network/go-perun/wallet"
+	"perun.network/go-perun/wire"
+
+	"github.com/hyperledger-labs/perun-node/blockchain/ethereum/ethereumtest"
+)
+
+func Test_AccessList(t *testing.T) {
+	rng := test.Prng(t)
+	alice, bob := ethereumtest.NewRandomAddress(rng), ethereumtest.NewRandomAddress(rng)
+	parseAddr := func(s string) (wallet.Address, error) {
+		switch s {
+		case "alice":
+			return alice, nil
+		case "bob":
+			return bob, nil
+		}
+		return nil, errors.New("invalid address")
+	}
+	aliceCert, bobCert := newCertFor("alice"), newCertFor("bob")
+
+	t.Run("happy_nil", func(t *testing.T) {
+		var a *AccessList
+		assert.True(t, a.allowsIP(net.ParseIP("10.0.0.1")))
+		assert.True(t, a.allowsPeer(alice, nil))
+	})
+	t.Run("happy_no_peer_rules", func(t *testing.T) {
+		a, err := NewAccessList(AccessRules{DenyCIDRs: []string{"10.0.0.0/8"}}, parseAddr)
+		require.NoError(t, err)
+		assert.True(t, a.allowsPeer(alice, nil), "certificate is not required without peer rules")
+	})
+	t.Run("happy_deny", func(t *testing.T) {
+		a, err := NewAccessList(AccessRules{DenyCIDRs: []string{"10.0.0.0/8"}, DenyPeers: []string{"bob"}}, parseAddr)
+		require.NoError(t, err)
+		assert.False(t, a.allowsIP(net.ParseIP("10.1.2.3")))
+		assert.True(t, a.allowsIP(net.ParseIP("192.168.1.1")))
+		assert.True(t, a.allowsPeer(alice, aliceCert))
+		assert.False(t, a.allowsPeer(bob, bobCert))
+	})
+	t.Run("happy_allow", func(t *testing.T) {
+		a, err := NewAccessList(AccessRules{
+			AllowCIDRs: []string{"10.0.0.0/8", "::1/128"},
+			DenyCIDRs:  []string{"10.0.0.0/16"},
+			AllowPeers: []string{"alice"},
+		}, parseAddr)
+		require.NoError(t, err)
+		assert.True(t, a.allowsIP(net.ParseIP("10.1.2.3")))
+		assert.True(t, a.allowsIP(net.ParseIP("::1")))
+		assert.False(t, a.allowsIP(net.ParseIP("10.0.2.3")), "deny rules should take precedence")
+		assert.False(t, a.allowsIP(net.ParseIP("192.168.1.1")))
+		assert.True(t, a.allowsPeer(alice, aliceCert))
+		assert.False(t, a.allowsPeer(bob, bobCert))
+	})
+	t.Run("err_unauthenticated_peer", func(t *testing.T) {
+		a, err := NewAccessList(AccessRules{DenyPeers: []string{"bob"}}, parseAddr)
+		require.NoError(t, err)
+		assert.False(t, a.allowsPeer(alice, nil), "peer without certificate")
+		assert.False(t, a.allowsPeer(alice, bobCert), "peer claiming an address other than in its certificate")
+		assert.False(t, a.allowsPeer(alice, newCertFor("carol")), "certificate without a valid address")
+	})
+	t.Run("err_invalid_rules", func(t *testing.T) {
+		_, err := NewAccessList(AccessRules{DenyCIDRs: []string{"10.0.0.1"}}, parseAddr)
+		assert.Error(t, err)
+
+		a, err := NewAccessList(AccessRules{DenyPeers: []string{"bob"}}, parseAddr)
+		require.NoError(t, err)
+		assert.Error(t, a.SetRules(AccessRules{DenyPeers: []string{"carol"}}))
+		assert.False(t, a.allowsPeer(bob, bobCert), "rules should be retained on error")
+	})
+}
+
+func Test_Listener_AccessList(t *testing.T) {
+	rng := test.Prng(t)
+	alice, bob, self := ethereumtest.NewRandomAddress(rng), ethereumtest.NewRandomAddress(rng),
+		ethereumtest.NewRandomAddress(rng)
+	parseAddr := func(s string) (wallet.Address, error) {
+		switch s {
+		case "alice":
+			return alice, nil
+		case "bob":
+			return bob, nil
+		}
+		return nil, errors.New("invalid address")
+	}
+
+	dir, err := ioutil.TempDir("", "perun-node-access")
+	require.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(dir) }) // nolint: errcheck, gosec  // error not relevant in test.
+	serverCert, serverKey := newSelfSignedCert(t, dir, "server")
+	aliceCert, aliceKey := newSelfSignedCert(t, dir, "alice")
+	bobCert, bobKey := newSelfSignedCert(t, dir, "bob")
+	caFile := filepath.Join(dir, "ca.crt")
+	var caPEM []byte
+	for _, certFile := range []string{aliceCert, bobCert} {
+		certPEM, readErr := ioutil.ReadFile(certFile)
+		require.NoError(t, readErr)
+		caPEM = append(caPEM, certPEM...)
+	}
+	require.NoError(t, ioutil.WriteFile(caFile, caPEM, 0o600))
+	serverTLS, err := NewTLSConfig(serverCert, serverKey, caFile, false)
+	require.NoError(t, err)
+	aliceTLS, err := NewTLSConfig(aliceCert, aliceKey, serverCert, false)
+	require.NoError(t, err)
+	bobTLS, err := NewTLSConfig(bobCert, bobKey, serverCert, false)
+	require.NoError(t, err)
+
+	newListener := func(t *testing.T, rules AccessRules, tlsConfig *tls.Config) (Backend, *Listener) {
+		access, err := NewAccessList(rules, parseAddr)
+		require.NoError(t, err)
+		backend := NewTCPBackend(time.Second).WithAccessList(access)
+		if tlsConfig != nil {
+			backend = backend.WithTLS(tlsConfig)
+		}
+		listener, err := backend.NewListener("127.0.0.1:0")
+		require.NoError(t, err)
+		t.Cleanup(func() { listener.Close() }) // nolint: errcheck, gosec  // error not relevant in test.
+		return backend, listener.(*Listener)
+	}
+	// sendFrom dials the listener (using TLS if clientTLS is not nil), sends a message from the peer and
+	// returns the error in receiving it.
+	sendFrom := func(t *testing.T, l *Listener, clientTLS *tls.Config, peer wire.Address) error {
+		recvd := make(chan error, 1)
+		go func() {
+			conn, err := l.Accept()
+			if err == nil {
+				_, err = conn.Recv()
+			}
+			recvd <- err
+		}()
+		backend := NewTCPBackend(time.Second)
+		if clientTLS != nil {
+			backend = backend.WithTLS(clientTLS)
+		}
+		d := backend.NewDialer().(*dialer)
+		d.Register(self, l.Addr().String())
+		conn, err := d.Dial(context.Background(), self)
+		require.NoError(t, err)
+		defer conn.Close() // nolint: errcheck  // error not relevant in test.
+		require.NoError(t, conn.Send(&wire.Envelope{Sender: peer, Recipient: self, Msg: wire.NewPingMsg()}))
+		return <-recvd
+	}
+
+	t.Run("happy", func(t *testing.T) {
+		_, l := newListener(t, AccessRules{DenyPeers: []string{"bob"}}, serverTLS)
+		assert.NoError(t, sendFrom(t, l, aliceTLS, alice))
+	})
+	t.Run("happy_no_peer_rules", func(t *testing.T) {
+		_, l := newListener(t, AccessRules{DenyCIDRs: []string{"10.0.0.0/8"}}, nil)
+		assert.NoError(t, sendFrom(t, l, nil, bob))
+	})
+	t.Run("err_peer_denied", func(t *testing.T) {
+		backend, l := newListener(t, AccessRules{DenyPeers: []string{"bob"}}, serverTLS)
+		assert.Error(t, sendFrom(t, l, bobTLS, bob))
+		assert.Equal(t, uint64(1), backend.ListenerStats().RejectedPeer)
+	})
+	t.Run("err_peer_impersonated", func(t *testing.T) {
+		backend, l := newListener(t, AccessRules{DenyPeers: []string{"bob"}}, serverTLS)
+		assert.Error(t, sendFrom(t, l, bobTLS, alice), "bob claiming to be alice")
+		assert.Equal(t, uint64(1), backend.ListenerStats().RejectedPeer)
+	})
+	t.Run("err_peer_without_tls", func(t *testing.T) {
+		backend, l := newListener(t, AccessRules{AllowPeers: []string{"alice"}}, nil)
+		assert.Error(t, sendFrom(t, l, nil, alice))
+		assert.Equal(t, uint64(1), backend.ListenerStats().RejectedPeer)
+	})
+	t.Run("err_ip_denied", func(t *testing.T) {
+		backend, l := newListener(t, AccessRules{DenyCIDRs: []string{"127.0.0.0/8"}}, nil)
+		go l.Accept() // nolint: errcheck  // returns error when the listener is closed.
+		conn, err := net.Dial("tcp", l.Addr().String())
+		require.NoError(t, err)
+		defer conn.Close() // nolint: errcheck  // error not relevant in test.
+		require.NoError(t, conn.SetReadDeadline(time.Now().Add(time.Second)))
+		_, err = conn.Read(make([]byte, 1))
+		assert.Error(t, err, "connection should be closed by listener")
+		assert.Equal(t, uint64(1), backend.ListenerStats().RejectedIP)
+	})
+}
+
+// newCertFor returns a certificate with the name as common name.
+func newCertFor(name string) *x509.Certificate {
+	return &x509.Certificate{Subject: pkix.Name{CommonName: name}}
+}
@@ -0,0 +1,90 @@
+// Copyright (c) 2020 - for information on the respective copyright owner
+// see the NOTICE file and/or the repository at
+// https://github.com/hyperledger-labs/perun-node
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tcp
+
+import (
+	"context"
+	"net"
+	"time"
+
+	"github.com/pkg/errors"
+	"perun.network/go-perun/wire"
+)
+
+// defaultCheckTimeout is used by CheckListener and CheckReachable when the dialer timeout of the backend is zero.
+const defaultCheckTimeout = 5 * time.Second
+
+// CheckListener checks if a listener can be started at the given address and if a connection
+// dialed to the address is accepted by it. The listener is closed before returning.
+//
+// This can be used as a self-check before starting the node, as it detects addresses that are invalid,
+// already in use or not reachable over the loopback interface.
+func (b Backend) CheckListener(addr string) error {
+	listener, err := b.NewListener(addr)
+	if err != nil {
+		return err
+	}
+	defer listener.Close() // nolint: errcheck  // listener is used only for the check.
+
+	timeout := b.dialerTimeout
+	if timeout == 0 {
+		timeout = defaultCheckTimeout
+	}
+	accepted := make(chan error, 1)
+	go func() {
+		conn, acceptErr := listener.Accept()
+		if acceptErr == nil {
+			acceptErr = conn.Close()
+		}
+		accepted <- acceptErr
+	}()
+
+	conn, err := net.DialTimeout("tcp", addr, timeout)
+	if err != nil {
+		return errors.Wrap(err, "dialing listener")
+	}
+	if err = conn.Close(); err != nil {
+		return errors.Wrap(err, "closing connection")
+	}
+	select {
+	case err = <-accepted:
+		return errors.Wrap(err, "accepting connection")
+	case <-time.After(timeout):
+		return errors.New("timed out waiting for listener to accept connection")
+	}
+}
+
+// CheckReachable checks if a connection can be established to the peer at the given address within the
+// dialer timeout of the backend. It can be used for checking the liveness of peers. The connection is dialed
+// in the same way as for the channels with the peer: addresses given as DNS records (see SRVScheme) are
+// resolved, the proxy configured for the peer is used and, if TLS is enabled, the handshake is completed.
+func (b Backend) CheckReachable(peer wire.Address, addr string) error {
+	timeout := b.dialerTimeout
+	if timeout == 0 {
+		timeout = defaultCheckTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	d := newDialer(b)
+	defer d.Close() // nolint: errcheck  // dialer is used only for the check.
+	d.Register(peer, addr)
+	conn, err := d.Dial(ctx, peer)
+	if err != nil {
+		return err
+	}
+	return errors.WithMessage(conn.Close(), "closing connection")
+}
@@ -0,0 +1,127 @@
+// Copyright (c) 2020 - for information on the respective copyright owner
+// see the NOTICE file and/or the repository at
+// https://github.com/hyperledger-labs/perun-node
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tcp
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"net/url"
+	"sync"
+
+	"github.com/pkg/errors"
+	pkgsync "perun.network/go-perun/pkg/sync"
+	"perun.network/go-perun/wallet"
+	"perun.network/go-perun/wire"
+	wirenet "perun.network/go-perun/wire/net"
+)
+
+// dialer dials peers over tcp, optionally via a proxy and secured with TLS. The connections are metered
+// if metrics are enabled. Like the dialer in go-perun,
+// it dials only the peers whose address is registered. Addresses given as DNS records are resolved
+// before each dial, see SRVScheme, unless a proxy is used for the peer.
+type dialer struct {
+	mutex     sync.RWMutex
+	peers     map[wallet.AddrKey]string
+	netDialer *net.Dialer
+	tlsConfig *tls.Config
+	proxies   Proxies
+	metrics   *metrics
+	resolver  *resolver
+
+	pkgsync.Closer
+}
+
+func newDialer(b Backend) *dialer {
+	return &dialer{
+		peers:     make(map[wallet.AddrKey]string),
+		netDialer: &net.Dialer{Timeout: b.dialerTimeout, KeepAlive: b.keepAlive},
+		tlsConfig: b.tlsConfig,
+		proxies:   b.proxies,
+		metrics:   b.metrics,
+		resolver:  b.resolver,
+	}
+}
+
+// Dial implements the net.Dialer interface defined in go-perun. If TLS is used, the connection is
+// returned after the handshake is complete.
+func (d *dialer) Dial(ctx context.Context, addr wire.Address) (wirenet.Conn, error) {
+	d.mutex.RLock()
+	commAddr, ok := d.peers[wallet.Key(addr)]
+	d.mutex.RUnlock()
+	if !ok {
+		return nil, errors.New("peer not found")
+	}
+
+	// Dialing should be aborted when the dialer is closed. Like in go-perun, a goroutine is used for this, as
+	// callbacks registered on the closer are never removed.
+	done := make(chan struct{})
+	defer close(done)
+	ctx, cancel := context.WithCancel(ctx)
+	go func() {
+		defer cancel()
+		select {
+		case <-d.Closed():
+		case <-done:
+		}
+	}()
+
+	proxyURL := d.proxies.forPeer(addr)
+	if proxyURL != nil && isDNSRecord(commAddr) {
+		// Resolving the record locally would send DNS queries outside the proxy.
+		return nil, errors.Errorf("failed to dial peer: address %s cannot be resolved via proxy", commAddr)
+	}
+	host, err := d.resolver.resolve(ctx, commAddr)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to dial peer")
+	}
+	conn, err := d.dialTCP(ctx, proxyURL, host)
+	if err != nil {
+		d.resolver.invalidate(commAddr)
+		return nil, errors.WithMessage(err, "failed to dial peer")
+	}
+	if d.tlsConfig == nil {
+		return d.metrics.wrap(conn), nil
+	}
+
+	config := d.tlsConfig.Clone()
+	if config.ServerName == "" {
+		config.ServerName, _, _ = net.SplitHostPort(host) // nolint: errcheck  // host was dialed, so it is valid.
+	}
+	tlsConn := tls.Client(conn, config)
+	if err = handshake(ctx, tlsConn); err != nil {
+		conn.Close() // nolint: errcheck, gosec  // handshake error is returned.
+		return nil, err
+	}
+	return d.metrics.wrap(tlsConn), nil
+}
+
+// dialTCP dials the host, via the proxy if it is not nil.
+func (d *dialer) dialTCP(ctx context.Context, proxyURL *url.URL, host string) (net.Conn, error) {
+	if proxyURL == nil {
+		conn, err := d.netDialer.DialContext(ctx, "tcp", host)
+		return conn, errors.WithStack(err)
+	}
+	return dialViaProxy(ctx, proxyURL, d.netDialer, host)
+}
+
+// Register registers the network address (host:port or a DNS record, see SRVScheme) for the peer address.
+func (d *dialer) Register(addr wire.Address, address string) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	d.peers[wallet.Key(addr)] = address
+}
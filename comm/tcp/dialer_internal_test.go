@@ -0,0 +1,72 @@
+// Copyright (c) 2020 - for information on the respective copyright owner
+// see the NOTICE file and/or the repository at
+// https://github.com/hyperledger-labs/perun-node
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tcp
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"perun.network/go-perun/pkg/test"
+
+	"github.com/hyperledger-labs/perun-node/blockchain/ethereum/ethereumtest"
+)
+
+func Test_Backend_NewDialer(t *testing.T) {
+	t.Run("happy_keep_alive", func(t *testing.T) {
+		d, ok := NewTCPBackend(time.Second).WithKeepAlive(time.Minute).NewDialer().(*dialer)
+		require.True(t, ok)
+		assert.Equal(t, time.Minute, d.netDialer.KeepAlive)
+		assert.Equal(t, time.Second, d.netDialer.Timeout)
+	})
+	t.Run("happy_close_aborts_dial", func(t *testing.T) {
+		// Server accepts the connection, but never completes the TLS handshake.
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		t.Cleanup(func() { listener.Close() }) // nolint: errcheck, gosec  // error not relevant in test.
+		go func() {
+			conn, acceptErr := listener.Accept()
+			if acceptErr == nil {
+				t.Cleanup(func() { conn.Close() }) // nolint: errcheck, gosec  // error not relevant in test.
+			}
+		}()
+
+		tlsConfig := &tls.Config{InsecureSkipVerify: true} // nolint: gosec  // handshake never completes.
+		d, ok := NewTCPBackend(time.Minute).WithTLS(tlsConfig).NewDialer().(*dialer)
+		require.True(t, ok)
+		peer := ethereumtest.NewRandomAddress(test.Prng(t))
+		d.Register(peer, listener.Addr().String())
+
+		dialErr := make(chan error, 1)
+		go func() {
+			_, errDial := d.Dial(context.Background(), peer)
+			dialErr <- errDial
+		}()
+		time.Sleep(100 * time.Millisecond)
+		require.NoError(t, d.Close())
+		select {
+		case got := <-dialErr:
+			assert.Error(t, got)
+		case <-time.After(5 * time.Second):
+			t.Fatal("dial should be aborted when the dialer is closed")
+		}
+	})
+}
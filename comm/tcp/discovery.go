@@ -0,0 +1,144 @@
+// Copyright (c) 2020 - for information on the respective copyright owner
+// see the NOTICE file and/or the repository at
+// https://github.com/hyperledger-labs/perun-node
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tcp
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Schemes for comm addresses that are resolved using DNS records, instead of being dialed directly.
+//
+// For "srv://<name>", the SRV records of name (e.g. _perun._tcp.example.com) are looked up and the target
+// with the highest priority is used. For "txt://<name>", the first TXT record of name that is a valid
+// host:port is used.
+//
+// The records are looked up using the local resolver, so such addresses cannot be used for peers dialed via a
+// proxy, as the lookup would bypass the proxy.
+const (
+	SRVScheme = "srv://"
+	TXTScheme = "txt://"
+)
+
+// resolver resolves the comm addresses given as DNS records into host:port. The results are cached for the
+// ttl, so that peers are not looked up on every dial. Zero ttl disables caching.
+type resolver struct {
+	ttl       time.Duration
+	lookupSRV func(ctx context.Context, service, proto, name string) (string, []*net.SRV, error)
+	lookupTXT func(ctx context.Context, name string) ([]string, error)
+
+	mutex sync.Mutex
+	cache map[string]cachedAddr
+}
+
+type cachedAddr struct {
+	hostPort string
+	expiry   time.Time
+}
+
+func newResolver(ttl time.Duration) *resolver {
+	return &resolver{
+		ttl:       ttl,
+		lookupSRV: net.DefaultResolver.LookupSRV,
+		lookupTXT: net.DefaultResolver.LookupTXT,
+		cache:     make(map[string]cachedAddr),
+	}
+}
+
+// isDNSRecord returns true if the comm address is given as a DNS record, see SRVScheme.
+func isDNSRecord(addr string) bool {
+	return strings.HasPrefix(addr, SRVScheme) || strings.HasPrefix(addr, TXTScheme)
+}
+
+// resolve returns the host:port for the comm address. Addresses without a DNS scheme are returned as such,
+// as is any address if the resolver is nil.
+func (r *resolver) resolve(ctx context.Context, addr string) (string, error) {
+	if r == nil {
+		return addr, nil
+	}
+	var lookup func(context.Context, string) (string, error)
+	var name string
+	switch {
+	case strings.HasPrefix(addr, SRVScheme):
+		lookup, name = r.resolveSRV, strings.TrimPrefix(addr, SRVScheme)
+	case strings.HasPrefix(addr, TXTScheme):
+		lookup, name = r.resolveTXT, strings.TrimPrefix(addr, TXTScheme)
+	default:
+		return addr, nil
+	}
+
+	r.mutex.Lock()
+	cached, ok := r.cache[addr]
+	r.mutex.Unlock()
+	if ok && time.Now().Before(cached.expiry) {
+		return cached.hostPort, nil
+	}
+
+	hostPort, err := lookup(ctx, name)
+	if err != nil {
+		return "", errors.WithMessage(err, "resolving "+addr)
+	}
+	if r.ttl > 0 {
+		r.mutex.Lock()
+		r.cache[addr] = cachedAddr{hostPort: hostPort, expiry: time.Now().Add(r.ttl)}
+		r.mutex.Unlock()
+	}
+	return hostPort, nil
+}
+
+// invalidate removes the cached result for the comm address, so that it is looked up again on the next
+// dial. It is used when dialing the resolved address fails, as the peer might have moved to another host.
+func (r *resolver) invalidate(addr string) {
+	if r == nil {
+		return
+	}
+	r.mutex.Lock()
+	delete(r.cache, addr)
+	r.mutex.Unlock()
+}
+
+func (r *resolver) resolveSRV(ctx context.Context, name string) (string, error) {
+	// Records are sorted by priority and randomized by weight within a priority.
+	_, records, err := r.lookupSRV(ctx, "", "", name)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	if len(records) == 0 {
+		return "", errors.New("no SRV records")
+	}
+	host := strings.TrimSuffix(records[0].Target, ".")
+	return net.JoinHostPort(host, strconv.Itoa(int(records[0].Port))), nil
+}
+
+func (r *resolver) resolveTXT(ctx context.Context, name string) (string, error) {
+	records, err := r.lookupTXT(ctx, name)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	for _, record := range records {
+		if _, _, splitErr := net.SplitHostPort(record); splitErr == nil {
+			return record, nil
+		}
+	}
+	return "", errors.New("no TXT record with host:port")
+}
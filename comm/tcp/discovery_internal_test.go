@@ -0,0 +1,135 @@
+// Copyright (c) 2020 - for information on the respective copyright owner
+// see the NOTICE file and/or the repository at
+// https://github.com/hyperledger-labs/perun-node
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tcp
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"perun.network/go-perun/pkg/test"
+
+	"github.com/hyperledger-labs/perun-node/blockchain/ethereum/ethereumtest"
+)
+
+// newFakeResolver returns a resolver that looks up the records in the given maps, counting the lookups.
+func newFakeResolver(ttl time.Duration, srv map[string][]*net.SRV, txt map[string][]string) (*resolver, *int) {
+	lookups := 0
+	r := newResolver(ttl)
+	r.lookupSRV = func(_ context.Context, _, _, name string) (string, []*net.SRV, error) {
+		lookups++
+		records, ok := srv[name]
+		if !ok {
+			return "", nil, errors.New("no such host")
+		}
+		return name, records, nil
+	}
+	r.lookupTXT = func(_ context.Context, name string) ([]string, error) {
+		lookups++
+		records, ok := txt[name]
+		if !ok {
+			return nil, errors.New("no such host")
+		}
+		return records, nil
+	}
+	return r, &lookups
+}
+
+func Test_resolver(t *testing.T) {
+	srv := map[string][]*net.SRV{
+		"_perun._tcp.example.com": {{Target: "node1.example.com.", Port: 5751}, {Target: "node2.example.com.", Port: 5752}},
+		"_perun._tcp.empty.com":   {},
+	}
+	txt := map[string][]string{"example.com": {"v=spf1 -all", "node1.example.com:5751"}}
+	ctx := context.Background()
+
+	t.Run("happy", func(t *testing.T) {
+		r, lookups := newFakeResolver(0, srv, txt)
+		got, err := r.resolve(ctx, "127.0.0.1:5751")
+		require.NoError(t, err)
+		assert.Equal(t, "127.0.0.1:5751", got)
+
+		got, err = r.resolve(ctx, "srv://_perun._tcp.example.com")
+		require.NoError(t, err)
+		assert.Equal(t, "node1.example.com:5751", got)
+
+		got, err = r.resolve(ctx, "txt://example.com")
+		require.NoError(t, err)
+		assert.Equal(t, "node1.example.com:5751", got)
+		assert.Equal(t, 2, *lookups)
+	})
+	t.Run("happy_cache", func(t *testing.T) {
+		r, lookups := newFakeResolver(time.Minute, srv, txt)
+		for i := 0; i < 3; i++ {
+			_, err := r.resolve(ctx, "srv://_perun._tcp.example.com")
+			require.NoError(t, err)
+		}
+		assert.Equal(t, 1, *lookups)
+
+		r.invalidate("srv://_perun._tcp.example.com")
+		_, err := r.resolve(ctx, "srv://_perun._tcp.example.com")
+		require.NoError(t, err)
+		assert.Equal(t, 2, *lookups)
+	})
+	t.Run("happy_nil", func(t *testing.T) {
+		var r *resolver
+		got, err := r.resolve(ctx, "srv://_perun._tcp.example.com")
+		require.NoError(t, err)
+		assert.Equal(t, "srv://_perun._tcp.example.com", got)
+	})
+	t.Run("err_not_found", func(t *testing.T) {
+		r, _ := newFakeResolver(0, srv, txt)
+		_, err := r.resolve(ctx, "srv://_perun._tcp.missing.com")
+		assert.Error(t, err)
+		_, err = r.resolve(ctx, "srv://_perun._tcp.empty.com")
+		assert.Error(t, err)
+		_, err = r.resolve(ctx, "txt://missing.com")
+		assert.Error(t, err)
+	})
+}
+
+func Test_dialer_Dial_SRV(t *testing.T) {
+	rng := test.Prng(t)
+	peer := ethereumtest.NewRandomAddress(rng)
+	listener, err := NewTCPBackend(time.Second).NewListener("127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close() // nolint: errcheck  // error not relevant in test.
+	go func() {
+		conn, acceptErr := listener.Accept()
+		if acceptErr == nil {
+			conn.Close() // nolint: errcheck, gosec  // error not relevant in test.
+		}
+	}()
+	_, port, err := net.SplitHostPort(listener.(*Listener).Addr().String())
+	require.NoError(t, err)
+	portNum, err := net.LookupPort("tcp", port)
+	require.NoError(t, err)
+
+	d := NewTCPBackend(time.Second).NewDialer().(*dialer)
+	d.resolver, _ = newFakeResolver(time.Minute, map[string][]*net.SRV{
+		"_perun._tcp.example.com": {{Target: "127.0.0.1.", Port: uint16(portNum)}},
+	}, nil)
+	d.Register(peer, "srv://_perun._tcp.example.com")
+
+	conn, err := d.Dial(context.Background(), peer)
+	require.NoError(t, err)
+	assert.NoError(t, conn.Close())
+}
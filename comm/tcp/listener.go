@@ -0,0 +1,310 @@
+// Copyright (c) 2020 - for information on the respective copyright owner
+// see the NOTICE file and/or the repository at
+// https://github.com/hyperledger-labs/perun-node
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tcp
+
+import (
+	"context"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// ListenerLimits are the limits on the incoming connections accepted by the listener, for protecting
+// a publicly reachable node from being flooded with connections. Zero value for a limit means there is
+// no limit.
+//
+// Connections exceeding the limits are closed as soon as they are accepted, unless BlockWhenFull is set.
+// The connections already established are not affected.
+type ListenerLimits struct {
+	// Maximum number of open incoming connections from a single IP address.
+	MaxConnsPerIP int
+	// Maximum number of incoming connections that have not yet completed the handshake (exchange of
+	// off-chain addresses). The handshake is complete once this node sends its address, which it does
+	// only after receiving the address of the peer.
+	MaxPendingConns int
+	// Maximum number of open incoming connections.
+	MaxConns int
+	// Maximum number of incoming connections from a single IP address per minute. Every connection
+	// counts, including the rejected ones, so that a host retrying in a loop stays rejected.
+	MaxConnRatePerIP int
+	// If true, the listener stops accepting connections while MaxConns connections are open, instead of
+	// closing the new ones. They then wait in the accept queue of the OS, which drops them when full.
+	BlockWhenFull bool
+}
+
+// ListenerStats are the counts of incoming connections accepted and rejected by the listeners
+// of a backend.
+type ListenerStats struct {
+	Accepted        uint64 `json:"accepted"`
+	RejectedPerIP   uint64 `json:"rejectedPerIP"`   // Rejected due to MaxConnsPerIP.
+	RejectedPending uint64 `json:"rejectedPending"` // Rejected due to MaxPendingConns.
+	RejectedMax     uint64 `json:"rejectedMax"`     // Rejected due to MaxConns.
+	RejectedRate    uint64 `json:"rejectedRate"`    // Rejected due to MaxConnRatePerIP.
+	RejectedIP      uint64 `json:"rejectedIP"`      // Rejected due to the CIDR rules of the access list.
+	RejectedPeer    uint64 `json:"rejectedPeer"`    // Rejected due to the peer rules of the access list.
+}
+
+// load returns a copy of the stats, loading the counts atomically.
+func (s *ListenerStats) load() ListenerStats {
+	return ListenerStats{
+		Accepted:        atomic.LoadUint64(&s.Accepted),
+		RejectedPerIP:   atomic.LoadUint64(&s.RejectedPerIP),
+		RejectedPending: atomic.LoadUint64(&s.RejectedPending),
+		RejectedMax:     atomic.LoadUint64(&s.RejectedMax),
+		RejectedRate:    atomic.LoadUint64(&s.RejectedRate),
+		RejectedIP:      atomic.LoadUint64(&s.RejectedIP),
+		RejectedPeer:    atomic.LoadUint64(&s.RejectedPeer),
+	}
+}
+
+// rateWindow is the duration of the window, in which the connections are counted for MaxConnRatePerIP.
+const rateWindow = time.Minute
+
+// limitedListener is a tcp listener that enforces the listener limits. It tracks the accepted connections
+// even if there are no limits, so that it can be drained.
+type limitedListener struct {
+	net.Listener
+	limits ListenerLimits
+	stats  *ListenerStats // Should be accessed atomically.
+	access *AccessList    // Rules for the IP addresses of the peers, all are allowed if nil.
+
+	mutex     sync.Mutex
+	slotFreed *sync.Cond // Signaled when a connection is released, uses mutex.
+	closed    bool
+	drained   chan struct{} // Closed when draining and there are no pending connections, nil if not draining.
+	perIP     map[string]int
+	pending   int
+	open      int
+
+	now         func() time.Time
+	rate        map[string]int // Connections per IP in the current rate window.
+	rateStarted time.Time
+}
+
+func newLimitedListener(l net.Listener, limits ListenerLimits, stats *ListenerStats) *limitedListener {
+	ll := &limitedListener{
+		Listener: l,
+		limits:   limits,
+		stats:    stats,
+		perIP:    make(map[string]int),
+		now:      time.Now,
+		rate:     make(map[string]int),
+	}
+	ll.slotFreed = sync.NewCond(&ll.mutex)
+	return ll
+}
+
+// Accept implements the net.Listener interface.
+//
+// Connections exceeding the limits are closed without being returned, because the accept loop in go-perun
+// stops on the first error.
+func (l *limitedListener) Accept() (net.Conn, error) {
+	conn, err := l.acceptTracked()
+	if err != nil {
+		return nil, err
+	}
+	return conn, nil
+}
+
+// Close closes the listener, also unblocking the Accept waiting for a slot to be freed. It does nothing
+// if the listener was closed by Drain.
+func (l *limitedListener) Close() error {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	if l.closed && l.drained != nil {
+		return nil
+	}
+	l.closed = true
+	l.slotFreed.Broadcast()
+	return l.Listener.Close()
+}
+
+// Drain stops accepting new connections and waits until there are no pending connections.
+func (l *limitedListener) Drain(ctx context.Context) error {
+	l.mutex.Lock()
+	if l.drained != nil || l.closed {
+		l.mutex.Unlock()
+		return errors.New("listener already closed")
+	}
+	l.closed = true
+	l.slotFreed.Broadcast()
+	err := l.Listener.Close()
+	l.drained = make(chan struct{})
+	l.checkDrained()
+	l.mutex.Unlock()
+	if err != nil {
+		return errors.Wrap(err, "closing listener")
+	}
+
+	select {
+	case <-l.drained:
+		return nil
+	case <-ctx.Done():
+		return errors.Wrap(ctx.Err(), "waiting for pending connections")
+	}
+}
+
+// checkDrained closes the drained channel if the listener is being drained and there are no pending
+// connections. It should be called with the mutex locked.
+func (l *limitedListener) checkDrained() {
+	if l.drained == nil || l.pending > 0 {
+		return
+	}
+	select {
+	case <-l.drained:
+	default:
+		close(l.drained)
+	}
+}
+
+// acceptTracked waits for a connection that can be accepted within the limits and returns it.
+func (l *limitedListener) acceptTracked() (*trackedConn, error) {
+	for {
+		if err := l.waitForSlot(); err != nil {
+			return nil, err
+		}
+		conn, err := l.Listener.Accept()
+		if err != nil {
+			return nil, errors.Wrap(err, "accept failed")
+		}
+		if tracked := l.admit(conn); tracked != nil {
+			atomic.AddUint64(&l.stats.Accepted, 1)
+			return tracked, nil
+		}
+		conn.Close() // nolint: errcheck, gosec  // connection is rejected, nothing to do if closing fails.
+	}
+}
+
+// waitForSlot waits until the number of open connections is within MaxConns, if BlockWhenFull is set.
+func (l *limitedListener) waitForSlot() error {
+	if !l.limits.BlockWhenFull || l.limits.MaxConns == 0 {
+		return nil
+	}
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	for l.open >= l.limits.MaxConns && !l.closed {
+		l.slotFreed.Wait()
+	}
+	if l.closed {
+		return errors.New("accept failed: listener closed")
+	}
+	return nil
+}
+
+// admit checks if the connection can be accepted within the limits and if so, starts tracking it.
+func (l *limitedListener) admit(conn net.Conn) *trackedConn {
+	ip := conn.RemoteAddr().String()
+	if host, _, err := net.SplitHostPort(ip); err == nil {
+		ip = host
+	}
+
+	if !l.access.allowsIP(net.ParseIP(ip)) {
+		atomic.AddUint64(&l.stats.RejectedIP, 1)
+		return nil
+	}
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	if l.limits.MaxConnRatePerIP != 0 && l.countRate(ip) > l.limits.MaxConnRatePerIP {
+		atomic.AddUint64(&l.stats.RejectedRate, 1)
+		return nil
+	}
+	if l.limits.MaxConns != 0 && l.open >= l.limits.MaxConns {
+		atomic.AddUint64(&l.stats.RejectedMax, 1)
+		return nil
+	}
+	if l.limits.MaxConnsPerIP != 0 && l.perIP[ip] >= l.limits.MaxConnsPerIP {
+		atomic.AddUint64(&l.stats.RejectedPerIP, 1)
+		return nil
+	}
+	if l.limits.MaxPendingConns != 0 && l.pending >= l.limits.MaxPendingConns {
+		atomic.AddUint64(&l.stats.RejectedPending, 1)
+		return nil
+	}
+	l.perIP[ip]++
+	l.pending++
+	l.open++
+	return &trackedConn{Conn: conn, listener: l, ip: ip}
+}
+
+// countRate counts the connection from the ip in the current rate window and returns the count.
+// It should be called with the mutex locked.
+func (l *limitedListener) countRate(ip string) int {
+	if now := l.now(); now.Sub(l.rateStarted) >= rateWindow {
+		l.rate = make(map[string]int)
+		l.rateStarted = now
+	}
+	l.rate[ip]++
+	return l.rate[ip]
+}
+
+// release stops tracking the connection. If pending is true, the connection had not completed the handshake.
+func (l *limitedListener) release(ip string, pending bool) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	if l.perIP[ip]--; l.perIP[ip] <= 0 {
+		delete(l.perIP, ip)
+	}
+	if pending {
+		l.pending--
+	}
+	l.open--
+	l.slotFreed.Signal()
+	l.checkDrained()
+}
+
+func (l *limitedListener) handshakeDone() {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.pending--
+	l.checkDrained()
+}
+
+// trackedConn is a connection accepted by the limited listener, which releases its slots in the listener
+// on completing the handshake and on being closed.
+type trackedConn struct {
+	net.Conn
+	listener *limitedListener
+	ip       string
+
+	mutex   sync.Mutex
+	written bool // If true, the handshake is complete.
+	closed  bool
+}
+
+func (c *trackedConn) Write(b []byte) (int, error) {
+	c.mutex.Lock()
+	if !c.written && !c.closed {
+		c.written = true
+		c.listener.handshakeDone()
+	}
+	c.mutex.Unlock()
+	return c.Conn.Write(b)
+}
+
+func (c *trackedConn) Close() error {
+	c.mutex.Lock()
+	if !c.closed {
+		c.closed = true
+		c.listener.release(c.ip, !c.written)
+	}
+	c.mutex.Unlock()
+	return c.Conn.Close()
+}
@@ -0,0 +1,178 @@
+// Copyright (c) 2020 - for information on the respective copyright owner
+// see the NOTICE file and/or the repository at
+// https://github.com/hyperledger-labs/perun-node
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tcp
+
+import (
+	"context"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_limitedListener(t *testing.T) {
+	setup := func(t *testing.T, limits ListenerLimits) (*limitedListener, chan *trackedConn) {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		l := newLimitedListener(listener, limits, &ListenerStats{})
+		t.Cleanup(func() { l.Close() }) // nolint: errcheck, gosec
+
+		accepted := make(chan *trackedConn, 10)
+		go func() {
+			for {
+				conn, err := l.acceptTracked()
+				if err != nil {
+					return
+				}
+				accepted <- conn
+			}
+		}()
+		return l, accepted
+	}
+	dial := func(t *testing.T, l *limitedListener) net.Conn {
+		conn, err := net.Dial("tcp", l.Addr().String())
+		require.NoError(t, err)
+		t.Cleanup(func() { conn.Close() }) // nolint: errcheck, gosec
+		return conn
+	}
+	requireAccepted := func(t *testing.T, accepted chan *trackedConn) *trackedConn {
+		select {
+		case conn := <-accepted:
+			return conn
+		case <-time.After(time.Second):
+			require.FailNow(t, "connection not accepted")
+			return nil
+		}
+	}
+	requireRejected := func(t *testing.T, conn net.Conn) {
+		require.NoError(t, conn.SetReadDeadline(time.Now().Add(time.Second)))
+		_, err := conn.Read(make([]byte, 1))
+		require.Equal(t, io.EOF, err, "connection should be closed by listener")
+	}
+
+	t.Run("happy_per_ip", func(t *testing.T) {
+		l, accepted := setup(t, ListenerLimits{MaxConnsPerIP: 1})
+		dial(t, l)
+		conn := requireAccepted(t, accepted)
+		requireRejected(t, dial(t, l))
+		assert.Equal(t, ListenerStats{Accepted: 1, RejectedPerIP: 1}, l.stats.load())
+
+		require.NoError(t, conn.Close())
+		dial(t, l)
+		requireAccepted(t, accepted)
+	})
+
+	t.Run("happy_pending", func(t *testing.T) {
+		l, accepted := setup(t, ListenerLimits{MaxPendingConns: 1})
+		dial(t, l)
+		conn := requireAccepted(t, accepted)
+		requireRejected(t, dial(t, l))
+		assert.Equal(t, ListenerStats{Accepted: 1, RejectedPending: 1}, l.stats.load())
+
+		_, err := conn.Write([]byte{0})
+		require.NoError(t, err)
+		dial(t, l)
+		requireAccepted(t, accepted)
+		require.NoError(t, conn.Close())
+		l.mutex.Lock()
+		defer l.mutex.Unlock()
+		assert.Equal(t, 1, l.pending, "closing a connection after handshake should not release pending slot")
+	})
+	t.Run("happy_max_conns", func(t *testing.T) {
+		l, accepted := setup(t, ListenerLimits{MaxConns: 1})
+		dial(t, l)
+		conn := requireAccepted(t, accepted)
+		requireRejected(t, dial(t, l))
+		assert.Equal(t, ListenerStats{Accepted: 1, RejectedMax: 1}, l.stats.load())
+
+		require.NoError(t, conn.Close())
+		dial(t, l)
+		requireAccepted(t, accepted)
+	})
+
+	t.Run("happy_block_when_full", func(t *testing.T) {
+		l, accepted := setup(t, ListenerLimits{MaxConns: 1, BlockWhenFull: true})
+		dial(t, l)
+		conn := requireAccepted(t, accepted)
+		dial(t, l)
+		select {
+		case <-accepted:
+			require.FailNow(t, "connection should wait until a slot is freed")
+		case <-time.After(100 * time.Millisecond):
+		}
+
+		require.NoError(t, conn.Close())
+		requireAccepted(t, accepted)
+		assert.Equal(t, ListenerStats{Accepted: 2}, l.stats.load())
+	})
+
+	t.Run("happy_rate_per_ip", func(t *testing.T) {
+		l, accepted := setup(t, ListenerLimits{MaxConnRatePerIP: 1})
+		now := time.Now()
+		l.mutex.Lock()
+		l.now = func() time.Time { return now }
+		l.mutex.Unlock()
+
+		dial(t, l)
+		conn := requireAccepted(t, accepted)
+		require.NoError(t, conn.Close())
+		requireRejected(t, dial(t, l))
+		assert.Equal(t, ListenerStats{Accepted: 1, RejectedRate: 1}, l.stats.load())
+
+		l.mutex.Lock()
+		l.now = func() time.Time { return now.Add(rateWindow) }
+		l.mutex.Unlock()
+		dial(t, l)
+		requireAccepted(t, accepted)
+	})
+	t.Run("happy_drain", func(t *testing.T) {
+		l, accepted := setup(t, ListenerLimits{})
+		dial(t, l)
+		conn := requireAccepted(t, accepted)
+
+		drained := make(chan error, 1)
+		go func() { drained <- l.Drain(context.Background()) }()
+		require.Eventually(t, func() bool {
+			_, err := net.Dial("tcp", l.Addr().String())
+			return err != nil
+		}, time.Second, 10*time.Millisecond, "listener should stop accepting new connections")
+		select {
+		case <-drained:
+			require.FailNow(t, "drain should wait for the pending connection")
+		default:
+		}
+
+		_, err := conn.Write([]byte{0})
+		require.NoError(t, err)
+		require.NoError(t, <-drained)
+		assert.NoError(t, l.Close())
+	})
+
+	t.Run("err_drain_timeout", func(t *testing.T) {
+		l, accepted := setup(t, ListenerLimits{})
+		dial(t, l)
+		requireAccepted(t, accepted)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		assert.Error(t, l.Drain(ctx))
+		assert.Error(t, l.Drain(context.Background()), "listener should be drained only once")
+	})
+}
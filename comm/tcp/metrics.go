@@ -0,0 +1,148 @@
+// Copyright (c) 2020 - for information on the respective copyright owner
+// see the NOTICE file and/or the repository at
+// https://github.com/hyperledger-labs/perun-node
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tcp
+
+import (
+	"net"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"perun.network/go-perun/wire"
+	wirenet "perun.network/go-perun/wire/net"
+)
+
+// PeerStats are the amounts of data exchanged with a peer over the off-chain connections, since the
+// backend was initialized.
+type PeerStats struct {
+	Peer         string    `json:"peer"` // Off-chain address of the peer.
+	BytesIn      uint64    `json:"bytesIn"`
+	BytesOut     uint64    `json:"bytesOut"`
+	MessagesIn   uint64    `json:"messagesIn"`
+	MessagesOut  uint64    `json:"messagesOut"`
+	LastActivity time.Time `json:"lastActivity"`
+}
+
+// metrics tracks the stats of the peers. It is shared by copies of the backend.
+type metrics struct {
+	mutex sync.Mutex
+	peers map[string]*PeerStats
+	now   func() time.Time
+}
+
+func newMetrics() *metrics {
+	return &metrics{peers: make(map[string]*PeerStats), now: time.Now}
+}
+
+// peerStats returns the stats of all the peers, sorted by their address.
+func (m *metrics) peerStats() []PeerStats {
+	if m == nil {
+		return nil
+	}
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	stats := make([]PeerStats, 0, len(m.peers))
+	for _, s := range m.peers {
+		stats = append(stats, *s)
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].Peer < stats[j].Peer })
+	return stats
+}
+
+// record adds the message and the bytes read or written to the stats of the peer.
+func (m *metrics) record(peer string, bytesIn, bytesOut, msgsIn, msgsOut uint64) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	s, ok := m.peers[peer]
+	if !ok {
+		s = &PeerStats{Peer: peer}
+		m.peers[peer] = s
+	}
+	s.BytesIn += bytesIn
+	s.BytesOut += bytesOut
+	s.MessagesIn += msgsIn
+	s.MessagesOut += msgsOut
+	s.LastActivity = m.now()
+}
+
+// wrap returns the connection for exchanging messages over conn, which is metered if metrics are
+// enabled (m is not nil).
+func (m *metrics) wrap(conn net.Conn) wirenet.Conn {
+	if m == nil {
+		return wirenet.NewIoConn(conn)
+	}
+	counting := &countingConn{Conn: conn}
+	return &meteredConn{Conn: wirenet.NewIoConn(counting), counting: counting, metrics: m}
+}
+
+// meteredConn is a connection that records the messages exchanged and the bytes read or written for
+// them in the metrics. The bytes are attributed to the peer sending or receiving the message, as
+// every envelope (including those of the address exchange) carries its address.
+type meteredConn struct {
+	wirenet.Conn
+	counting *countingConn
+	metrics  *metrics
+
+	mutex    sync.Mutex // Guards recorded, so that concurrent sends and receives record each byte once.
+	recorded [2]uint64  // Bytes read and written, that have already been recorded.
+}
+
+func (c *meteredConn) Recv() (*wire.Envelope, error) {
+	env, err := c.Conn.Recv()
+	if err != nil {
+		return nil, err
+	}
+	c.record(env.Sender, 1, 0)
+	return env, nil
+}
+
+func (c *meteredConn) Send(env *wire.Envelope) error {
+	if err := c.Conn.Send(env); err != nil {
+		return err
+	}
+	c.record(env.Recipient, 0, 1)
+	return nil
+}
+
+func (c *meteredConn) record(peer wire.Address, msgsIn, msgsOut uint64) {
+	c.mutex.Lock()
+	read, written := atomic.LoadUint64(&c.counting.read), atomic.LoadUint64(&c.counting.written)
+	bytesIn, bytesOut := read-c.recorded[0], written-c.recorded[1]
+	c.recorded = [2]uint64{read, written}
+	c.mutex.Unlock()
+	c.metrics.record(peer.String(), bytesIn, bytesOut, msgsIn, msgsOut)
+}
+
+// countingConn is a connection that counts the bytes read and written.
+type countingConn struct {
+	net.Conn
+	read    uint64 // Should be accessed atomically.
+	written uint64 // Should be accessed atomically.
+}
+
+func (c *countingConn) Read(b []byte) (int, error) {
+	n, err := c.Conn.Read(b)
+	atomic.AddUint64(&c.read, uint64(n))
+	return n, err
+}
+
+func (c *countingConn) Write(b []byte) (int, error) {
+	n, err := c.Conn.Write(b)
+	atomic.AddUint64(&c.written, uint64(n))
+	return n, err
+}
@@ -0,0 +1,74 @@
+// Copyright (c) 2020 - for information on the respective copyright owner
+// see the NOTICE file and/or the repository at
+// https://github.com/hyperledger-labs/perun-node
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tcp
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"perun.network/go-perun/pkg/test"
+	"perun.network/go-perun/wire"
+
+	"github.com/hyperledger-labs/perun-node/blockchain/ethereum/ethereumtest"
+)
+
+func Test_Backend_PeerStats(t *testing.T) {
+	rng := test.Prng(t)
+	serverAddr, clientAddr := ethereumtest.NewRandomAddress(rng), ethereumtest.NewRandomAddress(rng)
+	server, client := NewTCPBackend(time.Second), NewTCPBackend(time.Second)
+
+	listener, err := server.NewListener("127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() }) // nolint: errcheck, gosec  // error not relevant in test.
+	served := make(chan error, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err == nil {
+			if _, err = conn.Recv(); err == nil {
+				err = conn.Send(&wire.Envelope{Sender: serverAddr, Recipient: clientAddr, Msg: wire.NewPongMsg()})
+			}
+		}
+		served <- err
+	}()
+
+	dialer := client.NewDialer().(*dialer)
+	dialer.Register(serverAddr, listener.(*Listener).Addr().String())
+	conn, err := dialer.Dial(context.Background(), serverAddr)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() }) // nolint: errcheck, gosec  // error not relevant in test.
+	require.NoError(t, conn.Send(&wire.Envelope{Sender: clientAddr, Recipient: serverAddr, Msg: wire.NewPingMsg()}))
+	_, err = conn.Recv()
+	require.NoError(t, err)
+	require.NoError(t, <-served)
+
+	clientStats, serverStats := client.PeerStats(), server.PeerStats()
+	require.Len(t, clientStats, 1)
+	require.Len(t, serverStats, 1)
+	assert.Equal(t, serverAddr.String(), clientStats[0].Peer)
+	assert.Equal(t, clientAddr.String(), serverStats[0].Peer)
+	for _, s := range []PeerStats{clientStats[0], serverStats[0]} {
+		assert.Equal(t, uint64(1), s.MessagesIn)
+		assert.Equal(t, uint64(1), s.MessagesOut)
+		assert.NotZero(t, s.BytesIn)
+		assert.False(t, s.LastActivity.IsZero())
+	}
+	assert.Equal(t, clientStats[0].BytesOut, serverStats[0].BytesIn)
+	assert.Equal(t, clientStats[0].BytesIn, serverStats[0].BytesOut)
+}
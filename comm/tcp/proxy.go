@@ -0,0 +1,132 @@
+// Copyright (c) 2020 - for information on the respective copyright owner
+// see the NOTICE file and/or the repository at
+// https://github.com/hyperledger-labs/perun-node
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tcp
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/net/proxy"
+	"perun.network/go-perun/wallet"
+	"perun.network/go-perun/wire"
+)
+
+// Proxies are the proxies used for dialing the peers. Supported schemes are socks5 (socks5h to let
+// the proxy resolve host names, as required for tor) and http (using CONNECT method). Credentials, if
+// any, are taken from the user info in the URL.
+type Proxies struct {
+	// Proxy used for dialing all the peers, nil to dial directly.
+	Default *url.URL
+	// Proxies used for dialing specific peers, instead of the default one. A nil value means the peer
+	// is dialed directly.
+	Peers map[wallet.AddrKey]*url.URL
+}
+
+// ParseProxyURL parses the proxy URL and checks if its scheme is supported.
+func ParseProxyURL(rawURL string) (*url.URL, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, errors.Wrap(err, "parsing proxy url")
+	}
+	switch u.Scheme {
+	case "socks5", "socks5h", "http":
+		return u, nil
+	default:
+		return nil, errors.Errorf("unsupported proxy scheme %q", u.Scheme)
+	}
+}
+
+func (p Proxies) isZero() bool {
+	return p.Default == nil && len(p.Peers) == 0
+}
+
+func (p Proxies) forPeer(addr wire.Address) *url.URL {
+	if u, ok := p.Peers[wallet.Key(addr)]; ok {
+		return u
+	}
+	return p.Default
+}
+
+// dialViaProxy dials the host via the proxy.
+func dialViaProxy(ctx context.Context, proxyURL *url.URL, forward *net.Dialer, host string) (net.Conn, error) {
+	if proxyURL.Scheme == "http" {
+		return dialViaHTTPProxy(ctx, proxyURL, forward, host)
+	}
+	d, err := proxy.FromURL(proxyURL, forward)
+	if err != nil {
+		return nil, errors.Wrap(err, "initializing proxy dialer")
+	}
+	conn, err := d.(proxy.ContextDialer).DialContext(ctx, "tcp", host)
+	return conn, errors.Wrap(err, "dialing via proxy")
+}
+
+// dialViaHTTPProxy opens a tunnel to the host using CONNECT method of the http proxy.
+func dialViaHTTPProxy(ctx context.Context, proxyURL *url.URL, forward *net.Dialer, host string) (net.Conn, error) {
+	conn, err := forward.DialContext(ctx, "tcp", proxyURL.Host)
+	if err != nil {
+		return nil, errors.Wrap(err, "dialing proxy")
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)          // nolint: errcheck, gosec  // a failed write or read will be reported.
+		defer conn.SetDeadline(time.Time{}) // nolint: errcheck, gosec  // same as above.
+	}
+
+	req := &http.Request{
+		Method: http.MethodConnect,
+		URL:    &url.URL{Opaque: host},
+		Host:   host,
+		Header: make(http.Header),
+	}
+	if proxyURL.User != nil {
+		password, _ := proxyURL.User.Password()
+		credentials := base64.StdEncoding.EncodeToString([]byte(proxyURL.User.Username() + ":" + password))
+		req.Header.Set("Proxy-Authorization", "Basic "+credentials)
+	}
+	if err = req.Write(conn); err != nil {
+		conn.Close() // nolint: errcheck, gosec  // write error is returned.
+		return nil, errors.Wrap(err, "sending connect request to proxy")
+	}
+	br := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(br, req)
+	if err != nil {
+		conn.Close() // nolint: errcheck, gosec  // read error is returned.
+		return nil, errors.Wrap(err, "reading connect response from proxy")
+	}
+	resp.Body.Close() // nolint: errcheck, gosec  // response to connect has no body.
+	if resp.StatusCode != http.StatusOK {
+		conn.Close() // nolint: errcheck, gosec  // proxy error is returned.
+		return nil, errors.Errorf("proxy refused connect: %s", resp.Status)
+	}
+	return &bufferedConn{Conn: conn, r: br}, nil
+}
+
+// bufferedConn is a connection that reads from a buffered reader, so that the data read into the
+// buffer when reading the connect response is not lost.
+type bufferedConn struct {
+	net.Conn
+	r *bufio.Reader
+}
+
+func (c *bufferedConn) Read(b []byte) (int, error) {
+	return c.r.Read(b)
+}
@@ -0,0 +1,152 @@
+// Copyright (c) 2020 - for information on the respective copyright owner
+// see the NOTICE file and/or the repository at
+// https://github.com/hyperledger-labs/perun-node
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tcp
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"perun.network/go-perun/pkg/test"
+	"perun.network/go-perun/wallet"
+
+	"github.com/hyperledger-labs/perun-node/blockchain/ethereum/ethereumtest"
+)
+
+func Test_Dialer_Proxy(t *testing.T) {
+	rng := test.Prng(t)
+	peer := ethereumtest.NewRandomAddress(rng)
+	targetAddr := newEchoServer(t)
+	proxyAddr := newHTTPProxy(t, "Basic dXNlcjpwYXNz") // user:pass
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	t.Run("happy_http", func(t *testing.T) {
+		proxyURL, err := ParseProxyURL("http://user:pass@" + proxyAddr)
+		require.NoError(t, err)
+		d := newDialer(NewTCPBackend(time.Second).WithProxies(Proxies{Default: proxyURL}))
+		conn, err := d.dialTCP(ctx, d.proxies.forPeer(peer), targetAddr)
+		require.NoError(t, err)
+		assertEcho(t, conn)
+	})
+	t.Run("happy_peer_direct", func(t *testing.T) {
+		proxyURL, err := ParseProxyURL("http://" + proxyAddr)
+		require.NoError(t, err)
+		d := newDialer(NewTCPBackend(time.Second).WithProxies(Proxies{
+			Default: proxyURL,
+			Peers:   map[wallet.AddrKey]*url.URL{wallet.Key(peer): nil},
+		}))
+		conn, err := d.dialTCP(ctx, d.proxies.forPeer(peer), targetAddr)
+		require.NoError(t, err)
+		assertEcho(t, conn)
+	})
+	t.Run("err_proxy_refused", func(t *testing.T) {
+		proxyURL, err := ParseProxyURL("http://user:wrong@" + proxyAddr)
+		require.NoError(t, err)
+		d := newDialer(NewTCPBackend(time.Second).WithProxies(Proxies{Default: proxyURL}))
+		_, err = d.dialTCP(ctx, d.proxies.forPeer(peer), targetAddr)
+		assert.Error(t, err)
+	})
+	t.Run("err_dns_record_via_proxy", func(t *testing.T) {
+		proxyURL, err := ParseProxyURL("socks5h://" + proxyAddr)
+		require.NoError(t, err)
+		d := newDialer(NewTCPBackend(time.Second).WithProxies(Proxies{Default: proxyURL}))
+		var lookups *int
+		d.resolver, lookups = newFakeResolver(0, map[string][]*net.SRV{
+			"_perun._tcp.example.com": {{Target: "127.0.0.1.", Port: 5751}},
+		}, nil)
+		d.Register(peer, "srv://_perun._tcp.example.com")
+		_, err = d.Dial(ctx, peer)
+		assert.Error(t, err)
+		assert.Zero(t, *lookups, "records should not be looked up outside the proxy")
+	})
+	t.Run("err_unsupported_scheme", func(t *testing.T) {
+		_, err := ParseProxyURL("ftp://" + proxyAddr)
+		assert.Error(t, err)
+	})
+}
+
+func assertEcho(t *testing.T, conn net.Conn) {
+	defer conn.Close() // nolint: errcheck  // error not relevant in test.
+	_, err := conn.Write([]byte("ping"))
+	require.NoError(t, err)
+	got := make([]byte, 4)
+	_, err = io.ReadFull(conn, got)
+	require.NoError(t, err)
+	assert.Equal(t, "ping", string(got))
+}
+
+// newEchoServer starts a server that echoes back the data it receives and returns its address.
+func newEchoServer(t *testing.T) string {
+	return serve(t, func(conn net.Conn) {
+		io.Copy(conn, conn) // nolint: errcheck, gosec  // returns when the conn is closed.
+	})
+}
+
+// newHTTPProxy starts an http proxy that supports only the connect method and accepts requests with
+// the given authorization header. It returns the address of the proxy.
+func newHTTPProxy(t *testing.T, authorization string) string {
+	return serve(t, func(conn net.Conn) {
+		req, err := http.ReadRequest(bufio.NewReader(conn))
+		if err != nil {
+			return
+		}
+		if req.Method != http.MethodConnect || req.Header.Get("Proxy-Authorization") != authorization {
+			io.WriteString(conn, "HTTP/1.1 407 Proxy Authentication Required\r\n\r\n") // nolint: errcheck, gosec
+			return
+		}
+		target, err := net.Dial("tcp", req.Host)
+		if err != nil {
+			io.WriteString(conn, "HTTP/1.1 502 Bad Gateway\r\n\r\n") // nolint: errcheck, gosec
+			return
+		}
+		defer target.Close() // nolint: errcheck  // error not relevant in test.
+
+		io.WriteString(conn, "HTTP/1.1 200 Connection established\r\n\r\n") // nolint: errcheck, gosec
+		// Copying returns when either of the connections is closed, errors are not relevant in test.
+		go io.Copy(target, conn) // nolint: errcheck
+		io.Copy(conn, target)    // nolint: errcheck, gosec
+	})
+}
+
+// serve starts a listener on a random port that handles each connection using handle, closing it
+// afterwards. It returns the address of the listener.
+func serve(t *testing.T, handle func(net.Conn)) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() }) // nolint: errcheck, gosec  // error not relevant in test.
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close() // nolint: errcheck  // error not relevant in test.
+				handle(conn)
+			}()
+		}
+	}()
+	return listener.Addr().String()
+}
@@ -17,11 +17,13 @@
 package tcp
 
 import (
+	"context"
+	"crypto/tls"
+	stdnet "net"
 	"time"
 
 	"github.com/pkg/errors"
 	"perun.network/go-perun/wire/net"
-	"perun.network/go-perun/wire/net/simple"
 )
 
 // Backend is an off-chain communication backend that implements `CommBackend` for
@@ -29,13 +31,107 @@ import (
 type Backend struct {
 	// timeout to be used when dialing for new outgoing connections.
 	dialerTimeout time.Duration
+	// limits on the incoming connections, enforced by the listeners.
+	listenerLimits ListenerLimits
+	// stats of the listeners, shared by copies of the backend.
+	stats *ListenerStats
+	// tls config for securing the connections, nil if tls is not used.
+	tlsConfig *tls.Config
+	// proxies used for dialing the peers.
+	proxies Proxies
+	// period between keep-alive probes on the connections, zero for the default (15s) and negative to
+	// disable them.
+	keepAlive time.Duration
+	// metrics of the connections, shared by copies of the backend. Connections are not metered if nil.
+	metrics *metrics
+	// rules for accepting incoming connections, all are accepted if nil.
+	access *AccessList
+	// resolver for the peer addresses given as DNS records, shared by copies of the backend.
+	resolver *resolver
 }
 
 // NewListener returns a listener that can listen for incomig connections at
 // the specified address using tcp protocol.
+//
+// It enforces the listener limits of the backend, if any. If tls is configured, the handshake is done
+// when the connection is first read from or written to, so it does not block accepting other connections.
+//
+// The listener can be drained before closing it, see Listener.Drain.
 func (b Backend) NewListener(addr string) (net.Listener, error) {
-	listener, err := simple.NewTCPListener(addr)
-	return listener, errors.Wrap(err, "initializing listener")
+	listenConfig := stdnet.ListenConfig{KeepAlive: b.keepAlive}
+	listener, err := listenConfig.Listen(context.Background(), "tcp", addr)
+	if err != nil {
+		return nil, errors.Wrap(err, "initializing listener")
+	}
+	if b.tlsConfig != nil {
+		listener = tls.NewListener(listener, b.tlsConfig)
+	}
+	stats := b.stats
+	if stats == nil {
+		stats = &ListenerStats{}
+	}
+	limited := newLimitedListener(listener, b.listenerLimits, stats)
+	limited.access = b.access
+	return &Listener{limitedListener: limited, metrics: b.metrics}, nil
+}
+
+// WithListenerLimits returns a copy of the backend, whose listeners enforce the given limits.
+func (b Backend) WithListenerLimits(limits ListenerLimits) Backend {
+	b.listenerLimits = limits
+	return b
+}
+
+// WithTLS returns a copy of the backend, whose listeners and dialers secure the connections using tls.
+func (b Backend) WithTLS(config *tls.Config) Backend {
+	b.tlsConfig = config
+	return b
+}
+
+// WithProxies returns a copy of the backend, whose dialers dial the peers via the given proxies.
+func (b Backend) WithProxies(proxies Proxies) Backend {
+	b.proxies = proxies
+	return b
+}
+
+// WithAccessList returns a copy of the backend, whose listeners accept only the connections allowed by
+// the access list.
+func (b Backend) WithAccessList(access *AccessList) Backend {
+	b.access = access
+	return b
+}
+
+// AccessList returns the access list of the backend, nil if there is none.
+func (b Backend) AccessList() *AccessList {
+	return b.access
+}
+
+// WithDNSCacheTTL returns a copy of the backend, whose dialers cache the peer addresses resolved from DNS
+// records (see SRVScheme) for the given duration. Zero disables caching.
+func (b Backend) WithDNSCacheTTL(ttl time.Duration) Backend {
+	b.resolver = newResolver(ttl)
+	return b
+}
+
+// WithKeepAlive returns a copy of the backend, whose listeners and dialers use the given period between
+// keep-alive probes, for detecting dead connections. Zero means the default (15s) and negative
+// disables keep-alive probes.
+func (b Backend) WithKeepAlive(period time.Duration) Backend {
+	b.keepAlive = period
+	return b
+}
+
+// ListenerStats returns the counts of incoming connections accepted and rejected by the listeners.
+func (b Backend) ListenerStats() ListenerStats {
+	if b.stats == nil {
+		return ListenerStats{}
+	}
+	return b.stats.load()
+}
+
+// PeerStats returns the amounts of data exchanged with each of the peers over the connections of this
+// backend, sorted by the address of the peer.
+func (b Backend) PeerStats() []PeerStats {
+	return b.metrics.peerStats()
 }
 
 // NewDialer returns a dialer that can dial outgoing connections using on
@@ -45,7 +141,7 @@ func (b Backend) NewListener(addr string) (net.Listener, error) {
 // If the duration was set to zero, this program will not use any timeout.
 // However default timeouts based on the operating system will still apply.
 func (b Backend) NewDialer() net.Dialer {
-	return simple.NewTCPDialer(b.dialerTimeout)
+	return newDialer(b)
 }
 
 // NewTCPBackend returns a backend that can initialize off-chain communication
@@ -55,5 +151,42 @@ func (b Backend) NewDialer() net.Dialer {
 // If the duration was set to zero, this program will not use any timeout.
 // However default timeouts based on the operating system will still apply.
 func NewTCPBackend(dialerTimeout time.Duration) Backend {
-	return Backend{dialerTimeout: dialerTimeout}
+	return Backend{
+		dialerTimeout: dialerTimeout,
+		stats:         &ListenerStats{},
+		metrics:       newMetrics(),
+		resolver:      newResolver(0),
+	}
+}
+
+// Listener is the tcp listener returned by the backend. It enforces the listener limits and the access
+// list, and returns the accepted connections metered, if metrics are enabled.
+type Listener struct {
+	*limitedListener
+	metrics *metrics
+}
+
+// Accept implements the net.Listener interface defined in go-perun.
+func (l *Listener) Accept() (net.Conn, error) {
+	conn, err := l.limitedListener.Accept()
+	if err != nil {
+		return nil, err
+	}
+	if l.access == nil {
+		return l.metrics.wrap(conn), nil
+	}
+	ac := &accessConn{Conn: l.metrics.wrap(conn), access: l.access, stats: l.stats}
+	if tracked, ok := conn.(*trackedConn); ok {
+		ac.tlsConn, _ = tracked.Conn.(*tls.Conn)
+	}
+	return ac, nil
+}
+
+// Drain stops accepting new connections and waits until the connections already accepted complete the
+// handshake or are closed, so that peers which were connecting while the node is shutting down are not cut
+// off halfway. It returns an error if the context is done before that.
+//
+// Closing the listener after draining it does nothing and returns nil.
+func (l *Listener) Drain(ctx context.Context) error {
+	return l.limitedListener.Drain(ctx)
 }
@@ -24,8 +24,10 @@ import (
 	"github.com/phayes/freeport"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"perun.network/go-perun/pkg/test"
 
 	"github.com/hyperledger-labs/perun-node"
+	"github.com/hyperledger-labs/perun-node/blockchain/ethereum/ethereumtest"
 	"github.com/hyperledger-labs/perun-node/comm/tcp"
 )
 
@@ -54,3 +56,67 @@ func Test_Backend(t *testing.T) {
 	dialer := backend.NewDialer()
 	assert.NotNil(t, dialer)
 }
+
+func Test_Backend_CheckListener(t *testing.T) {
+	backend := tcp.NewTCPBackend(1 * time.Second)
+	port, err := freeport.GetFreePort()
+	require.NoError(t, err)
+	listenerAddr := fmt.Sprintf("127.0.0.1:%d", port)
+
+	t.Run("happy", func(t *testing.T) {
+		assert.NoError(t, backend.CheckListener(listenerAddr))
+	})
+
+	t.Run("err_addr_in_use", func(t *testing.T) {
+		listener, err := backend.NewListener(listenerAddr)
+		require.NoError(t, err)
+		t.Cleanup(func() {
+			if err = listener.Close(); err != nil {
+				t.Log("Error closing listener at address - " + listenerAddr)
+			}
+		})
+		assert.Error(t, backend.CheckListener(listenerAddr))
+	})
+
+	t.Run("err_invalid_addr", func(t *testing.T) {
+		assert.Error(t, backend.CheckListener("invalid-addr"))
+	})
+}
+
+func Test_Backend_CheckReachable(t *testing.T) {
+	backend := tcp.NewTCPBackend(1 * time.Second)
+	port, err := freeport.GetFreePort()
+	require.NoError(t, err)
+	listenerAddr := fmt.Sprintf("127.0.0.1:%d", port)
+	peer := ethereumtest.NewRandomAddress(test.Prng(t))
+
+	t.Run("happy", func(t *testing.T) {
+		listener, err := backend.NewListener(listenerAddr)
+		require.NoError(t, err)
+		t.Cleanup(func() {
+			if err = listener.Close(); err != nil {
+				t.Log("Error closing listener at address - " + listenerAddr)
+			}
+		})
+		assert.NoError(t, backend.CheckReachable(peer, listenerAddr))
+	})
+
+	t.Run("err_not_listening", func(t *testing.T) {
+		port, err := freeport.GetFreePort()
+		require.NoError(t, err)
+		assert.Error(t, backend.CheckReachable(peer, fmt.Sprintf("127.0.0.1:%d", port)))
+	})
+
+	t.Run("err_proxy_not_reachable", func(t *testing.T) {
+		ports, err := freeport.GetFreePorts(2)
+		require.NoError(t, err)
+		addr := fmt.Sprintf("127.0.0.1:%d", ports[0])
+		listener, err := backend.NewListener(addr)
+		require.NoError(t, err)
+		t.Cleanup(func() { listener.Close() }) // nolint: errcheck, gosec  // error not relevant in test.
+		proxyURL, err := tcp.ParseProxyURL(fmt.Sprintf("socks5://127.0.0.1:%d", ports[1]))
+		require.NoError(t, err)
+		proxied := backend.WithProxies(tcp.Proxies{Default: proxyURL})
+		assert.Error(t, proxied.CheckReachable(peer, addr), "peer should be dialed via proxy")
+	})
+}
@@ -0,0 +1,79 @@
+// Copyright (c) 2020 - for information on the respective copyright owner
+// see the NOTICE file and/or the repository at
+// https://github.com/hyperledger-labs/perun-node
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tcp
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"io/ioutil"
+
+	"github.com/pkg/errors"
+)
+
+// NewTLSConfig returns the TLS config for off-chain connections, using the certificate and key in the
+// given files (PEM encoded).
+//
+// If caFile is not empty, the certificates of the peers are verified using the CA certificates in it
+// (PEM encoded). Otherwise, the system roots are used for verifying the certificates of the peers
+// dialed by this node. If requireClientCert is true, the peers dialing this node must present a
+// certificate signed by one of the CAs in caFile (mutual TLS).
+func NewTLSConfig(certFile, keyFile, caFile string, requireClientCert bool) (*tls.Config, error) {
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, errors.Wrap(err, "loading certificate")
+	}
+	config := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+		ClientAuth:   tls.VerifyClientCertIfGiven,
+	}
+	if caFile == "" {
+		if requireClientCert {
+			return nil, errors.New("ca file is required for verifying client certificates")
+		}
+		return config, nil
+	}
+
+	caPEM, err := ioutil.ReadFile(caFile)
+	if err != nil {
+		return nil, errors.Wrap(err, "reading ca file")
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(caPEM) {
+		return nil, errors.New("no certificates found in ca file")
+	}
+	config.RootCAs, config.ClientCAs = pool, pool
+	if requireClientCert {
+		config.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	return config, nil
+}
+
+// handshake runs the TLS handshake on the connection, aborting it if the context is done.
+func handshake(ctx context.Context, conn *tls.Conn) error {
+	done := make(chan error, 1)
+	go func() { done <- conn.Handshake() }()
+	select {
+	case err := <-done:
+		return errors.Wrap(err, "tls handshake")
+	case <-ctx.Done():
+		conn.Close() // nolint: errcheck, gosec  // closing aborts the handshake, context error is returned.
+		<-done
+		return errors.Wrap(ctx.Err(), "tls handshake")
+	}
+}
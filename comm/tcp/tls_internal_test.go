@@ -0,0 +1,166 @@
+// Copyright (c) 2020 - for information on the respective copyright owner
+// see the NOTICE file and/or the repository at
+// https://github.com/hyperledger-labs/perun-node
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tcp
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/phayes/freeport"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"perun.network/go-perun/pkg/test"
+
+	"github.com/hyperledger-labs/perun-node/blockchain/ethereum/ethereumtest"
+)
+
+func Test_TLS(t *testing.T) {
+	dir, err := ioutil.TempDir("", "perun-node-tls")
+	require.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(dir) }) // nolint: errcheck, gosec  // error not relevant in test.
+	certFile, keyFile := newSelfSignedCert(t, dir, "trusted")
+	otherCertFile, otherKeyFile := newSelfSignedCert(t, dir, "other")
+
+	serverConfig, err := NewTLSConfig(certFile, keyFile, certFile, true)
+	require.NoError(t, err)
+	listenerAddr := newTLSListener(t, NewTCPBackend(time.Second).WithTLS(serverConfig))
+	peer := ethereumtest.NewRandomAddress(test.Prng(t))
+
+	t.Run("happy", func(t *testing.T) {
+		dialer := NewTCPBackend(time.Second).WithTLS(serverConfig).NewDialer().(*dialer)
+		dialer.Register(peer, listenerAddr)
+		conn, err := dialer.Dial(context.Background(), peer)
+		require.NoError(t, err)
+		assert.NoError(t, conn.Close())
+	})
+	t.Run("err_untrusted_server", func(t *testing.T) {
+		clientConfig, err := NewTLSConfig(certFile, keyFile, otherCertFile, false)
+		require.NoError(t, err)
+		dialer := NewTCPBackend(time.Second).WithTLS(clientConfig).NewDialer().(*dialer)
+		dialer.Register(peer, listenerAddr)
+		_, err = dialer.Dial(context.Background(), peer)
+		assert.Error(t, err)
+	})
+	t.Run("err_untrusted_client", func(t *testing.T) {
+		clientConfig, err := NewTLSConfig(otherCertFile, otherKeyFile, certFile, false)
+		require.NoError(t, err)
+		dialer := NewTCPBackend(time.Second).WithTLS(clientConfig).NewDialer().(*dialer)
+		dialer.Register(peer, listenerAddr)
+		conn, err := dialer.Dial(context.Background(), peer)
+		if err == nil { // In TLS 1.3, the client certificate is rejected after the client handshake completes.
+			_, err = conn.Recv()
+		}
+		assert.Error(t, err)
+	})
+	t.Run("err_peer_not_registered", func(t *testing.T) {
+		dialer := NewTCPBackend(time.Second).WithTLS(serverConfig).NewDialer()
+		_, err := dialer.Dial(context.Background(), peer)
+		assert.Error(t, err)
+	})
+}
+
+func Test_NewTLSConfig(t *testing.T) {
+	dir, err := ioutil.TempDir("", "perun-node-tls")
+	require.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(dir) }) // nolint: errcheck, gosec  // error not relevant in test.
+	certFile, keyFile := newSelfSignedCert(t, dir, "trusted")
+
+	t.Run("happy_without_ca", func(t *testing.T) {
+		config, err := NewTLSConfig(certFile, keyFile, "", false)
+		require.NoError(t, err)
+		assert.Nil(t, config.RootCAs)
+	})
+	t.Run("err_missing_cert", func(t *testing.T) {
+		_, err := NewTLSConfig(filepath.Join(dir, "missing.crt"), keyFile, "", false)
+		assert.Error(t, err)
+	})
+	t.Run("err_client_cert_without_ca", func(t *testing.T) {
+		_, err := NewTLSConfig(certFile, keyFile, "", true)
+		assert.Error(t, err)
+	})
+	t.Run("err_invalid_ca", func(t *testing.T) {
+		_, err := NewTLSConfig(certFile, keyFile, keyFile, false)
+		assert.Error(t, err)
+	})
+}
+
+// newTLSListener starts a listener that reads from the accepted connections (to run the tls handshake)
+// until they are closed, and returns its address.
+func newTLSListener(t *testing.T, backend Backend) string {
+	port, err := freeport.GetFreePort()
+	require.NoError(t, err)
+	addr := fmt.Sprintf("127.0.0.1:%d", port)
+	listener, err := backend.NewListener(addr)
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() }) // nolint: errcheck, gosec  // error not relevant in test.
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				conn.Recv() // nolint: errcheck, gosec  // returns error when the client closes the conn.
+				conn.Close()
+			}()
+		}
+	}()
+	return addr
+}
+
+// newSelfSignedCert generates a self-signed certificate for 127.0.0.1 and writes it and its key to files
+// in dir.
+func newSelfSignedCert(t *testing.T, dir, name string) (certFile, keyFile string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		IPAddresses:           []net.IP{net.ParseIP("127.0.0.1")},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	certDER, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	certFile, keyFile = filepath.Join(dir, name+".crt"), filepath.Join(dir, name+".key")
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certDER})
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+	require.NoError(t, ioutil.WriteFile(certFile, certPEM, 0o600))
+	require.NoError(t, ioutil.WriteFile(keyFile, keyPEM, 0o600))
+	return certFile, keyFile
+}
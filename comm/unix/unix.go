@@ -0,0 +1,68 @@
+// Copyright (c) 2020 - for information on the respective copyright owner
+// see the NOTICE file and/or the repository at
+// https://github.com/hyperledger-labs/perun-node
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package unix
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+	"perun.network/go-perun/wire/net"
+	"perun.network/go-perun/wire/net/simple"
+
+	"github.com/hyperledger-labs/perun-node"
+	"github.com/hyperledger-labs/perun-node/comm"
+)
+
+// CommType is the comm type, for which the backend is registered in the comm registry.
+const CommType = "unix"
+
+func init() {
+	comm.Register(CommType, func(cfg comm.Config) (perun.CommBackend, error) {
+		return NewUnixBackend(cfg.DialerTimeout), nil
+	})
+}
+
+// Backend is an off-chain communication backend that implements `CommBackend` for
+// unix domain sockets. It stores configuration required for initializing the adapters.
+type Backend struct {
+	// timeout to be used when dialing for new outgoing connections.
+	dialerTimeout time.Duration
+}
+
+// NewListener returns a listener that can listen for incoming connections at the specified
+// address (path to the socket file). The socket file is removed when the listener is closed.
+func (b Backend) NewListener(addr string) (net.Listener, error) {
+	listener, err := simple.NewUnixListener(addr)
+	return listener, errors.Wrap(err, "initializing listener")
+}
+
+// NewDialer returns a dialer that can dial outgoing connections using unix domain sockets.
+//
+// It uses the dial timeout configured during backend initialization.
+// If the duration was set to zero, this program will not use any timeout.
+func (b Backend) NewDialer() net.Dialer {
+	return simple.NewUnixDialer(b.dialerTimeout)
+}
+
+// NewUnixBackend returns a backend that can initialize off-chain communication
+// adapters for unix domain sockets.
+//
+// The provided dialerTimeout will be used when dialing for new outgoing connections.
+// If the duration was set to zero, this program will not use any timeout.
+func NewUnixBackend(dialerTimeout time.Duration) Backend {
+	return Backend{dialerTimeout: dialerTimeout}
+}
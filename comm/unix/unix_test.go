@@ -0,0 +1,78 @@
+// Copyright (c) 2020 - for information on the respective copyright owner
+// see the NOTICE file and/or the repository at
+// https://github.com/hyperledger-labs/perun-node
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package unix_test
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"perun.network/go-perun/pkg/test"
+	"perun.network/go-perun/wire/net/simple"
+
+	"github.com/hyperledger-labs/perun-node"
+	"github.com/hyperledger-labs/perun-node/blockchain/ethereum/ethereumtest"
+	"github.com/hyperledger-labs/perun-node/comm"
+	"github.com/hyperledger-labs/perun-node/comm/unix"
+)
+
+func Test_CommBackend_Interface(t *testing.T) {
+	assert.Implements(t, (*perun.CommBackend)(nil), new(unix.Backend))
+}
+
+func Test_Registered(t *testing.T) {
+	backend, err := comm.New(unix.CommType, comm.Config{DialerTimeout: time.Second})
+	require.NoError(t, err)
+	assert.Equal(t, unix.NewUnixBackend(time.Second), backend)
+}
+
+func Test_Backend(t *testing.T) {
+	dir, err := ioutil.TempDir("", "perun-node-unix")
+	require.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(dir) }) // nolint: errcheck, gosec  // error not relevant in test.
+	listenerAddr := filepath.Join(dir, "node.sock")
+	backend := unix.NewUnixBackend(1 * time.Second)
+
+	t.Run("happy", func(t *testing.T) {
+		listener, err := backend.NewListener(listenerAddr)
+		require.NoError(t, err)
+		t.Cleanup(func() { listener.Close() }) // nolint: errcheck, gosec  // error not relevant in test.
+		accepted := make(chan error, 1)
+		go func() {
+			_, err := listener.Accept()
+			accepted <- err
+		}()
+
+		dialer := backend.NewDialer()
+		peer := ethereumtest.NewRandomAddress(test.Prng(t))
+		dialer.(*simple.Dialer).Register(peer, listenerAddr)
+		conn, err := dialer.Dial(context.Background(), peer)
+		require.NoError(t, err)
+		assert.NoError(t, conn.Close())
+		assert.NoError(t, <-accepted)
+	})
+
+	t.Run("err_invalid_addr", func(t *testing.T) {
+		_, err := backend.NewListener(filepath.Join(dir, "missing", "node.sock"))
+		assert.Error(t, err)
+	})
+}
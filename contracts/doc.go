@@ -0,0 +1,30 @@
+// Copyright (c) 2020 - for information on the respective copyright owner
+// see the NOTICE file and/or the repository at
+// https://github.com/hyperledger-labs/perun-node
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package contracts implements signed manifests for updating the addresses
+// of the contracts used by the node, without upgrading the node binary.
+//
+// A manifest is published by the developers as a JSON document containing
+// the manifest and an ed25519 signature over its exact bytes. The node
+// fetches it from a configured URL, verifies the signature using the
+// configured public key and validates the contracts on the chain, before
+// staging it to a file. The staged manifest is verified again and used
+// when the node is started next.
+//
+// Each manifest has a version and a manifest can be staged only if its
+// version is higher than that of the staged manifest, so that an older
+// (validly signed) manifest cannot be used to roll back an update.
+package contracts
@@ -0,0 +1,161 @@
+// Copyright (c) 2020 - for information on the respective copyright owner
+// see the NOTICE file and/or the repository at
+// https://github.com/hyperledger-labs/perun-node
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package contracts
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+)
+
+// maxManifestSize is the maximum size of the manifest document that is read when fetching it.
+const maxManifestSize = 1 << 20
+
+// Manifest holds the addresses of the contracts to be used by the node.
+type Manifest struct {
+	Version     uint64 `json:"version"`
+	Network     string `json:"network"`
+	Adjudicator string `json:"adjudicator"`
+	Asset       string `json:"asset"`
+}
+
+// signedManifest is the format in which a manifest is published. Signature is computed over
+// the bytes of the manifest field.
+type signedManifest struct {
+	Manifest  json.RawMessage `json:"manifest"`
+	Signature string          `json:"signature"`
+}
+
+// ParsePublicKey parses the hex encoded ed25519 public key used for verifying manifests.
+func ParsePublicKey(str string) (ed25519.PublicKey, error) {
+	key, err := hex.DecodeString(str)
+	if err != nil {
+		return nil, errors.Wrap(err, "decoding public key")
+	}
+	if len(key) != ed25519.PublicKeySize {
+		return nil, errors.Errorf("public key should be %d bytes, got %d", ed25519.PublicKeySize, len(key))
+	}
+	return ed25519.PublicKey(key), nil
+}
+
+// Sign encodes the manifest and signs it using the private key. It returns the signed manifest in the
+// format expected by Verify.
+func Sign(m Manifest, key ed25519.PrivateKey) ([]byte, error) {
+	data, err := json.Marshal(m)
+	if err != nil {
+		return nil, errors.Wrap(err, "encoding manifest")
+	}
+	signed := signedManifest{
+		Manifest:  data,
+		Signature: base64.StdEncoding.EncodeToString(ed25519.Sign(key, data)),
+	}
+	data, err = json.Marshal(signed)
+	return data, errors.Wrap(err, "encoding signed manifest")
+}
+
+// Verify verifies the signature on the signed manifest using the public key and returns the manifest.
+func Verify(data []byte, key ed25519.PublicKey) (Manifest, error) {
+	var signed signedManifest
+	if err := json.Unmarshal(data, &signed); err != nil {
+		return Manifest{}, errors.Wrap(err, "decoding signed manifest")
+	}
+	sig, err := base64.StdEncoding.DecodeString(signed.Signature)
+	if err != nil {
+		return Manifest{}, errors.Wrap(err, "decoding signature")
+	}
+	if !ed25519.Verify(key, signed.Manifest, sig) {
+		return Manifest{}, errors.New("invalid signature on manifest")
+	}
+
+	var m Manifest
+	if err = json.Unmarshal(signed.Manifest, &m); err != nil {
+		return Manifest{}, errors.Wrap(err, "decoding manifest")
+	}
+	if m.Adjudicator == "" || m.Asset == "" {
+		return Manifest{}, errors.New("manifest should have adjudicator and asset addresses")
+	}
+	return m, nil
+}
+
+// Fetch fetches the signed manifest from the URL.
+func Fetch(ctx context.Context, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "creating request")
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "fetching manifest")
+	}
+	defer resp.Body.Close() // nolint: errcheck  // body is only read.
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("fetching manifest: server responded with status %s", resp.Status)
+	}
+	data, err := ioutil.ReadAll(&io.LimitedReader{R: resp.Body, N: maxManifestSize})
+	return data, errors.Wrap(err, "reading manifest")
+}
+
+// LoadStaged reads the signed manifest staged in the file and verifies it using the public key.
+// If no manifest is staged, it returns false.
+func LoadStaged(file string, key ed25519.PublicKey) (_ Manifest, isStaged bool, _ error) {
+	data, err := ioutil.ReadFile(filepath.Clean(file))
+	if os.IsNotExist(err) {
+		return Manifest{}, false, nil
+	}
+	if err != nil {
+		return Manifest{}, false, errors.Wrap(err, "reading staged manifest")
+	}
+	m, err := Verify(data, key)
+	if err != nil {
+		return Manifest{}, false, errors.WithMessage(err, "verifying staged manifest")
+	}
+	return m, true, nil
+}
+
+// Stage verifies the signed manifest and writes it to the file, if its version is higher than that of the
+// manifest already staged in the file (if any). The file is replaced atomically.
+//
+// Contracts in the manifest should be validated on the chain before staging.
+func Stage(file string, data []byte, key ed25519.PublicKey) (Manifest, error) {
+	m, err := Verify(data, key)
+	if err != nil {
+		return Manifest{}, err
+	}
+	staged, isStaged, err := LoadStaged(file, key)
+	if err != nil {
+		return Manifest{}, err
+	}
+	if isStaged && m.Version <= staged.Version {
+		return Manifest{}, errors.Errorf("manifest version %d is not higher than staged version %d",
+			m.Version, staged.Version)
+	}
+
+	tmpFile := file + ".tmp"
+	if err = ioutil.WriteFile(tmpFile, data, 0o600); err != nil {
+		return Manifest{}, errors.Wrap(err, "writing manifest")
+	}
+	return m, errors.Wrap(os.Rename(tmpFile, file), "writing manifest")
+}
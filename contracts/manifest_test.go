@@ -0,0 +1,194 @@
+// Copyright (c) 2020 - for information on the respective copyright owner
+// see the NOTICE file and/or the repository at
+// https://github.com/hyperledger-labs/perun-node
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package contracts_test
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/hex"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger-labs/perun-node/contracts"
+)
+
+var testManifest = contracts.Manifest{
+	Version:     2,
+	Network:     "dev",
+	Adjudicator: "0x9daEdAcb21dce86Af8604Ba1A1D7F9BFE55ddd63",
+	Asset:       "0x5992089d61cE79B6CF90506F70DD42B8E42FB21d",
+}
+
+func Test_ParsePublicKey(t *testing.T) {
+	pubKey, _ := newKey(t)
+
+	t.Run("happy", func(t *testing.T) {
+		got, err := contracts.ParsePublicKey(hex.EncodeToString(pubKey))
+		require.NoError(t, err)
+		assert.Equal(t, pubKey, got)
+	})
+
+	t.Run("err_invalid_hex", func(t *testing.T) {
+		_, err := contracts.ParsePublicKey("invalid-key")
+		assert.Error(t, err)
+	})
+
+	t.Run("err_invalid_length", func(t *testing.T) {
+		_, err := contracts.ParsePublicKey("abcd")
+		assert.Error(t, err)
+	})
+}
+
+func Test_Sign_Verify(t *testing.T) {
+	pubKey, privKey := newKey(t)
+	signed, err := contracts.Sign(testManifest, privKey)
+	require.NoError(t, err)
+
+	t.Run("happy", func(t *testing.T) {
+		got, err := contracts.Verify(signed, pubKey)
+		require.NoError(t, err)
+		assert.Equal(t, testManifest, got)
+	})
+
+	t.Run("err_wrong_key", func(t *testing.T) {
+		otherPubKey, _ := newKey(t)
+		_, err := contracts.Verify(signed, otherPubKey)
+		assert.Error(t, err)
+	})
+
+	t.Run("err_tampered", func(t *testing.T) {
+		tampered := []byte(string(signed))
+		idx := len(`{"manifest":{"version":`)
+		tampered[idx] = '9'
+		_, err := contracts.Verify(tampered, pubKey)
+		assert.Error(t, err)
+	})
+
+	t.Run("err_missing_addresses", func(t *testing.T) {
+		data, err := contracts.Sign(contracts.Manifest{Version: 1}, privKey)
+		require.NoError(t, err)
+		_, err = contracts.Verify(data, pubKey)
+		assert.Error(t, err)
+	})
+
+	t.Run("err_invalid_format", func(t *testing.T) {
+		_, err := contracts.Verify([]byte("invalid"), pubKey)
+		assert.Error(t, err)
+	})
+}
+
+func Test_Fetch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/manifest.json" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte("signed-manifest")) // nolint: errcheck, gosec  // test server.
+	}))
+	t.Cleanup(server.Close)
+
+	t.Run("happy", func(t *testing.T) {
+		data, err := contracts.Fetch(context.Background(), server.URL+"/manifest.json")
+		require.NoError(t, err)
+		assert.Equal(t, "signed-manifest", string(data))
+	})
+
+	t.Run("err_not_found", func(t *testing.T) {
+		_, err := contracts.Fetch(context.Background(), server.URL+"/missing.json")
+		assert.Error(t, err)
+	})
+}
+
+func Test_Stage(t *testing.T) {
+	pubKey, privKey := newKey(t)
+
+	t.Run("happy", func(t *testing.T) {
+		file := filepath.Join(tempDir(t), "manifest.json")
+		_, isStaged, err := contracts.LoadStaged(file, pubKey)
+		require.NoError(t, err)
+		assert.False(t, isStaged)
+
+		m, err := contracts.Stage(file, sign(t, testManifest, privKey), pubKey)
+		require.NoError(t, err)
+		assert.Equal(t, testManifest, m)
+
+		staged, isStaged, err := contracts.LoadStaged(file, pubKey)
+		require.NoError(t, err)
+		assert.True(t, isStaged)
+		assert.Equal(t, testManifest, staged)
+
+		newer := testManifest
+		newer.Version++
+		_, err = contracts.Stage(file, sign(t, newer, privKey), pubKey)
+		assert.NoError(t, err)
+	})
+
+	t.Run("err_not_newer_version", func(t *testing.T) {
+		file := filepath.Join(tempDir(t), "manifest.json")
+		_, err := contracts.Stage(file, sign(t, testManifest, privKey), pubKey)
+		require.NoError(t, err)
+
+		_, err = contracts.Stage(file, sign(t, testManifest, privKey), pubKey)
+		assert.Error(t, err)
+	})
+
+	t.Run("err_invalid_signature", func(t *testing.T) {
+		file := filepath.Join(tempDir(t), "manifest.json")
+		_, otherPrivKey := newKey(t)
+		_, err := contracts.Stage(file, sign(t, testManifest, otherPrivKey), pubKey)
+		assert.Error(t, err)
+		assert.NoFileExists(t, file)
+	})
+
+	t.Run("err_staged_file_tampered", func(t *testing.T) {
+		file := filepath.Join(tempDir(t), "manifest.json")
+		require.NoError(t, ioutil.WriteFile(file, []byte("tampered"), 0o600))
+		_, _, err := contracts.LoadStaged(file, pubKey)
+		assert.Error(t, err)
+	})
+}
+
+func newKey(t *testing.T) (ed25519.PublicKey, ed25519.PrivateKey) {
+	pubKey, privKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	return pubKey, privKey
+}
+
+func sign(t *testing.T, m contracts.Manifest, key ed25519.PrivateKey) []byte {
+	data, err := contracts.Sign(m, key)
+	require.NoError(t, err)
+	return data
+}
+
+func tempDir(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "")
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		if err = os.RemoveAll(dir); err != nil {
+			t.Log("Error in test cleanup: removing dir - " + dir)
+		}
+	})
+	return dir
+}
@@ -0,0 +1,26 @@
+// Copyright (c) 2020 - for information on the respective copyright owner
+// see the NOTICE file and/or the repository at
+// https://github.com/hyperledger-labs/perun-node
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package discovery announces the node on the local network and discovers
+// other nodes announcing themselves, using multicast DNS (mDNS, RFC 6762).
+//
+// It is meant for demos and test labs, where peers can then be added to the
+// contacts without manually exchanging their off-chain and comm addresses.
+// Each node announces a "_perun._tcp.local" service instance named after its
+// off-chain address, with the off-chain and comm addresses in the TXT record.
+// The announcements are not authenticated, so discovered peers should not be
+// trusted on networks that are not.
+package discovery
@@ -0,0 +1,308 @@
+// Copyright (c) 2020 - for information on the respective copyright owner
+// see the NOTICE file and/or the repository at
+// https://github.com/hyperledger-labs/perun-node
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package discovery
+
+import (
+	"context"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/net/dns/dnsmessage"
+	"perun.network/go-perun/wallet"
+
+	"github.com/hyperledger-labs/perun-node"
+	"github.com/hyperledger-labs/perun-node/log"
+)
+
+const (
+	// ServiceName is the name of the service announced by the nodes.
+	ServiceName = "_perun._tcp.local."
+	// TTL is the time for which an announcement is valid. Peers that are not announced again
+	// within the TTL are no longer listed as discovered.
+	TTL = 2 * time.Minute
+	// MaxPeers is the maximum number of discovered peers held by the service. Announcements from new peers are
+	// ignored until the ones held expire.
+	MaxPeers = 256
+
+	maxPacketLen = 9000 // Max size of mDNS packets, including the IP and UDP headers (RFC 6762, section 17).
+
+	txtOffChainAddr = "offchain="
+	txtCommAddr     = "commaddr="
+)
+
+// mdnsGroup is the IPv4 multicast address for mDNS.
+var mdnsGroup = &net.UDPAddr{IP: net.IPv4(224, 0, 0, 251), Port: 5353}
+
+// Peer is a node discovered on the local network.
+type Peer struct {
+	OffChainAddr string    `json:"offChainAddr"`
+	CommAddr     string    `json:"commAddr"`
+	LastSeen     time.Time `json:"lastSeen"`
+}
+
+// Service announces the node via mDNS and collects the announcements of other nodes.
+type Service struct {
+	log.Logger
+
+	offChainAddr string
+	commAddr     string
+	self         wallet.Address
+	backend      perun.WalletBackend // For parsing the off-chain addresses of peers.
+
+	mutex sync.RWMutex
+	peers map[string]Peer // Indexed by off-chain address.
+}
+
+// NewService returns a service that announces the given off-chain address and comm address (host:port).
+// If the host in the comm address is empty or unspecified, peers use the IP address from which the
+// announcement was received. The off-chain addresses announced by peers are parsed using the wallet backend.
+func NewService(offChainAddr, commAddr string, backend perun.WalletBackend) (*Service, error) {
+	self, err := backend.ParseAddr(offChainAddr)
+	if err != nil {
+		return nil, errors.WithMessage(err, "off-chain address")
+	}
+	return &Service{
+		Logger:       log.NewLoggerWithField("component", "discovery"),
+		offChainAddr: offChainAddr,
+		commAddr:     commAddr,
+		self:         self,
+		backend:      backend,
+		peers:        make(map[string]Peer),
+	}, nil
+}
+
+// Run announces the node at the given interval and responds to queries from other nodes, until the
+// context is cancelled. A query is sent on start, so that the nodes already running announce themselves.
+func (s *Service) Run(ctx context.Context, interval time.Duration) error {
+	recvConn, err := net.ListenMulticastUDP("udp4", nil, mdnsGroup)
+	if err != nil {
+		return errors.Wrap(err, "joining mdns group")
+	}
+	defer recvConn.Close() // nolint: errcheck  // nothing to do if closing fails.
+	sendConn, err := net.ListenUDP("udp4", &net.UDPAddr{})
+	if err != nil {
+		return errors.Wrap(err, "opening socket for sending")
+	}
+	defer sendConn.Close() // nolint: errcheck  // nothing to do if closing fails.
+
+	queried := make(chan struct{}, 1)
+	go s.receive(recvConn, queried)
+	s.send(sendConn, query)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		s.send(sendConn, s.announcement)
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		case <-queried:
+		}
+	}
+}
+
+// send builds the packet and sends it to the mdns group. Errors are logged.
+func (s *Service) send(conn net.PacketConn, build func() ([]byte, error)) {
+	packet, err := build()
+	if err == nil {
+		_, err = conn.WriteTo(packet, mdnsGroup)
+	}
+	if err != nil {
+		s.Error("Sending mdns packet: ", err)
+	}
+}
+
+// receive handles the packets received on the connection, until it is closed. When a query for the
+// service is received, it signals on queried.
+func (s *Service) receive(conn net.PacketConn, queried chan<- struct{}) {
+	buf := make([]byte, maxPacketLen)
+	for {
+		n, from, err := conn.ReadFrom(buf)
+		if err != nil {
+			return
+		}
+		var fromIP net.IP
+		if udpAddr, ok := from.(*net.UDPAddr); ok {
+			fromIP = udpAddr.IP
+		}
+		if !s.handle(buf[:n], fromIP, time.Now()) {
+			continue
+		}
+		select {
+		case queried <- struct{}{}:
+		default: // An announcement is already due.
+		}
+	}
+}
+
+// handle records the peers announced in a response and returns true if the packet is a query for the
+// service. Packets that cannot be parsed are ignored.
+func (s *Service) handle(packet []byte, from net.IP, now time.Time) (isQuery bool) {
+	var p dnsmessage.Parser
+	header, err := p.Start(packet)
+	if err != nil {
+		return false
+	}
+	questions, err := p.AllQuestions()
+	if err != nil {
+		return false
+	}
+	if !header.Response {
+		for _, q := range questions {
+			if strings.EqualFold(q.Name.String(), ServiceName) {
+				return true
+			}
+		}
+		return false
+	}
+
+	answers, err := p.AllAnswers()
+	if err != nil {
+		return false
+	}
+	for _, answer := range answers {
+		txt, ok := answer.Body.(*dnsmessage.TXTResource)
+		if !ok || !strings.HasSuffix(strings.ToLower(answer.Header.Name.String()), "."+ServiceName) {
+			continue
+		}
+		peer, ok := parseTXT(txt.TXT, from)
+		if !ok {
+			continue
+		}
+		addr, err := s.backend.ParseAddr(peer.OffChainAddr)
+		if err != nil || addr.Equals(s.self) {
+			continue
+		}
+		peer.OffChainAddr, peer.LastSeen = addr.String(), now
+		s.add(peer)
+	}
+	return false
+}
+
+// add records the peer, after removing the peers not announced within the TTL. If MaxPeers are already recorded,
+// a new peer is ignored.
+func (s *Service) add(peer Peer) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for key, p := range s.peers {
+		if peer.LastSeen.Sub(p.LastSeen) >= TTL {
+			delete(s.peers, key)
+		}
+	}
+	if _, known := s.peers[peer.OffChainAddr]; !known && len(s.peers) >= MaxPeers {
+		return
+	}
+	s.peers[peer.OffChainAddr] = peer
+}
+
+// parseTXT parses the peer from the TXT record of an announcement. If the host in the comm address is
+// empty or unspecified, it is replaced by the IP address from which the announcement was received.
+func parseTXT(records []string, from net.IP) (Peer, bool) {
+	var peer Peer
+	for _, record := range records {
+		switch {
+		case strings.HasPrefix(record, txtOffChainAddr):
+			peer.OffChainAddr = strings.TrimPrefix(record, txtOffChainAddr)
+		case strings.HasPrefix(record, txtCommAddr):
+			peer.CommAddr = strings.TrimPrefix(record, txtCommAddr)
+		}
+	}
+	host, port, err := net.SplitHostPort(peer.CommAddr)
+	if peer.OffChainAddr == "" || err != nil {
+		return Peer{}, false
+	}
+	if ip := net.ParseIP(host); (host == "" || ip != nil && ip.IsUnspecified()) && from != nil {
+		peer.CommAddr = net.JoinHostPort(from.String(), port)
+	}
+	return peer, true
+}
+
+// DiscoveredPeers returns the peers announced within the TTL, sorted by their off-chain address.
+func (s *Service) DiscoveredPeers() []Peer {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	peers := make([]Peer, 0, len(s.peers))
+	for _, peer := range s.peers {
+		if time.Since(peer.LastSeen) < TTL {
+			peers = append(peers, peer)
+		}
+	}
+	sort.Slice(peers, func(i, j int) bool { return peers[i].OffChainAddr < peers[j].OffChainAddr })
+	return peers
+}
+
+// announcement returns the mDNS response announcing the node: a PTR record pointing to the instance
+// for the node and the SRV and TXT records of the instance.
+func (s *Service) announcement() ([]byte, error) {
+	instance, err := dnsmessage.NewName(s.offChainAddr + "." + ServiceName)
+	if err != nil {
+		return nil, errors.Wrap(err, "instance name")
+	}
+	_, portStr, err := net.SplitHostPort(s.commAddr)
+	if err != nil {
+		return nil, errors.Wrap(err, "comm address")
+	}
+	port, err := strconv.ParseUint(portStr, 10, 16)
+	if err != nil {
+		return nil, errors.Wrap(err, "comm address port")
+	}
+
+	b := dnsmessage.NewBuilder(nil, dnsmessage.Header{Response: true, Authoritative: true})
+	b.EnableCompression()
+	if err = b.StartAnswers(); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	ttl := uint32(TTL / time.Second)
+	header := func(name dnsmessage.Name) dnsmessage.ResourceHeader {
+		return dnsmessage.ResourceHeader{Name: name, Class: dnsmessage.ClassINET, TTL: ttl}
+	}
+	service := dnsmessage.MustNewName(ServiceName)
+	if err = b.PTRResource(header(service), dnsmessage.PTRResource{PTR: instance}); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	// Address records for the target are not announced, as peers use the comm address in the TXT record.
+	if err = b.SRVResource(header(instance), dnsmessage.SRVResource{Target: instance, Port: uint16(port)}); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	txt := dnsmessage.TXTResource{TXT: []string{txtOffChainAddr + s.offChainAddr, txtCommAddr + s.commAddr}}
+	if err = b.TXTResource(header(instance), txt); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	packet, err := b.Finish()
+	return packet, errors.WithStack(err)
+}
+
+// query returns the mDNS query for the instances of the service.
+func query() ([]byte, error) {
+	b := dnsmessage.NewBuilder(nil, dnsmessage.Header{})
+	if err := b.StartQuestions(); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	q := dnsmessage.Question{Name: dnsmessage.MustNewName(ServiceName), Type: dnsmessage.TypePTR,
+		Class: dnsmessage.ClassINET}
+	if err := b.Question(q); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	packet, err := b.Finish()
+	return packet, errors.WithStack(err)
+}
@@ -0,0 +1,129 @@
+// Copyright (c) 2020 - for information on the respective copyright owner
+// see the NOTICE file and/or the repository at
+// https://github.com/hyperledger-labs/perun-node
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package discovery
+
+import (
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"perun.network/go-perun/pkg/test"
+
+	"github.com/hyperledger-labs/perun-node/blockchain/ethereum"
+	"github.com/hyperledger-labs/perun-node/blockchain/ethereum/ethereumtest"
+)
+
+func Test_Service_handle(t *testing.T) {
+	rng := test.Prng(t)
+	backend := ethereum.NewWalletBackend()
+	newService := func(t *testing.T, commAddr string) *Service {
+		s, err := NewService(ethereumtest.NewRandomAddress(rng).String(), commAddr, backend)
+		require.NoError(t, err)
+		return s
+	}
+	alice := newService(t, "192.168.1.10:5751")
+	from := net.IPv4(192, 168, 1, 10)
+	announcement, err := alice.announcement()
+	require.NoError(t, err)
+
+	t.Run("happy_announcement", func(t *testing.T) {
+		bob := newService(t, "192.168.1.11:5751")
+		assert.False(t, bob.handle(announcement, from, time.Now()))
+		peers := bob.DiscoveredPeers()
+		require.Len(t, peers, 1)
+		assert.Equal(t, alice.self.String(), peers[0].OffChainAddr)
+		assert.Equal(t, "192.168.1.10:5751", peers[0].CommAddr)
+	})
+	t.Run("happy_unspecified_host", func(t *testing.T) {
+		packet, err := newService(t, "0.0.0.0:5751").announcement()
+		require.NoError(t, err)
+		bob := newService(t, "192.168.1.11:5751")
+		bob.handle(packet, net.IPv4(192, 168, 1, 12), time.Now())
+		peers := bob.DiscoveredPeers()
+		require.Len(t, peers, 1)
+		assert.Equal(t, "192.168.1.12:5751", peers[0].CommAddr)
+	})
+	t.Run("happy_query", func(t *testing.T) {
+		packet, err := query()
+		require.NoError(t, err)
+		assert.True(t, alice.handle(packet, from, time.Now()))
+	})
+	t.Run("happy_own_announcement", func(t *testing.T) {
+		alice.handle(announcement, from, time.Now())
+		assert.Empty(t, alice.DiscoveredPeers())
+	})
+	t.Run("happy_expired", func(t *testing.T) {
+		bob := newService(t, "192.168.1.11:5751")
+		bob.handle(announcement, from, time.Now().Add(-TTL))
+		assert.Empty(t, bob.DiscoveredPeers())
+	})
+	t.Run("happy_pruned_on_insert", func(t *testing.T) {
+		bob := newService(t, "192.168.1.11:5751")
+		bob.handle(announcement, from, time.Now().Add(-TTL))
+		packet, err := newService(t, "192.168.1.12:5751").announcement()
+		require.NoError(t, err)
+		bob.handle(packet, net.IPv4(192, 168, 1, 12), time.Now())
+		assert.Len(t, bob.peers, 1, "expired peer should be removed")
+	})
+	t.Run("happy_max_peers", func(t *testing.T) {
+		bob := newService(t, "192.168.1.11:5751")
+		for i := 0; i < MaxPeers+1; i++ {
+			packet, err := newService(t, fmt.Sprintf("10.0.%d.%d:5751", i/250, i%250+1)).announcement()
+			require.NoError(t, err)
+			bob.handle(packet, nil, time.Now())
+		}
+		assert.Len(t, bob.DiscoveredPeers(), MaxPeers)
+		bob.handle(announcement, from, time.Now())
+		assert.Len(t, bob.DiscoveredPeers(), MaxPeers, "new peer should be ignored")
+	})
+	t.Run("happy_normalized_addr", func(t *testing.T) {
+		lower, err := NewService(strings.ToLower(alice.offChainAddr), "192.168.1.10:5751", backend)
+		require.NoError(t, err)
+		bob := newService(t, "192.168.1.11:5751")
+		announced, err := lower.announcement()
+		require.NoError(t, err)
+		bob.handle(announced, from, time.Now())
+		bob.handle(announcement, from, time.Now())
+		peers := bob.DiscoveredPeers()
+		require.Len(t, peers, 1)
+		assert.Equal(t, alice.self.String(), peers[0].OffChainAddr)
+	})
+	t.Run("err_invalid_off_chain_addr", func(t *testing.T) {
+		packet, err := (&Service{offChainAddr: "invalid", commAddr: "192.168.1.10:5751"}).announcement()
+		require.NoError(t, err)
+		bob := newService(t, "192.168.1.11:5751")
+		bob.handle(packet, from, time.Now())
+		assert.Empty(t, bob.DiscoveredPeers())
+
+		_, err = NewService("invalid", "192.168.1.10:5751", backend)
+		assert.Error(t, err)
+	})
+	t.Run("err_invalid_packet", func(t *testing.T) {
+		bob := newService(t, "192.168.1.11:5751")
+		assert.False(t, bob.handle([]byte("invalid"), from, time.Now()))
+		assert.False(t, bob.handle(announcement[:len(announcement)-4], from, time.Now()))
+		assert.Empty(t, bob.DiscoveredPeers())
+	})
+	t.Run("err_invalid_comm_addr", func(t *testing.T) {
+		_, err := newService(t, "192.168.1.10").announcement()
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,26 @@
+// Copyright (c) 2020 - for information on the respective copyright owner
+// see the NOTICE file and/or the repository at
+// https://github.com/hyperledger-labs/perun-node
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package disk implements monitoring of the free space on the disk holding
+// the data of the node.
+//
+// When the free space falls below the configured threshold, a warning is
+// logged and the monitor reports an error, that can be used to refuse
+// actions that require new data to be persisted (such as opening channels
+// or accepting updates). The threshold should leave enough headroom for
+// persisting the updates required for settling the open channels, so that
+// the node never writes into a full disk.
+package disk
@@ -0,0 +1,110 @@
+// Copyright (c) 2020 - for information on the respective copyright owner
+// see the NOTICE file and/or the repository at
+// https://github.com/hyperledger-labs/perun-node
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package disk
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/hyperledger-labs/perun-node/log"
+)
+
+// ErrLowDiskSpace is returned by Monitor.Err when the free space is below the threshold.
+type ErrLowDiskSpace struct {
+	Free      uint64
+	Threshold uint64
+}
+
+func (e ErrLowDiskSpace) Error() string {
+	return fmt.Sprintf("free disk space %d MB is below the threshold %d MB", e.Free/MB, e.Threshold/MB)
+}
+
+// MB is the number of bytes in a megabyte.
+const MB = 1 << 20
+
+// Monitor periodically checks the free space on the file system containing a directory.
+type Monitor struct {
+	log.Logger
+
+	dir       string
+	threshold uint64
+
+	mutex sync.RWMutex
+	free  uint64
+	low   bool
+}
+
+// NewMonitor returns a monitor that checks the free space on the file system containing the directory
+// and reports low disk space if it is below the threshold (in bytes).
+func NewMonitor(dir string, threshold uint64) *Monitor {
+	return &Monitor{
+		Logger:    log.NewLoggerWithField("component", "disk"),
+		dir:       dir,
+		threshold: threshold,
+	}
+}
+
+// Check reads the free space and stores it. A warning is logged when the free space falls below the
+// threshold and an info when it recovers. If reading fails, the previous value is retained.
+func (m *Monitor) Check() (uint64, error) {
+	free, err := FreeSpace(m.dir)
+	if err != nil {
+		return 0, err
+	}
+	m.mutex.Lock()
+	wasLow := m.low
+	m.free, m.low = free, free < m.threshold
+	m.mutex.Unlock()
+
+	switch {
+	case !wasLow && free < m.threshold:
+		m.Warn(ErrLowDiskSpace{Free: free, Threshold: m.threshold}.Error(),
+			", refusing new channels and updates until space is freed.")
+	case wasLow && free >= m.threshold:
+		m.Infof("Free disk space %d MB is above the threshold, accepting new channels and updates.", free/MB)
+	}
+	return free, nil
+}
+
+// Run checks the free space at the given interval, until the context is canceled.
+// Errors in reading the free space are logged.
+func (m *Monitor) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if _, err := m.Check(); err != nil {
+			m.Error("Checking free disk space: ", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Err returns an ErrLowDiskSpace if the last checked free space is below the threshold, else nil.
+func (m *Monitor) Err() error {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	if m.low {
+		return ErrLowDiskSpace{Free: m.free, Threshold: m.threshold}
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+// Copyright (c) 2020 - for information on the respective copyright owner
+// see the NOTICE file and/or the repository at
+// https://github.com/hyperledger-labs/perun-node
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package disk_test
+
+import (
+	"math"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger-labs/perun-node/disk"
+)
+
+func Test_FreeSpace(t *testing.T) {
+	t.Run("happy", func(t *testing.T) {
+		free, err := disk.FreeSpace(".")
+		require.NoError(t, err)
+		assert.NotZero(t, free)
+	})
+
+	t.Run("err_missing_dir", func(t *testing.T) {
+		_, err := disk.FreeSpace(filepath.Join("missing", "dir"))
+		assert.Error(t, err)
+	})
+}
+
+func Test_Monitor(t *testing.T) {
+	t.Run("happy_above_threshold", func(t *testing.T) {
+		monitor := disk.NewMonitor(".", disk.MB)
+		_, err := monitor.Check()
+		require.NoError(t, err)
+		assert.NoError(t, monitor.Err())
+	})
+
+	t.Run("happy_below_threshold", func(t *testing.T) {
+		monitor := disk.NewMonitor(".", math.MaxUint64)
+		_, err := monitor.Check()
+		require.NoError(t, err)
+		assert.IsType(t, disk.ErrLowDiskSpace{}, monitor.Err())
+	})
+
+	t.Run("err_missing_dir", func(t *testing.T) {
+		monitor := disk.NewMonitor(filepath.Join("missing", "dir"), math.MaxUint64)
+		_, err := monitor.Check()
+		assert.Error(t, err)
+		assert.NoError(t, monitor.Err(), "previous state should be retained")
+	})
+}
@@ -0,0 +1,24 @@
+// Copyright (c) 2020 - for information on the respective copyright owner
+// see the NOTICE file and/or the repository at
+// https://github.com/hyperledger-labs/perun-node
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package features implements flags for enabling experimental features of
+// the node at runtime.
+//
+// The set of enabled features is configured in the node config and can be
+// overridden at runtime (for example, using the admin API). Overrides take
+// precedence over the configured values until they are reset. Only the
+// features known to this version of the node can be enabled.
+package features
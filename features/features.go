@@ -0,0 +1,141 @@
+// Copyright (c) 2020 - for information on the respective copyright owner
+// see the NOTICE file and/or the repository at
+// https://github.com/hyperledger-labs/perun-node
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package features
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// Names of the known features.
+const (
+	// PipelinedUpdates allows sending a new update on a channel before the previous one is acknowledged.
+	PipelinedUpdates = "pipelined-updates"
+	// Compression compresses the messages sent to peers.
+	Compression = "compression"
+	// VirtualChannels allows opening channels funded via existing channels with an intermediary.
+	VirtualChannels = "virtual-channels"
+)
+
+// known is the list of features known to this version of the node.
+var known = []string{PipelinedUpdates, Compression, VirtualChannels}
+
+// Flags holds the state of feature flags. It is safe for concurrent use.
+type Flags struct {
+	mutex      sync.RWMutex
+	configured map[string]bool
+	overrides  map[string]bool
+}
+
+// New returns flags with the given features enabled. It returns an error if any of the features is unknown.
+func New(enabled []string) (*Flags, error) {
+	f := &Flags{overrides: make(map[string]bool)}
+	if err := f.Configure(enabled); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// Known returns the names of all the features known to this version of the node.
+func Known() []string {
+	return append([]string{}, known...)
+}
+
+// Configure replaces the set of configured features with the given ones. Overrides are retained.
+func (f *Flags) Configure(enabled []string) error {
+	configured := make(map[string]bool, len(enabled))
+	for _, name := range enabled {
+		if !isKnown(name) {
+			return errors.Errorf("unknown feature %q", name)
+		}
+		configured[name] = true
+	}
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.configured = configured
+	return nil
+}
+
+// Override enables or disables the feature, irrespective of the configured value.
+func (f *Flags) Override(name string, enabled bool) error {
+	if !isKnown(name) {
+		return errors.Errorf("unknown feature %q", name)
+	}
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.overrides[name] = enabled
+	return nil
+}
+
+// Reset removes the override for the feature, so that the configured value is used.
+func (f *Flags) Reset(name string) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	delete(f.overrides, name)
+}
+
+// Enabled returns true if the feature is enabled.
+func (f *Flags) Enabled(name string) bool {
+	f.mutex.RLock()
+	defer f.mutex.RUnlock()
+	return f.enabled(name)
+}
+
+// All returns the state of all known features.
+func (f *Flags) All() map[string]bool {
+	f.mutex.RLock()
+	defer f.mutex.RUnlock()
+	all := make(map[string]bool, len(known))
+	for _, name := range known {
+		all[name] = f.enabled(name)
+	}
+	return all
+}
+
+// Advertised returns the sorted list of enabled features, to be advertised to the peers.
+// Disabled features are not advertised, so that peers do not use them in the communication.
+func (f *Flags) Advertised() []string {
+	f.mutex.RLock()
+	defer f.mutex.RUnlock()
+	var advertised []string
+	for _, name := range known {
+		if f.enabled(name) {
+			advertised = append(advertised, name)
+		}
+	}
+	sort.Strings(advertised)
+	return advertised
+}
+
+// enabled should be called with the mutex locked.
+func (f *Flags) enabled(name string) bool {
+	if enabled, ok := f.overrides[name]; ok {
+		return enabled
+	}
+	return f.configured[name]
+}
+
+func isKnown(name string) bool {
+	for _, k := range known {
+		if k == name {
+			return true
+		}
+	}
+	return false
+}
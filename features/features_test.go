@@ -0,0 +1,72 @@
+// Copyright (c) 2020 - for information on the respective copyright owner
+// see the NOTICE file and/or the repository at
+// https://github.com/hyperledger-labs/perun-node
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package features_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger-labs/perun-node/features"
+)
+
+func Test_New(t *testing.T) {
+	t.Run("happy", func(t *testing.T) {
+		f, err := features.New([]string{features.Compression})
+		require.NoError(t, err)
+		assert.True(t, f.Enabled(features.Compression))
+		assert.False(t, f.Enabled(features.VirtualChannels))
+		assert.Equal(t, []string{features.Compression}, f.Advertised())
+	})
+
+	t.Run("err_unknown_feature", func(t *testing.T) {
+		_, err := features.New([]string{"unknown"})
+		assert.Error(t, err)
+	})
+}
+
+func Test_Flags_Override(t *testing.T) {
+	t.Run("happy", func(t *testing.T) {
+		f, err := features.New([]string{features.Compression})
+		require.NoError(t, err)
+
+		require.NoError(t, f.Override(features.Compression, false))
+		require.NoError(t, f.Override(features.PipelinedUpdates, true))
+		assert.Equal(t, map[string]bool{
+			features.Compression:      false,
+			features.PipelinedUpdates: true,
+			features.VirtualChannels:  false,
+		}, f.All())
+		assert.Equal(t, []string{features.PipelinedUpdates}, f.Advertised())
+
+		// Overrides are retained when configured features change.
+		require.NoError(t, f.Configure([]string{features.Compression, features.VirtualChannels}))
+		assert.False(t, f.Enabled(features.Compression))
+		assert.True(t, f.Enabled(features.VirtualChannels))
+
+		f.Reset(features.Compression)
+		assert.True(t, f.Enabled(features.Compression))
+	})
+
+	t.Run("err_unknown_feature", func(t *testing.T) {
+		f, err := features.New(nil)
+		require.NoError(t, err)
+		assert.Error(t, f.Override("unknown", true))
+		assert.Error(t, f.Configure([]string{"unknown"}))
+	})
+}
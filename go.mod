@@ -7,7 +7,10 @@ require (
 	github.com/gorilla/websocket v1.4.2
 	github.com/phayes/freeport v0.0.0-20180830031419-95f893ade6f2
 	github.com/pkg/errors v0.9.1
+	github.com/sirupsen/logrus v1.6.0
 	github.com/stretchr/testify v1.6.0
+	golang.org/x/crypto v0.0.0-20200510223506-06a226fb4e37
+	golang.org/x/net v0.0.0-20200528225125-3c3fba18258b
 	gopkg.in/yaml.v3 v3.0.0-20200615113413-eeeca48fe776
 	perun.network/go-perun v0.4.0
 )
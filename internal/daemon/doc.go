@@ -0,0 +1,25 @@
+// Copyright (c) 2020 - for information on the respective copyright owner
+// see the NOTICE file and/or the repository at
+// https://github.com/hyperledger-labs/perun-node
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package daemon implements the plumbing required for running the node as a
+// service under a supervisor such as systemd: managing a PID file and sending
+// readiness, status and watchdog notifications using the sd_notify protocol.
+//
+// The sd_notify protocol is implemented directly (without linking to
+// libsystemd), by sending datagrams to the unix socket at $NOTIFY_SOCKET.
+// When the node is not started by systemd, the variable is not set and the
+// notifications are silently skipped.
+package daemon
@@ -0,0 +1,111 @@
+// Copyright (c) 2020 - for information on the respective copyright owner
+// see the NOTICE file and/or the repository at
+// https://github.com/hyperledger-labs/perun-node
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package daemon
+
+import (
+	"context"
+	"net"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Notification states defined in the sd_notify protocol.
+const (
+	StateReady     = "READY=1"
+	StateReloading = "RELOADING=1"
+	StateStopping  = "STOPPING=1"
+	StateWatchdog  = "WATCHDOG=1"
+)
+
+// Notify sends the state notification to the service manager.
+//
+// It returns false, if notifications are not supported (NOTIFY_SOCKET is not set).
+// Multiple states can be sent in a single notification by separating them with a newline.
+func Notify(state string) (bool, error) {
+	socketAddr := &net.UnixAddr{
+		Name: os.Getenv("NOTIFY_SOCKET"),
+		Net:  "unixgram",
+	}
+	if socketAddr.Name == "" {
+		return false, nil
+	}
+	// Socket names starting with "@" are in the abstract namespace.
+	if socketAddr.Name[0] == '@' {
+		socketAddr.Name = "\x00" + socketAddr.Name[1:]
+	}
+
+	conn, err := net.DialUnix(socketAddr.Net, nil, socketAddr)
+	if err != nil {
+		return false, errors.Wrap(err, "connecting to notify socket")
+	}
+	defer conn.Close() // nolint: errcheck  // safe to ignore errors when closing a datagram socket after write.
+
+	if _, err = conn.Write([]byte(state)); err != nil {
+		return false, errors.Wrap(err, "writing to notify socket")
+	}
+	return true, nil
+}
+
+// Status returns a notification state that describes the status of the service in free form text.
+func Status(status string) string {
+	return "STATUS=" + status
+}
+
+// WatchdogInterval returns the interval within which the service manager expects watchdog
+// notifications. It returns false if the watchdog is not enabled for this process.
+func WatchdogInterval() (time.Duration, bool) {
+	usec, err := strconv.ParseInt(os.Getenv("WATCHDOG_USEC"), 10, 64)
+	if err != nil || usec <= 0 {
+		return 0, false
+	}
+	if pidStr := os.Getenv("WATCHDOG_PID"); pidStr != "" {
+		if pid, pidErr := strconv.Atoi(pidStr); pidErr != nil || pid != os.Getpid() {
+			return 0, false
+		}
+	}
+	return time.Duration(usec) * time.Microsecond, true
+}
+
+// RunWatchdog sends watchdog notifications at half the interval expected by the service manager,
+// until the context is cancelled. It returns immediately if the watchdog is not enabled.
+//
+// The health check is called before each notification and the notification is skipped if
+// it returns an error, causing the service manager to restart the service on repeated failures.
+func RunWatchdog(ctx context.Context, healthCheck func() error) error {
+	interval, ok := WatchdogInterval()
+	if !ok {
+		return nil
+	}
+	ticker := time.NewTicker(interval / 2)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			if healthCheck != nil && healthCheck() != nil {
+				continue
+			}
+			if _, err := Notify(StateWatchdog); err != nil {
+				return err
+			}
+		}
+	}
+}
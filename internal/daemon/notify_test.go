@@ -0,0 +1,130 @@
+// Copyright (c) 2020 - for information on the respective copyright owner
+// see the NOTICE file and/or the repository at
+// https://github.com/hyperledger-labs/perun-node
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package daemon_test
+
+import (
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger-labs/perun-node/internal/daemon"
+)
+
+func Test_Notify(t *testing.T) {
+	t.Run("happy", func(t *testing.T) {
+		conn := notifySocket(t)
+
+		ok, err := daemon.Notify(daemon.StateReady)
+		require.NoError(t, err)
+		assert.True(t, ok)
+		assert.Equal(t, daemon.StateReady, readNotification(t, conn))
+	})
+
+	t.Run("happy_not_supported", func(t *testing.T) {
+		setEnv(t, "NOTIFY_SOCKET", "")
+
+		ok, err := daemon.Notify(daemon.StateReady)
+		assert.NoError(t, err)
+		assert.False(t, ok)
+	})
+
+	t.Run("err_missing_socket", func(t *testing.T) {
+		setEnv(t, "NOTIFY_SOCKET", filepath.Join(tempDir(t), "missing.sock"))
+
+		ok, err := daemon.Notify(daemon.StateReady)
+		assert.Error(t, err)
+		assert.False(t, ok)
+	})
+}
+
+func Test_WatchdogInterval(t *testing.T) {
+	t.Run("happy", func(t *testing.T) {
+		setEnv(t, "WATCHDOG_USEC", "2000000")
+		setEnv(t, "WATCHDOG_PID", strconv.Itoa(os.Getpid()))
+
+		interval, ok := daemon.WatchdogInterval()
+		assert.True(t, ok)
+		assert.Equal(t, 2*time.Second, interval)
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		setEnv(t, "WATCHDOG_USEC", "")
+
+		_, ok := daemon.WatchdogInterval()
+		assert.False(t, ok)
+	})
+
+	t.Run("other_process", func(t *testing.T) {
+		setEnv(t, "WATCHDOG_USEC", "2000000")
+		setEnv(t, "WATCHDOG_PID", strconv.Itoa(os.Getppid()))
+
+		_, ok := daemon.WatchdogInterval()
+		assert.False(t, ok)
+	})
+}
+
+func Test_RunWatchdog(t *testing.T) {
+	conn := notifySocket(t)
+	setEnv(t, "WATCHDOG_USEC", "20000")
+	setEnv(t, "WATCHDOG_PID", "")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		assert.NoError(t, daemon.RunWatchdog(ctx, func() error { return nil }))
+	}()
+	assert.Equal(t, daemon.StateWatchdog, readNotification(t, conn))
+}
+
+// notifySocket starts listening on a unix datagram socket and sets its path in NOTIFY_SOCKET.
+func notifySocket(t *testing.T) *net.UnixConn {
+	socketPath := filepath.Join(tempDir(t), "notify.sock")
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: socketPath, Net: "unixgram"})
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		if err = conn.Close(); err != nil {
+			t.Log("Error in test cleanup: closing socket - " + socketPath)
+		}
+	})
+	setEnv(t, "NOTIFY_SOCKET", socketPath)
+	return conn
+}
+
+func readNotification(t *testing.T, conn *net.UnixConn) string {
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(time.Second)))
+	buf := make([]byte, 1024)
+	n, err := conn.Read(buf)
+	require.NoError(t, err)
+	return string(buf[:n])
+}
+
+// setEnv sets the environment variable and registers a cleanup function to unset it.
+func setEnv(t *testing.T, key, value string) {
+	require.NoError(t, os.Setenv(key, value))
+	t.Cleanup(func() {
+		if err := os.Unsetenv(key); err != nil {
+			t.Log("Error in test cleanup: unsetting env - " + key)
+		}
+	})
+}
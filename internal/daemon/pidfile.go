@@ -0,0 +1,72 @@
+// Copyright (c) 2020 - for information on the respective copyright owner
+// see the NOTICE file and/or the repository at
+// https://github.com/hyperledger-labs/perun-node
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package daemon
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/pkg/errors"
+)
+
+// WritePIDFile writes the process ID of the current process to the file at the given path.
+//
+// If the file already exists and the process ID in it belongs to a running process, an error is
+// returned, as it means another instance of the node is using the same PID file. Stale PID files
+// left behind by processes that were not shut down cleanly are overwritten.
+func WritePIDFile(path string) error {
+	if pid, err := readPIDFile(path); err == nil && pid != os.Getpid() && isRunning(pid) {
+		return errors.Errorf("pid file %s exists and process %d is running", path, pid)
+	}
+	err := ioutil.WriteFile(filepath.Clean(path), []byte(strconv.Itoa(os.Getpid())+"\n"), 0o600)
+	return errors.Wrap(err, "writing pid file")
+}
+
+// RemovePIDFile removes the PID file at the given path, if it was written by this process.
+func RemovePIDFile(path string) error {
+	pid, err := readPIDFile(path)
+	if err != nil {
+		return err
+	}
+	if pid != os.Getpid() {
+		return errors.Errorf("pid file %s was written by another process %d", path, pid)
+	}
+	return errors.Wrap(os.Remove(path), "removing pid file")
+}
+
+func readPIDFile(path string) (int, error) {
+	content, err := ioutil.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return 0, errors.Wrap(err, "reading pid file")
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(content)))
+	return pid, errors.Wrap(err, "parsing pid file")
+}
+
+// isRunning checks if a process with the given ID is running, by sending signal 0 to it.
+func isRunning(pid int) bool {
+	p, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	err = p.Signal(syscall.Signal(0))
+	return err == nil || errors.Is(err, syscall.EPERM)
+}
@@ -0,0 +1,83 @@
+// Copyright (c) 2020 - for information on the respective copyright owner
+// see the NOTICE file and/or the repository at
+// https://github.com/hyperledger-labs/perun-node
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package daemon_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger-labs/perun-node/internal/daemon"
+)
+
+func Test_WritePIDFile(t *testing.T) {
+	t.Run("happy", func(t *testing.T) {
+		pidFile := filepath.Join(tempDir(t), "node.pid")
+		require.NoError(t, daemon.WritePIDFile(pidFile))
+
+		content, err := ioutil.ReadFile(pidFile)
+		require.NoError(t, err)
+		assert.Equal(t, strconv.Itoa(os.Getpid())+"\n", string(content))
+		assert.NoError(t, daemon.RemovePIDFile(pidFile))
+		assert.NoFileExists(t, pidFile)
+	})
+
+	t.Run("happy_stale_pid_file", func(t *testing.T) {
+		pidFile := filepath.Join(tempDir(t), "node.pid")
+		require.NoError(t, ioutil.WriteFile(pidFile, []byte("999999999\n"), 0o600))
+		assert.NoError(t, daemon.WritePIDFile(pidFile))
+	})
+
+	t.Run("err_process_running", func(t *testing.T) {
+		pidFile := filepath.Join(tempDir(t), "node.pid")
+		require.NoError(t, ioutil.WriteFile(pidFile, []byte(strconv.Itoa(os.Getppid())), 0o600))
+		assert.Error(t, daemon.WritePIDFile(pidFile))
+	})
+
+	t.Run("err_invalid_dir", func(t *testing.T) {
+		assert.Error(t, daemon.WritePIDFile(filepath.Join(tempDir(t), "missing-dir", "node.pid")))
+	})
+}
+
+func Test_RemovePIDFile(t *testing.T) {
+	t.Run("err_missing_file", func(t *testing.T) {
+		assert.Error(t, daemon.RemovePIDFile(filepath.Join(tempDir(t), "node.pid")))
+	})
+
+	t.Run("err_other_process", func(t *testing.T) {
+		pidFile := filepath.Join(tempDir(t), "node.pid")
+		require.NoError(t, ioutil.WriteFile(pidFile, []byte(strconv.Itoa(os.Getppid())), 0o600))
+		assert.Error(t, daemon.RemovePIDFile(pidFile))
+		assert.FileExists(t, pidFile)
+	})
+}
+
+func tempDir(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "")
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		if err = os.RemoveAll(dir); err != nil {
+			t.Log("Error in test cleanup: removing dir - " + dir)
+		}
+	})
+	return dir
+}
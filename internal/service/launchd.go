@@ -0,0 +1,140 @@
+// Copyright (c) 2020 - for information on the respective copyright owner
+// see the NOTICE file and/or the repository at
+// https://github.com/hyperledger-labs/perun-node
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/pkg/errors"
+)
+
+// Launchd manages services as launchd agents of the current user.
+type Launchd struct {
+	AgentDir string
+	Run      Runner // If nil, commands are run using os/exec.
+}
+
+// DefaultAgentDir returns the directory in which the launchd agents of the current user are installed.
+func DefaultAgentDir() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", errors.Wrap(err, "finding home directory")
+	}
+	return filepath.Join(home, "Library", "LaunchAgents"), nil
+}
+
+// Install writes the property list for the agent and loads it. The agent is started at login and
+// restarted if it exits with an error.
+func (l *Launchd) Install(cfg Config) error {
+	if err := validate(cfg); err != nil {
+		return err
+	}
+	plistFile := l.plistFile(cfg.Name)
+	if _, err := os.Stat(plistFile); err == nil {
+		return errors.Errorf("service %s is already installed at %s", cfg.Name, plistFile)
+	}
+	plist, err := launchdPlist(cfg)
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(l.AgentDir, 0o755); err != nil {
+		return errors.Wrap(err, "creating agent directory")
+	}
+	if err = ioutil.WriteFile(plistFile, plist, 0o644); err != nil {
+		return errors.Wrap(err, "writing property list")
+	}
+	_, err = runnerOrDefault(l.Run)("launchctl", "load", "-w", plistFile)
+	return err
+}
+
+// Uninstall unloads the agent and removes its property list.
+func (l *Launchd) Uninstall(name string) error {
+	plistFile := l.plistFile(name)
+	if _, err := os.Stat(plistFile); err != nil {
+		return errors.Wrapf(err, "service %s is not installed", name)
+	}
+	if _, err := runnerOrDefault(l.Run)("launchctl", "unload", "-w", plistFile); err != nil {
+		return err
+	}
+	return errors.Wrap(os.Remove(plistFile), "removing property list")
+}
+
+// Status returns the status of the agent. It is running if launchd reports a PID for it.
+func (l *Launchd) Status(name string) (Status, error) {
+	if _, err := os.Stat(l.plistFile(name)); os.IsNotExist(err) {
+		return StatusNotInstalled, nil
+	}
+	// list exits with a non zero code when the agent is not loaded.
+	output, err := runnerOrDefault(l.Run)("launchctl", "list", name)
+	if err != nil || !strings.Contains(output, `"PID" = `) {
+		return StatusStopped, nil
+	}
+	return StatusRunning, nil
+}
+
+func (l *Launchd) plistFile(name string) string {
+	return filepath.Join(l.AgentDir, name+".plist")
+}
+
+var plistTemplate = template.Must(template.New("plist").Funcs(template.FuncMap{"xml": xmlEscape}).Parse(
+	`<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+	<key>Label</key>
+	<string>{{xml .Name}}</string>
+	<key>ProgramArguments</key>
+	<array>
+		<string>{{xml .Executable}}</string>
+{{- range .Args}}
+		<string>{{xml .}}</string>
+{{- end}}
+	</array>
+{{- if .WorkingDir}}
+	<key>WorkingDirectory</key>
+	<string>{{xml .WorkingDir}}</string>
+{{- end}}
+	<key>RunAtLoad</key>
+	<true/>
+	<key>KeepAlive</key>
+	<dict>
+		<key>SuccessfulExit</key>
+		<false/>
+	</dict>
+</dict>
+</plist>
+`))
+
+func launchdPlist(cfg Config) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := plistTemplate.Execute(&buf, cfg); err != nil {
+		return nil, errors.Wrap(err, "generating property list")
+	}
+	return buf.Bytes(), nil
+}
+
+func xmlEscape(s string) (string, error) {
+	var buf bytes.Buffer
+	err := xml.EscapeText(&buf, []byte(s))
+	return buf.String(), err
+}
@@ -0,0 +1,82 @@
+// Copyright (c) 2020 - for information on the respective copyright owner
+// see the NOTICE file and/or the repository at
+// https://github.com/hyperledger-labs/perun-node
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service_test
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger-labs/perun-node/internal/service"
+)
+
+func Test_Launchd(t *testing.T) {
+	cfg := service.Config{
+		Name:       "perun-node",
+		Executable: "/usr/local/bin/perunnode",
+		Args:       []string{"run", "-config", "/Users/a&b/node.yaml"},
+		WorkingDir: "/Users/a&b",
+	}
+
+	t.Run("happy", func(t *testing.T) {
+		agentDir := filepath.Join(tempDir(t), "LaunchAgents")
+		plistFile := filepath.Join(agentDir, "perun-node.plist")
+		r := &fakeRunner{outputs: map[string]string{"launchctl list perun-node": "{\n\t\"PID\" = 42;\n};\n"}}
+		l := &service.Launchd{AgentDir: agentDir, Run: r.run}
+		require.NoError(t, l.Install(cfg))
+
+		plist, err := ioutil.ReadFile(plistFile)
+		require.NoError(t, err)
+		assert.Contains(t, string(plist), "<string>perun-node</string>")
+		assert.Contains(t, string(plist), "<string>/Users/a&amp;b/node.yaml</string>")
+		assert.Equal(t, []string{"launchctl load -w " + plistFile}, r.cmds)
+
+		status, err := l.Status(cfg.Name)
+		require.NoError(t, err)
+		assert.Equal(t, service.StatusRunning, status)
+
+		require.NoError(t, l.Uninstall(cfg.Name))
+		assert.NoFileExists(t, plistFile)
+		status, err = l.Status(cfg.Name)
+		require.NoError(t, err)
+		assert.Equal(t, service.StatusNotInstalled, status)
+	})
+
+	t.Run("happy_stopped", func(t *testing.T) {
+		r := &fakeRunner{failing: "launchctl list perun-node"}
+		l := &service.Launchd{AgentDir: tempDir(t), Run: r.run}
+		require.NoError(t, l.Install(cfg))
+
+		status, err := l.Status(cfg.Name)
+		require.NoError(t, err)
+		assert.Equal(t, service.StatusStopped, status)
+	})
+
+	t.Run("err_already_installed", func(t *testing.T) {
+		l := &service.Launchd{AgentDir: tempDir(t), Run: (&fakeRunner{}).run}
+		require.NoError(t, l.Install(cfg))
+		assert.Error(t, l.Install(cfg))
+	})
+
+	t.Run("err_not_installed", func(t *testing.T) {
+		l := &service.Launchd{AgentDir: tempDir(t), Run: (&fakeRunner{}).run}
+		assert.Error(t, l.Uninstall(cfg.Name))
+	})
+}
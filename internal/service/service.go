@@ -0,0 +1,92 @@
+// Copyright (c) 2020 - for information on the respective copyright owner
+// see the NOTICE file and/or the repository at
+// https://github.com/hyperledger-labs/perun-node
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"os/exec"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// DefaultName is the default name of the service.
+const DefaultName = "perun-node"
+
+// Status of an installed service.
+const (
+	StatusNotInstalled Status = "not installed"
+	StatusRunning      Status = "running"
+	StatusStopped      Status = "stopped"
+)
+
+// ErrUnsupported is returned by New when services are not supported on the platform.
+var ErrUnsupported = errors.New("services are not supported on this platform")
+
+type (
+	// Config is the configuration of a service.
+	Config struct {
+		Name        string
+		Description string
+		Executable  string   // Absolute path of the executable.
+		Args        []string // Arguments for the executable.
+		WorkingDir  string
+		User        string // User to run the service as. Only used by systemd, launchd agents run as current user.
+	}
+
+	// Status is the status of a service.
+	Status string
+
+	// Manager installs, uninstalls and queries the status of services.
+	Manager interface {
+		// Install installs the service, enables it to be started at boot (or login) and starts it.
+		Install(cfg Config) error
+		// Uninstall stops the service and removes it.
+		Uninstall(name string) error
+		// Status returns the status of the service.
+		Status(name string) (Status, error)
+	}
+
+	// Runner runs the command with the given arguments and returns its combined output.
+	Runner func(name string, args ...string) (string, error)
+)
+
+// execRunner runs the command using os/exec. The output is included in the error, if any.
+func execRunner(name string, args ...string) (string, error) {
+	output, err := exec.Command(name, args...).CombinedOutput()
+	if err != nil {
+		return string(output), errors.Wrapf(err, "running %s %s: %s", name, strings.Join(args, " "),
+			strings.TrimSpace(string(output)))
+	}
+	return string(output), nil
+}
+
+func runnerOrDefault(r Runner) Runner {
+	if r == nil {
+		return execRunner
+	}
+	return r
+}
+
+func validate(cfg Config) error {
+	if cfg.Name == "" || strings.ContainsAny(cfg.Name, `/\ `) {
+		return errors.Errorf("invalid service name %q", cfg.Name)
+	}
+	if cfg.Executable == "" {
+		return errors.New("executable should be set")
+	}
+	return nil
+}
@@ -0,0 +1,137 @@
+// Copyright (c) 2020 - for information on the respective copyright owner
+// see the NOTICE file and/or the repository at
+// https://github.com/hyperledger-labs/perun-node
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// DefaultUnitDir is the directory in which system wide systemd units are installed.
+const DefaultUnitDir = "/etc/systemd/system"
+
+// Systemd manages services as systemd units.
+type Systemd struct {
+	UnitDir string
+	Run     Runner // If nil, commands are run using os/exec.
+}
+
+// Install writes the unit file, reloads systemd and enables and starts the unit.
+//
+// The unit is of type "notify", as the node notifies systemd when it is ready. Config is reloaded by
+// "systemctl reload" and the node is restarted if it exits with an error.
+func (s *Systemd) Install(cfg Config) error {
+	if err := validate(cfg); err != nil {
+		return err
+	}
+	unitFile := s.unitFile(cfg.Name)
+	if _, err := os.Stat(unitFile); err == nil {
+		return errors.Errorf("service %s is already installed at %s", cfg.Name, unitFile)
+	}
+	if err := ioutil.WriteFile(unitFile, []byte(systemdUnit(cfg)), 0o644); err != nil {
+		return errors.Wrap(err, "writing unit file")
+	}
+	run := runnerOrDefault(s.Run)
+	if _, err := run("systemctl", "daemon-reload"); err != nil {
+		return err
+	}
+	_, err := run("systemctl", "enable", "--now", unitName(cfg.Name))
+	return err
+}
+
+// Uninstall disables and stops the unit, removes the unit file and reloads systemd.
+func (s *Systemd) Uninstall(name string) error {
+	unitFile := s.unitFile(name)
+	if _, err := os.Stat(unitFile); err != nil {
+		return errors.Wrapf(err, "service %s is not installed", name)
+	}
+	run := runnerOrDefault(s.Run)
+	if _, err := run("systemctl", "disable", "--now", unitName(name)); err != nil {
+		return err
+	}
+	if err := os.Remove(unitFile); err != nil {
+		return errors.Wrap(err, "removing unit file")
+	}
+	_, err := run("systemctl", "daemon-reload")
+	return err
+}
+
+// Status returns the status of the unit.
+func (s *Systemd) Status(name string) (Status, error) {
+	if _, err := os.Stat(s.unitFile(name)); os.IsNotExist(err) {
+		return StatusNotInstalled, nil
+	}
+	// is-active exits with a non zero code when the unit is not active, so the error is ignored when
+	// there is an output.
+	output, err := runnerOrDefault(s.Run)("systemctl", "is-active", unitName(name))
+	state := strings.TrimSpace(output)
+	switch {
+	case state == "active":
+		return StatusRunning, nil
+	case state != "":
+		return StatusStopped, nil
+	default:
+		return "", err
+	}
+}
+
+func (s *Systemd) unitFile(name string) string {
+	return filepath.Join(s.UnitDir, unitName(name))
+}
+
+func unitName(name string) string {
+	return name + ".service"
+}
+
+func systemdUnit(cfg Config) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "[Unit]\nDescription=%s\n", cfg.Description)
+	b.WriteString("Wants=network-online.target\nAfter=network-online.target\n\n")
+
+	b.WriteString("[Service]\nType=notify\n")
+	execStart := []string{systemdQuote(cfg.Executable)}
+	for _, arg := range cfg.Args {
+		execStart = append(execStart, systemdQuote(arg))
+	}
+	fmt.Fprintf(&b, "ExecStart=%s\n", strings.Join(execStart, " "))
+	b.WriteString("ExecReload=/bin/kill -HUP $MAINPID\n")
+	if cfg.WorkingDir != "" {
+		fmt.Fprintf(&b, "WorkingDirectory=%s\n", systemdQuote(cfg.WorkingDir))
+	}
+	if cfg.User != "" {
+		fmt.Fprintf(&b, "User=%s\n", cfg.User)
+	}
+	b.WriteString("Restart=on-failure\nRestartSec=5\n\n")
+
+	b.WriteString("[Install]\nWantedBy=multi-user.target\n")
+	return b.String()
+}
+
+// systemdQuote escapes the specifiers and variables in the string and quotes it,
+// if it contains spaces or quotes.
+func systemdQuote(s string) string {
+	s = strings.NewReplacer("%", "%%", "$", "$$").Replace(s)
+	if !strings.ContainsAny(s, " \t\"'\\") {
+		return s
+	}
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
@@ -0,0 +1,129 @@
+// Copyright (c) 2020 - for information on the respective copyright owner
+// see the NOTICE file and/or the repository at
+// https://github.com/hyperledger-labs/perun-node
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger-labs/perun-node/internal/service"
+)
+
+func Test_Systemd(t *testing.T) {
+	cfg := service.Config{
+		Name:        "perun-node",
+		Description: "Perun node",
+		Executable:  "/usr/local/bin/perunnode",
+		Args:        []string{"run", "-config", "/var/lib/perun node/node.yaml"},
+		WorkingDir:  "/var/lib/perun node",
+		User:        "perun",
+	}
+
+	t.Run("happy", func(t *testing.T) {
+		r := &fakeRunner{outputs: map[string]string{"systemctl is-active perun-node.service": "active\n"}}
+		s := &service.Systemd{UnitDir: tempDir(t), Run: r.run}
+		require.NoError(t, s.Install(cfg))
+
+		unit, err := ioutil.ReadFile(filepath.Join(s.UnitDir, "perun-node.service"))
+		require.NoError(t, err)
+		assert.Contains(t, string(unit), "Type=notify\n")
+		assert.Contains(t, string(unit),
+			`ExecStart=/usr/local/bin/perunnode run -config "/var/lib/perun node/node.yaml"`+"\n")
+		assert.Contains(t, string(unit), "ExecReload=/bin/kill -HUP $MAINPID\n")
+		assert.Contains(t, string(unit), "User=perun\n")
+		assert.Equal(t, []string{"systemctl daemon-reload", "systemctl enable --now perun-node.service"}, r.cmds)
+
+		status, err := s.Status(cfg.Name)
+		require.NoError(t, err)
+		assert.Equal(t, service.StatusRunning, status)
+
+		require.NoError(t, s.Uninstall(cfg.Name))
+		assert.NoFileExists(t, filepath.Join(s.UnitDir, "perun-node.service"))
+		status, err = s.Status(cfg.Name)
+		require.NoError(t, err)
+		assert.Equal(t, service.StatusNotInstalled, status)
+	})
+
+	t.Run("happy_stopped", func(t *testing.T) {
+		r := &fakeRunner{outputs: map[string]string{"systemctl is-active perun-node.service": "inactive\n"}}
+		s := &service.Systemd{UnitDir: tempDir(t), Run: r.run}
+		require.NoError(t, s.Install(cfg))
+
+		status, err := s.Status(cfg.Name)
+		require.NoError(t, err)
+		assert.Equal(t, service.StatusStopped, status)
+	})
+
+	t.Run("err_already_installed", func(t *testing.T) {
+		s := &service.Systemd{UnitDir: tempDir(t), Run: (&fakeRunner{}).run}
+		require.NoError(t, s.Install(cfg))
+		assert.Error(t, s.Install(cfg))
+	})
+
+	t.Run("err_invalid_name", func(t *testing.T) {
+		s := &service.Systemd{UnitDir: tempDir(t), Run: (&fakeRunner{}).run}
+		invalidCfg := cfg
+		invalidCfg.Name = "perun/node"
+		assert.Error(t, s.Install(invalidCfg))
+	})
+
+	t.Run("err_not_installed", func(t *testing.T) {
+		s := &service.Systemd{UnitDir: tempDir(t), Run: (&fakeRunner{}).run}
+		assert.Error(t, s.Uninstall(cfg.Name))
+	})
+
+	t.Run("err_systemctl", func(t *testing.T) {
+		r := &fakeRunner{failing: "systemctl enable --now perun-node.service"}
+		s := &service.Systemd{UnitDir: tempDir(t), Run: r.run}
+		assert.Error(t, s.Install(cfg))
+	})
+}
+
+// fakeRunner records the commands, returns the output configured for them and fails the command
+// matching failing.
+type fakeRunner struct {
+	cmds    []string
+	outputs map[string]string
+	failing string
+}
+
+func (r *fakeRunner) run(name string, args ...string) (string, error) {
+	cmd := strings.Join(append([]string{name}, args...), " ")
+	r.cmds = append(r.cmds, cmd)
+	if cmd == r.failing {
+		return "", errors.New("command failed")
+	}
+	return r.outputs[cmd], nil
+}
+
+func tempDir(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "")
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		if err = os.RemoveAll(dir); err != nil {
+			t.Log("Error in test cleanup: removing dir - " + dir)
+		}
+	})
+	return dir
+}
@@ -0,0 +1,25 @@
+// Copyright (c) 2020 - for information on the respective copyright owner
+// see the NOTICE file and/or the repository at
+// https://github.com/hyperledger-labs/perun-node
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package supervisor runs the background components of the node and restarts
+// them, according to their restart policy, when they stop.
+//
+// A component is a function that runs until its context is cancelled. If it
+// returns an error or panics before that, the failure is logged (along with
+// the stack trace for panics) and the component is restarted after a backoff
+// that doubles on each consecutive restart. So, a failure in one component
+// does not silently stop it nor affect the other components.
+package supervisor
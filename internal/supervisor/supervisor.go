@@ -0,0 +1,125 @@
+// Copyright (c) 2020 - for information on the respective copyright owner
+// see the NOTICE file and/or the repository at
+// https://github.com/hyperledger-labs/perun-node
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package supervisor
+
+import (
+	"context"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/hyperledger-labs/perun-node/log"
+)
+
+// Policy decides if a component is restarted when it stops before its context is cancelled.
+type Policy int
+
+// Restart policies for the components.
+const (
+	// Permanent components are always restarted.
+	Permanent Policy = iota
+	// Transient components are restarted only if they fail (return an error or panic).
+	Transient
+	// Temporary components are never restarted.
+	Temporary
+)
+
+// Default backoff durations for restarting the components.
+const (
+	DefaultMinBackoff = time.Second
+	DefaultMaxBackoff = time.Minute
+)
+
+// Supervisor runs components in go-routines and restarts them according to their policy.
+type Supervisor struct {
+	log.Logger
+
+	// MinBackoff is the duration to wait before the first restart of a component. It is doubled on each
+	// consecutive restart, up to MaxBackoff, and is reset once the component runs longer than MaxBackoff.
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+
+	wg sync.WaitGroup
+}
+
+// New returns a supervisor with default backoff durations.
+func New() *Supervisor {
+	return &Supervisor{
+		Logger:     log.NewLoggerWithField("component", "supervisor"),
+		MinBackoff: DefaultMinBackoff,
+		MaxBackoff: DefaultMaxBackoff,
+	}
+}
+
+// Go runs the component with the given name in a go-routine, until the context is cancelled.
+func (s *Supervisor) Go(ctx context.Context, name string, policy Policy, run func(context.Context) error) {
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		s.supervise(ctx, name, policy, run)
+	}()
+}
+
+// Wait waits until all the components have stopped. Components stop only after their context is cancelled
+// or, if they are not to be restarted, when they return.
+func (s *Supervisor) Wait() {
+	s.wg.Wait()
+}
+
+func (s *Supervisor) supervise(ctx context.Context, name string, policy Policy, run func(context.Context) error) {
+	backoff := s.MinBackoff
+	for {
+		start := time.Now()
+		err := runSafely(ctx, run)
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			s.Errorf("Component %s failed: %v", name, err)
+		} else {
+			s.Warnf("Component %s stopped", name)
+		}
+		if policy == Temporary || (policy == Transient && err == nil) {
+			return
+		}
+
+		if time.Since(start) > s.MaxBackoff {
+			backoff = s.MinBackoff
+		}
+		s.Infof("Restarting component %s in %v", name, backoff)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		if backoff *= 2; backoff > s.MaxBackoff {
+			backoff = s.MaxBackoff
+		}
+	}
+}
+
+// runSafely runs the function and returns the panic, if any, as an error.
+func runSafely(ctx context.Context, run func(context.Context) error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = errors.Errorf("panic: %v\n%s", r, debug.Stack())
+		}
+	}()
+	return run(ctx)
+}
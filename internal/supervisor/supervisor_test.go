@@ -0,0 +1,86 @@
+// Copyright (c) 2020 - for information on the respective copyright owner
+// see the NOTICE file and/or the repository at
+// https://github.com/hyperledger-labs/perun-node
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package supervisor_test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/hyperledger-labs/perun-node/internal/supervisor"
+)
+
+func Test_Supervisor(t *testing.T) {
+	newSupervisor := func() *supervisor.Supervisor {
+		s := supervisor.New()
+		s.MinBackoff, s.MaxBackoff = time.Millisecond, 4*time.Millisecond
+		return s
+	}
+	// runs returns a component that counts its runs and behaves as the given function on each of them.
+	runs := func(count *int32, behave func(run int32) error) func(context.Context) error {
+		return func(context.Context) error {
+			return behave(atomic.AddInt32(count, 1))
+		}
+	}
+
+	t.Run("happy_restart_on_panic_and_error", func(t *testing.T) {
+		s := newSupervisor()
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		var count int32
+		s.Go(ctx, "test", supervisor.Transient, runs(&count, func(run int32) error {
+			switch run {
+			case 1:
+				panic("test panic")
+			case 2:
+				return assert.AnError
+			}
+			<-ctx.Done()
+			return nil
+		}))
+		assert.Eventually(t, func() bool { return atomic.LoadInt32(&count) == 3 }, time.Second, time.Millisecond)
+		cancel()
+		s.Wait()
+		assert.EqualValues(t, 3, atomic.LoadInt32(&count))
+	})
+
+	t.Run("happy_permanent_restart_on_return", func(t *testing.T) {
+		s := newSupervisor()
+		ctx, cancel := context.WithCancel(context.Background())
+		var count int32
+		s.Go(ctx, "test", supervisor.Permanent, runs(&count, func(int32) error { return nil }))
+		assert.Eventually(t, func() bool { return atomic.LoadInt32(&count) > 3 }, time.Second, time.Millisecond)
+		cancel()
+		s.Wait()
+	})
+
+	t.Run("happy_no_restart", func(t *testing.T) {
+		for policy, behave := range map[supervisor.Policy]func(int32) error{
+			supervisor.Transient: func(int32) error { return nil },
+			supervisor.Temporary: func(int32) error { return assert.AnError },
+		} {
+			s := newSupervisor()
+			var count int32
+			s.Go(context.Background(), "test", policy, runs(&count, behave))
+			s.Wait()
+			assert.EqualValues(t, 1, atomic.LoadInt32(&count))
+		}
+	})
+}
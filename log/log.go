@@ -0,0 +1,136 @@
+// Copyright (c) 2020 - for information on the respective copyright owner
+// see the NOTICE file and/or the repository at
+// https://github.com/hyperledger-labs/perun-node
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package log
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	perunLog "perun.network/go-perun/log"
+	perunLogrus "perun.network/go-perun/log/logrus"
+)
+
+// Logger defines the methods for logging. It is same as the logger used in go-perun.
+type Logger = perunLog.Logger
+
+var (
+	logger *logrus.Logger
+
+	// Path and handle of the log file, if logs are written to a file. Guarded by the fileMutex.
+	fileMutex sync.Mutex
+	filePath  string
+	file      *os.File
+)
+
+func init() {
+	logger = logrus.New()
+	logger.SetFormatter(&logrus.TextFormatter{FullTimestamp: true})
+	perunLog.Set(perunLogrus.FromLogrus(logger))
+}
+
+// InitLogger sets the level and output of the logger. If the logFile is empty, logs are written to stdout.
+// Otherwise, they are appended to the file at the given path.
+func InitLogger(levelStr, logFile string) error {
+	if err := SetLevel(levelStr); err != nil {
+		return err
+	}
+
+	fileMutex.Lock()
+	defer fileMutex.Unlock()
+	filePath = logFile
+	return setOutput()
+}
+
+// ReopenFile closes and reopens the log file. It should be called after the log file has
+// been rotated by external tools, so that the subsequent logs are written to the new file.
+// It does nothing if logs are written to stdout.
+func ReopenFile() error {
+	fileMutex.Lock()
+	defer fileMutex.Unlock()
+	return setOutput()
+}
+
+// RotateFile renames the log file by appending the current time (as "20060102-150405") to its name
+// and reopens the log file, so that subsequent logs are written to a new file. The name of the rotated
+// file is returned. It does nothing if logs are written to stdout.
+func RotateFile() (string, error) {
+	fileMutex.Lock()
+	defer fileMutex.Unlock()
+	if filePath == "" {
+		return "", nil
+	}
+	rotatedPath := filePath + "." + time.Now().Format("20060102-150405")
+	if err := os.Rename(filePath, rotatedPath); err != nil {
+		return "", errors.Wrap(err, "renaming log file")
+	}
+	return rotatedPath, setOutput()
+}
+
+// setOutput opens the log file (if configured) and sets it as the output of the logger,
+// closing the previously opened file. It should be called with the fileMutex held.
+func setOutput() error {
+	var out io.Writer = os.Stdout
+	var f *os.File
+	if filePath != "" {
+		var err error
+		f, err = os.OpenFile(filepath.Clean(filePath), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+		if err != nil {
+			return errors.Wrap(err, "opening log file")
+		}
+		out = f
+	}
+	logger.SetOutput(out)
+
+	prevFile := file
+	file = f
+	if prevFile != nil {
+		return errors.Wrap(prevFile.Close(), "closing previous log file")
+	}
+	return nil
+}
+
+// SetLevel sets the log level. It is safe to call this method while the logger is in use,
+// hence it can used for changing the log level at runtime.
+func SetLevel(levelStr string) error {
+	level, err := logrus.ParseLevel(levelStr)
+	if err != nil {
+		return errors.Wrap(err, "parsing log level")
+	}
+	logger.SetLevel(level)
+	return nil
+}
+
+// Level returns the current log level.
+func Level() string {
+	return logger.GetLevel().String()
+}
+
+// NewLogger returns a logger that writes to the global logger.
+func NewLogger() Logger {
+	return perunLogrus.FromLogrus(logger)
+}
+
+// NewLoggerWithField returns a logger that writes to the global logger and
+// adds the given field to each log entry.
+func NewLoggerWithField(key string, value interface{}) Logger {
+	return NewLogger().WithField(key, value)
+}
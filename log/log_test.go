@@ -0,0 +1,132 @@
+// Copyright (c) 2020 - for information on the respective copyright owner
+// see the NOTICE file and/or the repository at
+// https://github.com/hyperledger-labs/perun-node
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package log_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger-labs/perun-node/log"
+)
+
+func Test_InitLogger(t *testing.T) {
+	t.Run("happy_stdout", func(t *testing.T) {
+		assert.NoError(t, log.InitLogger("info", ""))
+		assert.Equal(t, "info", log.Level())
+	})
+
+	t.Run("happy_file", func(t *testing.T) {
+		logDir, err := ioutil.TempDir("", "")
+		require.NoError(t, err)
+		t.Cleanup(func() {
+			if err = os.RemoveAll(logDir); err != nil {
+				t.Log("Error in test cleanup: removing dir - " + logDir)
+			}
+		})
+		logFile := filepath.Join(logDir, "node.log")
+
+		require.NoError(t, log.InitLogger("debug", logFile))
+		log.NewLoggerWithField("test", "logger").Debug("test log entry")
+		content, err := ioutil.ReadFile(logFile)
+		require.NoError(t, err)
+		assert.Contains(t, string(content), "test log entry")
+		require.NoError(t, log.InitLogger("info", ""))
+	})
+
+	t.Run("err_invalid_level", func(t *testing.T) {
+		assert.Error(t, log.InitLogger("invalid-level", ""))
+	})
+
+	t.Run("err_invalid_file", func(t *testing.T) {
+		assert.Error(t, log.InitLogger("info", filepath.Join("missing-dir", "node.log")))
+	})
+}
+
+func Test_ReopenFile(t *testing.T) {
+	logDir, err := ioutil.TempDir("", "")
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		if err = os.RemoveAll(logDir); err != nil {
+			t.Log("Error in test cleanup: removing dir - " + logDir)
+		}
+	})
+	logFile := filepath.Join(logDir, "node.log")
+	rotatedLogFile := filepath.Join(logDir, "node.log.1")
+
+	require.NoError(t, log.InitLogger("info", logFile))
+	log.NewLogger().Info("before rotation")
+	require.NoError(t, os.Rename(logFile, rotatedLogFile))
+	require.NoError(t, log.ReopenFile())
+	log.NewLogger().Info("after rotation")
+
+	content, err := ioutil.ReadFile(rotatedLogFile)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "before rotation")
+	assert.NotContains(t, string(content), "after rotation")
+	content, err = ioutil.ReadFile(logFile)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "after rotation")
+	require.NoError(t, log.InitLogger("info", ""))
+}
+
+func Test_RotateFile(t *testing.T) {
+	t.Run("happy", func(t *testing.T) {
+		logDir, err := ioutil.TempDir("", "")
+		require.NoError(t, err)
+		t.Cleanup(func() {
+			if err = os.RemoveAll(logDir); err != nil {
+				t.Log("Error in test cleanup: removing dir - " + logDir)
+			}
+		})
+		logFile := filepath.Join(logDir, "node.log")
+
+		require.NoError(t, log.InitLogger("info", logFile))
+		log.NewLogger().Info("before rotation")
+		rotatedLogFile, err := log.RotateFile()
+		require.NoError(t, err)
+		log.NewLogger().Info("after rotation")
+
+		content, err := ioutil.ReadFile(rotatedLogFile)
+		require.NoError(t, err)
+		assert.Contains(t, string(content), "before rotation")
+		content, err = ioutil.ReadFile(logFile)
+		require.NoError(t, err)
+		assert.Contains(t, string(content), "after rotation")
+		require.NoError(t, log.InitLogger("info", ""))
+	})
+
+	t.Run("happy_stdout", func(t *testing.T) {
+		rotatedLogFile, err := log.RotateFile()
+		require.NoError(t, err)
+		assert.Empty(t, rotatedLogFile)
+	})
+}
+
+func Test_SetLevel(t *testing.T) {
+	require.NoError(t, log.SetLevel("error"))
+	assert.Equal(t, "error", log.Level())
+	require.NoError(t, log.SetLevel("info"))
+	assert.Equal(t, "info", log.Level())
+
+	assert.Error(t, log.SetLevel("invalid-level"))
+	assert.Equal(t, "info", log.Level())
+}
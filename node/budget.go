@@ -0,0 +1,52 @@
+// Copyright (c) 2020 - for information on the respective copyright owner
+// see the NOTICE file and/or the repository at
+// https://github.com/hyperledger-labs/perun-node
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package node
+
+import (
+	"math/big"
+
+	"github.com/pkg/errors"
+	"perun.network/go-perun/pkg/sortedkv"
+
+	"github.com/hyperledger-labs/perun-node"
+	"github.com/hyperledger-labs/perun-node/clock"
+	"github.com/hyperledger-labs/perun-node/payment"
+)
+
+// newBudget returns a budget enforcing the spending limits. Peers in the limits are resolved using the contacts and
+// the spendings are persisted in the database.
+func newBudget(limits []SpendingLimit, contacts perun.ContactsReader, clk clock.Clock,
+	db sortedkv.Database) (*payment.Budget, error) {
+	paymentLimits := make([]payment.Limit, len(limits))
+	for i, l := range limits {
+		amount, ok := new(big.Int).SetString(l.Amount, 10)
+		if !ok {
+			return nil, errors.Errorf("invalid amount %q", l.Amount)
+		}
+		peer := l.Peer
+		if p, found := contacts.ReadByAlias(l.Peer); found {
+			peer = p.OffChainAddr.String()
+		}
+		paymentLimits[i] = payment.Limit{
+			Peer:            peer,
+			Period:          payment.Period(l.Period),
+			Amount:          amount,
+			RequireApproval: l.RequireApproval,
+		}
+	}
+	return payment.NewBudget(clk, paymentLimits, db)
+}
@@ -0,0 +1,63 @@
+// Copyright (c) 2020 - for information on the respective copyright owner
+// see the NOTICE file and/or the repository at
+// https://github.com/hyperledger-labs/perun-node
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package node
+
+import (
+	"github.com/pkg/errors"
+
+	"github.com/hyperledger-labs/perun-node"
+	"github.com/hyperledger-labs/perun-node/blockchain/ethereum"
+)
+
+// Check verifies the consistency of the node data with the given configuration, without starting the node.
+//
+// It checks that the user accounts can be unlocked using the keystores and passwords (or the signer) in the config, the
+// blockchain node is reachable and valid contracts are deployed at the configured addresses.
+func Check(cfg Config) error {
+	walletBackend := ethereum.NewWalletBackend()
+	_, remoteSigner, err := newUser(cfg, walletBackend)
+	if err != nil {
+		return errors.WithMessage(err, "initializing user")
+	}
+	if remoteSigner != nil {
+		remoteSigner.Close() // nolint: errcheck, gosec  // the connection was only used for checking.
+	}
+
+	onChainAddr, err := walletBackend.ParseAddr(cfg.User.OnChainAddr)
+	if err != nil {
+		return errors.WithMessage(err, "parsing on-chain address")
+	}
+	adjudicator, err := walletBackend.ParseAddr(cfg.Adjudicator)
+	if err != nil {
+		return errors.WithMessage(err, "parsing adjudicator address")
+	}
+	asset, err := walletBackend.ParseAddr(cfg.Asset)
+	if err != nil {
+		return errors.WithMessage(err, "parsing asset address")
+	}
+
+	cred := perun.Credential{
+		Addr:     onChainAddr,
+		Keystore: cfg.User.OnChainWallet.KeystorePath,
+		Password: cfg.User.OnChainWallet.Password,
+	}
+	chain, err := ethereum.NewChainBackend(cfg.ChainURL, cfg.ChainConnTimeout, cred)
+	if err != nil {
+		return errors.WithMessage(err, "connecting to blockchain")
+	}
+	return errors.WithMessage(chain.ValidateContracts(adjudicator, asset), "validating contracts")
+}
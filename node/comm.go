@@ -0,0 +1,129 @@
+// Copyright (c) 2020 - for information on the respective copyright owner
+// see the NOTICE file and/or the repository at
+// https://github.com/hyperledger-labs/perun-node
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package node
+
+import (
+	"net/url"
+
+	"github.com/pkg/errors"
+	"perun.network/go-perun/wallet"
+
+	"github.com/hyperledger-labs/perun-node"
+	"github.com/hyperledger-labs/perun-node/comm"
+	"github.com/hyperledger-labs/perun-node/comm/tcp"
+	_ "github.com/hyperledger-labs/perun-node/comm/unix" // Registers the unix backend.
+)
+
+// newCommBackends returns the tcp backend for off-chain communication, with the listener limits, proxies
+// and TLS (if enabled) set as per the config, along with the backend to be used by the client for the
+// comm type of the user. The tcp backend is used for "tcp", which is also the default. Backends for other
+// comm types are taken from the comm registry.
+func newCommBackends(cfg Config, walletBackend perun.WalletBackend,
+	commType string) (tcp.Backend, perun.CommBackend, error) {
+	tcpComm, err := newTCPBackend(cfg, walletBackend)
+	if err != nil {
+		return tcp.Backend{}, nil, err
+	}
+	if commType == "" || commType == "tcp" {
+		return tcpComm, tcpComm, nil
+	}
+	clientComm, err := comm.New(commType, comm.Config{DialerTimeout: cfg.CommDialerTimeout})
+	if err != nil {
+		return tcp.Backend{}, nil, err
+	}
+	return tcpComm, clientComm, nil
+}
+
+func newTCPBackend(cfg Config, walletBackend perun.WalletBackend) (tcp.Backend, error) {
+	proxies, err := newProxies(cfg, walletBackend)
+	if err != nil {
+		return tcp.Backend{}, err
+	}
+	if err = checkPeerRules(cfg); err != nil {
+		return tcp.Backend{}, err
+	}
+	access, err := tcp.NewAccessList(cfg.CommAccess, walletBackend.ParseAddr)
+	if err != nil {
+		return tcp.Backend{}, errors.WithMessage(err, "access rules")
+	}
+	tcpComm := tcp.NewTCPBackend(cfg.CommDialerTimeout).WithListenerLimits(tcp.ListenerLimits{
+		MaxConnsPerIP:    cfg.CommMaxConnsPerIP,
+		MaxPendingConns:  cfg.CommMaxPendingConns,
+		MaxConns:         cfg.CommMaxConns,
+		MaxConnRatePerIP: cfg.CommMaxConnRatePerIP,
+		BlockWhenFull:    cfg.CommBlockWhenFull,
+	}).WithProxies(proxies).WithKeepAlive(cfg.CommKeepAlive).WithAccessList(access).
+		WithDNSCacheTTL(cfg.CommDNSCacheTTL)
+	if cfg.CommTLSCertFile == "" && cfg.CommTLSKeyFile == "" {
+		return tcpComm, nil
+	}
+	tlsConfig, err := tcp.NewTLSConfig(cfg.CommTLSCertFile, cfg.CommTLSKeyFile, cfg.CommTLSCAFile,
+		cfg.CommTLSRequireClientCert)
+	if err != nil {
+		return tcp.Backend{}, err
+	}
+	return tcpComm.WithTLS(tlsConfig), nil
+}
+
+// newProxies parses the proxies for dialing the peers from the config.
+func newProxies(cfg Config, walletBackend perun.WalletBackend) (tcp.Proxies, error) {
+	var proxies tcp.Proxies
+	var err error
+	if cfg.CommProxy != "" {
+		if proxies.Default, err = tcp.ParseProxyURL(cfg.CommProxy); err != nil {
+			return tcp.Proxies{}, err
+		}
+	}
+	if len(cfg.CommPeerProxies) == 0 {
+		return proxies, nil
+	}
+	proxies.Peers = make(map[wallet.AddrKey]*url.URL, len(cfg.CommPeerProxies))
+	for peer, rawURL := range cfg.CommPeerProxies {
+		addr, err := walletBackend.ParseAddr(peer)
+		if err != nil {
+			return tcp.Proxies{}, errors.WithMessage(err, "parsing peer address for proxy")
+		}
+		var proxyURL *url.URL
+		if rawURL != "" {
+			if proxyURL, err = tcp.ParseProxyURL(rawURL); err != nil {
+				return tcp.Proxies{}, errors.WithMessage(err, peer)
+			}
+		}
+		proxies.Peers[wallet.Key(addr)] = proxyURL
+	}
+	return proxies, nil
+}
+
+// ReloadAccess is a reload handler that updates the access rules for incoming off-chain connections.
+func (n *Node) ReloadAccess(_, current Config) error {
+	if err := checkPeerRules(current); err != nil {
+		return err
+	}
+	return n.comm.AccessList().SetRules(current.CommAccess)
+}
+
+// checkPeerRules returns an error if there are peer rules in the access rules, but the client certificates
+// of the peers are not verified. Such rules cannot be enforced, see tcp.AccessRules.
+func checkPeerRules(cfg Config) error {
+	if len(cfg.CommAccess.AllowPeers) == 0 && len(cfg.CommAccess.DenyPeers) == 0 {
+		return nil
+	}
+	if cfg.CommTLSCertFile == "" || cfg.CommTLSCAFile == "" {
+		return errors.New("peer rules in access rules require TLS with a CA file for verifying client certificates")
+	}
+	return nil
+}
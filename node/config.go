@@ -0,0 +1,213 @@
+// Copyright (c) 2020 - for information on the respective copyright owner
+// see the NOTICE file and/or the repository at
+// https://github.com/hyperledger-labs/perun-node
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package node
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+
+	"github.com/hyperledger-labs/perun-node/comm/tcp"
+	"github.com/hyperledger-labs/perun-node/session"
+)
+
+// Config represents the configuration parameters for the node.
+type Config struct {
+	// Network for which the preset values are used for the parameters that are not set.
+	// See ApplyPreset for details.
+	Network string `yaml:"network"`
+
+	LogLevel string `yaml:"loglevel"`
+	LogFile  string `yaml:"logfile"`
+
+	// Parameters for connecting to the blockchain and addresses of on-chain contracts
+	// used for establishing state channel network.
+	ChainURL         string        `yaml:"chainurl"`
+	Adjudicator      string        `yaml:"adjudicator"`
+	Asset            string        `yaml:"asset"`
+	ChainConnTimeout time.Duration `yaml:"chainconntimeout"`
+
+	// Path to directory containing persistence database.
+	DatabaseDir string `yaml:"databasedir"`
+	// Timeout for re-establishing all open channels (if any) that was persisted during the
+	// previous running instance of the node.
+	PeerReconnTimeout time.Duration `yaml:"peerreconntimeout"`
+
+	// Timeout used when dialing for new outgoing off-chain connections.
+	CommDialerTimeout time.Duration `yaml:"commdialertimeout"`
+	// Limits on the incoming off-chain connections, for a node reachable from the internet. Zero value
+	// means there is no limit. See tcp.ListenerLimits for details.
+	CommMaxConnsPerIP    int  `yaml:"commmaxconnsperip"`
+	CommMaxPendingConns  int  `yaml:"commmaxpendingconns"`
+	CommMaxConns         int  `yaml:"commmaxconns"`
+	CommMaxConnRatePerIP int  `yaml:"commmaxconnrateperip"`
+	CommBlockWhenFull    bool `yaml:"commblockwhenfull"`
+	// Rules for accepting incoming off-chain connections based on the IP address and the off-chain address
+	// of the peer. It can be reloaded and also updated at runtime via the admin API. Peer rules apply to the
+	// address in the verified TLS client certificate and hence require TLS with a CA file, see tcp.AccessRules.
+	CommAccess tcp.AccessRules `yaml:"commaccess"`
+	// Period between keep-alive probes on the off-chain connections, for detecting dead connections.
+	// Zero means the default (15s) and negative disables keep-alive probes.
+	CommKeepAlive time.Duration `yaml:"commkeepalive"`
+	// Duration for caching the comm addresses of peers resolved from DNS records (srv:// or txt://
+	// addresses, see tcp.SRVScheme). Zero disables caching. Such addresses cannot be used for peers dialed via a
+	// proxy.
+	CommDNSCacheTTL time.Duration `yaml:"commdnscachettl"`
+	// Certificate and key files (PEM encoded) for securing the off-chain connections with TLS, empty to
+	// disable it. If a CA file is given, certificates of the peers are verified using it instead of the
+	// system roots. If RequireClientCert is true, peers dialing the node must present a certificate
+	// signed by the CA.
+	CommTLSCertFile          string `yaml:"commtlscertfile"`
+	CommTLSKeyFile           string `yaml:"commtlskeyfile"`
+	CommTLSCAFile            string `yaml:"commtlscafile"`
+	CommTLSRequireClientCert bool   `yaml:"commtlsrequireclientcert"`
+	// Proxy (socks5, socks5h or http URL) for dialing the peers, empty to dial directly. Proxies for
+	// specific peers can be set in CommPeerProxies, keyed by their off-chain address; an empty value
+	// means the peer is dialed directly.
+	CommProxy       string            `yaml:"commproxy"`
+	CommPeerProxies map[string]string `yaml:"commpeerproxies"`
+	// Path to the yaml file containing the contacts of the user.
+	ContactsFile string `yaml:"contactsfile"`
+
+	// Max time to wait for in-progress updates to complete, when shutting down the node. Defaults to 30s.
+	ShutdownTimeout time.Duration `yaml:"shutdowntimeout"`
+
+	// URL for fetching the signed contract manifest, public key (hex encoded ed25519) for verifying it
+	// and the file where it is staged. When a manifest is staged, the contract addresses in it are used
+	// instead of adjudicator and asset.
+	ManifestURL    string `yaml:"manifesturl"`
+	ManifestPubKey string `yaml:"manifestpubkey"`
+	ManifestFile   string `yaml:"manifestfile"`
+
+	// Experimental features to be enabled. See package features for the list of known features.
+	Features []string `yaml:"features"`
+
+	// Address for serving the admin API, empty to disable it. It must be a loopback address,
+	// as the API does not implement authentication.
+	AdminAddr string `yaml:"adminaddr"`
+
+	// Timezone used by the clock of the node, empty for local timezone.
+	Timezone string `yaml:"timezone"`
+	// NTP server for detecting the skew of local clock, empty to disable the detection.
+	NTPServer string `yaml:"ntpserver"`
+	// Maximum offset of local clock, beyond which a warning is logged. Defaults to 10s.
+	MaxClockSkew time.Duration `yaml:"maxclockskew"`
+	// If true, channel proposals are refused while the clock skew exceeds the maximum.
+	RefuseOnClockSkew bool `yaml:"refuseonclockskew"`
+
+	// Minimum free space (in MB) on the disk holding the database, below which new channels and updates are
+	// refused. Zero disables the monitoring.
+	MinFreeDiskSpace uint64 `yaml:"minfreediskspace"`
+
+	// If true, the node announces itself on the local network via mDNS and discovers the other nodes doing
+	// so, which are listed at /peers/discovered in the admin API. Meant for demos and test labs.
+	MDNSDiscovery bool `yaml:"mdnsdiscovery"`
+
+	// Limits on resources used by the node. Zero value means there is no limit.
+	MaxOpenChannels     int `yaml:"maxopenchannels"`
+	MaxPeers            int `yaml:"maxpeers"`
+	MaxPendingProposals int `yaml:"maxpendingproposals"`
+
+	// If true, the protocol invariants (such as conservation of balances and increase of version by one) are
+	// checked on each new state of the channels, independent of go-perun. Channels violating them are halted and
+	// reported at /violations in the admin API.
+	CheckInvariants bool `yaml:"checkinvariants"`
+
+	// Channels not updated for ChannelIdleTimeout are closed by the close-idle-channels job, with a warning logged
+	// by the job from ChannelIdleWarning before that. Zero timeout means idle channels are not closed. The policy
+	// can be overridden for each channel via the admin API.
+	ChannelIdleTimeout time.Duration `yaml:"channelidletimeout"`
+	ChannelIdleWarning time.Duration `yaml:"channelidlewarning"`
+	// Default closing mode of the channels: "manual" (default) rejects the requests from peers to close channels
+	// cooperatively, "auto" accepts them and settles the channels right after. It can be reloaded and overridden
+	// for each channel via the admin API.
+	ClosingMode string `yaml:"closingmode"`
+
+	// Maintenance jobs to be run periodically, mapping the name of each job to its schedule (as a cron
+	// expression). See package scheduler for the syntax of schedules and Job* constants for the known jobs.
+	Jobs map[string]string `yaml:"jobs"`
+
+	// Limits on the amount spent on outgoing payments made by the node: subscriptions, streams, paid invoices and
+	// refunds. The spendings are persisted in the database, so they are counted across restarts.
+	SpendingLimits []SpendingLimit `yaml:"spendinglimits"`
+
+	// Oracle for converting the amounts of invoices in fiat currencies to the asset. It is disabled if the URL
+	// is empty.
+	FiatOracle FiatOracleConfig `yaml:"fiatoracle"`
+
+	// Unix socket of the signer holding the off-chain keys and the file containing the token for
+	// authenticating with it. If set, the off-chain wallet in the user config is not used.
+	SignerSocket    string `yaml:"signersocket"`
+	SignerTokenFile string `yaml:"signertokenfile"`
+
+	User session.UserConfig `yaml:"user"`
+}
+
+// SpendingLimit is a limit on the amount spent on outgoing payments in each period ("daily" or "monthly").
+// Peer is the alias or off-chain address of the peer, empty for a node-wide limit. Amount is a decimal string in
+// the smallest unit of the asset. If RequireApproval is true, payments exceeding the limit wait for approval
+// using the admin API instead of being rejected. Limits cannot be scoped to a label, as payments carry none.
+type SpendingLimit struct {
+	Peer            string `yaml:"peer"`
+	Period          string `yaml:"period"`
+	Amount          string `yaml:"amount"`
+	RequireApproval bool   `yaml:"requireapproval"`
+}
+
+// FiatOracleConfig is the configuration of the oracle for prices of the asset in fiat currencies.
+// See payment.HTTPOracle for the API of the oracle and payment.Converter for the other parameters.
+type FiatOracleConfig struct {
+	URL       string  `yaml:"url"`
+	Decimals  int     `yaml:"decimals"`
+	Tolerance float64 `yaml:"tolerance"`
+}
+
+// ParseConfig parses the node configuration from the given yaml file and then applies
+// the overrides set in the environment variables. See package documentation for details on
+// how the names of environment variables are derived from the keys in the file. Then, if a network
+// is selected, the parameters that are still not set are set to the values in the network preset.
+// Finally, the defaults are applied.
+//
+// Unknown keys in the config file are treated as error. The file can be empty, in which case all
+// the values should be set using environment variables.
+func ParseConfig(configFile string) (Config, error) {
+	f, err := os.Open(filepath.Clean(configFile))
+	if err != nil {
+		return Config{}, errors.Wrap(err, "opening config file")
+	}
+	defer f.Close() // nolint: errcheck, gosec  // safe to defer f.Close() for files opened in read mode.
+
+	cfg := Config{}
+	decoder := yaml.NewDecoder(f)
+	decoder.KnownFields(true)
+	if err = decoder.Decode(&cfg); err != nil && err != io.EOF {
+		return Config{}, errors.Wrap(err, "decoding config file")
+	}
+
+	if err = ApplyEnv(EnvPrefix, &cfg); err != nil {
+		return Config{}, errors.WithMessage(err, "applying overrides from environment")
+	}
+	if err = ApplyPreset(&cfg); err != nil {
+		return Config{}, errors.WithMessage(err, "applying network preset")
+	}
+	ApplyDefaults(&cfg)
+	return cfg, nil
+}
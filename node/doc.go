@@ -0,0 +1,43 @@
+// Copyright (c) 2020 - for information on the respective copyright owner
+// see the NOTICE file and/or the repository at
+// https://github.com/hyperledger-labs/perun-node
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package node implements the components for configuring and running an
+// instance of perun-node.
+//
+// The node is configured using a yaml file. Value of each key in the file
+// can be overridden by setting the corresponding environment variable. The
+// name of the environment variable is formed by joining the prefix "PERUN"
+// and the keys on the path to the value (from the top level key) with an
+// underscore and converting it to upper case. For example, the password of
+// on-chain wallet of the user can be set using
+// "PERUN_USER_ONCHAINWALLET_PASSWORD". Lists are set as comma separated
+// values, maps as comma separated key=value pairs (or a JSON object) and
+// lists of structs as JSON arrays, such as
+// PERUN_SPENDINGLIMITS='[{"period": "daily", "amount": "100"}]'. See ApplyEnv
+// for details.
+//
+// For values that are secrets (such as passwords), it is also possible to
+// specify the path to a file containing the value by appending "_FILE" to
+// the name of the variable. For example:
+// "PERUN_USER_ONCHAINWALLET_PASSWORD_FILE=/run/secrets/onchain-password".
+// This enables the use of secret mounting mechanisms provided by container
+// orchestration tools.
+//
+// A set of default values for a network can be selected using the
+// "network" key. Supported values are "mainnet", "testnet" and "dev".
+// Parameters set in the file or environment take precedence over the
+// preset values.
+package node
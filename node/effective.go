@@ -0,0 +1,122 @@
+// Copyright (c) 2020 - for information on the respective copyright owner
+// see the NOTICE file and/or the repository at
+// https://github.com/hyperledger-labs/perun-node
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package node
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+
+	"github.com/hyperledger-labs/perun-node/log"
+)
+
+// Masked is the value that replaces secrets in the config, when it is shared (e.g. for support).
+// Secrets that are not set remain empty.
+const Masked = "***"
+
+// EffectiveConfig is the configuration in effect on a running node. Config holds the parameters with
+// defaults, presets, environment overrides and reloads applied and secrets masked. Runtime holds the
+// state that can be changed on the running node.
+type EffectiveConfig struct {
+	Config  Config        `yaml:"config"`
+	Runtime RuntimeConfig `yaml:"runtime"`
+}
+
+// RuntimeConfig is the state that can be changed on a running node, via the admin API or reloads.
+type RuntimeConfig struct {
+	LogLevel    string          `yaml:"loglevel"`
+	Maintenance bool            `yaml:"maintenance"`
+	Features    map[string]bool `yaml:"features"`
+}
+
+// MaskSecrets returns a copy of the config, with the secrets replaced by Masked. Credentials in the user info
+// of URLs (chain, fiat oracle and proxies) are masked too.
+func MaskSecrets(cfg Config) Config {
+	for _, secret := range []*string{&cfg.User.OnChainWallet.Password, &cfg.User.OffChainWallet.Password} {
+		if *secret != "" {
+			*secret = Masked
+		}
+	}
+	cfg.ChainURL = maskURL(cfg.ChainURL)
+	cfg.FiatOracle.URL = maskURL(cfg.FiatOracle.URL)
+	cfg.CommProxy = maskURL(cfg.CommProxy)
+	if cfg.CommPeerProxies != nil {
+		proxies := make(map[string]string, len(cfg.CommPeerProxies))
+		for peer, proxy := range cfg.CommPeerProxies {
+			proxies[peer] = maskURL(proxy)
+		}
+		cfg.CommPeerProxies = proxies
+	}
+	return cfg
+}
+
+// maskURL returns the URL with the user info (if any) replaced by Masked. If the URL cannot be parsed, it is
+// masked entirely, as it is not known which part of it is the user info.
+func maskURL(rawURL string) string {
+	if rawURL == "" {
+		return ""
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return Masked
+	}
+	if u.User == nil {
+		return rawURL
+	}
+	// Inserted as is, as url.User would escape the asterisks.
+	u.User = nil
+	return strings.Replace(u.String(), "//", "//"+Masked+"@", 1)
+}
+
+// EffectiveConfig returns the configuration in effect on the node.
+func (n *Node) EffectiveConfig() EffectiveConfig {
+	n.configMutex.Lock()
+	cfg := n.config
+	n.configMutex.Unlock()
+
+	return EffectiveConfig{
+		Config: MaskSecrets(cfg),
+		Runtime: RuntimeConfig{
+			LogLevel:    log.Level(),
+			Maintenance: n.Client.InMaintenance(),
+			Features:    n.Features.All(),
+		},
+	}
+}
+
+// ReloadConfig is a reload handler that updates the config returned by EffectiveConfig.
+func (n *Node) ReloadConfig(_, current Config) error {
+	n.configMutex.Lock()
+	defer n.configMutex.Unlock()
+	// Contract addresses are not reloadable, retain those in use (which may be from a staged manifest).
+	current.Adjudicator, current.Asset = n.config.Adjudicator, n.config.Asset
+	n.config = current
+	return nil
+}
+
+// effectiveConfigDump returns the effective config as a generic value with the same keys as in the config
+// file, so that it can be encoded as JSON for the admin API.
+func (n *Node) effectiveConfigDump() (interface{}, error) {
+	data, err := yaml.Marshal(n.EffectiveConfig())
+	if err != nil {
+		return nil, errors.Wrap(err, "encoding config")
+	}
+	var dump map[string]interface{}
+	return dump, errors.Wrap(yaml.Unmarshal(data, &dump), "decoding config")
+}
@@ -0,0 +1,225 @@
+// Copyright (c) 2020 - for information on the respective copyright owner
+// see the NOTICE file and/or the repository at
+// https://github.com/hyperledger-labs/perun-node
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package node
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// EnvPrefix is the prefix for the names of all environment variables used for configuring the node.
+	EnvPrefix = "PERUN"
+
+	// EnvFileSuffix is the suffix for the names of environment variables that hold the path to a file,
+	// the contents of which should be used as the value.
+	EnvFileSuffix = "_FILE"
+)
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// ApplyEnv overrides the fields in the struct pointed to by cfg with values from the environment variables.
+//
+// Name of the environment variable for each field is formed by joining the prefix and the yaml keys of the
+// field (and that of each enclosing struct) with an underscore, in upper case. If the environment variable
+// suffixed with "_FILE" is set instead, the contents of the file at that path (with trailing newlines
+// removed) is used as the value. It is an error to set both the variables for the same field.
+//
+// Supported field types are string, bool, integers, floats, time.Duration, slice of strings (comma separated
+// values), maps with string keys (comma separated key=value pairs, or a JSON object if the values may contain
+// commas), slices of other types (as JSON arrays, using the yaml keys of struct fields) and structs composed
+// of these.
+func ApplyEnv(prefix string, cfg interface{}) error {
+	v := reflect.ValueOf(cfg)
+	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Struct {
+		return errors.New("config should be a pointer to struct")
+	}
+	return applyEnvToStruct(prefix, v.Elem())
+}
+
+func applyEnvToStruct(prefix string, v reflect.Value) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		key := envKey(field)
+		if key == "" || field.PkgPath != "" { // Skip ignored and unexported fields.
+			continue
+		}
+		name := prefix + "_" + key
+
+		if field.Type.Kind() == reflect.Struct {
+			if err := applyEnvToStruct(name, v.Field(i)); err != nil {
+				return err
+			}
+			continue
+		}
+
+		value, isSet, err := lookupEnv(name)
+		if err != nil {
+			return err
+		}
+		if !isSet {
+			continue
+		}
+		if err = setValue(v.Field(i), value); err != nil {
+			return errors.WithMessage(err, "parsing value of "+name)
+		}
+	}
+	return nil
+}
+
+// envKey returns the key for the field, as used in the name of the environment variable.
+// Key is derived from yaml tag of the field and falls back to the field name if there is no tag.
+// Empty string is returned if the field is to be ignored.
+func envKey(field reflect.StructField) string {
+	key := strings.Split(field.Tag.Get("yaml"), ",")[0]
+	if key == "-" {
+		return ""
+	}
+	if key == "" {
+		key = field.Name
+	}
+	return strings.ToUpper(key)
+}
+
+// lookupEnv returns the value for the environment variable, either set directly
+// or using a file (via the variable with "_FILE" suffix).
+func lookupEnv(name string) (value string, isSet bool, _ error) {
+	value, isSet = os.LookupEnv(name)
+	filePath, isFileSet := os.LookupEnv(name + EnvFileSuffix)
+	if !isFileSet {
+		return value, isSet, nil
+	}
+	if isSet {
+		return "", false, errors.Errorf("both %s and %s are set, use only one", name, name+EnvFileSuffix)
+	}
+
+	content, err := ioutil.ReadFile(filepath.Clean(filePath))
+	if err != nil {
+		return "", false, errors.Wrap(err, "reading value of "+name+" from file")
+	}
+	return strings.TrimRight(string(content), "\r\n"), true, nil
+}
+
+func setValue(v reflect.Value, value string) error {
+	if v.Type() == durationType {
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(d))
+		return nil
+	}
+
+	switch v.Kind() { // nolint: exhaustive // Other types are not supported.
+	case reflect.String:
+		v.SetString(value)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(value, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(value, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(value, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+	case reflect.Slice:
+		if v.Type().Elem().Kind() != reflect.String {
+			return decodeJSON(v, value)
+		}
+		v.Set(reflect.ValueOf(splitList(value)).Convert(v.Type()))
+	case reflect.Map:
+		return setMap(v, value)
+	default:
+		return errors.New("unsupported type " + v.Type().String())
+	}
+	return nil
+}
+
+// splitList splits the comma separated values and trims the spaces around each of them.
+func splitList(value string) []string {
+	if value == "" {
+		return []string{}
+	}
+	values := strings.Split(value, ",")
+	for i := range values {
+		values[i] = strings.TrimSpace(values[i])
+	}
+	return values
+}
+
+// setMap sets the map from comma separated key=value pairs or, if the value starts with "{", from a JSON object.
+// Values in the pairs are parsed like those of the other fields.
+func setMap(v reflect.Value, value string) error {
+	if v.Type().Key().Kind() != reflect.String {
+		return errors.New("unsupported type " + v.Type().String())
+	}
+	if strings.HasPrefix(strings.TrimSpace(value), "{") {
+		return decodeJSON(v, value)
+	}
+	m := reflect.MakeMap(v.Type())
+	for _, pair := range splitList(value) {
+		kv := strings.SplitN(pair, "=", 2)
+		if len(kv) != 2 {
+			return errors.Errorf("invalid key=value pair %q", pair)
+		}
+		elem := reflect.New(v.Type().Elem()).Elem()
+		if err := setValue(elem, strings.TrimSpace(kv[1])); err != nil {
+			return errors.WithMessage(err, "parsing value for key "+kv[0])
+		}
+		m.SetMapIndex(reflect.ValueOf(strings.TrimSpace(kv[0])).Convert(v.Type().Key()), elem)
+	}
+	v.Set(m)
+	return nil
+}
+
+// decodeJSON sets the value from its JSON encoding. As JSON is a subset of yaml, it is decoded like the config
+// file, so that the keys of struct fields are the same as in the file.
+func decodeJSON(v reflect.Value, value string) error {
+	decoded := reflect.New(v.Type())
+	decoder := yaml.NewDecoder(strings.NewReader(value))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(decoded.Interface()); err != nil && err != io.EOF {
+		return errors.Wrap(err, "decoding json")
+	}
+	v.Set(decoded.Elem())
+	return nil
+}
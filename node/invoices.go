@@ -0,0 +1,164 @@
+// Copyright (c) 2020 - for information on the respective copyright owner
+// see the NOTICE file and/or the repository at
+// https://github.com/hyperledger-labs/perun-node
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package node
+
+import (
+	"context"
+	"math/big"
+	"net/http"
+	"time"
+
+	"perun.network/go-perun/channel"
+
+	"github.com/hyperledger-labs/perun-node"
+	"github.com/hyperledger-labs/perun-node/log"
+	"github.com/hyperledger-labs/perun-node/payment"
+)
+
+// oracleTimeout is the timeout for fetching the prices from the fiat oracle when settling invoices.
+const oracleTimeout = 10 * time.Second
+
+// newConverter returns the converter for invoices in fiat, if the oracle is configured.
+func newConverter(cfg FiatOracleConfig) *payment.Converter {
+	if cfg.URL == "" {
+		return nil
+	}
+	return &payment.Converter{
+		Oracle:    payment.HTTPOracle{URL: cfg.URL, Client: &http.Client{Timeout: oracleTimeout}},
+		Decimals:  cfg.Decimals,
+		Tolerance: cfg.Tolerance,
+	}
+}
+
+// CreateInvoice generates an invoice for receiving the amount in the asset configured for the node.
+func (n *Node) CreateInvoice(amount *big.Int, expiry time.Duration, memo string) (payment.InvoiceRecord, error) {
+	return n.invoices.Create(amount, n.asset(), expiry, memo)
+}
+
+// CreateFiatInvoice generates an invoice for receiving the fiat amount in the asset configured for the node.
+// It requires the fiat oracle to be configured.
+func (n *Node) CreateFiatInvoice(fiat payment.FiatAmount, expiry time.Duration, memo string) (
+	payment.InvoiceRecord, error) {
+	return n.invoices.CreateFiat(fiat, n.asset(), expiry, memo)
+}
+
+func (n *Node) asset() string {
+	n.configMutex.Lock()
+	defer n.configMutex.Unlock()
+	return n.config.Asset
+}
+
+// PayInvoice pays the encoded invoice over the open channel with the ID and returns the decoded invoice. The
+// payment is recorded, so that the receipt from the payee can be stored with it. Invoices in fiat can be paid only
+// if the fiat oracle is configured.
+func (n *Node) PayInvoice(ctx context.Context, id channel.ID, encoded string) (payment.Invoice, error) {
+	inv, err := payment.DecodeInvoice(encoded)
+	if err != nil {
+		return payment.Invoice{}, err
+	}
+	ch, err := n.paymentChannel(id)
+	if err != nil {
+		return payment.Invoice{}, err
+	}
+	paid, err := payment.PayInvoice(ctx, ch, inv, n.converter, n.Budget)
+	if err != nil {
+		return payment.Invoice{}, err
+	}
+	n.payments.Add(inv, ch, paid)
+	return inv, nil
+}
+
+// ListPayments returns the invoices paid by the node, along with the receipts sent by the payees.
+func (n *Node) ListPayments() []payment.PaidInvoice {
+	return n.payments.List()
+}
+
+// GetPayment returns the latest payment of the invoice with the ID, made by the node.
+func (n *Node) GetPayment(id payment.InvoiceID) (payment.PaidInvoice, bool) {
+	return n.payments.Get(id)
+}
+
+// AddReceipt verifies the receipt for an invoice paid by the node and stores it with the payment. It is used for
+// receipts obtained from the payee out of band, the ones sent by the payee are stored automatically.
+func (n *Node) AddReceipt(receipt payment.Receipt) error {
+	return n.payments.SetReceipt(receipt)
+}
+
+// ListInvoices returns the invoices generated by the node.
+func (n *Node) ListInvoices() []payment.InvoiceRecord {
+	return n.invoices.List()
+}
+
+// GetInvoice returns the invoice with the ID, generated by the node.
+func (n *Node) GetInvoice(id payment.InvoiceID) (payment.InvoiceRecord, bool) {
+	return n.invoices.Get(id)
+}
+
+// RefundInvoice pays back the amount of the settled invoice with the ID to the payer.
+func (n *Node) RefundInvoice(ctx context.Context, id payment.InvoiceID, reason string) (payment.InvoiceRecord, error) {
+	return n.invoices.Refund(ctx, id, n.paymentChannel, n.Budget, reason)
+}
+
+// ListReceipts returns the receipts for the payments of invoices generated by the node.
+func (n *Node) ListReceipts() []payment.Receipt {
+	return n.invoices.Receipts()
+}
+
+// settleInvoice returns a payment handler that settles the invoice (if any) paid by the received payment and
+// stores a receipt for it, signed using the off-chain account of the user. The receipt is returned to be sent to
+// the payer.
+func settleInvoice(invoices *payment.Invoices, offChain perun.Credential, logger log.Logger) func(
+	payment.Received) *payment.Receipt {
+	return func(rcv payment.Received) *payment.Receipt {
+		ctx, cancel := context.WithTimeout(context.Background(), oracleTimeout)
+		defer cancel()
+		record, ok := invoices.Settle(ctx, rcv)
+		if !ok {
+			logger.Infof("Received payment of %v on channel %x, no matching invoice", rcv.Amount, rcv.Channel)
+			return nil
+		}
+		logger.Infof("Received payment of %v on channel %x, settled invoice %x", rcv.Amount, rcv.Channel,
+			record.ID)
+
+		acc, err := offChain.Wallet.Unlock(offChain.Addr)
+		if err != nil {
+			logger.Error("Unlocking account for signing receipt: ", err)
+			return nil
+		}
+		receipt, err := payment.NewReceipt(acc, record.ID, rcv)
+		if err == nil {
+			err = invoices.SetReceipt(receipt)
+		}
+		if err != nil {
+			logger.Error("Issuing receipt: ", err)
+			return nil
+		}
+		return &receipt
+	}
+}
+
+// storeReceipt returns a receipt handler that verifies the receipts sent by the payees and stores them with the
+// paid invoices.
+func storeReceipt(payments *payment.Payments, logger log.Logger) func(payment.Receipt) {
+	return func(receipt payment.Receipt) {
+		if err := payments.SetReceipt(receipt); err != nil {
+			logger.Errorf("Rejecting receipt for invoice %x: %v", receipt.InvoiceID, err)
+			return
+		}
+		logger.Infof("Received receipt for invoice %x", receipt.InvoiceID)
+	}
+}
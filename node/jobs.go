@@ -0,0 +1,129 @@
+// Copyright (c) 2020 - for information on the respective copyright owner
+// see the NOTICE file and/or the repository at
+// https://github.com/hyperledger-labs/perun-node
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package node
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/hyperledger-labs/perun-node/clock"
+	"github.com/hyperledger-labs/perun-node/log"
+	"github.com/hyperledger-labs/perun-node/scheduler"
+)
+
+// Names of the maintenance jobs that can be scheduled using the "jobs" key in the config.
+const (
+	// JobRotateLogs rotates the log file. See log.RotateFile for details.
+	JobRotateLogs = "rotate-logs"
+	// JobCheckPeers checks if the peers with open channels are reachable at the addresses in the contacts.
+	JobCheckPeers = "check-peers"
+	// JobCloseIdleChannels closes the channels that are idle as per their idle policy. See Client.CloseIdle.
+	JobCloseIdleChannels = "close-idle-channels"
+)
+
+// newScheduler returns a scheduler with the configured jobs (mapping the name of each job to its schedule).
+// It returns nil if no jobs are configured.
+func (n *Node) newScheduler(jobs map[string]string, clk clock.Clock) (*scheduler.Scheduler, error) {
+	if len(jobs) == 0 {
+		return nil, nil
+	}
+	known := map[string]scheduler.Job{
+		JobRotateLogs:        n.rotateLogs,
+		JobCheckPeers:        n.checkPeers,
+		JobCloseIdleChannels: n.closeIdleChannels,
+	}
+	s := scheduler.New(clk)
+	for name, spec := range jobs {
+		job, ok := known[name]
+		if !ok {
+			return nil, errors.Errorf("unknown job %s, known jobs are: %s", name, strings.Join(jobNames(known), ", "))
+		}
+		if err := s.Add(name, spec, job); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+func jobNames(jobs map[string]scheduler.Job) []string {
+	names := make([]string, 0, len(jobs))
+	for name := range jobs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (n *Node) rotateLogs(context.Context) error {
+	rotatedFile, err := log.RotateFile()
+	if err != nil {
+		return err
+	}
+	if rotatedFile != "" {
+		n.Info("Rotated log file to ", rotatedFile)
+	}
+	return nil
+}
+
+// checkPeers dials each of the peers with open channels and logs a warning for those that are not reachable.
+// Peers that are not in the contacts or use a comm type other than tcp are skipped.
+func (n *Node) checkPeers(ctx context.Context) error {
+	var unreachable int
+	peers := n.Client.Peers()
+	for _, addr := range peers {
+		if ctx.Err() != nil {
+			return errors.Wrap(ctx.Err(), "checking peers")
+		}
+		peer, ok := n.Contacts.ReadByOffChainAddr(addr)
+		if !ok {
+			n.Warn("No contact for peer ", addr, ", cannot check liveness")
+			continue
+		}
+		if peer.CommType != "tcp" {
+			continue
+		}
+		if err := n.comm.CheckReachable(peer.OffChainAddr, peer.CommAddr); err != nil {
+			n.Warnf("Peer %s (%s) is not reachable at %s: %v", peer.Alias, addr, peer.CommAddr, err)
+			unreachable++
+		}
+	}
+	if unreachable > 0 {
+		return errors.Errorf("%d of %d peers are not reachable", unreachable, len(peers))
+	}
+	return nil
+}
+
+// closeIdleChannels closes the idle channels, CloseWorkers channels at a time, and logs the result for each.
+func (n *Node) closeIdleChannels(ctx context.Context) error {
+	results := n.Client.CloseIdle(ctx, CloseWorkers)
+	var failed int
+	for id, err := range results {
+		if err != nil {
+			n.Errorf("Closing idle channel %x: %v", id, err)
+			failed++
+			continue
+		}
+		n.Infof("Closed idle channel %x", id)
+	}
+	if failed > 0 {
+		return errors.Errorf("%d of %d idle channels could not be closed", failed, len(results))
+	}
+	return nil
+}
@@ -0,0 +1,362 @@
+// Copyright (c) 2020 - for information on the respective copyright owner
+// see the NOTICE file and/or the repository at
+// https://github.com/hyperledger-labs/perun-node
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package node
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"perun.network/go-perun/channel"
+
+	"github.com/hyperledger-labs/perun-node"
+	"github.com/hyperledger-labs/perun-node/admin"
+	"github.com/hyperledger-labs/perun-node/blockchain/ethereum"
+	"github.com/hyperledger-labs/perun-node/client"
+	"github.com/hyperledger-labs/perun-node/clock"
+	"github.com/hyperledger-labs/perun-node/comm/tcp"
+	"github.com/hyperledger-labs/perun-node/contacts/contactsyaml"
+	"github.com/hyperledger-labs/perun-node/contracts"
+	"github.com/hyperledger-labs/perun-node/discovery"
+	"github.com/hyperledger-labs/perun-node/disk"
+	"github.com/hyperledger-labs/perun-node/features"
+	"github.com/hyperledger-labs/perun-node/log"
+	"github.com/hyperledger-labs/perun-node/payment"
+	"github.com/hyperledger-labs/perun-node/scheduler"
+	"github.com/hyperledger-labs/perun-node/session"
+	"github.com/hyperledger-labs/perun-node/signer"
+)
+
+// Node is a running instance of perun-node. It runs a state channel client for the configured user,
+// along with the contacts of the user.
+type Node struct {
+	log.Logger
+
+	Client   *client.Client
+	Contacts perun.Contacts
+	Clock    clock.Clock
+
+	// SkewMonitor checks the skew of local clock. It is nil if no NTP server is configured.
+	SkewMonitor *clock.SkewMonitor
+	// DiskMonitor checks the free space on the disk holding the database. It is nil if the monitoring is disabled.
+	DiskMonitor *disk.Monitor
+	// Discovery announces the node and discovers other nodes on the local network. It is nil if mDNS
+	// discovery is disabled.
+	Discovery *discovery.Service
+	// Admin serves the admin API. It is nil if the admin API is disabled.
+	Admin *admin.Server
+	// Features holds the feature flags, which can be overridden using the admin API.
+	Features *features.Flags
+
+	// Scheduler runs the configured maintenance jobs. It is nil if no jobs are configured.
+	Scheduler *scheduler.Scheduler
+	// Subscriptions executes the recurring payments, which can be managed using the admin API.
+	Subscriptions *payment.Subscriptions
+	// Streams executes the streams of payments, which can be managed using the admin API.
+	Streams *payment.Streams
+	// Budget enforces the spending limits on the outgoing payments: subscriptions, streams, invoices and refunds.
+	Budget *payment.Budget
+
+	invoices  *payment.Invoices
+	payments  *payment.Payments  // Invoices paid by the node.
+	converter *payment.Converter // Converter for invoices in fiat, nil if the fiat oracle is not configured.
+	signer    *signer.Wallet     // Connection to the signer, nil if off-chain keys are held by the node.
+	comm      tcp.Backend
+
+	configMutex sync.Mutex
+	config      Config
+}
+
+const (
+	// ClockCheckInterval is the interval at which the skew monitor should check the clock.
+	ClockCheckInterval = 15 * time.Minute
+	// DiskCheckInterval is the interval at which the disk monitor should check the free space.
+	DiskCheckInterval = time.Minute
+	// DiscoveryInterval is the interval at which the node should announce itself, if mDNS discovery is enabled.
+	DiscoveryInterval = 30 * time.Second
+	// SubscriptionCheckInterval is the interval at which the subscriptions should be checked for due payments.
+	SubscriptionCheckInterval = time.Second
+	// ConfigWatchInterval is the interval at which the reloader should check the config file for changes.
+	ConfigWatchInterval = 5 * time.Second
+	// CloseWorkers is the maximum number of channels closed at the same time, when closing all channels.
+	CloseWorkers = 16
+)
+
+// New initializes the logger, unlocks the user accounts, loads the contacts and starts the
+// state channel client using the given configuration.
+func New(cfg Config) (*Node, error) {
+	ApplyDefaults(&cfg)
+	if err := log.InitLogger(cfg.LogLevel, cfg.LogFile); err != nil {
+		return nil, errors.WithMessage(err, "initializing logger")
+	}
+
+	if err := applyStagedManifest(&cfg); err != nil {
+		return nil, err
+	}
+	clk, err := clock.New(cfg.Timezone)
+	if err != nil {
+		return nil, errors.WithMessage(err, "initializing clock")
+	}
+	skewMonitor, diskMonitor := newSkewMonitor(cfg, clk), newDiskMonitor(cfg)
+	featureFlags, err := features.New(cfg.Features)
+	if err != nil {
+		return nil, errors.WithMessage(err, "initializing feature flags")
+	}
+
+	walletBackend := ethereum.NewWalletBackend()
+	user, remoteSigner, err := newUser(cfg, walletBackend)
+	if err != nil {
+		return nil, errors.WithMessage(err, "initializing user")
+	}
+	contacts, err := contactsyaml.New(cfg.ContactsFile, walletBackend)
+	if err != nil {
+		return nil, errors.WithMessage(err, "loading contacts")
+	}
+
+	converter := newConverter(cfg.FiatOracle)
+	invoices, payments := payment.NewInvoices(clk, converter), payment.NewPayments(clk)
+	comm, clientComm, err := newCommBackends(cfg, walletBackend, user.CommType)
+	if err != nil {
+		return nil, errors.WithMessage(err, "initializing off-chain communication")
+	}
+	c, err := client.NewEthereumPaymentClient(
+		newClientConfig(cfg, clk, skewMonitor, diskMonitor, invoices, payments, user.OffChain), user, clientComm)
+	if err != nil {
+		return nil, errors.WithMessage(err, "initializing state channel client")
+	}
+
+	n := &Node{
+		Logger:      log.NewLoggerWithField("component", "node"),
+		Client:      c,
+		Contacts:    contacts,
+		Clock:       clk,
+		SkewMonitor: skewMonitor,
+		DiskMonitor: diskMonitor,
+		Features:    featureFlags,
+		invoices:    invoices,
+		payments:    payments,
+		converter:   converter,
+		signer:      remoteSigner,
+		comm:        comm,
+		config:      cfg,
+	}
+	if err = n.initServices(cfg); err != nil {
+		n.Client.Close() // nolint: errcheck  // error in initializing services is returned.
+		return nil, err
+	}
+	return n, nil
+}
+
+// initServices initializes the subscriptions and streams with the spending limits, the scheduler for the
+// configured jobs, the mDNS discovery (if enabled) and starts the admin API (if enabled).
+func (n *Node) initServices(cfg Config) (err error) {
+	if cfg.MDNSDiscovery {
+		if n.Discovery, err = discovery.NewService(cfg.User.OffChainAddr, cfg.User.CommAddr,
+			ethereum.NewWalletBackend()); err != nil {
+			return errors.WithMessage(err, "initializing mdns discovery")
+		}
+	}
+	if n.Budget, err = newBudget(cfg.SpendingLimits, n.Contacts, n.Clock, n.Client.Database()); err != nil {
+		return errors.WithMessage(err, "initializing spending limits")
+	}
+	n.Subscriptions = payment.NewSubscriptions(n.Clock, n.paymentChannel, n.Budget, n.reportSubscriptionFailure)
+	n.Streams = payment.NewStreams(n.paymentChannel, n.Budget, n.reportStreamFailure)
+	if n.Scheduler, err = n.newScheduler(cfg.Jobs, n.Clock); err != nil {
+		return err
+	}
+	if cfg.AdminAddr == "" {
+		return nil
+	}
+	return n.startAdmin(cfg.AdminAddr)
+}
+
+// newUser initializes the user. If a signer is configured, the off-chain wallet of the user is the
+// connection to the signer, which is also returned.
+func newUser(cfg Config, wb perun.WalletBackend) (perun.User, *signer.Wallet, error) {
+	if cfg.SignerSocket == "" {
+		user, err := session.NewUnlockedUser(wb, cfg.User)
+		return user, nil, err
+	}
+	token, err := signer.ReadTokenFile(cfg.SignerTokenFile)
+	if err != nil {
+		return perun.User{}, nil, err
+	}
+	remoteSigner, err := signer.Dial(cfg.SignerSocket, token)
+	if err != nil {
+		return perun.User{}, nil, err
+	}
+	user, err := session.NewUnlockedUserWithOffChainWallet(wb, cfg.User, remoteSigner)
+	if err != nil {
+		remoteSigner.Close() // nolint: errcheck  // error in initializing user is returned.
+		return perun.User{}, nil, err
+	}
+	return user, remoteSigner, nil
+}
+
+// newClientConfig returns the configuration for the state channel client from the node configuration. Received
+// payments settle the invoices and the receipts sent by the payees are stored with the paid invoices.
+func newClientConfig(cfg Config, clk clock.Clock, skewMonitor *clock.SkewMonitor, diskMonitor *disk.Monitor,
+	invoices *payment.Invoices, payments *payment.Payments, offChain perun.Credential) client.Config {
+	invoicesLog := log.NewLoggerWithField("component", "invoices")
+	clientCfg := client.Config{
+		Chain: client.ChainConfig{
+			Adjudicator: cfg.Adjudicator,
+			Asset:       cfg.Asset,
+			URL:         cfg.ChainURL,
+			ConnTimeout: cfg.ChainConnTimeout,
+		},
+		DatabaseDir:       cfg.DatabaseDir,
+		PeerReconnTimeout: cfg.PeerReconnTimeout,
+		Limits: client.Limits{
+			MaxOpenChannels:     cfg.MaxOpenChannels,
+			MaxPeers:            cfg.MaxPeers,
+			MaxPendingProposals: cfg.MaxPendingProposals,
+		},
+		OnPayment:       settleInvoice(invoices, offChain, invoicesLog),
+		OnReceipt:       storeReceipt(payments, invoicesLog),
+		CheckInvariants: cfg.CheckInvariants,
+		IdlePolicy:      client.IdlePolicy{Timeout: cfg.ChannelIdleTimeout, WarnBefore: cfg.ChannelIdleWarning},
+		ClosingMode:     client.ClosingMode(cfg.ClosingMode),
+		Now:             clk.Now,
+	}
+	if cfg.RefuseOnClockSkew && skewMonitor != nil {
+		clientCfg.TimeCheck = skewMonitor.Err
+	}
+	if diskMonitor != nil {
+		clientCfg.DiskCheck = diskMonitor.Err
+	}
+	return clientCfg
+}
+
+// startAdmin registers the handlers for the admin API and starts serving it at the given address.
+func (n *Node) startAdmin(addr string) error {
+	n.Admin = admin.NewServer()
+	n.Admin.Handle("/maintenance", admin.MaintenanceHandler(n.Client))
+	n.Admin.Handle("/features", admin.FeaturesHandler(n.Features))
+	n.Admin.Handle("/config", admin.ConfigHandler(n.effectiveConfigDump))
+	n.Admin.Handle("/invoices", admin.InvoicesHandler(n))
+	n.Admin.Handle("/receipts", admin.ReceiptsHandler(n.ListReceipts))
+	n.Admin.Handle("/refunds", admin.RefundsHandler(n.RefundInvoice))
+	n.Admin.Handle("/violations", admin.StatsHandler(func() interface{} { return n.Client.Violations() }))
+	n.Admin.Handle("/watchers", admin.StatsHandler(func() interface{} { return n.Client.Watchers() }))
+	n.Admin.Handle("/listener", admin.StatsHandler(func() interface{} { return n.comm.ListenerStats() }))
+	n.Admin.Handle("/access", admin.AccessHandler(n.comm.AccessList()))
+	n.Admin.Handle("/peers/stats", admin.StatsHandler(func() interface{} { return n.comm.PeerStats() }))
+	n.Admin.Handle("/peers/discovered", admin.StatsHandler(func() interface{} { return n.DiscoveredPeers() }))
+	n.Admin.Handle("/channels/close", admin.CloseAllHandler(n.CloseAllChannels))
+	n.Admin.Handle("/channels/idle", admin.IdleHandler(n.Client))
+	n.Admin.Handle("/channels/closingmode", admin.ClosingModesHandler(n.Client))
+	n.Admin.Handle("/invoices/pay", admin.PayInvoiceHandler(n.PayInvoice))
+	n.Admin.Handle("/payments", admin.PaymentsHandler(n))
+	n.Admin.Handle("/subscriptions", admin.SubscriptionsHandler(n.Subscriptions))
+	n.Admin.Handle("/streams", admin.StreamsHandler(n.Streams))
+	n.Admin.Handle("/approvals", admin.ApprovalsHandler(n.Budget))
+	return n.Admin.Start(addr)
+}
+
+// applyStagedManifest sets the contract addresses in the config to those in the staged manifest, if any.
+func applyStagedManifest(cfg *Config) error {
+	if cfg.ManifestFile == "" {
+		return nil
+	}
+	key, err := contracts.ParsePublicKey(cfg.ManifestPubKey)
+	if err != nil {
+		return errors.WithMessage(err, "parsing manifest public key")
+	}
+	m, isStaged, err := contracts.LoadStaged(cfg.ManifestFile, key)
+	if err != nil || !isStaged {
+		return err
+	}
+	log.NewLoggerWithField("component", "node").Infof("Using contracts from staged manifest version %d", m.Version)
+	cfg.Adjudicator, cfg.Asset = m.Adjudicator, m.Asset
+	return nil
+}
+
+// ReloadClosingMode is a reload handler that updates the default closing mode of the channels.
+func (n *Node) ReloadClosingMode(_, current Config) error {
+	return n.Client.SetDefaultClosingMode(client.ClosingMode(current.ClosingMode))
+}
+
+// ReloadFeatures is a reload handler that updates the configured feature flags.
+func (n *Node) ReloadFeatures(_, current Config) error {
+	return n.Features.Configure(current.Features)
+}
+
+func newSkewMonitor(cfg Config, clk clock.Clock) *clock.SkewMonitor {
+	if cfg.NTPServer == "" {
+		return nil
+	}
+	return clock.NewSkewMonitor(clk, cfg.NTPServer, cfg.MaxClockSkew)
+}
+
+func newDiskMonitor(cfg Config) *disk.Monitor {
+	if cfg.MinFreeDiskSpace == 0 {
+		return nil
+	}
+	return disk.NewMonitor(cfg.DatabaseDir, cfg.MinFreeDiskSpace*disk.MB)
+}
+
+// DiscoveredPeers returns the peers discovered on the local network. It is empty if mDNS discovery is disabled.
+func (n *Node) DiscoveredPeers() []discovery.Peer {
+	if n.Discovery == nil {
+		return []discovery.Peer{}
+	}
+	return n.Discovery.DiscoveredPeers()
+}
+
+// CloseAllChannels closes all the open channels of the node, CloseWorkers channels at a time, and
+// returns the error (nil if successful) for each channel.
+func (n *Node) CloseAllChannels(ctx context.Context) map[channel.ID]error {
+	n.Infof("Closing all channels")
+	results := n.Client.CloseAll(ctx, CloseWorkers)
+	for id, err := range results {
+		if err != nil {
+			n.Errorf("Closing channel %x: %v", id, err)
+		}
+	}
+	return results
+}
+
+// Shutdown gracefully shuts down the node.
+//
+// It stops accepting new channels, waits for the in-progress updates to complete until the
+// context expires and then closes the state channel client, which also shuts down the listener
+// for off-chain communication. Finally, the contacts are written to the storage. Admin API (if enabled)
+// is shut down first.
+//
+// The shutdown proceeds to completion even if any of the steps fail and the first
+// error (if any) is returned.
+func (n *Node) Shutdown(ctx context.Context) error {
+	n.Info("Shutting down node")
+	var err error
+	if n.Admin != nil {
+		err = n.Admin.Shutdown(ctx)
+	}
+	if clientErr := n.Client.Shutdown(ctx); clientErr != nil && err == nil {
+		err = errors.WithMessage(clientErr, "shutting down state channel client")
+	}
+	if contactsErr := n.Contacts.UpdateStorage(); contactsErr != nil && err == nil {
+		err = errors.WithMessage(contactsErr, "writing contacts to storage")
+	}
+	if n.signer != nil {
+		if signerErr := n.signer.Close(); signerErr != nil && err == nil {
+			err = signerErr
+		}
+	}
+	return err
+}
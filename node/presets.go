@@ -0,0 +1,109 @@
+// Copyright (c) 2020 - for information on the respective copyright owner
+// see the NOTICE file and/or the repository at
+// https://github.com/hyperledger-labs/perun-node
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package node
+
+import (
+	"reflect"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Names of the networks for which presets are defined.
+const (
+	NetworkMainnet = "mainnet"
+	NetworkTestnet = "testnet"
+	NetworkDev     = "dev"
+)
+
+// presets holds the default values of config parameters for each network.
+//
+// Dev preset is for a local ganache-cli node started with the account used in integration tests
+// (0x8450c0055cB180C7C37A25866132A740b812937B), where the contracts are deployed by the first two
+// transactions of the account and hence have deterministic addresses.
+//
+// Public networks have longer timeouts. As there are no canonical deployments of the contracts and
+// the URL depends on the provider, chain URL and contract addresses should be set explicitly for them.
+var presets = map[string]Config{
+	NetworkDev: {
+		LogLevel:          "debug",
+		ChainURL:          "ws://127.0.0.1:8545",
+		Adjudicator:       "0x9daEdAcb21dce86Af8604Ba1A1D7F9BFE55ddd63",
+		Asset:             "0x5992089d61cE79B6CF90506F70DD42B8E42FB21d",
+		ChainConnTimeout:  10 * time.Second,
+		PeerReconnTimeout: 20 * time.Second,
+		CommDialerTimeout: 10 * time.Second,
+		ShutdownTimeout:   10 * time.Second,
+	},
+	NetworkTestnet: {
+		LogLevel:          "info",
+		ChainConnTimeout:  30 * time.Second,
+		PeerReconnTimeout: time.Minute,
+		CommDialerTimeout: 15 * time.Second,
+		ShutdownTimeout:   30 * time.Second,
+	},
+	NetworkMainnet: {
+		LogLevel:          "warn",
+		ChainConnTimeout:  30 * time.Second,
+		PeerReconnTimeout: time.Minute,
+		CommDialerTimeout: 15 * time.Second,
+		ShutdownTimeout:   time.Minute,
+	},
+}
+
+// defaults holds the default values of config parameters that are used irrespective of the network.
+var defaults = Config{
+	ShutdownTimeout: 30 * time.Second,
+	MaxClockSkew:    10 * time.Second,
+}
+
+// ApplyPreset sets the parameters that are not set in the config to the values in the preset
+// for the network (if any) selected in the config. Values set explicitly take precedence over the preset.
+//
+// For public networks, an error is returned if the chain URL or the contract addresses are not set.
+func ApplyPreset(cfg *Config) error {
+	if cfg.Network == "" {
+		return nil
+	}
+	preset, ok := presets[cfg.Network]
+	if !ok {
+		return errors.Errorf("unknown network %q, should be one of %s, %s or %s",
+			cfg.Network, NetworkMainnet, NetworkTestnet, NetworkDev)
+	}
+
+	setZeroFields(cfg, preset)
+	if cfg.ChainURL == "" || cfg.Adjudicator == "" || cfg.Asset == "" {
+		return errors.Errorf("chainurl, adjudicator and asset should be set for network %s", cfg.Network)
+	}
+	return nil
+}
+
+// ApplyDefaults sets the parameters that are not set in the config (after applying the preset) to
+// their default values.
+func ApplyDefaults(cfg *Config) {
+	setZeroFields(cfg, defaults)
+}
+
+// setZeroFields sets the fields that are zero in the config to the corresponding values in the source.
+func setZeroFields(cfg *Config, src Config) {
+	v, s := reflect.ValueOf(cfg).Elem(), reflect.ValueOf(src)
+	for i := 0; i < v.NumField(); i++ {
+		if v.Field(i).IsZero() && !s.Field(i).IsZero() {
+			v.Field(i).Set(s.Field(i))
+		}
+	}
+}
@@ -0,0 +1,168 @@
+// Copyright (c) 2020 - for information on the respective copyright owner
+// see the NOTICE file and/or the repository at
+// https://github.com/hyperledger-labs/perun-node
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package node
+
+import (
+	"context"
+	"os"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/hyperledger-labs/perun-node/log"
+)
+
+// ReloadHandler is called when the configuration is reloaded, with the previous and the new configuration.
+// It should apply the changes in reloadable parameters to the running components.
+type ReloadHandler func(prev, current Config) error
+
+// Reloader reloads the configuration of a running node from the config file.
+//
+// Only a selected set of parameters can be changed at runtime (see reloadable). If any of the
+// other parameters were changed in the file, the reload is rejected and the running configuration
+// is retained. The changes to those parameters take effect only after a restart.
+//
+// A reload can be triggered explicitly (for example, on a SIGHUP) or by watching the config file for changes.
+type Reloader struct {
+	configFile string
+
+	mutex    sync.Mutex
+	current  Config
+	modTime  time.Time
+	handlers []ReloadHandler
+}
+
+// NewReloader returns a reloader for the config file with the given config as the running configuration.
+// A handler for updating the log level is registered by default.
+func NewReloader(configFile string, current Config) *Reloader {
+	r := &Reloader{
+		configFile: configFile,
+		current:    current,
+		modTime:    modTime(configFile),
+	}
+	r.OnReload(reloadLogLevel)
+	return r
+}
+
+// OnReload registers a handler that will be called on each successful reload.
+func (r *Reloader) OnReload(h ReloadHandler) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.handlers = append(r.handlers, h)
+}
+
+// Config returns the current running configuration.
+func (r *Reloader) Config() Config {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.current
+}
+
+// Reload parses the config file and applies the changes in reloadable parameters by invoking the
+// registered handlers. If any of the handlers return an error, the remaining handlers are not invoked and
+// the handlers that were already invoked are invoked again in reverse order with the previous and the new
+// configuration swapped, so that the running components are rolled back to the running configuration.
+func (r *Reloader) Reload() error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.modTime = modTime(r.configFile)
+	cfg, err := ParseConfig(r.configFile)
+	if err != nil {
+		return err
+	}
+	if !reflect.DeepEqual(reloadable(r.current, cfg), cfg) {
+		return errors.New("parameters other than the reloadable ones have changed, restart the node to apply them")
+	}
+
+	for i, h := range r.handlers {
+		if err = h(r.current, cfg); err != nil {
+			return r.rollback(r.handlers[:i], cfg, errors.WithMessage(err, "applying changes"))
+		}
+	}
+	r.current = cfg
+	return nil
+}
+
+// rollback reverts the changes applied by the given handlers and returns the error that caused the rollback.
+// If reverting fails, the remaining handlers are still invoked and the error is included in the returned error,
+// as the running components may then be inconsistent with the running configuration.
+func (r *Reloader) rollback(applied []ReloadHandler, cfg Config, cause error) error {
+	for i := len(applied) - 1; i >= 0; i-- {
+		if err := applied[i](cfg, r.current); err != nil {
+			cause = errors.WithMessagef(cause, "rolling back: %v", err)
+		}
+	}
+	return cause
+}
+
+// Watch polls the config file for changes at the given interval and reloads it on each change.
+// Errors in reloading are logged. It returns when the context is cancelled.
+func (r *Reloader) Watch(ctx context.Context, interval time.Duration) {
+	logger := log.NewLoggerWithField("component", "config-reloader")
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if !r.isModified() {
+				continue
+			}
+			if err := r.Reload(); err != nil {
+				logger.Error("Reloading config: ", err)
+				continue
+			}
+			logger.Info("Reloaded config from file ", r.configFile)
+		}
+	}
+}
+
+func (r *Reloader) isModified() bool {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return !modTime(r.configFile).Equal(r.modTime)
+}
+
+// reloadable returns a copy of the current config, with the values of reloadable parameters
+// taken from the new config.
+func reloadable(current, newCfg Config) Config {
+	current.LogLevel = newCfg.LogLevel
+	current.Features = newCfg.Features
+	current.CommAccess = newCfg.CommAccess
+	current.ClosingMode = newCfg.ClosingMode
+	return current
+}
+
+func reloadLogLevel(prev, current Config) error {
+	if prev.LogLevel == current.LogLevel {
+		return nil
+	}
+	return log.SetLevel(current.LogLevel)
+}
+
+// modTime returns the modification time of the file or zero value, if the file cannot be accessed.
+func modTime(file string) time.Time {
+	info, err := os.Stat(file)
+	if err != nil {
+		return time.Time{}
+	}
+	return info.ModTime()
+}
@@ -0,0 +1,155 @@
+// Copyright (c) 2020 - for information on the respective copyright owner
+// see the NOTICE file and/or the repository at
+// https://github.com/hyperledger-labs/perun-node
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package node_test
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger-labs/perun-node/log"
+	"github.com/hyperledger-labs/perun-node/node"
+)
+
+func Test_Reloader_Reload(t *testing.T) {
+	t.Run("happy_log_level", func(t *testing.T) {
+		configFile := tempConfigFile(t)
+		cfg, err := node.ParseConfig(configFile)
+		require.NoError(t, err)
+		r := node.NewReloader(configFile, cfg)
+
+		handlerCalled := false
+		r.OnReload(func(prev, current node.Config) error {
+			handlerCalled = true
+			assert.Equal(t, "debug", prev.LogLevel)
+			assert.Equal(t, "error", current.LogLevel)
+			return nil
+		})
+
+		updateConfigFile(t, configFile, "loglevel: debug", "loglevel: error")
+		require.NoError(t, r.Reload())
+		assert.True(t, handlerCalled)
+		assert.Equal(t, "error", log.Level())
+		assert.Equal(t, "error", r.Config().LogLevel)
+	})
+
+	t.Run("happy_closing_mode", func(t *testing.T) {
+		configFile := tempConfigFile(t)
+		cfg, err := node.ParseConfig(configFile)
+		require.NoError(t, err)
+		r := node.NewReloader(configFile, cfg)
+
+		var reloadedMode string
+		r.OnReload(func(_, current node.Config) error {
+			reloadedMode = current.ClosingMode
+			return nil
+		})
+
+		updateConfigFile(t, configFile, "closingmode: manual", "closingmode: auto")
+		require.NoError(t, r.Reload())
+		assert.Equal(t, "auto", reloadedMode)
+		assert.Equal(t, "auto", r.Config().ClosingMode)
+	})
+
+	t.Run("err_non_reloadable_param", func(t *testing.T) {
+		configFile := tempConfigFile(t)
+		cfg, err := node.ParseConfig(configFile)
+		require.NoError(t, err)
+		r := node.NewReloader(configFile, cfg)
+
+		updateConfigFile(t, configFile, "chainurl: ws://127.0.0.1:8545", "chainurl: ws://127.0.0.1:8546")
+		assert.Error(t, r.Reload())
+		assert.Equal(t, cfg, r.Config())
+	})
+
+	t.Run("err_invalid_log_level", func(t *testing.T) {
+		configFile := tempConfigFile(t)
+		cfg, err := node.ParseConfig(configFile)
+		require.NoError(t, err)
+		r := node.NewReloader(configFile, cfg)
+
+		updateConfigFile(t, configFile, "loglevel: debug", "loglevel: invalid-level")
+		assert.Error(t, r.Reload())
+		assert.Equal(t, cfg, r.Config())
+	})
+
+	t.Run("err_handler_rolls_back", func(t *testing.T) {
+		configFile := tempConfigFile(t)
+		cfg, err := node.ParseConfig(configFile)
+		require.NoError(t, err)
+		require.NoError(t, log.SetLevel(cfg.LogLevel))
+		r := node.NewReloader(configFile, cfg)
+
+		var applied []string
+		r.OnReload(func(_, current node.Config) error {
+			applied = append(applied, current.ClosingMode)
+			return nil
+		})
+		r.OnReload(func(_, _ node.Config) error {
+			return assert.AnError
+		})
+
+		updateConfigFile(t, configFile, "loglevel: debug", "loglevel: error")
+		updateConfigFile(t, configFile, "closingmode: manual", "closingmode: auto")
+		assert.Error(t, r.Reload())
+		assert.Equal(t, []string{"auto", "manual"}, applied)
+		assert.Equal(t, "debug", log.Level())
+		assert.Equal(t, cfg, r.Config())
+	})
+}
+
+func Test_Reloader_Watch(t *testing.T) {
+	configFile := tempConfigFile(t)
+	cfg, err := node.ParseConfig(configFile)
+	require.NoError(t, err)
+	r := node.NewReloader(configFile, cfg)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go r.Watch(ctx, 10*time.Millisecond)
+
+	updateConfigFile(t, configFile, "loglevel: debug", "loglevel: warning")
+	assert.Eventually(t, func() bool {
+		return r.Config().LogLevel == "warning"
+	}, time.Second, 10*time.Millisecond)
+}
+
+// tempConfigFile makes a copy of the valid config file in testdata and returns the path to it.
+func tempConfigFile(t *testing.T) string {
+	content, err := ioutil.ReadFile(validConfigFile)
+	require.NoError(t, err)
+	return tempFile(t, string(content))
+}
+
+// updateConfigFile replaces the old string with new one in the config file and
+// bumps its modification time, so that the change is detected even on file
+// systems with coarse timestamps.
+func updateConfigFile(t *testing.T, configFile, oldStr, newStr string) {
+	content, err := ioutil.ReadFile(configFile)
+	require.NoError(t, err)
+	updated := strings.Replace(string(content), oldStr, newStr, 1)
+	require.NoError(t, ioutil.WriteFile(configFile, []byte(updated), 0o600))
+
+	bumpedTime := time.Now().Add(time.Second)
+	require.NoError(t, os.Chtimes(configFile, bumpedTime, bumpedTime))
+}
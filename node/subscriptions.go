@@ -0,0 +1,48 @@
+// Copyright (c) 2020 - for information on the respective copyright owner
+// see the NOTICE file and/or the repository at
+// https://github.com/hyperledger-labs/perun-node
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package node
+
+import (
+	"perun.network/go-perun/channel"
+
+	"github.com/hyperledger-labs/perun-node/payment"
+)
+
+// paymentChannel returns the open channel with the given ID for making payments. Channels halted due to
+// violation of protocol invariants are not returned.
+func (n *Node) paymentChannel(id channel.ID) (payment.Channel, error) {
+	if err := n.Client.CheckHalted(id); err != nil {
+		return nil, err
+	}
+	ch, err := n.Client.Channel(id)
+	if err != nil {
+		return nil, err
+	}
+	return ch, nil
+}
+
+// reportSubscriptionFailure logs the failure of a payment for the subscription.
+func (n *Node) reportSubscriptionFailure(sub payment.Subscription, err error) {
+	n.Errorf("Payment of %v for subscription %s on channel %s failed, retrying at %v: %v",
+		sub.Amount, sub.ID, sub.Channel, sub.Next, err)
+}
+
+// reportStreamFailure logs the failure of a payment that stopped the stream.
+func (n *Node) reportStreamFailure(stream payment.StreamInfo, err error) {
+	n.Errorf("Payment of %v for stream %s on channel %s failed, stream is stopped: %v",
+		stream.Amount, stream.ID, stream.Channel, err)
+}
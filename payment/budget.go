@@ -0,0 +1,381 @@
+// Copyright (c) 2020 - for information on the respective copyright owner
+// see the NOTICE file and/or the repository at
+// https://github.com/hyperledger-labs/perun-node
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package payment
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"perun.network/go-perun/pkg/sortedkv"
+
+	"github.com/hyperledger-labs/perun-node/clock"
+)
+
+// Period is the period over which the spending is limited. Periods are calendar days or months in the timezone
+// of the clock used by the budget.
+type Period string
+
+// Periods for limits.
+const (
+	Daily   Period = "daily"
+	Monthly Period = "monthly"
+)
+
+// Limit is a limit on the amount spent in each period, either on payments to a peer or on all payments.
+type Limit struct {
+	Peer   string // Off-chain address of the peer, empty for a node-wide limit.
+	Period Period
+	Amount *big.Int
+	// If true, payments exceeding the limit wait for approval instead of being rejected.
+	RequireApproval bool
+}
+
+func (l Limit) String() string {
+	if l.Peer == "" {
+		return fmt.Sprintf("%s limit of %v", l.Period, l.Amount)
+	}
+	return fmt.Sprintf("%s limit of %v for peer %s", l.Period, l.Amount, l.Peer)
+}
+
+// ErrBudgetExceeded is returned when a payment is rejected because it would exceed a limit.
+type ErrBudgetExceeded struct {
+	Limit Limit
+}
+
+func (e ErrBudgetExceeded) Error() string {
+	return "payment would exceed the " + e.Limit.String()
+}
+
+// ErrApprovalRequired is returned when a payment would exceed a limit that requires approval.
+// The payment can be retried once the approval with the ID is approved.
+type ErrApprovalRequired struct {
+	ID    string
+	Limit Limit
+}
+
+func (e ErrApprovalRequired) Error() string {
+	return fmt.Sprintf("payment would exceed the %v, waiting for approval %s", e.Limit, e.ID)
+}
+
+// Approval is a request for approving a payment that exceeds a limit.
+type Approval struct {
+	ID       string    `json:"id"`
+	Peer     string    `json:"peer"`
+	Amount   *big.Int  `json:"amount"`
+	Ref      string    `json:"ref"` // Reference for the payment given by the payer, such as the subscription ID.
+	Limit    string    `json:"limit"`
+	Created  time.Time `json:"created"`
+	Approved bool      `json:"approved"`
+}
+
+// spendingKeyPrefix is the prefix of the keys in the persistence database, under which the spendings are stored.
+// The rest of the key is the time of the spending (zero padded unix nano), so that keys are in the order of time,
+// followed by a random suffix.
+const spendingKeyPrefix = "perun-node:spending:"
+
+type spending struct {
+	key    string // Key in the database, empty if the budget has no database.
+	peer   string
+	amount *big.Int
+	at     time.Time
+}
+
+// storedSpending is the value stored in the database for a spending.
+type storedSpending struct {
+	Peer   string    `json:"peer"`
+	Amount *big.Int  `json:"amount"`
+	At     time.Time `json:"at"`
+}
+
+// Budget enforces limits on the amount spent on outgoing payments.
+//
+// Spendings of the current month are stored in the database (if any), so that the amounts spent are retained
+// across restarts. Approvals are held only in memory.
+type Budget struct {
+	clock  clock.Clock
+	limits []Limit
+	db     sortedkv.Database
+
+	mutex     sync.Mutex
+	spendings []spending
+	approvals []*Approval
+}
+
+// NewBudget returns a budget that enforces the limits. Spendings are stored in the database and the ones already
+// stored are loaded. If the database is nil, spendings are held only in memory.
+func NewBudget(clk clock.Clock, limits []Limit, db sortedkv.Database) (*Budget, error) {
+	for _, l := range limits {
+		if l.Period != Daily && l.Period != Monthly {
+			return nil, errors.Errorf("unknown period %q, should be %s or %s", l.Period, Daily, Monthly)
+		}
+		if l.Amount == nil || l.Amount.Sign() < 0 {
+			return nil, errors.Errorf("amount in %v should not be negative", l)
+		}
+	}
+	b := &Budget{clock: clk, limits: limits, db: db}
+	if db == nil {
+		return b, nil
+	}
+	return b, b.load()
+}
+
+// load loads the spendings stored in the database.
+func (b *Budget) load() error {
+	it := b.db.NewIteratorWithPrefix(spendingKeyPrefix)
+	defer it.Close() // nolint: errcheck  // iterator is used only for reading.
+	for it.Next() {
+		var stored storedSpending
+		if err := json.Unmarshal(it.ValueBytes(), &stored); err != nil || stored.Amount == nil {
+			return errors.Errorf("invalid spending %q in database", it.Key())
+		}
+		b.spendings = append(b.spendings, spending{
+			key:    it.Key(),
+			peer:   stored.Peer,
+			amount: stored.Amount,
+			at:     stored.At.In(b.clock.Now().Location()),
+		})
+	}
+	return nil
+}
+
+// Pay pays the amount over the channel, if spending it on a payment to the peer in the channel is within the
+// limits (see Spend). The spending is refunded if the payment fails. If the budget is nil, the amount is paid
+// without checking any limits.
+func (b *Budget) Pay(ctx context.Context, ch Channel, ref string, amount *big.Int) error {
+	if b == nil {
+		return Pay(ctx, ch, amount)
+	}
+	peer := Peer(ch)
+	if err := b.Spend(peer, ref, amount); err != nil {
+		return err
+	}
+	err := Pay(ctx, ch, amount)
+	if err != nil {
+		b.Refund(peer, amount)
+	}
+	return err
+}
+
+// Spend records the spending of the amount on a payment to the peer, if it is within the limits. Otherwise,
+// ErrBudgetExceeded or ErrApprovalRequired is returned. The reference identifies the payment for approvals, the
+// same approval is returned on retrying a payment with the same reference and amount.
+//
+// If the payment fails, the amount should be refunded.
+func (b *Budget) Spend(peer, ref string, amount *big.Int) error {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	now := b.clock.Now()
+	b.prune(now)
+
+	if approval := b.approval(peer, ref, amount); approval != nil && approval.Approved {
+		b.removeApproval(approval.ID)
+	} else if err := b.check(peer, ref, amount, now); err != nil {
+		return err
+	}
+	s := spending{peer: peer, amount: new(big.Int).Set(amount), at: now}
+	if err := b.store(&s); err != nil {
+		return err
+	}
+	b.spendings = append(b.spendings, s)
+	return nil
+}
+
+// store stores the spending in the database (if any) and sets its key, it should be called with mutex locked.
+func (b *Budget) store(s *spending) error {
+	if b.db == nil {
+		return nil
+	}
+	var suffix [4]byte
+	if _, err := rand.Read(suffix[:]); err != nil {
+		return errors.Wrap(err, "generating key for spending")
+	}
+	value, err := json.Marshal(storedSpending{Peer: s.peer, Amount: s.amount, At: s.at})
+	if err != nil {
+		return errors.Wrap(err, "encoding spending")
+	}
+	key := fmt.Sprintf("%s%020d:%s", spendingKeyPrefix, s.at.UnixNano(), hex.EncodeToString(suffix[:]))
+	if err = b.db.PutBytes(key, value); err != nil {
+		return errors.Wrap(err, "storing spending")
+	}
+	s.key = key
+	return nil
+}
+
+// remove removes the spending from the database, if it is stored. Errors are ignored, as a spending left in the
+// database only makes the limits stricter after a restart, until it is pruned.
+func (b *Budget) remove(s spending) {
+	if b.db != nil && s.key != "" {
+		b.db.Delete(s.key) // nolint: errcheck, gosec  // see above.
+	}
+}
+
+// check returns an error if spending the amount exceeds any limit. Limits that reject payments take precedence
+// over those that require approval.
+func (b *Budget) check(peer, ref string, amount *big.Int, now time.Time) error {
+	var needsApproval *Limit
+	for i := range b.limits {
+		l := b.limits[i]
+		if l.Peer != "" && !strings.EqualFold(l.Peer, peer) {
+			continue
+		}
+		spent := b.spent(l.Peer, periodStart(l.Period, now))
+		if spent.Add(spent, amount).Cmp(l.Amount) <= 0 {
+			continue
+		}
+		if !l.RequireApproval {
+			return ErrBudgetExceeded{Limit: l}
+		}
+		if needsApproval == nil {
+			needsApproval = &l
+		}
+	}
+	if needsApproval == nil {
+		return nil
+	}
+	approval := b.approval(peer, ref, amount)
+	if approval == nil {
+		var id [8]byte
+		if _, err := rand.Read(id[:]); err != nil {
+			return errors.Wrap(err, "generating approval id")
+		}
+		approval = &Approval{
+			ID:      hex.EncodeToString(id[:]),
+			Peer:    peer,
+			Amount:  new(big.Int).Set(amount),
+			Ref:     ref,
+			Limit:   needsApproval.String(),
+			Created: now,
+		}
+		b.approvals = append(b.approvals, approval)
+	}
+	return ErrApprovalRequired{ID: approval.ID, Limit: *needsApproval}
+}
+
+// Refund removes the latest spending of the amount on a payment to the peer, when the payment fails.
+func (b *Budget) Refund(peer string, amount *big.Int) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	for i := len(b.spendings) - 1; i >= 0; i-- {
+		if b.spendings[i].peer == peer && b.spendings[i].amount.Cmp(amount) == 0 {
+			b.remove(b.spendings[i])
+			b.spendings = append(b.spendings[:i], b.spendings[i+1:]...)
+			return
+		}
+	}
+}
+
+// Spent returns the amount spent on payments to the peer (or on all payments, if peer is empty) in the
+// current period.
+func (b *Budget) Spent(peer string, period Period) *big.Int {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	now := b.clock.Now()
+	b.prune(now)
+	return b.spent(peer, periodStart(period, now))
+}
+
+// Approvals returns the approvals that are pending or approved but not yet used by the payment.
+func (b *Budget) Approvals() []Approval {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	approvals := make([]Approval, len(b.approvals))
+	for i := range b.approvals {
+		approvals[i] = *b.approvals[i]
+	}
+	return approvals
+}
+
+// Approve approves the payment, so that it is allowed when retried.
+func (b *Budget) Approve(id string) error {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	for _, approval := range b.approvals {
+		if approval.ID == id {
+			approval.Approved = true
+			return nil
+		}
+	}
+	return errors.New("unknown approval")
+}
+
+// Reject rejects the payment by removing the approval. If the payment is retried, a new approval is required.
+func (b *Budget) Reject(id string) error {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	if !b.removeApproval(id) {
+		return errors.New("unknown approval")
+	}
+	return nil
+}
+
+// spent returns the amount spent since the start time, it should be called with mutex locked.
+func (b *Budget) spent(peer string, start time.Time) *big.Int {
+	total := new(big.Int)
+	for _, s := range b.spendings {
+		if !s.at.Before(start) && (peer == "" || strings.EqualFold(peer, s.peer)) {
+			total.Add(total, s.amount)
+		}
+	}
+	return total
+}
+
+// prune removes the spendings before the current month, it should be called with mutex locked.
+func (b *Budget) prune(now time.Time) {
+	start := periodStart(Monthly, now)
+	i := 0
+	for i < len(b.spendings) && b.spendings[i].at.Before(start) {
+		b.remove(b.spendings[i])
+		i++
+	}
+	b.spendings = b.spendings[i:]
+}
+
+// approval returns the approval for the payment, it should be called with mutex locked.
+func (b *Budget) approval(peer, ref string, amount *big.Int) *Approval {
+	for _, approval := range b.approvals {
+		if approval.Peer == peer && approval.Ref == ref && approval.Amount.Cmp(amount) == 0 {
+			return approval
+		}
+	}
+	return nil
+}
+
+// removeApproval removes the approval with the id, it should be called with mutex locked.
+func (b *Budget) removeApproval(id string) bool {
+	for i, approval := range b.approvals {
+		if approval.ID == id {
+			b.approvals = append(b.approvals[:i], b.approvals[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+func periodStart(period Period, now time.Time) time.Time {
+	if period == Monthly {
+		return time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+	}
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+}
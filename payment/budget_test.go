@@ -0,0 +1,153 @@
+// Copyright (c) 2020 - for information on the respective copyright owner
+// see the NOTICE file and/or the repository at
+// https://github.com/hyperledger-labs/perun-node
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package payment_test
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"perun.network/go-perun/pkg/sortedkv/memorydb"
+
+	"github.com/hyperledger-labs/perun-node/payment"
+)
+
+func Test_Budget(t *testing.T) {
+	setup := func(t *testing.T, limits ...payment.Limit) (*fakeClock, *payment.Budget) {
+		clk := &fakeClock{now: time.Date(2020, 10, 31, 12, 0, 0, 0, time.UTC)}
+		budget, err := payment.NewBudget(clk, limits, nil)
+		require.NoError(t, err)
+		return clk, budget
+	}
+
+	t.Run("happy_daily_monthly", func(t *testing.T) {
+		clk, budget := setup(t,
+			payment.Limit{Period: payment.Daily, Amount: big.NewInt(10)},
+			payment.Limit{Period: payment.Monthly, Amount: big.NewInt(15)})
+		require.NoError(t, budget.Spend("bob", "1", big.NewInt(6)))
+		assert.IsType(t, payment.ErrBudgetExceeded{}, budget.Spend("carol", "2", big.NewInt(5)))
+		require.NoError(t, budget.Spend("carol", "2", big.NewInt(4)))
+
+		clk.now = clk.now.Add(12 * time.Hour) // Next day and month.
+		require.NoError(t, budget.Spend("bob", "1", big.NewInt(10)))
+		assert.EqualValues(t, 10, budget.Spent("", payment.Monthly).Int64())
+		assert.EqualValues(t, 0, budget.Spent("carol", payment.Daily).Int64())
+	})
+
+	t.Run("happy_per_peer", func(t *testing.T) {
+		_, budget := setup(t, payment.Limit{Peer: "BOB", Period: payment.Daily, Amount: big.NewInt(5)})
+		require.NoError(t, budget.Spend("bob", "1", big.NewInt(5)))
+		assert.Error(t, budget.Spend("bob", "1", big.NewInt(1)))
+		assert.NoError(t, budget.Spend("carol", "2", big.NewInt(50)))
+	})
+
+	t.Run("happy_refund", func(t *testing.T) {
+		_, budget := setup(t, payment.Limit{Period: payment.Daily, Amount: big.NewInt(5)})
+		require.NoError(t, budget.Spend("bob", "1", big.NewInt(5)))
+		budget.Refund("bob", big.NewInt(5))
+		assert.NoError(t, budget.Spend("bob", "1", big.NewInt(5)))
+	})
+
+	t.Run("happy_approval", func(t *testing.T) {
+		_, budget := setup(t, payment.Limit{Period: payment.Daily, Amount: big.NewInt(5), RequireApproval: true})
+		err := budget.Spend("bob", "sub", big.NewInt(6))
+		var approvalErr payment.ErrApprovalRequired
+		require.True(t, errors.As(err, &approvalErr))
+		assert.Equal(t, err, budget.Spend("bob", "sub", big.NewInt(6)), "same approval on retry")
+		require.Len(t, budget.Approvals(), 1)
+		assert.False(t, budget.Approvals()[0].Approved)
+
+		require.NoError(t, budget.Approve(approvalErr.ID))
+		require.NoError(t, budget.Spend("bob", "sub", big.NewInt(6)))
+		assert.Empty(t, budget.Approvals(), "approval is used")
+		assert.IsType(t, payment.ErrApprovalRequired{}, budget.Spend("bob", "sub", big.NewInt(6)))
+	})
+
+	t.Run("happy_reject", func(t *testing.T) {
+		_, budget := setup(t, payment.Limit{Period: payment.Daily, Amount: big.NewInt(5), RequireApproval: true})
+		var approvalErr payment.ErrApprovalRequired
+		require.True(t, errors.As(budget.Spend("bob", "sub", big.NewInt(6)), &approvalErr))
+		require.NoError(t, budget.Reject(approvalErr.ID))
+		assert.Empty(t, budget.Approvals())
+		assert.Error(t, budget.Approve("unknown"))
+		assert.Error(t, budget.Reject("unknown"))
+	})
+
+	t.Run("happy_hard_limit_precedence", func(t *testing.T) {
+		_, budget := setup(t,
+			payment.Limit{Period: payment.Daily, Amount: big.NewInt(5), RequireApproval: true},
+			payment.Limit{Period: payment.Monthly, Amount: big.NewInt(8)})
+		assert.IsType(t, payment.ErrBudgetExceeded{}, budget.Spend("bob", "sub", big.NewInt(9)))
+	})
+
+	t.Run("happy_persisted", func(t *testing.T) {
+		clk := &fakeClock{now: time.Date(2020, 10, 31, 12, 0, 0, 0, time.UTC)}
+		limits := []payment.Limit{{Period: payment.Monthly, Amount: big.NewInt(10)}}
+		db := memorydb.NewDatabase()
+		budget, err := payment.NewBudget(clk, limits, db)
+		require.NoError(t, err)
+		require.NoError(t, budget.Spend("bob", "1", big.NewInt(4)))
+		require.NoError(t, budget.Spend("bob", "2", big.NewInt(3)))
+		budget.Refund("bob", big.NewInt(3))
+
+		restarted, err := payment.NewBudget(clk, limits, db)
+		require.NoError(t, err)
+		assert.EqualValues(t, 4, restarted.Spent("bob", payment.Monthly).Int64())
+		assert.IsType(t, payment.ErrBudgetExceeded{}, restarted.Spend("bob", "3", big.NewInt(7)))
+
+		clk.now = clk.now.Add(24 * time.Hour) // Next month, previous spendings are pruned.
+		assert.EqualValues(t, 0, restarted.Spent("bob", payment.Monthly).Int64())
+		it := db.NewIteratorWithPrefix("")
+		assert.False(t, it.Next(), "pruned spendings should be removed from database")
+		require.NoError(t, it.Close())
+	})
+
+	t.Run("happy_pay", func(t *testing.T) {
+		_, budget := setup(t, payment.Limit{Period: payment.Daily, Amount: big.NewInt(5)})
+		ch := &fakeChannel{idx: 0, state: newTestState(t, 10, 0)}
+		require.NoError(t, budget.Pay(context.Background(), ch, "1", big.NewInt(4)))
+		assert.IsType(t, payment.ErrBudgetExceeded{}, budget.Pay(context.Background(), ch, "2", big.NewInt(4)))
+		assert.EqualValues(t, 4, ch.balance(1))
+
+		ch.err = assert.AnError
+		assert.Error(t, budget.Pay(context.Background(), ch, "3", big.NewInt(1)))
+		assert.EqualValues(t, 4, budget.Spent("", payment.Daily).Int64(), "failed payment should be refunded")
+
+		var noBudget *payment.Budget
+		ch.err = nil
+		require.NoError(t, noBudget.Pay(context.Background(), ch, "4", big.NewInt(6)))
+		assert.EqualValues(t, 10, ch.balance(1))
+	})
+
+	t.Run("err_invalid_stored_spending", func(t *testing.T) {
+		db := memorydb.NewDatabase()
+		require.NoError(t, db.Put("perun-node:spending:1", "invalid"))
+		_, err := payment.NewBudget(&fakeClock{}, nil, db)
+		assert.Error(t, err)
+	})
+
+	t.Run("err_invalid_limit", func(t *testing.T) {
+		_, err := payment.NewBudget(&fakeClock{}, []payment.Limit{{Period: "weekly", Amount: big.NewInt(1)}}, nil)
+		assert.Error(t, err)
+		_, err = payment.NewBudget(&fakeClock{}, []payment.Limit{{Period: payment.Daily}}, nil)
+		assert.Error(t, err)
+	})
+}
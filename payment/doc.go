@@ -0,0 +1,29 @@
+// Copyright (c) 2020 - for information on the respective copyright owner
+// see the NOTICE file and/or the repository at
+// https://github.com/hyperledger-labs/perun-node
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package payment implements payments on two party payment channels.
+//
+// A payment is a channel update that transfers an amount of the first asset
+// in the channel from the balance of the payer to that of the payee, without
+// any other change to the state. Pay sends a payment and ValidateIncoming
+// checks if an incoming update is a valid payment to this node, so that it
+// can be accepted (co-signed) automatically.
+//
+// Stream builds on these for paying at a fixed rate: either an amount per
+// interval of time (e.g. for media streaming) or an amount per unit of usage
+// (e.g. for pay-per-use APIs), optionally capped by a total limit that the
+// payer authorizes.
+package payment
@@ -0,0 +1,157 @@
+// Copyright (c) 2020 - for information on the respective copyright owner
+// see the NOTICE file and/or the repository at
+// https://github.com/hyperledger-labs/perun-node
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package payment
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// maxFiatDecimals is the number of decimals used for printing fiat amounts that are not exact decimals.
+const maxFiatDecimals = 18
+
+var currencyRegexp = regexp.MustCompile("^[A-Z]{3}$")
+
+// FiatAmount is an amount in a fiat currency.
+type FiatAmount struct {
+	Value    *big.Rat
+	Currency string // ISO 4217 code of the currency, such as EUR.
+}
+
+// ParseFiat parses a fiat amount given as decimal value and currency code separated by space, such as "5.00 EUR".
+func ParseFiat(s string) (FiatAmount, error) {
+	fields := strings.Fields(s)
+	if len(fields) != 2 {
+		return FiatAmount{}, errors.Errorf("fiat amount %q should be value and currency, such as \"5.00 EUR\"", s)
+	}
+	value, ok := new(big.Rat).SetString(fields[0])
+	if !ok || value.Sign() <= 0 {
+		return FiatAmount{}, errors.Errorf("invalid value %q, should be a positive decimal", fields[0])
+	}
+	currency := strings.ToUpper(fields[1])
+	if !currencyRegexp.MatchString(currency) {
+		return FiatAmount{}, errors.Errorf("invalid currency code %q", fields[1])
+	}
+	return FiatAmount{Value: value, Currency: currency}, nil
+}
+
+func (f FiatAmount) String() string {
+	decimals, pow := 0, big.NewInt(1)
+	for decimals < maxFiatDecimals && new(big.Int).Mod(pow, f.Value.Denom()).Sign() != 0 {
+		decimals++
+		pow.Mul(pow, big.NewInt(10))
+	}
+	return f.Value.FloatString(decimals) + " " + f.Currency
+}
+
+// Oracle provides the price of one (whole) unit of the asset in fiat currencies.
+type Oracle interface {
+	Price(ctx context.Context, currency string) (*big.Rat, error)
+}
+
+// HTTPOracle is an oracle that fetches the prices from an HTTP endpoint. The currency code is added as
+// "currency" to the query parameters of the URL and the response should be a JSON object with the price as
+// decimal string, such as {"price": "1234.56"}.
+type HTTPOracle struct {
+	URL    string
+	Client *http.Client // If nil, http.DefaultClient is used.
+}
+
+// Price implements Oracle.
+func (o HTTPOracle) Price(ctx context.Context, currency string) (*big.Rat, error) {
+	u, err := url.Parse(o.URL)
+	if err != nil {
+		return nil, errors.Wrap(err, "parsing oracle url")
+	}
+	query := u.Query()
+	query.Set("currency", currency)
+	u.RawQuery = query.Encode()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "creating request")
+	}
+	client := o.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "fetching price")
+	}
+	defer resp.Body.Close() // nolint: errcheck  // response body is only read.
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("fetching price: unexpected status %s", resp.Status)
+	}
+	var body struct {
+		Price string `json:"price"`
+	}
+	if err = json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, errors.Wrap(err, "decoding price")
+	}
+	price, ok := new(big.Rat).SetString(body.Price)
+	if !ok || price.Sign() <= 0 {
+		return nil, errors.Errorf("invalid price %q", body.Price)
+	}
+	return price, nil
+}
+
+// Converter converts fiat amounts to amounts of the asset, using the price from the oracle.
+type Converter struct {
+	Oracle Oracle
+	// Number of decimals of the asset, for converting whole units to the smallest unit used in the channels.
+	Decimals int
+	// Maximum relative difference (e.g. 0.01 for 1%) between an amount received for a fiat amount and the
+	// amount converted by this node, for the payment to be accepted.
+	Tolerance float64
+}
+
+// ToAsset returns the amount of the asset (in the smallest unit) worth the fiat amount, rounded down.
+func (c Converter) ToAsset(ctx context.Context, f FiatAmount) (*big.Int, error) {
+	price, err := c.Oracle.Price(ctx, f.Currency)
+	if err != nil {
+		return nil, err
+	}
+	scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(c.Decimals)), nil)
+	amount := new(big.Rat).Quo(f.Value, price)
+	amount.Mul(amount, new(big.Rat).SetInt(scale))
+	return new(big.Int).Quo(amount.Num(), amount.Denom()), nil
+}
+
+// Validate checks if the amount of the asset is worth the fiat amount, within the tolerance.
+func (c Converter) Validate(ctx context.Context, f FiatAmount, amount *big.Int) error {
+	expected, err := c.ToAsset(ctx, f)
+	if err != nil {
+		return err
+	}
+	diff := new(big.Int).Sub(amount, expected)
+	tolerance := new(big.Rat).SetFloat64(c.Tolerance)
+	if tolerance == nil {
+		return errors.New("invalid tolerance")
+	}
+	maxDiff := tolerance.Mul(tolerance, new(big.Rat).SetInt(expected))
+	if new(big.Rat).SetInt(diff.Abs(diff)).Cmp(maxDiff) > 0 {
+		return errors.Errorf("amount %v is not within tolerance of %v, worth %v", amount, expected, f)
+	}
+	return nil
+}
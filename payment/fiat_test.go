@@ -0,0 +1,119 @@
+// Copyright (c) 2020 - for information on the respective copyright owner
+// see the NOTICE file and/or the repository at
+// https://github.com/hyperledger-labs/perun-node
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package payment_test
+
+import (
+	"context"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger-labs/perun-node/payment"
+)
+
+// fakeOracle returns the prices for fiat currencies from the map.
+type fakeOracle map[string]*big.Rat
+
+func (o fakeOracle) Price(_ context.Context, currency string) (*big.Rat, error) {
+	price, ok := o[currency]
+	if !ok {
+		return nil, assert.AnError
+	}
+	return price, nil
+}
+
+func Test_ParseFiat(t *testing.T) {
+	t.Run("happy", func(t *testing.T) {
+		for s, want := range map[string]string{"5 EUR": "5 EUR", "0.50 usd": "0.5 USD", "1.125 EUR": "1.125 EUR"} {
+			fiat, err := payment.ParseFiat(s)
+			require.NoError(t, err, s)
+			assert.Equal(t, want, fiat.String())
+		}
+	})
+
+	t.Run("err_invalid", func(t *testing.T) {
+		for _, s := range []string{"5", "EUR 5", "-1 EUR", "0 EUR", "5 EURO", "5 E1R", "€5"} {
+			_, err := payment.ParseFiat(s)
+			assert.Error(t, err, s)
+		}
+	})
+}
+
+func Test_HTTPOracle(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Query().Get("currency") {
+		case "EUR":
+			_, _ = w.Write([]byte(`{"price": "1234.5"}`))
+		case "USD":
+			_, _ = w.Write([]byte(`{"price": "invalid"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	oracle := payment.HTTPOracle{URL: server.URL + "/price?asset=eth"}
+
+	t.Run("happy", func(t *testing.T) {
+		price, err := oracle.Price(context.Background(), "EUR")
+		require.NoError(t, err)
+		assert.Equal(t, big.NewRat(24690, 20), price)
+	})
+
+	t.Run("err_invalid_price", func(t *testing.T) {
+		_, err := oracle.Price(context.Background(), "USD")
+		assert.Error(t, err)
+	})
+
+	t.Run("err_status", func(t *testing.T) {
+		_, err := oracle.Price(context.Background(), "JPY")
+		assert.Error(t, err)
+	})
+}
+
+func Test_Converter(t *testing.T) {
+	conv := payment.Converter{Oracle: fakeOracle{"EUR": big.NewRat(3, 1)}, Decimals: 18, Tolerance: 0.05}
+	fiat, err := payment.ParseFiat("1 EUR")
+	require.NoError(t, err)
+	third, ok := new(big.Int).SetString("333333333333333333", 10)
+	require.True(t, ok)
+
+	t.Run("happy_to_asset", func(t *testing.T) {
+		amount, err := conv.ToAsset(context.Background(), fiat)
+		require.NoError(t, err)
+		assert.Equal(t, third, amount, "should be rounded down")
+	})
+
+	t.Run("happy_validate", func(t *testing.T) {
+		lower := new(big.Int).Div(new(big.Int).Mul(third, big.NewInt(96)), big.NewInt(100))
+		assert.NoError(t, conv.Validate(context.Background(), fiat, lower))
+		assert.NoError(t, conv.Validate(context.Background(), fiat, third))
+	})
+
+	t.Run("err_validate_out_of_tolerance", func(t *testing.T) {
+		assert.Error(t, conv.Validate(context.Background(), fiat, new(big.Int).Div(third, big.NewInt(2))))
+		assert.Error(t, conv.Validate(context.Background(), fiat, new(big.Int).Mul(third, big.NewInt(2))))
+	})
+
+	t.Run("err_oracle", func(t *testing.T) {
+		_, err := conv.ToAsset(context.Background(), payment.FiatAmount{Value: big.NewRat(1, 1), Currency: "USD"})
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,200 @@
+// Copyright (c) 2020 - for information on the respective copyright owner
+// see the NOTICE file and/or the repository at
+// https://github.com/hyperledger-labs/perun-node
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package payment
+
+import (
+	"bytes"
+	"context"
+	"encoding/base32"
+	"encoding/binary"
+	"encoding/hex"
+	"io"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// InvoicePrefix is the prefix of encoded invoices.
+	InvoicePrefix = "PERUN"
+
+	invoiceFormatVersion = 2 // Version 2 adds the fiat amount.
+	maxInvoiceFieldSize  = 1024
+)
+
+// Invoices are encoded in upper case base32, so that they can be stored compactly in QR codes
+// using the alphanumeric mode.
+var invoiceEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// InvoiceID identifies an invoice. It is generated randomly by the payee.
+type InvoiceID [32]byte
+
+// MarshalText encodes the ID as hex string.
+func (id InvoiceID) MarshalText() ([]byte, error) {
+	return []byte(hex.EncodeToString(id[:])), nil
+}
+
+// UnmarshalText decodes the ID from hex string.
+func (id *InvoiceID) UnmarshalText(text []byte) error {
+	b, err := hex.DecodeString(string(text))
+	if err != nil || len(b) != len(id) {
+		return errors.New("invalid invoice ID")
+	}
+	copy(id[:], b)
+	return nil
+}
+
+// Invoice is a request for payment of an amount of an asset, generated by the payee.
+//
+// Payments are plain channel updates and carry no reference to the invoice they pay. The payee settles an open
+// invoice when it receives a payment of exactly its amount (or worth its fiat amount) in its asset, so the ID is
+// not a proof of payment. It only identifies the invoice to the payee and the payer, such as in receipts and
+// refunds. A payment of the amount made for any other reason also settles the invoice.
+type Invoice struct {
+	Amount *big.Int  `json:"amount"`
+	Asset  string    `json:"asset"`
+	ID     InvoiceID `json:"id"`
+	Expiry time.Time `json:"expiry"`
+	Memo   string    `json:"memo"`
+	// Fiat amount (such as "5.00 EUR") to be paid instead of Amount, if set. It is converted to the amount of the
+	// asset when paying the invoice.
+	Fiat string `json:"fiat,omitempty"`
+}
+
+// Expired returns true if the invoice has expired at the given time.
+func (inv Invoice) Expired(now time.Time) bool {
+	return !now.Before(inv.Expiry)
+}
+
+// Encode encodes the invoice as a compact string that can be shared with the payer.
+func (inv Invoice) Encode() string {
+	var buf bytes.Buffer
+	buf.WriteByte(invoiceFormatVersion)
+	writeField(&buf, inv.Amount.Bytes())
+	writeField(&buf, []byte(inv.Asset))
+	buf.Write(inv.ID[:])
+	writeUvarint(&buf, uint64(inv.Expiry.Unix()))
+	writeField(&buf, []byte(inv.Memo))
+	writeField(&buf, []byte(inv.Fiat))
+	return InvoicePrefix + invoiceEncoding.EncodeToString(buf.Bytes())
+}
+
+// DecodeInvoice decodes an invoice encoded using Encode. Lower case invoices are also accepted.
+func DecodeInvoice(encoded string) (Invoice, error) {
+	encoded = strings.ToUpper(strings.TrimSpace(encoded))
+	if !strings.HasPrefix(encoded, InvoicePrefix) {
+		return Invoice{}, errors.New("invoice should start with " + InvoicePrefix)
+	}
+	data, err := invoiceEncoding.DecodeString(strings.TrimPrefix(encoded, InvoicePrefix))
+	if err != nil {
+		return Invoice{}, errors.Wrap(err, "decoding invoice")
+	}
+	inv, err := readInvoice(bytes.NewReader(data))
+	return inv, errors.WithMessage(err, "decoding invoice")
+}
+
+func readInvoice(r *bytes.Reader) (inv Invoice, err error) {
+	version, err := r.ReadByte()
+	if err != nil || version == 0 || version > invoiceFormatVersion {
+		return Invoice{}, errors.New("unsupported format version")
+	}
+	var amount, asset, memo, fiat []byte
+	var expiry uint64
+	if amount, err = readField(r); err != nil {
+		return Invoice{}, err
+	}
+	if asset, err = readField(r); err != nil {
+		return Invoice{}, err
+	}
+	if _, err = io.ReadFull(r, inv.ID[:]); err != nil {
+		return Invoice{}, errors.Wrap(err, "reading invoice ID")
+	}
+	if expiry, err = binary.ReadUvarint(r); err != nil {
+		return Invoice{}, errors.Wrap(err, "reading expiry")
+	}
+	if memo, err = readField(r); err != nil {
+		return Invoice{}, err
+	}
+	if version >= 2 {
+		if fiat, err = readField(r); err != nil {
+			return Invoice{}, err
+		}
+	}
+	if r.Len() != 0 {
+		return Invoice{}, errors.New("unexpected trailing data")
+	}
+	inv.Amount = new(big.Int).SetBytes(amount)
+	inv.Asset = string(asset)
+	inv.Expiry = time.Unix(int64(expiry), 0)
+	inv.Memo = string(memo)
+	inv.Fiat = string(fiat)
+	return inv, nil
+}
+
+func writeUvarint(buf *bytes.Buffer, x uint64) {
+	var b [binary.MaxVarintLen64]byte
+	buf.Write(b[:binary.PutUvarint(b[:], x)])
+}
+
+func writeField(buf *bytes.Buffer, field []byte) {
+	writeUvarint(buf, uint64(len(field)))
+	buf.Write(field)
+}
+
+func readField(r *bytes.Reader) ([]byte, error) {
+	size, err := binary.ReadUvarint(r)
+	if err != nil {
+		return nil, errors.Wrap(err, "reading field size")
+	}
+	if size > maxInvoiceFieldSize {
+		return nil, errors.Errorf("field size %d exceeds max size %d", size, maxInvoiceFieldSize)
+	}
+	field := make([]byte, size)
+	_, err = io.ReadFull(r, field)
+	return field, errors.Wrap(err, "reading field")
+}
+
+// PayInvoice pays the invoice over the channel, if it has not expired and the channel is in the asset
+// requested in the invoice, and returns the amount paid. Invoices in fiat are converted to the amount of the
+// asset using the converter. The payment is limited by the budget, if it is not nil.
+func PayInvoice(ctx context.Context, ch Channel, inv Invoice, conv *Converter, budget *Budget) (*big.Int, error) {
+	if inv.Expired(time.Now()) {
+		return nil, errors.New("invoice has expired")
+	}
+	if asset := Asset(ch.State()); !strings.EqualFold(asset, inv.Asset) {
+		return nil, errors.Errorf("invoice is for asset %s, channel is in %s", inv.Asset, asset)
+	}
+	amount := inv.Amount
+	if inv.Fiat != "" {
+		if conv == nil {
+			return nil, errors.New("converter is required for paying invoices in fiat")
+		}
+		fiat, err := ParseFiat(inv.Fiat)
+		if err != nil {
+			return nil, err
+		}
+		if amount, err = conv.ToAsset(ctx, fiat); err != nil {
+			return nil, errors.WithMessage(err, "converting fiat amount")
+		}
+	}
+	if err := budget.Pay(ctx, ch, "invoice:"+hex.EncodeToString(inv.ID[:]), amount); err != nil {
+		return nil, err
+	}
+	return amount, nil
+}
@@ -0,0 +1,131 @@
+// Copyright (c) 2020 - for information on the respective copyright owner
+// see the NOTICE file and/or the repository at
+// https://github.com/hyperledger-labs/perun-node
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package payment_test
+
+import (
+	"context"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger-labs/perun-node/payment"
+)
+
+func newTestInvoice() payment.Invoice {
+	return payment.Invoice{
+		Amount: big.NewInt(1e18),
+		Asset:  "0x5992089d61cE79B6CF90506F70DD42B8E42FB21d",
+		ID:     payment.InvoiceID{1, 2, 3},
+		Expiry: time.Unix(1600000000, 0),
+		Memo:   "coffee",
+	}
+}
+
+func Test_Invoice_Encode_Decode(t *testing.T) {
+	t.Run("happy", func(t *testing.T) {
+		inv := newTestInvoice()
+		encoded := inv.Encode()
+		assert.True(t, strings.HasPrefix(encoded, payment.InvoicePrefix))
+		assert.Equal(t, strings.ToUpper(encoded), encoded, "should be QR alphanumeric")
+
+		decoded, err := payment.DecodeInvoice(strings.ToLower(encoded))
+		require.NoError(t, err)
+		assert.Equal(t, inv.Amount, decoded.Amount)
+		assert.Equal(t, inv.Asset, decoded.Asset)
+		assert.Equal(t, inv.ID, decoded.ID)
+		assert.True(t, inv.Expiry.Equal(decoded.Expiry))
+		assert.Equal(t, inv.Memo, decoded.Memo)
+	})
+
+	t.Run("happy_fiat", func(t *testing.T) {
+		inv := newTestInvoice()
+		inv.Amount, inv.Fiat = new(big.Int), "5.25 EUR"
+		decoded, err := payment.DecodeInvoice(inv.Encode())
+		require.NoError(t, err)
+		assert.Equal(t, "5.25 EUR", decoded.Fiat)
+		assert.Zero(t, decoded.Amount.Sign())
+	})
+
+	t.Run("err_prefix", func(t *testing.T) {
+		_, err := payment.DecodeInvoice("INVOICE")
+		assert.Error(t, err)
+	})
+
+	t.Run("err_truncated", func(t *testing.T) {
+		encoded := newTestInvoice().Encode()
+		_, err := payment.DecodeInvoice(encoded[:len(encoded)-8])
+		assert.Error(t, err)
+	})
+}
+
+func Test_PayInvoice(t *testing.T) {
+	inv := newTestInvoice()
+	inv.Amount = big.NewInt(4)
+	inv.Asset = ""
+	inv.Expiry = time.Now().Add(time.Hour)
+
+	t.Run("happy", func(t *testing.T) {
+		ch := &fakeChannel{idx: 0, state: newTestState(t, 10, 10)}
+		paid, err := payment.PayInvoice(context.Background(), ch, inv, nil, nil)
+		require.NoError(t, err)
+		assert.EqualValues(t, 4, paid.Int64())
+		assert.EqualValues(t, 6, ch.balance(0))
+	})
+
+	t.Run("happy_fiat", func(t *testing.T) {
+		fiatInv := inv
+		fiatInv.Amount, fiatInv.Fiat = new(big.Int), "2 EUR"
+		ch := &fakeChannel{idx: 0, state: newTestState(t, 10, 10)}
+		conv := &payment.Converter{Oracle: fakeOracle{"EUR": big.NewRat(1, 2)}}
+		paid, err := payment.PayInvoice(context.Background(), ch, fiatInv, conv, nil)
+		require.NoError(t, err)
+		assert.EqualValues(t, 4, paid.Int64())
+		assert.EqualValues(t, 6, ch.balance(0))
+		_, err = payment.PayInvoice(context.Background(), ch, fiatInv, nil, nil)
+		assert.Error(t, err, "converter is required")
+	})
+
+	t.Run("err_budget_exceeded", func(t *testing.T) {
+		budget, err := payment.NewBudget(&fakeClock{now: time.Now()},
+			[]payment.Limit{{Period: payment.Daily, Amount: big.NewInt(3)}}, nil)
+		require.NoError(t, err)
+		ch := &fakeChannel{idx: 0, state: newTestState(t, 10, 10)}
+		_, err = payment.PayInvoice(context.Background(), ch, inv, nil, budget)
+		assert.IsType(t, payment.ErrBudgetExceeded{}, err)
+		assert.EqualValues(t, 10, ch.balance(0))
+	})
+
+	t.Run("err_expired", func(t *testing.T) {
+		expired := inv
+		expired.Expiry = time.Now()
+		ch := &fakeChannel{idx: 0, state: newTestState(t, 10, 10)}
+		_, err := payment.PayInvoice(context.Background(), ch, expired, nil, nil)
+		assert.Error(t, err)
+	})
+
+	t.Run("err_asset", func(t *testing.T) {
+		otherAsset := inv
+		otherAsset.Asset = "other"
+		ch := &fakeChannel{idx: 0, state: newTestState(t, 10, 10)}
+		_, err := payment.PayInvoice(context.Background(), ch, otherAsset, nil, nil)
+		assert.Error(t, err)
+	})
+}
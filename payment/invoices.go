@@ -0,0 +1,325 @@
+// Copyright (c) 2020 - for information on the respective copyright owner
+// see the NOTICE file and/or the repository at
+// https://github.com/hyperledger-labs/perun-node
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package payment
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"perun.network/go-perun/channel"
+
+	"github.com/hyperledger-labs/perun-node/clock"
+)
+
+const (
+	// MaxOpenInvoices is the maximum number of open invoices. New invoices cannot be created beyond it.
+	MaxOpenInvoices = 1000
+
+	maxRecords       = 10000
+	expiredRetention = time.Hour
+)
+
+// InvoiceStatus is the status of an invoice generated by the node.
+type InvoiceStatus string
+
+// Statuses of an invoice.
+const (
+	InvoiceOpen    InvoiceStatus = "open"
+	InvoiceSettled InvoiceStatus = "settled"
+	InvoiceExpired InvoiceStatus = "expired"
+	// Refunding is the status of a settled invoice while the refund is being paid.
+	InvoiceRefunding InvoiceStatus = "refunding"
+	InvoiceRefunded  InvoiceStatus = "refunded"
+)
+
+// InvoiceRecord is an invoice generated by the node, along with its status.
+type InvoiceRecord struct {
+	Invoice
+	Encoded   string        `json:"encoded"`
+	Status    InvoiceStatus `json:"status"`
+	SettledAt time.Time     `json:"settledAt,omitempty"`
+	Received  *big.Int      `json:"received,omitempty"` // Amount received, if the invoice is settled.
+	Version   uint64        `json:"version,omitempty"`  // Version of the channel state that settled the invoice.
+	Channel   string        `json:"channel,omitempty"`  // ID (as hex string) of the channel that settled the invoice.
+	Receipt   *Receipt      `json:"receipt,omitempty"`  // Receipt for the payment, if the invoice is settled.
+	Refund    *Refund       `json:"refund,omitempty"`   // Refund of the payment, if the invoice is refunded.
+	// Amount requested when creating the invoice, if Amount was increased to make it unique.
+	Requested *big.Int `json:"requested,omitempty"`
+
+	channelID channel.ID
+}
+
+// Invoices generates invoices for receiving payments and settles them when the payments are received.
+//
+// Payments carry no reference to the invoice they pay. So, the amount of each open invoice in an asset is kept
+// unique and a received payment settles the open invoice for the same asset and amount. Invoices in fiat are
+// settled by payments worth the fiat amount, within the tolerance of the converter, only if no other open
+// invoice in fiat accepts the payment.
+//
+// Invoices are held in memory and are lost when the node is restarted. Expired invoices are removed after
+// expiredRetention and the oldest settled invoices are removed when there are more than maxRecords.
+type Invoices struct {
+	clock     clock.Clock
+	converter *Converter
+
+	mutex   sync.Mutex
+	records []*InvoiceRecord
+}
+
+// NewInvoices returns an empty set of invoices that uses the clock for expiry. If converter is nil, invoices
+// in fiat cannot be created.
+func NewInvoices(clk clock.Clock, converter *Converter) *Invoices {
+	return &Invoices{clock: clk, converter: converter}
+}
+
+// Create generates an invoice for the amount of the asset, that expires after the given duration.
+//
+// If another open invoice in the asset is for the same amount, the amount is increased in steps of the smallest
+// unit until it is unique, so that a payment settles only the invoice it was made for. The requested amount is
+// then retained in the Requested field of the returned invoice, so that the caller can see the increase.
+func (i *Invoices) Create(amount *big.Int, asset string, expiry time.Duration, memo string) (InvoiceRecord, error) {
+	if amount == nil || amount.Sign() <= 0 {
+		return InvoiceRecord{}, errors.New("amount should be positive")
+	}
+	return i.create(Invoice{Amount: new(big.Int).Set(amount), Asset: asset, Memo: memo}, expiry)
+}
+
+// CreateFiat generates an invoice for the fiat amount, to be paid in the asset, that expires after the given
+// duration.
+func (i *Invoices) CreateFiat(fiat FiatAmount, asset string, expiry time.Duration, memo string) (
+	InvoiceRecord, error) {
+	if i.converter == nil {
+		return InvoiceRecord{}, errors.New("fiat oracle is not configured")
+	}
+	return i.create(Invoice{Amount: new(big.Int), Asset: asset, Memo: memo, Fiat: fiat.String()}, expiry)
+}
+
+func (i *Invoices) create(inv Invoice, expiry time.Duration) (InvoiceRecord, error) {
+	if expiry <= 0 {
+		return InvoiceRecord{}, errors.New("expiry should be positive")
+	}
+	record := &InvoiceRecord{Invoice: inv, Status: InvoiceOpen}
+	if _, err := rand.Read(record.ID[:]); err != nil {
+		return InvoiceRecord{}, errors.Wrap(err, "generating invoice ID")
+	}
+
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+	now := i.clock.Now()
+	i.updateExpired(now)
+	i.prune(now)
+	if err := i.makeUnique(record); err != nil {
+		return InvoiceRecord{}, err
+	}
+	// Expiry is encoded in seconds, so the invoice is rounded down to match the decoded one.
+	record.Expiry = now.Add(expiry).Truncate(time.Second)
+	record.Encoded = record.Encode()
+	i.records = append(i.records, record)
+	return *record, nil
+}
+
+// makeUnique increases the amount of the new invoice until no other open invoice in the asset is for the same
+// amount and records the requested amount, if it was increased. Invoices in fiat are rejected if another open
+// invoice in the asset is for the same fiat amount, as the amount paid for them is known only when the payment is
+// received. It should be called with mutex locked.
+func (i *Invoices) makeUnique(record *InvoiceRecord) error {
+	amounts := make(map[string]bool)
+	open := 0
+	for _, r := range i.records {
+		if r.Status != InvoiceOpen {
+			continue
+		}
+		open++
+		if !strings.EqualFold(r.Asset, record.Asset) {
+			continue
+		}
+		if record.Fiat != "" && r.Fiat == record.Fiat {
+			return errors.Errorf("an open invoice for %s in %s already exists", record.Fiat, record.Asset)
+		}
+		if r.Fiat == "" {
+			amounts[r.Amount.String()] = true
+		}
+	}
+	if open >= MaxOpenInvoices {
+		return errors.Errorf("there are already %d open invoices", open)
+	}
+	if record.Fiat != "" {
+		return nil
+	}
+	requested := new(big.Int).Set(record.Amount)
+	for amounts[record.Amount.String()] {
+		record.Amount.Add(record.Amount, big.NewInt(1))
+	}
+	if record.Amount.Cmp(requested) != 0 {
+		record.Requested = requested
+	}
+	return nil
+}
+
+// Settle marks the open invoice paid by the received payment as settled and returns it. If there is no such
+// invoice, or the payment is accepted by more than one open invoice in fiat, false is returned. The context is
+// used for fetching prices for invoices in fiat.
+func (i *Invoices) Settle(ctx context.Context, rcv Received) (InvoiceRecord, bool) {
+	if record, ok := i.settle(rcv, func(r *InvoiceRecord) bool {
+		return r.Fiat == "" && r.Amount.Cmp(rcv.Amount) == 0
+	}); ok {
+		return record, true
+	}
+	if i.converter == nil {
+		return InvoiceRecord{}, false
+	}
+	// Fiat amounts are validated without holding the lock, as fetching the prices may be slow.
+	var matched []InvoiceID
+	for _, candidate := range i.List() {
+		if candidate.Status != InvoiceOpen || candidate.Fiat == "" || !strings.EqualFold(candidate.Asset, rcv.Asset) {
+			continue
+		}
+		fiat, err := ParseFiat(candidate.Fiat)
+		if err != nil || i.converter.Validate(ctx, fiat, rcv.Amount) != nil {
+			continue
+		}
+		matched = append(matched, candidate.ID)
+	}
+	// If more than one invoice accepts the payment, it cannot be known which one was paid.
+	if len(matched) != 1 {
+		return InvoiceRecord{}, false
+	}
+	return i.settle(rcv, func(r *InvoiceRecord) bool {
+		return r.ID == matched[0]
+	})
+}
+
+// settle marks the open invoice in the asset of the received payment, that matches, as settled.
+func (i *Invoices) settle(rcv Received, match func(*InvoiceRecord) bool) (InvoiceRecord, bool) {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+
+	now := i.clock.Now()
+	i.updateExpired(now)
+	for _, record := range i.records {
+		if record.Status == InvoiceOpen && strings.EqualFold(record.Asset, rcv.Asset) && match(record) {
+			record.Status = InvoiceSettled
+			record.SettledAt = now
+			record.Received = new(big.Int).Set(rcv.Amount)
+			record.Version = rcv.Version
+			record.Channel = hex.EncodeToString(rcv.Channel[:])
+			record.channelID = rcv.Channel
+			return *record, true
+		}
+	}
+	return InvoiceRecord{}, false
+}
+
+// SetReceipt stores the receipt for the payment of the settled invoice with the same ID.
+func (i *Invoices) SetReceipt(receipt Receipt) error {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+
+	for _, record := range i.records {
+		if record.ID != receipt.InvoiceID {
+			continue
+		}
+		if record.Status == InvoiceOpen || record.Status == InvoiceExpired {
+			return errors.New("invoice is not settled")
+		}
+		record.Receipt = &receipt
+		return nil
+	}
+	return errors.New("unknown invoice")
+}
+
+// Receipts returns the receipts for all the settled invoices, in the order of creation of the invoices.
+func (i *Invoices) Receipts() []Receipt {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+
+	receipts := []Receipt{}
+	for _, record := range i.records {
+		if record.Receipt != nil {
+			receipts = append(receipts, *record.Receipt)
+		}
+	}
+	return receipts
+}
+
+// Get returns the invoice with the given ID.
+func (i *Invoices) Get(id InvoiceID) (InvoiceRecord, bool) {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+
+	i.updateExpired(i.clock.Now())
+	for _, record := range i.records {
+		if record.ID == id {
+			return *record, true
+		}
+	}
+	return InvoiceRecord{}, false
+}
+
+// List returns all the invoices in the order of creation.
+func (i *Invoices) List() []InvoiceRecord {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+
+	i.updateExpired(i.clock.Now())
+	records := make([]InvoiceRecord, len(i.records))
+	for j := range i.records {
+		records[j] = *i.records[j]
+	}
+	return records
+}
+
+// updateExpired marks the open invoices that have expired. It should be called with mutex locked.
+func (i *Invoices) updateExpired(now time.Time) {
+	for _, record := range i.records {
+		if record.Status == InvoiceOpen && record.Expired(now) {
+			record.Status = InvoiceExpired
+		}
+	}
+}
+
+// prune removes the invoices that have expired before expiredRetention and, if there are more than maxRecords
+// invoices, the oldest settled or refunded ones. It should be called with mutex locked.
+func (i *Invoices) prune(now time.Time) {
+	i.records = filterRecords(i.records, func(r *InvoiceRecord) bool {
+		return r.Status != InvoiceExpired || now.Sub(r.Expiry) < expiredRetention
+	})
+	excess := len(i.records) - maxRecords
+	i.records = filterRecords(i.records, func(r *InvoiceRecord) bool {
+		if excess > 0 && (r.Status == InvoiceSettled || r.Status == InvoiceRefunded) {
+			excess--
+			return false
+		}
+		return true
+	})
+}
+
+func filterRecords(records []*InvoiceRecord, keep func(*InvoiceRecord) bool) []*InvoiceRecord {
+	kept := make([]*InvoiceRecord, 0, len(records))
+	for _, record := range records {
+		if keep(record) {
+			kept = append(kept, record)
+		}
+	}
+	return kept
+}
@@ -0,0 +1,171 @@
+// Copyright (c) 2020 - for information on the respective copyright owner
+// see the NOTICE file and/or the repository at
+// https://github.com/hyperledger-labs/perun-node
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package payment_test
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger-labs/perun-node/payment"
+)
+
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time { return c.now }
+
+func Test_Invoices(t *testing.T) {
+	setup := func(t *testing.T) (*fakeClock, *payment.Invoices, payment.InvoiceRecord) {
+		clk := &fakeClock{now: time.Unix(1600000000, 0)}
+		invoices := payment.NewInvoices(clk, nil)
+		record, err := invoices.Create(big.NewInt(5), "asset", time.Minute, "memo")
+		require.NoError(t, err)
+		return clk, invoices, record
+	}
+
+	t.Run("happy_settle", func(t *testing.T) {
+		_, invoices, record := setup(t)
+		assert.Equal(t, payment.InvoiceOpen, record.Status)
+
+		_, ok := invoices.Settle(context.Background(), payment.Received{Asset: "asset", Amount: big.NewInt(4), Version: 2})
+		assert.False(t, ok, "amount does not match")
+		settled, ok := invoices.Settle(context.Background(),
+			payment.Received{Asset: "ASSET", Amount: big.NewInt(5), Version: 3})
+		require.True(t, ok)
+		assert.Equal(t, record.ID, settled.ID)
+		assert.Equal(t, payment.InvoiceSettled, settled.Status)
+		assert.EqualValues(t, 3, settled.Version)
+
+		_, ok = invoices.Settle(context.Background(), payment.Received{Asset: "asset", Amount: big.NewInt(5)})
+		assert.False(t, ok, "invoice is already settled")
+		got, ok := invoices.Get(record.ID)
+		require.True(t, ok)
+		assert.Equal(t, payment.InvoiceSettled, got.Status)
+	})
+
+	t.Run("happy_receipt", func(t *testing.T) {
+		_, invoices, record := setup(t)
+		receipt := payment.Receipt{InvoiceID: record.ID, Amount: big.NewInt(5)}
+		assert.Error(t, invoices.SetReceipt(receipt), "invoice is not settled")
+
+		_, ok := invoices.Settle(context.Background(), payment.Received{Asset: "asset", Amount: big.NewInt(5)})
+		require.True(t, ok)
+		require.NoError(t, invoices.SetReceipt(receipt))
+		assert.Equal(t, []payment.Receipt{receipt}, invoices.Receipts())
+		assert.Error(t, invoices.SetReceipt(payment.Receipt{}), "unknown invoice")
+	})
+
+	t.Run("happy_expired", func(t *testing.T) {
+		clk, invoices, _ := setup(t)
+		clk.now = clk.now.Add(time.Minute)
+		_, ok := invoices.Settle(context.Background(), payment.Received{Asset: "asset", Amount: big.NewInt(5)})
+		assert.False(t, ok)
+		list := invoices.List()
+		require.Len(t, list, 1)
+		assert.Equal(t, payment.InvoiceExpired, list[0].Status)
+	})
+
+	t.Run("happy_unique_id", func(t *testing.T) {
+		_, invoices, record := setup(t)
+		other, err := invoices.Create(big.NewInt(5), "asset", time.Minute, "memo")
+		require.NoError(t, err)
+		assert.NotEqual(t, record.ID, other.ID)
+		assert.NotEqual(t, payment.InvoiceID{}, other.ID)
+	})
+
+	t.Run("happy_unique_amount", func(t *testing.T) {
+		_, invoices, record := setup(t)
+		other, err := invoices.Create(big.NewInt(5), "asset", time.Minute, "memo")
+		require.NoError(t, err)
+		assert.EqualValues(t, 6, other.Amount.Int64())
+		assert.EqualValues(t, 5, other.Requested.Int64())
+		assert.Nil(t, record.Requested)
+
+		settled, ok := invoices.Settle(context.Background(), payment.Received{Asset: "asset", Amount: big.NewInt(6)})
+		require.True(t, ok)
+		assert.Equal(t, other.ID, settled.ID)
+		got, ok := invoices.Get(record.ID)
+		require.True(t, ok)
+		assert.Equal(t, payment.InvoiceOpen, got.Status)
+	})
+
+	t.Run("happy_pruned", func(t *testing.T) {
+		clk, invoices, record := setup(t)
+		clk.now = clk.now.Add(time.Minute + time.Hour)
+		_, err := invoices.Create(big.NewInt(5), "asset", time.Minute, "memo")
+		require.NoError(t, err)
+		_, ok := invoices.Get(record.ID)
+		assert.False(t, ok, "expired invoice should be removed")
+	})
+
+	t.Run("happy_fiat", func(t *testing.T) {
+		clk := &fakeClock{now: time.Unix(1600000000, 0)}
+		conv := &payment.Converter{Oracle: fakeOracle{"EUR": big.NewRat(2, 1)}, Decimals: 2, Tolerance: 0.01}
+		invoices := payment.NewInvoices(clk, conv)
+		fiat, err := payment.ParseFiat("5 EUR")
+		require.NoError(t, err)
+		record, err := invoices.CreateFiat(fiat, "asset", time.Minute, "")
+		require.NoError(t, err)
+		assert.Equal(t, "5 EUR", record.Fiat)
+
+		// 5 EUR is worth 2.5 units of the asset, i.e. 250 in smallest unit. Tolerance is 2.5.
+		_, ok := invoices.Settle(context.Background(), payment.Received{Asset: "asset", Amount: big.NewInt(247)})
+		assert.False(t, ok)
+		settled, ok := invoices.Settle(context.Background(), payment.Received{Asset: "asset", Amount: big.NewInt(248)})
+		require.True(t, ok)
+		assert.Equal(t, record.ID, settled.ID)
+		assert.EqualValues(t, 248, settled.Received.Int64())
+
+		_, err = invoices.CreateFiat(fiat, "asset", time.Minute, "")
+		require.NoError(t, err)
+		_, err = invoices.CreateFiat(fiat, "asset", time.Minute, "")
+		assert.Error(t, err, "open invoice for the same fiat amount")
+		other, err := payment.ParseFiat("5.01 EUR")
+		require.NoError(t, err)
+		_, err = invoices.CreateFiat(other, "asset", time.Minute, "")
+		require.NoError(t, err)
+		_, ok = invoices.Settle(context.Background(), payment.Received{Asset: "asset", Amount: big.NewInt(250)})
+		assert.False(t, ok, "payment accepted by both invoices cannot be attributed")
+	})
+
+	t.Run("err_invalid", func(t *testing.T) {
+		_, invoices, _ := setup(t)
+		_, err := invoices.Create(big.NewInt(0), "asset", time.Minute, "")
+		assert.Error(t, err)
+		_, err = invoices.Create(big.NewInt(1), "asset", 0, "")
+		assert.Error(t, err)
+		_, err = invoices.CreateFiat(payment.FiatAmount{Value: big.NewRat(1, 1), Currency: "EUR"}, "asset",
+			time.Minute, "")
+		assert.Error(t, err, "fiat oracle is not configured")
+	})
+
+	t.Run("err_too_many_open", func(t *testing.T) {
+		_, invoices, _ := setup(t)
+		for i := 1; i < payment.MaxOpenInvoices; i++ {
+			_, err := invoices.Create(big.NewInt(5), "asset", time.Minute, "")
+			require.NoError(t, err)
+		}
+		_, err := invoices.Create(big.NewInt(5), "asset", time.Minute, "")
+		assert.Error(t, err)
+	})
+}
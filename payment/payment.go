@@ -0,0 +1,138 @@
+// Copyright (c) 2020 - for information on the respective copyright owner
+// see the NOTICE file and/or the repository at
+// https://github.com/hyperledger-labs/perun-node
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package payment
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/pkg/errors"
+	"perun.network/go-perun/channel"
+	"perun.network/go-perun/client"
+	"perun.network/go-perun/wire"
+)
+
+// assetIdx is the index of the asset used for payments.
+const assetIdx = 0
+
+// Channel is the subset of methods of a go-perun channel used for payments.
+type Channel interface {
+	Idx() channel.Index
+	State() *channel.State
+	Update(ctx context.Context, up client.ChannelUpdate) error
+	Peers() []wire.Address
+}
+
+// Received is a payment received on a channel.
+type Received struct {
+	Channel channel.ID
+	Asset   string
+	Amount  *big.Int
+	Version uint64 // Version of the channel state after the payment.
+}
+
+// ErrInsufficientBalance is returned when the balance of the payer is less than the amount to be paid.
+type ErrInsufficientBalance struct {
+	Balance *big.Int
+	Amount  *big.Int
+}
+
+func (e ErrInsufficientBalance) Error() string {
+	return fmt.Sprintf("insufficient balance %v for paying %v", e.Balance, e.Amount)
+}
+
+// Pay pays the amount to the peer in the two party channel.
+func Pay(ctx context.Context, ch Channel, amount *big.Int) error {
+	state, err := transfer(ch.State(), ch.Idx(), amount)
+	if err != nil {
+		return err
+	}
+	return errors.WithMessage(ch.Update(ctx, client.ChannelUpdate{State: state, ActorIdx: ch.Idx()}), "sending payment")
+}
+
+// ValidateIncoming checks if the next state is a valid payment from the peer to self in the two party channel,
+// with the current state. If so, the amount received is returned.
+func ValidateIncoming(current, next *channel.State, self channel.Index) (*big.Int, error) {
+	if err := checkTwoParty(current); err != nil {
+		return nil, err
+	}
+	if len(next.Balances) != len(current.Balances) || len(next.Balances[assetIdx]) != 2 {
+		return nil, errors.New("allocation should not change in a payment")
+	}
+	amount := new(big.Int).Sub(next.Balances[assetIdx][self], current.Balances[assetIdx][self])
+	if amount.Sign() <= 0 {
+		return nil, errors.New("amount received should be positive")
+	}
+	expected, err := transfer(current, 1-self, amount)
+	if err != nil {
+		return nil, err
+	}
+	if err = expected.Equal(next); err != nil {
+		return nil, errors.WithMessage(err, "state should not change other than the balances in a payment")
+	}
+	return amount, nil
+}
+
+// transfer returns the next state with the amount transferred from the payer to the other participant.
+func transfer(current *channel.State, payer channel.Index, amount *big.Int) (*channel.State, error) {
+	if err := checkTwoParty(current); err != nil {
+		return nil, err
+	}
+	if amount.Sign() <= 0 {
+		return nil, errors.New("amount should be positive")
+	}
+	balance := current.Balances[assetIdx][payer]
+	if balance.Cmp(amount) < 0 {
+		return nil, ErrInsufficientBalance{Balance: new(big.Int).Set(balance), Amount: amount}
+	}
+
+	next := current.Clone()
+	bals := next.Balances[assetIdx]
+	bals[payer] = new(big.Int).Sub(bals[payer], amount)
+	bals[1-payer] = new(big.Int).Add(bals[1-payer], amount)
+	next.Version++
+	return next, nil
+}
+
+// Peer returns the off-chain address of the peer in the two party channel.
+func Peer(ch Channel) string {
+	peers := ch.Peers()
+	if len(peers) != 2 {
+		return ""
+	}
+	return peers[1-ch.Idx()].String()
+}
+
+// Asset returns the asset used for payments in the channel with the given state. It is empty, if the channel
+// has no assets.
+func Asset(state *channel.State) string {
+	if len(state.Assets) == 0 {
+		return ""
+	}
+	return fmt.Sprint(state.Assets[assetIdx])
+}
+
+func checkTwoParty(state *channel.State) error {
+	if state.IsFinal {
+		return errors.New("channel is finalized")
+	}
+	if len(state.Balances) == 0 || len(state.Balances[assetIdx]) != 2 {
+		return errors.New("payments are supported only on two party channels")
+	}
+	return nil
+}
@@ -0,0 +1,145 @@
+// Copyright (c) 2020 - for information on the respective copyright owner
+// see the NOTICE file and/or the repository at
+// https://github.com/hyperledger-labs/perun-node
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package payment_test
+
+import (
+	"context"
+	"math/big"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	simwallet "perun.network/go-perun/backend/sim/wallet"
+	"perun.network/go-perun/channel"
+	"perun.network/go-perun/client"
+	"perun.network/go-perun/wire"
+
+	"github.com/hyperledger-labs/perun-node/payment"
+)
+
+// fakeChannel is a channel that applies all updates locally.
+type fakeChannel struct {
+	idx   channel.Index
+	state *channel.State
+	peers []wire.Address
+	err   error
+}
+
+func (ch *fakeChannel) Idx() channel.Index              { return ch.idx }
+func (ch *fakeChannel) Peers() []wire.Address           { return ch.peers }
+func (ch *fakeChannel) State() *channel.State           { return ch.state.Clone() }
+func (ch *fakeChannel) balance(idx channel.Index) int64 { return ch.state.Balances[0][idx].Int64() }
+
+func (ch *fakeChannel) Update(_ context.Context, up client.ChannelUpdate) error {
+	if ch.err != nil {
+		return ch.err
+	}
+	ch.state = up.State
+	return nil
+}
+
+func newTestState(t *testing.T, bals ...int64) *channel.State {
+	rng := rand.New(rand.NewSource(1729))
+	state := &channel.State{
+		App:  channel.NewMockApp(simwallet.NewRandomAddress(rng)),
+		Data: channel.NewMockOp(channel.OpValid),
+	}
+	_, err := rng.Read(state.ID[:])
+	require.NoError(t, err)
+	state.Balances = [][]*big.Int{make([]*big.Int, len(bals))}
+	for i := range bals {
+		state.Balances[0][i] = big.NewInt(bals[i])
+	}
+	return state
+}
+
+func Test_Pay(t *testing.T) {
+	t.Run("happy", func(t *testing.T) {
+		ch := &fakeChannel{idx: 1, state: newTestState(t, 10, 10)}
+		require.NoError(t, payment.Pay(context.Background(), ch, big.NewInt(3)))
+		assert.EqualValues(t, 13, ch.balance(0))
+		assert.EqualValues(t, 7, ch.balance(1))
+		assert.EqualValues(t, 1, ch.state.Version)
+	})
+
+	t.Run("err_insufficient_balance", func(t *testing.T) {
+		ch := &fakeChannel{idx: 0, state: newTestState(t, 2, 10)}
+		err := payment.Pay(context.Background(), ch, big.NewInt(3))
+		require.Error(t, err)
+		assert.IsType(t, payment.ErrInsufficientBalance{}, err)
+		assert.EqualValues(t, 2, ch.balance(0))
+	})
+
+	t.Run("err_non_positive_amount", func(t *testing.T) {
+		ch := &fakeChannel{idx: 0, state: newTestState(t, 10, 10)}
+		assert.Error(t, payment.Pay(context.Background(), ch, big.NewInt(0)))
+	})
+
+	t.Run("err_multi_party", func(t *testing.T) {
+		ch := &fakeChannel{idx: 0, state: newTestState(t, 10, 10, 10)}
+		assert.Error(t, payment.Pay(context.Background(), ch, big.NewInt(1)))
+	})
+
+	t.Run("err_update", func(t *testing.T) {
+		ch := &fakeChannel{idx: 0, state: newTestState(t, 10, 10), err: assert.AnError}
+		assert.Error(t, payment.Pay(context.Background(), ch, big.NewInt(1)))
+	})
+}
+
+func Test_ValidateIncoming(t *testing.T) {
+	pay := func(t *testing.T, payer channel.Index, amount int64) (current, next *channel.State) {
+		ch := &fakeChannel{idx: payer, state: newTestState(t, 10, 10)}
+		current = ch.State()
+		require.NoError(t, payment.Pay(context.Background(), ch, big.NewInt(amount)))
+		return current, ch.State()
+	}
+
+	t.Run("happy", func(t *testing.T) {
+		current, next := pay(t, 0, 4)
+		amount, err := payment.ValidateIncoming(current, next, 1)
+		require.NoError(t, err)
+		assert.EqualValues(t, 4, amount.Int64())
+	})
+
+	t.Run("err_payment_by_self", func(t *testing.T) {
+		current, next := pay(t, 1, 4)
+		_, err := payment.ValidateIncoming(current, next, 1)
+		assert.Error(t, err)
+	})
+
+	t.Run("err_version_skipped", func(t *testing.T) {
+		current, next := pay(t, 0, 4)
+		next.Version++
+		_, err := payment.ValidateIncoming(current, next, 1)
+		assert.Error(t, err)
+	})
+
+	t.Run("err_final", func(t *testing.T) {
+		current, next := pay(t, 0, 4)
+		next.IsFinal = true
+		_, err := payment.ValidateIncoming(current, next, 1)
+		assert.Error(t, err)
+	})
+
+	t.Run("err_money_created", func(t *testing.T) {
+		current, next := pay(t, 0, 4)
+		next.Balances[0][0] = big.NewInt(10)
+		_, err := payment.ValidateIncoming(current, next, 1)
+		assert.Error(t, err)
+	})
+}
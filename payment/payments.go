@@ -0,0 +1,131 @@
+// Copyright (c) 2020 - for information on the respective copyright owner
+// see the NOTICE file and/or the repository at
+// https://github.com/hyperledger-labs/perun-node
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package payment
+
+import (
+	"encoding/hex"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"perun.network/go-perun/wallet"
+
+	"github.com/hyperledger-labs/perun-node/clock"
+)
+
+// PaidInvoice is an invoice paid by the node, along with the receipt from the payee, once it is received.
+type PaidInvoice struct {
+	Invoice
+	Channel string    `json:"channel"` // ID (as hex string) of the channel over which the invoice was paid.
+	Paid    *big.Int  `json:"paid"`    // Amount paid, it is the converted amount for invoices in fiat.
+	Payee   string    `json:"payee"`   // Off-chain address of the payee.
+	PaidAt  time.Time `json:"paidAt"`
+	Receipt *Receipt  `json:"receipt,omitempty"`
+
+	payee wallet.Address
+}
+
+// Payments holds the invoices paid by the node and the receipts for them.
+//
+// Paid invoices are held in memory and are lost when the node is restarted. The oldest ones are removed when
+// there are more than maxRecords.
+type Payments struct {
+	clock clock.Clock
+
+	mutex   sync.Mutex
+	records []*PaidInvoice
+}
+
+// NewPayments returns an empty set of paid invoices, that uses the clock for the time of payments.
+func NewPayments(clk clock.Clock) *Payments {
+	return &Payments{clock: clk}
+}
+
+// Add records the payment of the amount for the invoice, over the two party channel.
+func (p *Payments) Add(inv Invoice, ch Channel, amount *big.Int) PaidInvoice {
+	id := ch.State().ID
+	record := &PaidInvoice{
+		Invoice: inv,
+		Channel: hex.EncodeToString(id[:]),
+		Paid:    new(big.Int).Set(amount),
+		PaidAt:  p.clock.Now(),
+	}
+	if peers := ch.Peers(); len(peers) == 2 {
+		record.payee = peers[1-ch.Idx()]
+		record.Payee = record.payee.String()
+	}
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.records = append(p.records, record)
+	if excess := len(p.records) - maxRecords; excess > 0 {
+		p.records = p.records[excess:]
+	}
+	return *record
+}
+
+// SetReceipt stores the receipt for the paid invoice with the same ID, if it is for the channel and
+// amount of the payment and is signed by the payee.
+func (p *Payments) SetReceipt(receipt Receipt) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	for j := len(p.records) - 1; j >= 0; j-- {
+		record := p.records[j]
+		if record.ID != receipt.InvoiceID || record.Receipt != nil {
+			continue
+		}
+		if record.Channel != receipt.Channel || receipt.Amount == nil || record.Paid.Cmp(receipt.Amount) != 0 {
+			return errors.New("receipt does not match the payment")
+		}
+		if record.payee == nil {
+			return errors.New("payee of the payment is unknown")
+		}
+		if err := receipt.Verify(record.payee); err != nil {
+			return err
+		}
+		record.Receipt = &receipt
+		return nil
+	}
+	return errors.New("no payment without receipt for the invoice")
+}
+
+// Get returns the latest payment of the invoice with the given ID.
+func (p *Payments) Get(id InvoiceID) (PaidInvoice, bool) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	for j := len(p.records) - 1; j >= 0; j-- {
+		if p.records[j].ID == id {
+			return *p.records[j], true
+		}
+	}
+	return PaidInvoice{}, false
+}
+
+// List returns all the paid invoices in the order of payment.
+func (p *Payments) List() []PaidInvoice {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	records := make([]PaidInvoice, len(p.records))
+	for j := range p.records {
+		records[j] = *p.records[j]
+	}
+	return records
+}
@@ -0,0 +1,88 @@
+// Copyright (c) 2020 - for information on the respective copyright owner
+// see the NOTICE file and/or the repository at
+// https://github.com/hyperledger-labs/perun-node
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package payment_test
+
+import (
+	"encoding/hex"
+	"math/big"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	simwallet "perun.network/go-perun/backend/sim/wallet"
+	"perun.network/go-perun/wire"
+
+	"github.com/hyperledger-labs/perun-node/payment"
+)
+
+func Test_Payments(t *testing.T) {
+	rng := rand.New(rand.NewSource(1729))
+	payer, payee := simwallet.NewRandomAccount(rng), simwallet.NewRandomAccount(rng)
+	inv := newTestInvoice()
+
+	setup := func(t *testing.T) (*payment.Payments, payment.Received) {
+		ch := &fakeChannel{
+			idx:   0,
+			state: newTestState(t, 10, 10),
+			peers: []wire.Address{payer.Address(), payee.Address()},
+		}
+		payments := payment.NewPayments(&fakeClock{now: time.Unix(1600000000, 0)})
+		paid := payments.Add(inv, ch, big.NewInt(5))
+		assert.Equal(t, hex.EncodeToString(ch.state.ID[:]), paid.Channel)
+		assert.Equal(t, payee.Address().String(), paid.Payee)
+		return payments, payment.Received{Channel: ch.state.ID, Amount: big.NewInt(5), Version: 1}
+	}
+
+	t.Run("happy", func(t *testing.T) {
+		payments, rcv := setup(t)
+		receipt, err := payment.NewReceipt(payee, inv.ID, rcv)
+		require.NoError(t, err)
+		require.NoError(t, payments.SetReceipt(receipt))
+
+		paid, ok := payments.Get(inv.ID)
+		require.True(t, ok)
+		assert.Equal(t, &receipt, paid.Receipt)
+		assert.Len(t, payments.List(), 1)
+		assert.Error(t, payments.SetReceipt(receipt), "already has a receipt")
+	})
+
+	t.Run("err_other_signer", func(t *testing.T) {
+		payments, rcv := setup(t)
+		receipt, err := payment.NewReceipt(payer, inv.ID, rcv)
+		require.NoError(t, err)
+		assert.Error(t, payments.SetReceipt(receipt))
+	})
+
+	t.Run("err_other_amount", func(t *testing.T) {
+		payments, rcv := setup(t)
+		rcv.Amount = big.NewInt(4)
+		receipt, err := payment.NewReceipt(payee, inv.ID, rcv)
+		require.NoError(t, err)
+		assert.Error(t, payments.SetReceipt(receipt))
+	})
+
+	t.Run("err_unknown_invoice", func(t *testing.T) {
+		payments, rcv := setup(t)
+		receipt, err := payment.NewReceipt(payee, payment.InvoiceID{9}, rcv)
+		require.NoError(t, err)
+		assert.Error(t, payments.SetReceipt(receipt))
+		_, ok := payments.Get(payment.InvoiceID{9})
+		assert.False(t, ok)
+	})
+}
@@ -0,0 +1,127 @@
+// Copyright (c) 2020 - for information on the respective copyright owner
+// see the NOTICE file and/or the repository at
+// https://github.com/hyperledger-labs/perun-node
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package payment
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"math/big"
+
+	"github.com/pkg/errors"
+	perunio "perun.network/go-perun/pkg/io"
+	"perun.network/go-perun/wallet"
+	"perun.network/go-perun/wire"
+)
+
+// receiptDomain is prefixed to the data signed in receipts, so that the signatures cannot be used in other contexts.
+const receiptDomain = "perun-node payment receipt"
+
+// ReceiptMsgType is the type of the off-chain message used by the payee for delivering a receipt to the payer.
+// Types from 128 are used for the messages of perun-node, so that they do not collide with the types added to the
+// go-perun wire protocol.
+const ReceiptMsgType wire.Type = 128
+
+func init() {
+	wire.RegisterExternalDecoder(ReceiptMsgType, decodeReceiptMsg, "Receipt")
+}
+
+// Receipt is a proof of payment signed by the payee. It binds the invoice paid (identified by its ID),
+// the amount and the version of the channel state after the payment.
+type Receipt struct {
+	InvoiceID InvoiceID `json:"invoiceId"`
+	Channel   string    `json:"channel"` // Channel ID as hex string.
+	Amount    *big.Int  `json:"amount"`
+	Version   uint64    `json:"version"`
+	Payee     string    `json:"payee"` // Off-chain address of the payee.
+	Signature []byte    `json:"signature"`
+}
+
+// NewReceipt returns a receipt for the payment of the invoice with the ID, signed by the payee.
+func NewReceipt(payee wallet.Account, id InvoiceID, rcv Received) (Receipt, error) {
+	r := Receipt{
+		InvoiceID: id,
+		Channel:   hex.EncodeToString(rcv.Channel[:]),
+		Amount:    new(big.Int).Set(rcv.Amount),
+		Version:   rcv.Version,
+		Payee:     payee.Address().String(),
+	}
+	sig, err := payee.SignData(r.signedData())
+	if err != nil {
+		return Receipt{}, errors.WithMessage(err, "signing receipt")
+	}
+	r.Signature = sig
+	return r, nil
+}
+
+// Verify checks if the receipt is signed by the payee.
+func (r Receipt) Verify(payee wallet.Address) error {
+	if r.Payee != payee.String() {
+		return errors.New("receipt is issued by a different payee")
+	}
+	ok, err := wallet.VerifySignature(r.signedData(), r.Signature, payee)
+	if err != nil {
+		return errors.WithMessage(err, "verifying signature")
+	}
+	if !ok {
+		return errors.New("invalid signature")
+	}
+	return nil
+}
+
+func (r Receipt) signedData() []byte {
+	var buf bytes.Buffer
+	writeField(&buf, []byte(receiptDomain))
+	buf.Write(r.InvoiceID[:])
+	writeField(&buf, []byte(r.Channel))
+	writeField(&buf, r.Amount.Bytes())
+	writeUvarint(&buf, r.Version)
+	writeField(&buf, []byte(r.Payee))
+	return buf.Bytes()
+}
+
+// ReceiptMsg is the off-chain message for delivering a receipt to the payer.
+type ReceiptMsg struct {
+	Receipt Receipt
+}
+
+// Type returns the type of the message.
+func (ReceiptMsg) Type() wire.Type {
+	return ReceiptMsgType
+}
+
+// Encode encodes the receipt as JSON.
+func (m ReceiptMsg) Encode(w io.Writer) error {
+	data, err := json.Marshal(m.Receipt)
+	if err != nil {
+		return errors.Wrap(err, "encoding receipt")
+	}
+	return perunio.Encode(w, string(data))
+}
+
+func decodeReceiptMsg(r io.Reader) (wire.Msg, error) {
+	var data string
+	if err := perunio.Decode(r, &data); err != nil {
+		return nil, err
+	}
+	var m ReceiptMsg
+	if err := json.Unmarshal([]byte(data), &m.Receipt); err != nil || m.Receipt.Amount == nil {
+		return nil, errors.New("invalid receipt")
+	}
+	return &m, nil
+}
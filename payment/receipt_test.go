@@ -0,0 +1,74 @@
+// Copyright (c) 2020 - for information on the respective copyright owner
+// see the NOTICE file and/or the repository at
+// https://github.com/hyperledger-labs/perun-node
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package payment_test
+
+import (
+	"bytes"
+	"math/big"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	simwallet "perun.network/go-perun/backend/sim/wallet"
+	"perun.network/go-perun/wire"
+
+	"github.com/hyperledger-labs/perun-node/payment"
+)
+
+func Test_Receipt(t *testing.T) {
+	rng := rand.New(rand.NewSource(1729))
+	payee := simwallet.NewRandomAccount(rng)
+	rcv := payment.Received{Channel: [32]byte{1}, Amount: big.NewInt(5), Version: 3}
+
+	t.Run("happy", func(t *testing.T) {
+		receipt, err := payment.NewReceipt(payee, payment.InvoiceID{2}, rcv)
+		require.NoError(t, err)
+		assert.EqualValues(t, 3, receipt.Version)
+		assert.NoError(t, receipt.Verify(payee.Address()))
+	})
+
+	t.Run("err_tampered", func(t *testing.T) {
+		receipt, err := payment.NewReceipt(payee, payment.InvoiceID{2}, rcv)
+		require.NoError(t, err)
+		receipt.Amount = big.NewInt(50)
+		assert.Error(t, receipt.Verify(payee.Address()))
+	})
+
+	t.Run("err_other_payee", func(t *testing.T) {
+		receipt, err := payment.NewReceipt(payee, payment.InvoiceID{2}, rcv)
+		require.NoError(t, err)
+		assert.Error(t, receipt.Verify(simwallet.NewRandomAddress(rng)))
+	})
+}
+
+func Test_ReceiptMsg(t *testing.T) {
+	rng := rand.New(rand.NewSource(1729))
+	payee := simwallet.NewRandomAccount(rng)
+	receipt, err := payment.NewReceipt(payee, payment.InvoiceID{2},
+		payment.Received{Channel: [32]byte{1}, Amount: big.NewInt(5), Version: 3})
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, wire.Encode(&payment.ReceiptMsg{Receipt: receipt}, &buf))
+	msg, err := wire.Decode(&buf)
+	require.NoError(t, err)
+	require.IsType(t, &payment.ReceiptMsg{}, msg)
+	got := msg.(*payment.ReceiptMsg).Receipt
+	assert.Equal(t, receipt, got)
+	assert.NoError(t, got.Verify(payee.Address()))
+}
@@ -0,0 +1,84 @@
+// Copyright (c) 2020 - for information on the respective copyright owner
+// see the NOTICE file and/or the repository at
+// https://github.com/hyperledger-labs/perun-node
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package payment
+
+import (
+	"context"
+	"encoding/hex"
+	"math/big"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Refund is the refund of the payment for an invoice, paid back to the payer over the channel that settled
+// the invoice.
+type Refund struct {
+	Amount  *big.Int  `json:"amount"`
+	Version uint64    `json:"version"` // Version of the channel state after the refund.
+	Reason  string    `json:"reason"`
+	At      time.Time `json:"at"`
+}
+
+// Refund pays back the amount received for the settled invoice with the ID to the payer, over the channel
+// that settled the invoice, and records it in the invoice. An invoice can be refunded only once.
+//
+// The refund is a payment like any other and is not linked to the invoice on the payer's side. It is limited by
+// the budget, if it is not nil.
+func (i *Invoices) Refund(ctx context.Context, id InvoiceID, lookup ChannelLookup, budget *Budget, reason string) (
+	InvoiceRecord, error) {
+	record, err := i.startRefund(id)
+	if err != nil {
+		return InvoiceRecord{}, err
+	}
+	ch, err := lookup(record.channelID)
+	if err == nil {
+		err = budget.Pay(ctx, ch, "refund:"+hex.EncodeToString(id[:]), record.Received)
+	}
+
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+	if err != nil {
+		record.Status = InvoiceSettled
+		return InvoiceRecord{}, errors.WithMessage(err, "paying refund")
+	}
+	record.Status = InvoiceRefunded
+	record.Refund = &Refund{
+		Amount:  new(big.Int).Set(record.Received),
+		Version: ch.State().Version,
+		Reason:  reason,
+		At:      i.clock.Now(),
+	}
+	return *record, nil
+}
+
+// startRefund marks the settled invoice as being refunded, so that it is not refunded concurrently.
+func (i *Invoices) startRefund(id InvoiceID) (*InvoiceRecord, error) {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+	for _, record := range i.records {
+		if record.ID != id {
+			continue
+		}
+		if record.Status != InvoiceSettled {
+			return nil, errors.Errorf("invoice is %s, only settled invoices can be refunded", record.Status)
+		}
+		record.Status = InvoiceRefunding
+		return record, nil
+	}
+	return nil, errors.New("unknown invoice")
+}
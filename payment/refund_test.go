@@ -0,0 +1,95 @@
+// Copyright (c) 2020 - for information on the respective copyright owner
+// see the NOTICE file and/or the repository at
+// https://github.com/hyperledger-labs/perun-node
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package payment_test
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"perun.network/go-perun/channel"
+
+	"github.com/hyperledger-labs/perun-node/payment"
+)
+
+func Test_Invoices_Refund(t *testing.T) {
+	ctx := context.Background()
+	setup := func(t *testing.T, balance int64) (
+		*fakeChannel, payment.ChannelLookup, *payment.Invoices, payment.InvoiceID) {
+		ch := &fakeChannel{idx: 1, state: newTestState(t, 0, balance)}
+		lookup := func(id channel.ID) (payment.Channel, error) {
+			if id != ch.state.ID {
+				return nil, assert.AnError
+			}
+			return ch, nil
+		}
+		invoices := payment.NewInvoices(&fakeClock{now: time.Unix(1600000000, 0)}, nil)
+		record, err := invoices.Create(big.NewInt(5), "", time.Minute, "")
+		require.NoError(t, err)
+		_, ok := invoices.Settle(context.Background(),
+			payment.Received{Channel: ch.state.ID, Amount: big.NewInt(5), Version: 1})
+		require.True(t, ok)
+		return ch, lookup, invoices, record.ID
+	}
+
+	t.Run("happy", func(t *testing.T) {
+		ch, lookup, invoices, id := setup(t, 5)
+		record, err := invoices.Refund(ctx, id, lookup, nil, "out of stock")
+		require.NoError(t, err)
+		assert.Equal(t, payment.InvoiceRefunded, record.Status)
+		require.NotNil(t, record.Refund)
+		assert.Equal(t, "out of stock", record.Refund.Reason)
+		assert.EqualValues(t, 1, record.Refund.Version)
+		assert.EqualValues(t, 5, ch.balance(0))
+
+		_, err = invoices.Refund(ctx, id, lookup, nil, "")
+		assert.Error(t, err, "already refunded")
+	})
+
+	t.Run("err_payment_failed", func(t *testing.T) {
+		_, lookup, invoices, id := setup(t, 4)
+		_, err := invoices.Refund(ctx, id, lookup, nil, "")
+		assert.Error(t, err)
+		got, ok := invoices.Get(id)
+		require.True(t, ok)
+		assert.Equal(t, payment.InvoiceSettled, got.Status, "should be refundable again")
+	})
+
+	t.Run("err_budget_exceeded", func(t *testing.T) {
+		ch, lookup, invoices, id := setup(t, 5)
+		budget, err := payment.NewBudget(&fakeClock{now: time.Now()},
+			[]payment.Limit{{Period: payment.Daily, Amount: big.NewInt(4)}}, nil)
+		require.NoError(t, err)
+		_, err = invoices.Refund(ctx, id, lookup, budget, "")
+		assert.IsType(t, payment.ErrBudgetExceeded{}, errors.Cause(err))
+		assert.EqualValues(t, 0, ch.balance(0))
+	})
+
+	t.Run("err_not_settled", func(t *testing.T) {
+		_, lookup, invoices, _ := setup(t, 5)
+		record, err := invoices.Create(big.NewInt(5), "", time.Minute, "")
+		require.NoError(t, err)
+		_, err = invoices.Refund(ctx, record.ID, lookup, nil, "")
+		assert.Error(t, err)
+		_, err = invoices.Refund(ctx, payment.InvoiceID{}, lookup, nil, "")
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,92 @@
+// Copyright (c) 2020 - for information on the respective copyright owner
+// see the NOTICE file and/or the repository at
+// https://github.com/hyperledger-labs/perun-node
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package payment
+
+import (
+	"context"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// ErrLimitReached is returned when paying would exceed the limit authorized for the stream.
+var ErrLimitReached = errors.New("limit authorized for the stream is reached")
+
+// Stream pays a fixed amount to the peer in a channel, either per interval of time (see Run) or per unit of
+// usage (see Charge). The total amount paid is capped by the limit (if any).
+type Stream struct {
+	ch     Channel
+	amount *big.Int
+	limit  *big.Int
+	budget *Budget // Budget limiting the payments, nil if there is none.
+	ref    string  // Reference for the payments in the budget.
+
+	mutex sync.Mutex
+	paid  *big.Int
+}
+
+// NewStream returns a stream that pays the amount per interval or unit of usage over the channel.
+// If limit is nil, there is no limit on the total amount paid.
+func NewStream(ch Channel, amount, limit *big.Int) (*Stream, error) {
+	if amount.Sign() <= 0 {
+		return nil, errors.New("amount should be positive")
+	}
+	return &Stream{ch: ch, amount: amount, limit: limit, paid: new(big.Int)}, nil
+}
+
+// Run pays the amount at each interval, until the context is canceled or a payment fails. It returns
+// ErrLimitReached if the limit is reached and nil if the context is canceled.
+func (s *Stream) Run(ctx context.Context, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+		if err := s.Charge(ctx, 1); err != nil {
+			return err
+		}
+	}
+}
+
+// Charge pays the amount for the given units of usage (such as the number of messages or API calls).
+// Charges are sent one at a time.
+func (s *Stream) Charge(ctx context.Context, units int64) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	total := new(big.Int).Mul(s.amount, big.NewInt(units))
+	if s.limit != nil && new(big.Int).Add(s.paid, total).Cmp(s.limit) > 0 {
+		return ErrLimitReached
+	}
+	if err := s.budget.Pay(ctx, s.ch, s.ref, total); err != nil {
+		return err
+	}
+	s.paid.Add(s.paid, total)
+	return nil
+}
+
+// Paid returns the total amount paid over the stream.
+func (s *Stream) Paid() *big.Int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return new(big.Int).Set(s.paid)
+}
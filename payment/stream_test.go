@@ -0,0 +1,73 @@
+// Copyright (c) 2020 - for information on the respective copyright owner
+// see the NOTICE file and/or the repository at
+// https://github.com/hyperledger-labs/perun-node
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package payment_test
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger-labs/perun-node/payment"
+)
+
+func Test_Stream(t *testing.T) {
+	t.Run("happy_charge", func(t *testing.T) {
+		ch := &fakeChannel{idx: 0, state: newTestState(t, 10, 0)}
+		stream, err := payment.NewStream(ch, big.NewInt(2), nil)
+		require.NoError(t, err)
+		require.NoError(t, stream.Charge(context.Background(), 3))
+		require.NoError(t, stream.Charge(context.Background(), 1))
+		assert.EqualValues(t, 8, stream.Paid().Int64())
+		assert.EqualValues(t, 8, ch.balance(1))
+	})
+
+	t.Run("happy_run_until_limit", func(t *testing.T) {
+		ch := &fakeChannel{idx: 0, state: newTestState(t, 10, 0)}
+		stream, err := payment.NewStream(ch, big.NewInt(2), big.NewInt(5))
+		require.NoError(t, err)
+		assert.Equal(t, payment.ErrLimitReached, stream.Run(context.Background(), time.Millisecond))
+		assert.EqualValues(t, 4, stream.Paid().Int64())
+		assert.EqualValues(t, 6, ch.balance(0))
+	})
+
+	t.Run("happy_run_until_canceled", func(t *testing.T) {
+		ch := &fakeChannel{idx: 0, state: newTestState(t, 10, 0)}
+		stream, err := payment.NewStream(ch, big.NewInt(1), nil)
+		require.NoError(t, err)
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		assert.NoError(t, stream.Run(ctx, time.Hour))
+		assert.Zero(t, stream.Paid().Int64())
+	})
+
+	t.Run("err_run_insufficient_balance", func(t *testing.T) {
+		ch := &fakeChannel{idx: 0, state: newTestState(t, 3, 0)}
+		stream, err := payment.NewStream(ch, big.NewInt(2), nil)
+		require.NoError(t, err)
+		assert.IsType(t, payment.ErrInsufficientBalance{}, stream.Run(context.Background(), time.Millisecond))
+		assert.EqualValues(t, 2, stream.Paid().Int64())
+	})
+
+	t.Run("err_non_positive_amount", func(t *testing.T) {
+		_, err := payment.NewStream(&fakeChannel{}, big.NewInt(0), nil)
+		assert.Error(t, err)
+	})
+}
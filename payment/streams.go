@@ -0,0 +1,266 @@
+// Copyright (c) 2020 - for information on the respective copyright owner
+// see the NOTICE file and/or the repository at
+// https://github.com/hyperledger-labs/perun-node
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package payment
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"perun.network/go-perun/channel"
+)
+
+// StreamStatus is the status of a stream managed by Streams.
+type StreamStatus string
+
+// Statuses of a stream.
+const (
+	StreamActive    StreamStatus = "active"
+	StreamStopped   StreamStatus = "stopped"
+	StreamCompleted StreamStatus = "completed" // Limit authorized for the stream is reached.
+	StreamFailed    StreamStatus = "failed"
+)
+
+// StreamInfo is a stream of payments over a channel, managed by Streams.
+type StreamInfo struct {
+	ID       string        `json:"id"`
+	Channel  string        `json:"channel"` // Channel ID as hex string.
+	Amount   *big.Int      `json:"amount"`  // Amount paid per interval or unit of usage.
+	Interval time.Duration `json:"interval"`
+	Limit    *big.Int      `json:"limit"` // Nil, if there is no limit.
+	Status   StreamStatus  `json:"status"`
+
+	Paid      *big.Int `json:"paid"`      // Total amount paid.
+	LastError string   `json:"lastError"` // Error in the last payment, if it failed.
+}
+
+// MarshalJSON encodes the stream as JSON, with the interval as a duration string such as "1s". Interval is
+// empty for streams charged per unit of usage.
+func (s StreamInfo) MarshalJSON() ([]byte, error) {
+	type streamInfo StreamInfo
+	interval := ""
+	if s.Interval > 0 {
+		interval = s.Interval.String()
+	}
+	return json.Marshal(struct {
+		streamInfo
+		Interval string `json:"interval"`
+	}{streamInfo(s), interval})
+}
+
+// Streams manages the streams of payments made by the node.
+//
+// A stream either pays the amount at every interval until it is stopped, or pays for the units of usage
+// charged using Charge (when the interval is zero). Payments are limited by the budget, if any. A timed stream
+// is stopped when a payment fails and the failure is reported to the handler. Streams are held in memory and
+// are lost when the node is restarted.
+type Streams struct {
+	lookup    ChannelLookup
+	budget    *Budget
+	onFailure func(StreamInfo, error)
+
+	mutex   sync.Mutex
+	streams []*managedStream
+	wg      sync.WaitGroup
+}
+
+type managedStream struct {
+	info   StreamInfo
+	stream *Stream
+	cancel context.CancelFunc // Stops a timed stream, nil for streams charged per unit of usage.
+}
+
+// NewStreams returns an empty set of streams. Channels for payments are retrieved using lookup, payments are
+// limited by the budget (if not nil) and onFailure (if not nil) is called when a timed stream fails.
+func NewStreams(lookup ChannelLookup, budget *Budget, onFailure func(StreamInfo, error)) *Streams {
+	return &Streams{lookup: lookup, budget: budget, onFailure: onFailure}
+}
+
+// Start starts a stream paying the amount over the channel at every interval or, if the interval is zero, for
+// each unit of usage charged using Charge. The total amount paid is capped by the limit, if it is not nil.
+func (s *Streams) Start(id channel.ID, amount, limit *big.Int, interval time.Duration) (StreamInfo, error) {
+	switch {
+	case amount == nil || amount.Sign() <= 0:
+		return StreamInfo{}, errors.New("amount should be positive")
+	case interval < 0:
+		return StreamInfo{}, errors.New("interval should not be negative")
+	case limit != nil && limit.Sign() <= 0:
+		return StreamInfo{}, errors.New("limit should be positive")
+	}
+	ch, err := s.lookup(id)
+	if err != nil {
+		return StreamInfo{}, err
+	}
+	info := StreamInfo{Channel: hex.EncodeToString(id[:]), Amount: new(big.Int).Set(amount), Interval: interval}
+	if limit != nil {
+		info.Limit = new(big.Int).Set(limit)
+	}
+	stream, err := NewStream(ch, new(big.Int).Set(amount), info.Limit)
+	if err != nil {
+		return StreamInfo{}, err
+	}
+	var streamID [8]byte
+	if _, err = rand.Read(streamID[:]); err != nil {
+		return StreamInfo{}, errors.Wrap(err, "generating stream id")
+	}
+	info.ID, info.Status = hex.EncodeToString(streamID[:]), StreamActive
+	stream.budget, stream.ref = s.budget, info.ID
+	m := &managedStream{info: info, stream: stream}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.streams = append(s.streams, m)
+	if interval > 0 {
+		var ctx context.Context
+		ctx, m.cancel = context.WithCancel(context.Background())
+		s.wg.Add(1)
+		go s.run(ctx, m)
+	}
+	return m.copy(), nil
+}
+
+// run pays the timed stream until it is stopped or a payment fails.
+func (s *Streams) run(ctx context.Context, m *managedStream) {
+	defer s.wg.Done()
+	err := m.stream.Run(ctx, m.info.Interval)
+
+	s.mutex.Lock()
+	switch {
+	case err == nil || ctx.Err() != nil: // Stopped, even if a payment in progress was aborted.
+		m.info.Status = StreamStopped
+	case errors.Is(err, ErrLimitReached):
+		m.info.Status = StreamCompleted
+	default:
+		m.info.Status = StreamFailed
+		m.info.LastError = err.Error()
+	}
+	info := m.copy()
+	s.mutex.Unlock()
+
+	if info.Status == StreamFailed && s.onFailure != nil {
+		s.onFailure(info, err)
+	}
+}
+
+// Charge pays for the units of usage over the active stream, which should have no interval.
+func (s *Streams) Charge(ctx context.Context, id string, units int64) error {
+	if units <= 0 {
+		return errors.New("units should be positive")
+	}
+	m, err := s.chargeable(id)
+	if err != nil {
+		return err
+	}
+	err = m.stream.Charge(ctx, units)
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	m.info.LastError = ""
+	if err != nil {
+		m.info.LastError = err.Error()
+	}
+	return err
+}
+
+// chargeable returns the stream with the ID, if it is active and has no interval.
+func (s *Streams) chargeable(id string) (*managedStream, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	m := s.get(id)
+	switch {
+	case m == nil:
+		return nil, errors.New("unknown stream")
+	case m.cancel != nil:
+		return nil, errors.New("timed streams cannot be charged")
+	case m.info.Status != StreamActive:
+		return nil, errors.Errorf("stream is %s", m.info.Status)
+	}
+	return m, nil
+}
+
+// Stop stops the active stream.
+func (s *Streams) Stop(id string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	m := s.get(id)
+	if m == nil {
+		return errors.New("unknown stream")
+	}
+	if m.info.Status != StreamActive {
+		return errors.Errorf("stream is %s", m.info.Status)
+	}
+	m.info.Status = StreamStopped
+	if m.cancel != nil {
+		m.cancel()
+	}
+	return nil
+}
+
+// List returns all the streams in the order in which they were started.
+func (s *Streams) List() []StreamInfo {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	streams := make([]StreamInfo, len(s.streams))
+	for i := range s.streams {
+		streams[i] = s.streams[i].copy()
+	}
+	return streams
+}
+
+// Run waits until the context is canceled. Then it stops all the active streams and waits for the payments in
+// progress to complete.
+func (s *Streams) Run(ctx context.Context) {
+	<-ctx.Done()
+	s.mutex.Lock()
+	for _, m := range s.streams {
+		if m.info.Status != StreamActive {
+			continue
+		}
+		m.info.Status = StreamStopped
+		if m.cancel != nil {
+			m.cancel()
+		}
+	}
+	s.mutex.Unlock()
+	s.wg.Wait()
+}
+
+// get returns the stream with the ID, it should be called with mutex locked.
+func (s *Streams) get(id string) *managedStream {
+	for _, m := range s.streams {
+		if m.info.ID == id {
+			return m
+		}
+	}
+	return nil
+}
+
+// copy returns a copy of the stream info, it should be called with mutex of the streams locked.
+func (m *managedStream) copy() StreamInfo {
+	c := m.info
+	c.Amount = new(big.Int).Set(m.info.Amount)
+	c.Paid = m.stream.Paid()
+	if m.info.Limit != nil {
+		c.Limit = new(big.Int).Set(m.info.Limit)
+	}
+	return c
+}
@@ -0,0 +1,141 @@
+// Copyright (c) 2020 - for information on the respective copyright owner
+// see the NOTICE file and/or the repository at
+// https://github.com/hyperledger-labs/perun-node
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package payment_test
+
+import (
+	"context"
+	"encoding/hex"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"perun.network/go-perun/channel"
+
+	"github.com/hyperledger-labs/perun-node/payment"
+)
+
+func Test_Streams(t *testing.T) {
+	setup := func(t *testing.T, balance int64, budget *payment.Budget) (*fakeChannel, *payment.Streams,
+		chan error) {
+		ch := &fakeChannel{idx: 0, state: newTestState(t, balance, 0)}
+		lookup := func(id channel.ID) (payment.Channel, error) {
+			if id != ch.state.ID {
+				return nil, assert.AnError
+			}
+			return ch, nil
+		}
+		failures := make(chan error, 1)
+		return ch, payment.NewStreams(lookup, budget, func(_ payment.StreamInfo, err error) { failures <- err }),
+			failures
+	}
+	waitForStatus := func(t *testing.T, streams *payment.Streams, status payment.StreamStatus) payment.StreamInfo {
+		var info payment.StreamInfo
+		require.Eventually(t, func() bool {
+			info = streams.List()[0]
+			return info.Status == status
+		}, time.Second, time.Millisecond)
+		return info
+	}
+
+	t.Run("happy_timed_until_limit", func(t *testing.T) {
+		ch, streams, _ := setup(t, 10, nil)
+		started, err := streams.Start(ch.state.ID, big.NewInt(2), big.NewInt(5), time.Millisecond)
+		require.NoError(t, err)
+		assert.Equal(t, payment.StreamActive, started.Status)
+		assert.Equal(t, hex.EncodeToString(ch.state.ID[:]), started.Channel)
+
+		info := waitForStatus(t, streams, payment.StreamCompleted)
+		assert.EqualValues(t, 4, info.Paid.Int64())
+		assert.EqualValues(t, 4, ch.balance(1))
+	})
+
+	t.Run("happy_stop", func(t *testing.T) {
+		ch, streams, _ := setup(t, 10, nil)
+		started, err := streams.Start(ch.state.ID, big.NewInt(1), nil, time.Hour)
+		require.NoError(t, err)
+		require.NoError(t, streams.Stop(started.ID))
+		waitForStatus(t, streams, payment.StreamStopped)
+		assert.Error(t, streams.Stop(started.ID), "stream is already stopped")
+	})
+
+	t.Run("happy_run_stops_all", func(t *testing.T) {
+		ch, streams, _ := setup(t, 10, nil)
+		_, err := streams.Start(ch.state.ID, big.NewInt(1), nil, time.Hour)
+		require.NoError(t, err)
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		streams.Run(ctx)
+		assert.Equal(t, payment.StreamStopped, streams.List()[0].Status)
+	})
+
+	t.Run("happy_charge", func(t *testing.T) {
+		ch, streams, _ := setup(t, 10, nil)
+		started, err := streams.Start(ch.state.ID, big.NewInt(2), big.NewInt(6), 0)
+		require.NoError(t, err)
+		require.NoError(t, streams.Charge(context.Background(), started.ID, 2))
+		assert.Equal(t, payment.ErrLimitReached, streams.Charge(context.Background(), started.ID, 2))
+		info := streams.List()[0]
+		assert.EqualValues(t, 4, info.Paid.Int64())
+		assert.Equal(t, payment.ErrLimitReached.Error(), info.LastError)
+		assert.EqualValues(t, 4, ch.balance(1))
+
+		require.NoError(t, streams.Stop(started.ID))
+		assert.Error(t, streams.Charge(context.Background(), started.ID, 1), "stream is stopped")
+	})
+
+	t.Run("err_timed_payment_fails", func(t *testing.T) {
+		ch, streams, failures := setup(t, 3, nil)
+		_, err := streams.Start(ch.state.ID, big.NewInt(2), nil, time.Millisecond)
+		require.NoError(t, err)
+		info := waitForStatus(t, streams, payment.StreamFailed)
+		assert.EqualValues(t, 2, info.Paid.Int64())
+		assert.NotEmpty(t, info.LastError)
+		assert.IsType(t, payment.ErrInsufficientBalance{}, <-failures)
+	})
+
+	t.Run("err_budget_exceeded", func(t *testing.T) {
+		budget, err := payment.NewBudget(&fakeClock{now: time.Now()},
+			[]payment.Limit{{Period: payment.Daily, Amount: big.NewInt(3)}}, nil)
+		require.NoError(t, err)
+		ch, streams, _ := setup(t, 10, budget)
+		started, err := streams.Start(ch.state.ID, big.NewInt(2), nil, 0)
+		require.NoError(t, err)
+		require.NoError(t, streams.Charge(context.Background(), started.ID, 1))
+		assert.IsType(t, payment.ErrBudgetExceeded{}, streams.Charge(context.Background(), started.ID, 1))
+		assert.EqualValues(t, 2, ch.balance(1))
+	})
+
+	t.Run("err_invalid", func(t *testing.T) {
+		ch, streams, _ := setup(t, 10, nil)
+		_, err := streams.Start(ch.state.ID, big.NewInt(0), nil, 0)
+		assert.Error(t, err)
+		_, err = streams.Start(ch.state.ID, big.NewInt(1), big.NewInt(0), 0)
+		assert.Error(t, err)
+		_, err = streams.Start(ch.state.ID, big.NewInt(1), nil, -time.Second)
+		assert.Error(t, err)
+		_, err = streams.Start(channel.ID{}, big.NewInt(1), nil, 0)
+		assert.Error(t, err, "unknown channel")
+
+		timed, err := streams.Start(ch.state.ID, big.NewInt(1), nil, time.Hour)
+		require.NoError(t, err)
+		assert.Error(t, streams.Charge(context.Background(), timed.ID, 1), "timed stream")
+		assert.Error(t, streams.Charge(context.Background(), "unknown", 1))
+		require.NoError(t, streams.Stop(timed.ID))
+	})
+}
@@ -0,0 +1,271 @@
+// Copyright (c) 2020 - for information on the respective copyright owner
+// see the NOTICE file and/or the repository at
+// https://github.com/hyperledger-labs/perun-node
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package payment
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"perun.network/go-perun/channel"
+
+	"github.com/hyperledger-labs/perun-node/clock"
+)
+
+// SubscriptionStatus is the status of a subscription.
+type SubscriptionStatus string
+
+// Statuses of a subscription.
+const (
+	SubscriptionActive   SubscriptionStatus = "active"
+	SubscriptionPaused   SubscriptionStatus = "paused"
+	SubscriptionCanceled SubscriptionStatus = "canceled"
+	SubscriptionEnded    SubscriptionStatus = "ended"
+)
+
+// Subscription is a recurring payment of an amount, once every interval, over a channel until the end date.
+type Subscription struct {
+	ID       string             `json:"id"`
+	Channel  string             `json:"channel"` // Channel ID as hex string.
+	Amount   *big.Int           `json:"amount"`
+	Interval time.Duration      `json:"interval"`
+	End      time.Time          `json:"end"` // Zero, if the subscription has no end date.
+	Status   SubscriptionStatus `json:"status"`
+
+	Paid      *big.Int  `json:"paid"`      // Total amount paid.
+	Next      time.Time `json:"next"`      // Time at which the next payment is due.
+	LastError string    `json:"lastError"` // Error in the last payment, if it failed.
+
+	channelID channel.ID
+}
+
+// MarshalJSON encodes the subscription as JSON, with the interval as a duration string such as "24h0m0s".
+func (s Subscription) MarshalJSON() ([]byte, error) {
+	type subscription Subscription
+	return json.Marshal(struct {
+		subscription
+		Interval string `json:"interval"`
+	}{subscription(s), s.Interval.String()})
+}
+
+// ChannelLookup returns the open channel with the given ID.
+type ChannelLookup func(channel.ID) (Channel, error)
+
+// Subscriptions executes the payments for subscriptions when they are due.
+//
+// Payments are made at the start of each interval. If a payment fails (e.g. when the balance in the channel is
+// insufficient), it is retried in the next interval and the failure is reported to the handler. Payments
+// waiting for approval in the budget are retried until approved and reported only once. Subscriptions are held
+// in memory and are lost when the node is restarted.
+type Subscriptions struct {
+	clock     clock.Clock
+	lookup    ChannelLookup
+	budget    *Budget
+	onFailure func(Subscription, error)
+
+	mutex sync.Mutex
+	subs  []*Subscription
+}
+
+// NewSubscriptions returns an empty set of subscriptions. Channels for payments are retrieved using lookup,
+// payments are limited by the budget (if not nil) and onFailure (if not nil) is called when a payment fails.
+func NewSubscriptions(clk clock.Clock, lookup ChannelLookup, budget *Budget,
+	onFailure func(Subscription, error)) *Subscriptions {
+	return &Subscriptions{clock: clk, lookup: lookup, budget: budget, onFailure: onFailure}
+}
+
+// Add adds a subscription for paying the amount every interval over the channel, until the end date.
+// If end is zero, the payments continue until the subscription is canceled. The first payment is due immediately.
+func (s *Subscriptions) Add(ch channel.ID, amount *big.Int, interval time.Duration, end time.Time) (
+	Subscription, error) {
+	now := s.clock.Now()
+	switch {
+	case amount == nil || amount.Sign() <= 0:
+		return Subscription{}, errors.New("amount should be positive")
+	case interval <= 0:
+		return Subscription{}, errors.New("interval should be positive")
+	case !end.IsZero() && !end.After(now):
+		return Subscription{}, errors.New("end date should be in the future")
+	}
+	var id [8]byte
+	if _, err := rand.Read(id[:]); err != nil {
+		return Subscription{}, errors.Wrap(err, "generating subscription id")
+	}
+	sub := &Subscription{
+		ID:        hex.EncodeToString(id[:]),
+		Channel:   hex.EncodeToString(ch[:]),
+		Amount:    new(big.Int).Set(amount),
+		Interval:  interval,
+		End:       end,
+		Status:    SubscriptionActive,
+		Paid:      new(big.Int),
+		Next:      now,
+		channelID: ch,
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.subs = append(s.subs, sub)
+	return sub.copy(), nil
+}
+
+// Pause pauses an active subscription. Payments due while it is paused are skipped.
+func (s *Subscriptions) Pause(id string) error {
+	return s.setStatus(id, SubscriptionActive, SubscriptionPaused)
+}
+
+// Resume resumes a paused subscription. If a payment was due while it was paused, it is made immediately.
+func (s *Subscriptions) Resume(id string) error {
+	return s.setStatus(id, SubscriptionPaused, SubscriptionActive)
+}
+
+// Cancel cancels an active or paused subscription.
+func (s *Subscriptions) Cancel(id string) error {
+	if err := s.setStatus(id, SubscriptionActive, SubscriptionCanceled); err == nil {
+		return nil
+	}
+	return s.setStatus(id, SubscriptionPaused, SubscriptionCanceled)
+}
+
+func (s *Subscriptions) setStatus(id string, from, to SubscriptionStatus) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	sub := s.get(id)
+	if sub == nil {
+		return errors.New("unknown subscription")
+	}
+	if sub.Status != from {
+		return errors.Errorf("subscription is %s", sub.Status)
+	}
+	sub.Status = to
+	if to == SubscriptionActive && sub.Next.Before(s.clock.Now()) {
+		sub.Next = s.clock.Now()
+	}
+	return nil
+}
+
+// List returns all the subscriptions in the order in which they were added.
+func (s *Subscriptions) List() []Subscription {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	subs := make([]Subscription, len(s.subs))
+	for i := range s.subs {
+		subs[i] = s.subs[i].copy()
+	}
+	return subs
+}
+
+// Run pays the subscriptions that are due, checking at every interval until the context is canceled.
+func (s *Subscriptions) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		s.PayDue(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// PayDue makes the payments for all the active subscriptions that are due. Subscriptions past their end date
+// are marked as ended.
+func (s *Subscriptions) PayDue(ctx context.Context) {
+	for _, sub := range s.due() {
+		err := s.pay(ctx, sub)
+		updated := s.paid(sub.ID, err)
+		alreadyReported := errors.As(err, &ErrApprovalRequired{}) && updated.LastError == sub.LastError
+		if err != nil && s.onFailure != nil && !alreadyReported {
+			s.onFailure(updated, err)
+		}
+	}
+}
+
+func (s *Subscriptions) pay(ctx context.Context, sub Subscription) error {
+	ch, err := s.lookup(sub.channelID)
+	if err != nil {
+		return err
+	}
+	return s.budget.Pay(ctx, ch, sub.ID, sub.Amount)
+}
+
+// due returns a copy of the subscriptions for which a payment is due.
+func (s *Subscriptions) due() []Subscription {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	now := s.clock.Now()
+	var due []Subscription
+	for _, sub := range s.subs {
+		if sub.Status != SubscriptionActive {
+			continue
+		}
+		if !sub.End.IsZero() && !now.Before(sub.End) {
+			sub.Status = SubscriptionEnded
+			continue
+		}
+		if !now.Before(sub.Next) {
+			due = append(due, sub.copy())
+		}
+	}
+	return due
+}
+
+// paid records the result of the payment for the subscription and schedules the next payment, unless the
+// payment is waiting for approval. Payments missed in the past intervals are not made up for.
+func (s *Subscriptions) paid(id string, err error) Subscription {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	sub := s.get(id)
+	if errors.As(err, &ErrApprovalRequired{}) {
+		sub.LastError = err.Error()
+		return sub.copy()
+	}
+	if err != nil {
+		sub.LastError = err.Error()
+	} else {
+		sub.LastError = ""
+		sub.Paid.Add(sub.Paid, sub.Amount)
+	}
+	sub.Next = sub.Next.Add(sub.Interval)
+	if now := s.clock.Now(); sub.Next.Before(now) {
+		sub.Next = now.Add(sub.Interval)
+	}
+	return sub.copy()
+}
+
+// get returns the subscription with the ID, it should be called with mutex locked.
+func (s *Subscriptions) get(id string) *Subscription {
+	for _, sub := range s.subs {
+		if sub.ID == id {
+			return sub
+		}
+	}
+	return nil
+}
+
+func (s *Subscription) copy() Subscription {
+	c := *s
+	c.Amount = new(big.Int).Set(s.Amount)
+	c.Paid = new(big.Int).Set(s.Paid)
+	return c
+}
@@ -0,0 +1,160 @@
+// Copyright (c) 2020 - for information on the respective copyright owner
+// see the NOTICE file and/or the repository at
+// https://github.com/hyperledger-labs/perun-node
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package payment_test
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"perun.network/go-perun/channel"
+
+	"github.com/hyperledger-labs/perun-node/payment"
+)
+
+func Test_Subscriptions(t *testing.T) {
+	type failure struct {
+		sub payment.Subscription
+		err error
+	}
+	setup := func(t *testing.T, balance int64) (*fakeClock, *fakeChannel, *payment.Subscriptions, *[]failure) {
+		clk := &fakeClock{now: time.Unix(1600000000, 0)}
+		ch := &fakeChannel{idx: 0, state: newTestState(t, balance, 0)}
+		lookup := func(id channel.ID) (payment.Channel, error) {
+			if id != ch.state.ID {
+				return nil, assert.AnError
+			}
+			return ch, nil
+		}
+		failures := &[]failure{}
+		onFailure := func(sub payment.Subscription, err error) { *failures = append(*failures, failure{sub, err}) }
+		return clk, ch, payment.NewSubscriptions(clk, lookup, nil, onFailure), failures
+	}
+	ctx := context.Background()
+
+	t.Run("happy_recurring_until_end", func(t *testing.T) {
+		clk, ch, subs, failures := setup(t, 10)
+		sub, err := subs.Add(ch.state.ID, big.NewInt(2), time.Hour, clk.now.Add(150*time.Minute))
+		require.NoError(t, err)
+
+		subs.PayDue(ctx)
+		subs.PayDue(ctx)
+		assert.EqualValues(t, 2, ch.balance(1), "second payment is not due yet")
+		clk.now = clk.now.Add(time.Hour)
+		subs.PayDue(ctx)
+		clk.now = clk.now.Add(time.Hour)
+		subs.PayDue(ctx)
+		clk.now = clk.now.Add(time.Hour)
+		subs.PayDue(ctx)
+		assert.EqualValues(t, 6, ch.balance(1))
+
+		list := subs.List()
+		require.Len(t, list, 1)
+		assert.Equal(t, sub.ID, list[0].ID)
+		assert.Equal(t, payment.SubscriptionEnded, list[0].Status)
+		assert.EqualValues(t, 6, list[0].Paid.Int64())
+		assert.Empty(t, *failures)
+	})
+
+	t.Run("happy_pause_resume_cancel", func(t *testing.T) {
+		clk, ch, subs, _ := setup(t, 10)
+		sub, err := subs.Add(ch.state.ID, big.NewInt(1), time.Hour, time.Time{})
+		require.NoError(t, err)
+
+		require.NoError(t, subs.Pause(sub.ID))
+		assert.Error(t, subs.Pause(sub.ID), "already paused")
+		clk.now = clk.now.Add(5 * time.Hour)
+		subs.PayDue(ctx)
+		assert.EqualValues(t, 0, ch.balance(1))
+
+		require.NoError(t, subs.Resume(sub.ID))
+		subs.PayDue(ctx)
+		assert.EqualValues(t, 1, ch.balance(1), "missed payments are not made up for")
+
+		require.NoError(t, subs.Cancel(sub.ID))
+		clk.now = clk.now.Add(time.Hour)
+		subs.PayDue(ctx)
+		assert.EqualValues(t, 1, ch.balance(1))
+		assert.Equal(t, payment.SubscriptionCanceled, subs.List()[0].Status)
+		assert.Error(t, subs.Resume(sub.ID))
+		assert.Error(t, subs.Cancel("unknown"))
+	})
+
+	t.Run("happy_failure_notification", func(t *testing.T) {
+		clk, ch, subs, failures := setup(t, 3)
+		_, err := subs.Add(ch.state.ID, big.NewInt(2), time.Hour, time.Time{})
+		require.NoError(t, err)
+
+		subs.PayDue(ctx)
+		clk.now = clk.now.Add(time.Hour)
+		subs.PayDue(ctx)
+		require.Len(t, *failures, 1)
+		assert.IsType(t, payment.ErrInsufficientBalance{}, (*failures)[0].err)
+		assert.NotEmpty(t, (*failures)[0].sub.LastError)
+		assert.Equal(t, clk.now.Add(time.Hour), (*failures)[0].sub.Next, "should be retried in next interval")
+		assert.Equal(t, payment.SubscriptionActive, subs.List()[0].Status)
+	})
+
+	t.Run("happy_approval_required", func(t *testing.T) {
+		clk, ch, _, failures := setup(t, 10)
+		budget, err := payment.NewBudget(clk, []payment.Limit{
+			{Period: payment.Daily, Amount: big.NewInt(1), RequireApproval: true},
+		}, nil)
+		require.NoError(t, err)
+		lookup := func(channel.ID) (payment.Channel, error) { return ch, nil }
+		onFailure := func(sub payment.Subscription, err error) { *failures = append(*failures, failure{sub, err}) }
+		subs := payment.NewSubscriptions(clk, lookup, budget, onFailure)
+		_, err = subs.Add(ch.state.ID, big.NewInt(2), time.Hour, time.Time{})
+		require.NoError(t, err)
+
+		subs.PayDue(ctx)
+		subs.PayDue(ctx)
+		require.Len(t, *failures, 1, "waiting for approval should be reported once")
+		approvals := budget.Approvals()
+		require.Len(t, approvals, 1)
+		require.NoError(t, budget.Approve(approvals[0].ID))
+		subs.PayDue(ctx)
+		assert.EqualValues(t, 2, ch.balance(1))
+		assert.EqualValues(t, 2, budget.Spent("", payment.Daily).Int64())
+	})
+
+	t.Run("happy_json", func(t *testing.T) {
+		_, ch, subs, _ := setup(t, 3)
+		sub, err := subs.Add(ch.state.ID, big.NewInt(2), 24*time.Hour, time.Time{})
+		require.NoError(t, err)
+		data, err := json.Marshal(sub)
+		require.NoError(t, err)
+		var decoded map[string]interface{}
+		require.NoError(t, json.Unmarshal(data, &decoded))
+		assert.Equal(t, "24h0m0s", decoded["interval"])
+		assert.Equal(t, sub.ID, decoded["id"])
+	})
+
+	t.Run("err_invalid", func(t *testing.T) {
+		clk, ch, subs, _ := setup(t, 3)
+		_, err := subs.Add(ch.state.ID, big.NewInt(0), time.Hour, time.Time{})
+		assert.Error(t, err)
+		_, err = subs.Add(ch.state.ID, big.NewInt(1), 0, time.Time{})
+		assert.Error(t, err)
+		_, err = subs.Add(ch.state.ID, big.NewInt(1), time.Hour, clk.now)
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,102 @@
+// Copyright (c) 2020 - for information on the respective copyright owner
+// see the NOTICE file and/or the repository at
+// https://github.com/hyperledger-labs/perun-node
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package paywall
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/hyperledger-labs/perun-node/admin"
+	"github.com/hyperledger-labs/perun-node/payment"
+)
+
+// AdminIssuer is an Issuer that uses the admin API of a node, for services running in a separate process.
+//
+// As GetInvoice cannot return errors, an invoice that cannot be fetched from the node is reported as not
+// found. So, the requests are answered with new invoices while the node is unreachable.
+type AdminIssuer struct {
+	URL    string       // Base URL of the admin API, such as "http://127.0.0.1:8081".
+	Client *http.Client // If nil, http.DefaultClient is used.
+}
+
+// CreateInvoice implements Issuer.
+func (a AdminIssuer) CreateInvoice(amount *big.Int, expiry time.Duration, memo string) (
+	payment.InvoiceRecord, error) {
+	body, err := json.Marshal(admin.InvoiceRequest{Amount: amount.String(), Expiry: expiry.String(), Memo: memo})
+	if err != nil {
+		return payment.InvoiceRecord{}, errors.Wrap(err, "encoding request")
+	}
+	resp, err := a.client().Post(a.endpoint(), "application/json", bytes.NewReader(body))
+	if err != nil {
+		return payment.InvoiceRecord{}, errors.Wrap(err, "creating invoice")
+	}
+	defer resp.Body.Close() // nolint: errcheck  // response body is only read.
+	if resp.StatusCode != http.StatusCreated {
+		return payment.InvoiceRecord{}, errors.WithMessage(responseError(resp), "creating invoice")
+	}
+	var record payment.InvoiceRecord
+	if err = json.NewDecoder(resp.Body).Decode(&record); err != nil {
+		return payment.InvoiceRecord{}, errors.Wrap(err, "decoding invoice")
+	}
+	return record, nil
+}
+
+// GetInvoice implements Issuer.
+func (a AdminIssuer) GetInvoice(id payment.InvoiceID) (payment.InvoiceRecord, bool) {
+	idText, err := id.MarshalText()
+	if err != nil {
+		return payment.InvoiceRecord{}, false
+	}
+	resp, err := a.client().Get(a.endpoint() + "?invoiceId=" + string(idText))
+	if err != nil {
+		return payment.InvoiceRecord{}, false
+	}
+	defer resp.Body.Close() // nolint: errcheck  // response body is only read.
+	var record payment.InvoiceRecord
+	if resp.StatusCode != http.StatusOK || json.NewDecoder(resp.Body).Decode(&record) != nil {
+		return payment.InvoiceRecord{}, false
+	}
+	return record, true
+}
+
+func (a AdminIssuer) endpoint() string {
+	return strings.TrimSuffix(a.URL, "/") + "/invoices"
+}
+
+func (a AdminIssuer) client() *http.Client {
+	if a.Client == nil {
+		return http.DefaultClient
+	}
+	return a.Client
+}
+
+// responseError returns the error in the body of an error response from the admin API.
+func responseError(resp *http.Response) error {
+	var body struct {
+		Error string `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil || body.Error == "" {
+		return errors.Errorf("unexpected status %s", resp.Status)
+	}
+	return errors.Errorf("%s: %s", resp.Status, body.Error)
+}
@@ -0,0 +1,36 @@
+// Copyright (c) 2020 - for information on the respective copyright owner
+// see the NOTICE file and/or the repository at
+// https://github.com/hyperledger-labs/perun-node
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package paywall implements an HTTP middleware for charging a payment per
+// request, over the payment channels of a perun node.
+//
+// A request without proof of payment is answered with status 402 (Payment
+// Required) and an invoice. The client pays the invoice over a channel with
+// the node and repeats the request with the ID of the invoice in
+// the Authorization header:
+//
+//	Authorization: Perun <invoice ID as hex string>
+//
+// The request is passed on to the wrapped handler once the node reports the
+// invoice as settled. Until then, it is answered with 402 and the same
+// invoice, so that the client can retry.
+//
+// The middleware can use the node directly, when embedded in the same
+// process, or its admin API (see AdminIssuer) otherwise:
+//
+//	pw := paywall.New(paywall.AdminIssuer{URL: "http://127.0.0.1:8081"}, big.NewInt(1000), time.Minute)
+//	http.Handle("/api/", pw.Handler(apiHandler))
+package paywall
@@ -0,0 +1,229 @@
+// Copyright (c) 2020 - for information on the respective copyright owner
+// see the NOTICE file and/or the repository at
+// https://github.com/hyperledger-labs/perun-node
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package paywall
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/hyperledger-labs/perun-node/payment"
+)
+
+const (
+	// Scheme is the authentication scheme used in the WWW-Authenticate and Authorization headers.
+	Scheme = "Perun"
+
+	// MaxPendingPerClient is the maximum number of invoices issued to a client (identified by its IP address, or
+	// its /64 prefix for IPv6) that are neither redeemed nor expired. Further requests from the client are answered
+	// with status 429 (Too Many Requests).
+	MaxPendingPerClient = 5
+
+	// MaxPending is the maximum number of invoices issued by a paywall that are neither redeemed nor expired, so
+	// that it takes only a share of the open invoices of the node (see payment.MaxOpenInvoices). Further requests
+	// are answered with status 503 (Service Unavailable).
+	MaxPending = 200
+
+	// clientPrefixLen is the length of the prefix identifying clients with IPv6 addresses, as a client usually
+	// gets a whole /64 network.
+	clientPrefixLen = 64
+)
+
+var (
+	errTooManyPending = errors.New("too many unpaid invoices")
+	errPaywallFull    = errors.New("too many unpaid invoices for the paywall, retry later")
+)
+
+// Issuer generates invoices for receiving payments and reports their status.
+//
+// It is implemented by the node and by AdminIssuer.
+type Issuer interface {
+	CreateInvoice(amount *big.Int, expiry time.Duration, memo string) (payment.InvoiceRecord, error)
+	GetInvoice(id payment.InvoiceID) (payment.InvoiceRecord, bool)
+}
+
+// Challenge is the body of the responses with status 402 (Payment Required).
+type Challenge struct {
+	Invoice   string            `json:"invoice"` // Encoded invoice, to be paid by the client.
+	InvoiceID payment.InvoiceID `json:"invoiceId"`
+	Amount    *big.Int          `json:"amount"`
+	Expiry    time.Time         `json:"expiry"`
+}
+
+// Paywall is an HTTP middleware that requires a payment for each request to the wrapped handlers.
+//
+// Each invoice unlocks a single request, for the method and path it was issued for. Invoices issued by other
+// paywalls or for other purposes are not accepted.
+type Paywall struct {
+	issuer Issuer
+	price  *big.Int
+	expiry time.Duration
+
+	mutex   sync.Mutex
+	pending map[payment.InvoiceID]pending // Invoices issued and not yet redeemed.
+}
+
+type pending struct {
+	client string
+	expiry time.Time
+}
+
+// New returns a paywall that charges the price (in the smallest unit of the asset configured for the node)
+// per request. Invoices issued for the requests expire after the expiry duration.
+//
+// The amount of an invoice can be slightly higher than the price, as the issuer keeps the amounts of open
+// invoices unique (see payment.Invoices.Create).
+func New(issuer Issuer, price *big.Int, expiry time.Duration) *Paywall {
+	return &Paywall{
+		issuer:  issuer,
+		price:   new(big.Int).Set(price),
+		expiry:  expiry,
+		pending: make(map[payment.InvoiceID]pending),
+	}
+}
+
+// Handler wraps the handler, so that it is called only for requests that have been paid for.
+func (p *Paywall) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paid, record, err := p.authorize(r, r.Method+" "+r.URL.Path)
+		switch {
+		case err == errTooManyPending:
+			writeJSON(w, http.StatusTooManyRequests, struct {
+				Error string `json:"error"`
+			}{err.Error()})
+		case err != nil:
+			writeJSON(w, http.StatusServiceUnavailable, struct {
+				Error string `json:"error"`
+			}{err.Error()})
+		case paid:
+			next.ServeHTTP(w, r)
+		default:
+			w.Header().Set("WWW-Authenticate", fmt.Sprintf("%s invoice=%q", Scheme, record.Encoded))
+			writeJSON(w, http.StatusPaymentRequired, Challenge{
+				Invoice:   record.Encoded,
+				InvoiceID: record.ID,
+				Amount:    record.Amount,
+				Expiry:    record.Expiry,
+			})
+		}
+	})
+}
+
+// authorize reports if the request carries the invoice ID of a settled invoice issued for the resource and
+// redeems it. Otherwise, it returns the invoice to be paid: the one in the request, if it is still open, or a
+// new one, if the client has less than MaxPendingPerClient pending invoices.
+func (p *Paywall) authorize(r *http.Request, resource string) (bool, payment.InvoiceRecord, error) {
+	if id, ok := invoiceID(r); ok && p.isPending(id) {
+		record, found := p.issuer.GetInvoice(id)
+		if found && record.Memo == resource {
+			if record.Status == payment.InvoiceSettled && p.redeem(id) {
+				return true, record, nil
+			}
+			if record.Status == payment.InvoiceOpen {
+				return false, record, nil
+			}
+		}
+	}
+	client := clientID(r)
+	total, ofClient := p.countPending(client)
+	if ofClient >= MaxPendingPerClient {
+		return false, payment.InvoiceRecord{}, errTooManyPending
+	}
+	if total >= MaxPending {
+		return false, payment.InvoiceRecord{}, errPaywallFull
+	}
+	record, err := p.issuer.CreateInvoice(p.price, p.expiry, resource)
+	if err != nil {
+		return false, payment.InvoiceRecord{}, err
+	}
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.pending[record.ID] = pending{client: client, expiry: record.Expiry}
+	return false, record, nil
+}
+
+// countPending removes the expired invoices and returns the number of pending invoices, in total and issued to
+// the client.
+func (p *Paywall) countPending(client string) (total, ofClient int) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	now := time.Now()
+	for id, inv := range p.pending {
+		if now.After(inv.expiry) {
+			delete(p.pending, id)
+			continue
+		}
+		total++
+		if inv.client == client {
+			ofClient++
+		}
+	}
+	return total, ofClient
+}
+
+func (p *Paywall) isPending(id payment.InvoiceID) bool {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	_, ok := p.pending[id]
+	return ok
+}
+
+// redeem marks the invoice as used and reports if it was pending until now.
+func (p *Paywall) redeem(id payment.InvoiceID) bool {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	_, ok := p.pending[id]
+	delete(p.pending, id)
+	return ok
+}
+
+// clientID returns the IP address of the client that sent the request or, for IPv6, its /64 prefix.
+func clientID(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || ip.To4() != nil {
+		return host
+	}
+	return ip.Mask(net.CIDRMask(clientPrefixLen, 8*net.IPv6len)).String() + fmt.Sprintf("/%d", clientPrefixLen)
+}
+
+// invoiceID returns the invoice ID in the Authorization header of the request, if any.
+func invoiceID(r *http.Request) (payment.InvoiceID, bool) {
+	var id payment.InvoiceID
+	fields := strings.Fields(r.Header.Get("Authorization"))
+	if len(fields) != 2 || !strings.EqualFold(fields[0], Scheme) {
+		return id, false
+	}
+	return id, id.UnmarshalText([]byte(fields[1])) == nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v) // nolint: errcheck, gosec  // nothing to do if writing response fails.
+}
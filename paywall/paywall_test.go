@@ -0,0 +1,204 @@
+// Copyright (c) 2020 - for information on the respective copyright owner
+// see the NOTICE file and/or the repository at
+// https://github.com/hyperledger-labs/perun-node
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package paywall_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger-labs/perun-node/admin"
+	"github.com/hyperledger-labs/perun-node/clock"
+	"github.com/hyperledger-labs/perun-node/payment"
+	"github.com/hyperledger-labs/perun-node/paywall"
+)
+
+// issuer implements paywall.Issuer and admin.InvoiceIssuer, like the node.
+type issuer struct {
+	*payment.Invoices
+}
+
+func (i issuer) CreateInvoice(amount *big.Int, expiry time.Duration, memo string) (payment.InvoiceRecord, error) {
+	return i.Create(amount, "asset", expiry, memo)
+}
+
+func (i issuer) CreateFiatInvoice(fiat payment.FiatAmount, expiry time.Duration, memo string) (
+	payment.InvoiceRecord, error) {
+	return i.CreateFiat(fiat, "asset", expiry, memo)
+}
+
+func (i issuer) GetInvoice(id payment.InvoiceID) (payment.InvoiceRecord, bool) { return i.Get(id) }
+
+func (i issuer) ListInvoices() []payment.InvoiceRecord { return i.List() }
+
+func Test_Paywall(t *testing.T) {
+	resource := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte("resource"))
+	})
+	setup := func(t *testing.T) (*payment.Invoices, http.Handler) {
+		clk, err := clock.New("UTC")
+		require.NoError(t, err)
+		invoices := payment.NewInvoices(clk, nil)
+		return invoices, paywall.New(issuer{invoices}, big.NewInt(10), time.Minute).Handler(resource)
+	}
+	request := func(handler http.Handler, path, authorization string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if authorization != "" {
+			req.Header.Set("Authorization", authorization)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+	challenge := func(t *testing.T, rec *httptest.ResponseRecorder) (paywall.Challenge, string) {
+		require.Equal(t, http.StatusPaymentRequired, rec.Code)
+		var c paywall.Challenge
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &c))
+		assert.Equal(t, `Perun invoice="`+c.Invoice+`"`, rec.Header().Get("WWW-Authenticate"))
+		id, err := c.InvoiceID.MarshalText()
+		require.NoError(t, err)
+		return c, "Perun " + string(id)
+	}
+	pay := func(t *testing.T, invoices *payment.Invoices) {
+		_, ok := invoices.Settle(context.Background(), payment.Received{Asset: "asset", Amount: big.NewInt(10)})
+		require.True(t, ok)
+	}
+
+	t.Run("happy", func(t *testing.T) {
+		invoices, handler := setup(t)
+		c, auth := challenge(t, request(handler, "/a", ""))
+		assert.EqualValues(t, 10, c.Amount.Int64())
+
+		unpaid, _ := challenge(t, request(handler, "/a", auth))
+		assert.Equal(t, c.Invoice, unpaid.Invoice, "open invoice should be returned again")
+
+		pay(t, invoices)
+		rec := request(handler, "/a", auth)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "resource", rec.Body.String())
+
+		again, _ := challenge(t, request(handler, "/a", auth))
+		assert.NotEqual(t, c.Invoice, again.Invoice, "invoice should unlock only one request")
+	})
+
+	t.Run("happy_payment_bound_to_invoice", func(t *testing.T) {
+		invoices, handler := setup(t)
+		first, firstAuth := challenge(t, request(handler, "/a", ""))
+		second, secondAuth := challenge(t, request(handler, "/a", ""))
+		require.NotEqual(t, first.Amount, second.Amount)
+
+		_, ok := invoices.Settle(context.Background(), payment.Received{Asset: "asset", Amount: second.Amount})
+		require.True(t, ok)
+		challenge(t, request(handler, "/a", firstAuth))
+		assert.Equal(t, http.StatusOK, request(handler, "/a", secondAuth).Code)
+	})
+
+	t.Run("err_too_many_pending", func(t *testing.T) {
+		_, handler := setup(t)
+		for i := 0; i < paywall.MaxPendingPerClient; i++ {
+			challenge(t, request(handler, "/a", ""))
+		}
+		assert.Equal(t, http.StatusTooManyRequests, request(handler, "/a", "").Code)
+	})
+
+	requestFrom := func(handler http.Handler, remoteAddr string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/a", nil)
+		req.RemoteAddr = remoteAddr
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Run("err_too_many_pending_ipv6_prefix", func(t *testing.T) {
+		_, handler := setup(t)
+		for i := 0; i < paywall.MaxPendingPerClient; i++ {
+			challenge(t, requestFrom(handler, fmt.Sprintf("[2001:db8:0:1::%x]:1234", i+1)))
+		}
+		assert.Equal(t, http.StatusTooManyRequests, requestFrom(handler, "[2001:db8:0:1:ffff::1]:1234").Code,
+			"addresses in the same /64 should count as one client")
+		challenge(t, requestFrom(handler, "[2001:db8:0:2::1]:1234"))
+	})
+
+	t.Run("err_paywall_full", func(t *testing.T) {
+		_, handler := setup(t)
+		for i := 0; i < paywall.MaxPending; i++ {
+			challenge(t, requestFrom(handler, fmt.Sprintf("10.0.%d.%d:1234", i/250, i%250+1)))
+		}
+		assert.Equal(t, http.StatusServiceUnavailable, requestFrom(handler, "10.1.0.1:1234").Code)
+	})
+
+	t.Run("err_other_resource", func(t *testing.T) {
+		invoices, handler := setup(t)
+		c, auth := challenge(t, request(handler, "/a", ""))
+		pay(t, invoices)
+		other, _ := challenge(t, request(handler, "/b", auth))
+		assert.NotEqual(t, c.Invoice, other.Invoice)
+	})
+
+	t.Run("err_invoice_not_issued_by_paywall", func(t *testing.T) {
+		invoices, handler := setup(t)
+		record, err := invoices.Create(big.NewInt(10), "asset", time.Minute, "GET /a")
+		require.NoError(t, err)
+		pay(t, invoices)
+		id, err := record.ID.MarshalText()
+		require.NoError(t, err)
+		challenge(t, request(handler, "/a", "Perun "+string(id)))
+	})
+
+	t.Run("err_invalid_authorization", func(t *testing.T) {
+		_, handler := setup(t)
+		challenge(t, request(handler, "/a", "Perun xyz"))
+		challenge(t, request(handler, "/a", "Bearer token"))
+	})
+}
+
+func Test_AdminIssuer(t *testing.T) {
+	clk, err := clock.New("UTC")
+	require.NoError(t, err)
+	server := httptest.NewServer(admin.InvoicesHandler(issuer{payment.NewInvoices(clk, nil)}))
+	defer server.Close()
+	adminIssuer := paywall.AdminIssuer{URL: server.URL + "/"}
+
+	t.Run("happy", func(t *testing.T) {
+		record, err := adminIssuer.CreateInvoice(big.NewInt(10), time.Minute, "memo")
+		require.NoError(t, err)
+		assert.EqualValues(t, 10, record.Amount.Int64())
+
+		got, ok := adminIssuer.GetInvoice(record.ID)
+		require.True(t, ok)
+		assert.Equal(t, record.Encoded, got.Encoded)
+		assert.Equal(t, payment.InvoiceOpen, got.Status)
+	})
+
+	t.Run("err_create", func(t *testing.T) {
+		_, err := adminIssuer.CreateInvoice(big.NewInt(10), 0, "memo")
+		assert.Error(t, err)
+	})
+
+	t.Run("err_get_unknown", func(t *testing.T) {
+		_, ok := adminIssuer.GetInvoice(payment.InvoiceID{})
+		assert.False(t, ok)
+	})
+}
@@ -0,0 +1,29 @@
+// Copyright (c) 2020 - for information on the respective copyright owner
+// see the NOTICE file and/or the repository at
+// https://github.com/hyperledger-labs/perun-node
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package persistence manages the versioning of the schema used for storing
+// the channel states in the persistence database.
+//
+// The schema version is stored in the database under a key that does not
+// collide with the keys used by the go-perun persister. Each change to the
+// format of the stored data is implemented as a forward migration that
+// upgrades the database by one version. Pending migrations are run in order
+// when the node is started, after a copy of the database directory is made
+// as backup.
+//
+// Databases created before the schema was versioned do not have the version
+// key and are treated as version 1.
+package persistence
@@ -0,0 +1,174 @@
+// Copyright (c) 2020 - for information on the respective copyright owner
+// see the NOTICE file and/or the repository at
+// https://github.com/hyperledger-labs/perun-node
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package persistence
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+	"perun.network/go-perun/pkg/sortedkv"
+	"perun.network/go-perun/pkg/sortedkv/leveldb"
+)
+
+// SchemaVersionKey is the key under which the schema version is stored in the database.
+const SchemaVersionKey = "perun-node:schema-version"
+
+// Migration upgrades the database from the previous schema version to Version.
+type Migration struct {
+	Version     int
+	Description string
+	Migrate     func(db sortedkv.Database) error
+}
+
+// Migrations is the list of migrations for the schema of the persistence database, in the order of versions.
+// Version 1 is the schema used by go-perun v0.4.0 and hence no migrations are defined yet.
+var Migrations = []Migration{}
+
+// LatestVersion returns the schema version after applying all the migrations.
+func LatestVersion(migrations []Migration) int {
+	if len(migrations) == 0 {
+		return 1
+	}
+	return migrations[len(migrations)-1].Version
+}
+
+// Migrate upgrades the database at dbPath to the latest schema version by running the pending migrations.
+//
+// Before running the migrations, a copy of the database directory is made at
+// "<dbPath>.backup-v<version>-<timestamp>". A new database is initialized with the latest version.
+// If the database has a newer version than the latest known version, an error is returned, as the
+// data might not be read correctly.
+//
+// The database should not be in use when migrations are run.
+func Migrate(dbPath string, migrations []Migration) error {
+	if err := validateMigrations(migrations); err != nil {
+		return err
+	}
+	latest := LatestVersion(migrations)
+	if _, err := os.Stat(dbPath); os.IsNotExist(err) {
+		return withDatabase(dbPath, func(db sortedkv.Database) error {
+			return setVersion(db, latest)
+		})
+	}
+
+	var version int
+	err := withDatabase(dbPath, func(db sortedkv.Database) (err error) {
+		version, err = Version(db)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if version > latest {
+		return errors.Errorf("database schema version %d is newer than the latest supported version %d",
+			version, latest)
+	}
+	if version == latest {
+		return nil
+	}
+
+	backupDir := fmt.Sprintf("%s.backup-v%d-%s", filepath.Clean(dbPath), version, time.Now().Format("20060102150405"))
+	if err = copyDir(dbPath, backupDir); err != nil {
+		return errors.WithMessage(err, "backing up database before migration")
+	}
+	return withDatabase(dbPath, func(db sortedkv.Database) error {
+		for _, m := range migrations[version-1:] {
+			if err := m.Migrate(db); err != nil {
+				return errors.WithMessagef(err, "migrating database to version %d (%s), backup at %s",
+					m.Version, m.Description, backupDir)
+			}
+			if err := setVersion(db, m.Version); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Version returns the schema version of the database. It returns 1 if the version is not set.
+func Version(db sortedkv.Reader) (int, error) {
+	has, err := db.Has(SchemaVersionKey)
+	if err != nil {
+		return 0, errors.Wrap(err, "reading schema version")
+	}
+	if !has {
+		return 1, nil
+	}
+	value, err := db.Get(SchemaVersionKey)
+	if err != nil {
+		return 0, errors.Wrap(err, "reading schema version")
+	}
+	version, err := strconv.Atoi(value)
+	return version, errors.Wrap(err, "parsing schema version")
+}
+
+// withDatabase opens the database, calls the function and closes the database.
+func withDatabase(dbPath string, f func(sortedkv.Database) error) error {
+	db, err := leveldb.LoadDatabase(dbPath)
+	if err != nil {
+		return errors.Wrap(err, "opening persistence database in dir - "+dbPath)
+	}
+	err = f(db)
+	if closeErr := db.Close(); closeErr != nil && err == nil {
+		err = errors.Wrap(closeErr, "closing persistence database")
+	}
+	return err
+}
+
+func setVersion(db sortedkv.Writer, version int) error {
+	return errors.Wrap(db.Put(SchemaVersionKey, strconv.Itoa(version)), "writing schema version")
+}
+
+// validateMigrations checks if the versions of the migrations are consecutive, starting from 2.
+func validateMigrations(migrations []Migration) error {
+	for i, m := range migrations {
+		if m.Version != i+2 {
+			return errors.Errorf("migration %d has version %d, expected %d", i, m.Version, i+2)
+		}
+	}
+	return nil
+}
+
+// copyDir copies the regular files in the src directory to the dst directory. Sub directories are not copied,
+// as the leveldb database directory does not have any.
+func copyDir(src, dst string) error {
+	files, err := ioutil.ReadDir(src)
+	if err != nil {
+		return errors.Wrap(err, "reading dir")
+	}
+	if err = os.Mkdir(dst, 0o700); err != nil {
+		return errors.Wrap(err, "creating dir")
+	}
+	for _, f := range files {
+		if !f.Mode().IsRegular() {
+			continue
+		}
+		data, readErr := ioutil.ReadFile(filepath.Join(src, f.Name()))
+		if readErr != nil {
+			return errors.Wrap(readErr, "reading file")
+		}
+		if err = ioutil.WriteFile(filepath.Join(dst, f.Name()), data, 0o600); err != nil {
+			return errors.Wrap(err, "writing file")
+		}
+	}
+	return nil
+}
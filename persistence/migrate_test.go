@@ -0,0 +1,186 @@
+// Copyright (c) 2020 - for information on the respective copyright owner
+// see the NOTICE file and/or the repository at
+// https://github.com/hyperledger-labs/perun-node
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package persistence_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"perun.network/go-perun/pkg/sortedkv"
+	"perun.network/go-perun/pkg/sortedkv/leveldb"
+
+	"github.com/hyperledger-labs/perun-node/persistence"
+)
+
+var testMigrations = []persistence.Migration{
+	{Version: 2, Description: "rename key", Migrate: func(db sortedkv.Database) error {
+		value, err := db.Get("old-key")
+		if err != nil {
+			return err
+		}
+		if err = db.Put("new-key", value); err != nil {
+			return err
+		}
+		return db.Delete("old-key")
+	}},
+	{Version: 3, Description: "add key", Migrate: func(db sortedkv.Database) error {
+		return db.Put("added-key", "added-value")
+	}},
+}
+
+func Test_Migrate(t *testing.T) {
+	t.Run("happy_new_db", func(t *testing.T) {
+		dbPath := filepath.Join(tempDir(t), "db")
+		require.NoError(t, persistence.Migrate(dbPath, testMigrations))
+		assertVersion(t, dbPath, 3)
+		assertNoBackup(t, dbPath)
+	})
+
+	t.Run("happy_unversioned_db", func(t *testing.T) {
+		dbPath := newDB(t, map[string]string{"old-key": "value"})
+		require.NoError(t, persistence.Migrate(dbPath, testMigrations))
+
+		db := openDB(t, dbPath)
+		assertValue(t, db, "new-key", "value")
+		assertValue(t, db, "added-key", "added-value")
+		has, err := db.Has("old-key")
+		require.NoError(t, err)
+		assert.False(t, has)
+		assertVersionOf(t, db, 3)
+
+		backupDir := findBackup(t, dbPath)
+		require.NotEmpty(t, backupDir)
+		assert.Contains(t, backupDir, ".backup-v1-")
+		backupDB := openDB(t, backupDir)
+		assertValue(t, backupDB, "old-key", "value")
+	})
+
+	t.Run("happy_partially_migrated_db", func(t *testing.T) {
+		dbPath := newDB(t, map[string]string{persistence.SchemaVersionKey: "2", "new-key": "value"})
+		require.NoError(t, persistence.Migrate(dbPath, testMigrations))
+
+		db := openDB(t, dbPath)
+		assertValue(t, db, "new-key", "value")
+		assertValue(t, db, "added-key", "added-value")
+		assertVersionOf(t, db, 3)
+	})
+
+	t.Run("happy_latest_version", func(t *testing.T) {
+		dbPath := newDB(t, map[string]string{persistence.SchemaVersionKey: "3"})
+		require.NoError(t, persistence.Migrate(dbPath, testMigrations))
+		assertVersion(t, dbPath, 3)
+		assertNoBackup(t, dbPath)
+	})
+
+	t.Run("happy_no_migrations", func(t *testing.T) {
+		dbPath := newDB(t, map[string]string{"some-key": "value"})
+		require.NoError(t, persistence.Migrate(dbPath, persistence.Migrations))
+		assertVersion(t, dbPath, 1)
+		assertNoBackup(t, dbPath)
+	})
+
+	t.Run("err_newer_version", func(t *testing.T) {
+		dbPath := newDB(t, map[string]string{persistence.SchemaVersionKey: "4"})
+		assert.Error(t, persistence.Migrate(dbPath, testMigrations))
+	})
+
+	t.Run("err_migration_failed", func(t *testing.T) {
+		failing := []persistence.Migration{{Version: 2, Migrate: func(sortedkv.Database) error {
+			return errors.New("migration error")
+		}}}
+		dbPath := newDB(t, map[string]string{"some-key": "value"})
+		assert.Error(t, persistence.Migrate(dbPath, failing))
+		assertVersion(t, dbPath, 1)
+		assert.NotEmpty(t, findBackup(t, dbPath))
+	})
+
+	t.Run("err_invalid_migration_versions", func(t *testing.T) {
+		invalid := []persistence.Migration{{Version: 3}}
+		assert.Error(t, persistence.Migrate(filepath.Join(tempDir(t), "db"), invalid))
+	})
+}
+
+func newDB(t *testing.T, entries map[string]string) string {
+	dbPath := filepath.Join(tempDir(t), "db")
+	db, err := leveldb.LoadDatabase(dbPath)
+	require.NoError(t, err)
+	for k, v := range entries {
+		require.NoError(t, db.Put(k, v))
+	}
+	require.NoError(t, db.Close())
+	return dbPath
+}
+
+func openDB(t *testing.T, dbPath string) sortedkv.Database {
+	db, err := leveldb.LoadDatabase(dbPath)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		if err = db.Close(); err != nil {
+			t.Log("Error in test cleanup: closing db - " + dbPath)
+		}
+	})
+	return db
+}
+
+func assertValue(t *testing.T, db sortedkv.Database, key, want string) {
+	got, err := db.Get(key)
+	require.NoError(t, err)
+	assert.Equal(t, want, got)
+}
+
+func assertVersion(t *testing.T, dbPath string, want int) {
+	assertVersionOf(t, openDB(t, dbPath), want)
+}
+
+func assertVersionOf(t *testing.T, db sortedkv.Database, want int) {
+	got, err := persistence.Version(db)
+	require.NoError(t, err)
+	assert.Equal(t, want, got)
+}
+
+func assertNoBackup(t *testing.T, dbPath string) {
+	assert.Empty(t, findBackup(t, dbPath))
+}
+
+// findBackup returns the path of the backup of the database, if found.
+func findBackup(t *testing.T, dbPath string) string {
+	files, err := ioutil.ReadDir(filepath.Dir(dbPath))
+	require.NoError(t, err)
+	for _, f := range files {
+		if strings.HasPrefix(f.Name(), filepath.Base(dbPath)+".backup-") {
+			return filepath.Join(filepath.Dir(dbPath), f.Name())
+		}
+	}
+	return ""
+}
+
+func tempDir(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "")
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		if err = os.RemoveAll(dir); err != nil {
+			t.Log("Error in test cleanup: removing dir - " + dir)
+		}
+	})
+	return dir
+}
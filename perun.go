@@ -91,6 +91,9 @@ type User struct {
 	// List of participant addresses for this user in each open channel.
 	// OffChain credential is used for managing all these accounts.
 	PartAddrs []wallet.Address
+
+	// Addresses on which the node listens for incoming off-chain connections. If empty, CommAddr is used.
+	ListenAddrs []string
 }
 
 // Session provides a context for the user to interact with a node. It manages user data (such as IDs, contacts),
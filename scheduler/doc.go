@@ -0,0 +1,27 @@
+// Copyright (c) 2020 - for information on the respective copyright owner
+// see the NOTICE file and/or the repository at
+// https://github.com/hyperledger-labs/perun-node
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package scheduler implements a cron-like scheduler for running periodic
+// maintenance jobs of the node.
+//
+// Schedules are specified using the standard five field cron expression
+// (minute, hour, day of month, month and day of week), with support for
+// lists, ranges and steps (e.g. "*/15 0-6,22,23 * * 1-5"). Predefined
+// schedules "@hourly", "@daily" (or "@midnight"), "@weekly", "@monthly"
+// and fixed intervals "@every <duration>" (e.g. "@every 10m") are also
+// supported. Times are evaluated in the timezone of the clock used by the
+// scheduler.
+package scheduler
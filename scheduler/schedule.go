@@ -0,0 +1,192 @@
+// Copyright (c) 2020 - for information on the respective copyright owner
+// see the NOTICE file and/or the repository at
+// https://github.com/hyperledger-labs/perun-node
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scheduler
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// maxSearchYears is the number of years searched for the next activation of a cron schedule.
+// If there is none within this period (e.g. for "0 0 30 2 *"), the schedule never activates.
+const maxSearchYears = 5
+
+// Schedule returns the time of next activation after the given time. Zero time is returned if
+// the schedule never activates.
+type Schedule interface {
+	Next(after time.Time) time.Time
+}
+
+type (
+	// cronSchedule is a schedule defined by a cron expression. Each field is a bitset of the allowed values.
+	cronSchedule struct {
+		minute, hour, dom, month, dow uint64
+		domRestricted, dowRestricted  bool
+	}
+
+	// intervalSchedule activates at a fixed interval.
+	intervalSchedule time.Duration
+
+	// field describes the allowed range of values for a field in a cron expression.
+	field struct {
+		name     string
+		min, max int
+	}
+)
+
+var (
+	minuteField = field{name: "minute", min: 0, max: 59}
+	hourField   = field{name: "hour", min: 0, max: 23}
+	domField    = field{name: "day of month", min: 1, max: 31}
+	monthField  = field{name: "month", min: 1, max: 12}
+	dowField    = field{name: "day of week", min: 0, max: 7} // Both 0 and 7 are sunday.
+
+	descriptors = map[string]string{
+		"@hourly":   "0 * * * *",
+		"@daily":    "0 0 * * *",
+		"@midnight": "0 0 * * *",
+		"@weekly":   "0 0 * * 0",
+		"@monthly":  "0 0 1 * *",
+	}
+)
+
+// Parse parses the schedule from a cron expression, predefined schedule or interval.
+// See package documentation for the supported syntax.
+func Parse(spec string) (Schedule, error) {
+	spec = strings.TrimSpace(spec)
+	if strings.HasPrefix(spec, "@every ") {
+		interval, err := time.ParseDuration(strings.TrimSpace(strings.TrimPrefix(spec, "@every ")))
+		if err != nil {
+			return nil, errors.Wrap(err, "parsing interval")
+		}
+		if interval < time.Second {
+			return nil, errors.New("interval should be at least one second")
+		}
+		return intervalSchedule(interval), nil
+	}
+	if expr, ok := descriptors[spec]; ok {
+		spec = expr
+	}
+
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return nil, errors.Errorf("cron expression should have 5 fields, got %d", len(fields))
+	}
+	s := cronSchedule{}
+	var err error
+	for i, f := range []struct {
+		field
+		bits *uint64
+	}{{minuteField, &s.minute}, {hourField, &s.hour}, {domField, &s.dom}, {monthField, &s.month}, {dowField, &s.dow}} {
+		if *f.bits, err = parseField(fields[i], f.field); err != nil {
+			return nil, err
+		}
+	}
+	if s.dow&(1<<7) != 0 {
+		s.dow |= 1 // Sunday can be specified as 7.
+	}
+	s.domRestricted, s.dowRestricted = fields[2] != "*", fields[4] != "*"
+	return s, nil
+}
+
+// parseField parses a comma separated list of values, ranges and steps into a bitset.
+func parseField(expr string, f field) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(expr, ",") {
+		rangeExpr, step := part, 1
+		if i := strings.Index(part, "/"); i != -1 {
+			var err error
+			if step, err = strconv.Atoi(part[i+1:]); err != nil || step < 1 {
+				return 0, errors.Errorf("invalid step in %s field: %q", f.name, part)
+			}
+			rangeExpr = part[:i]
+		}
+		low, high, err := parseRange(rangeExpr, f)
+		if err != nil {
+			return 0, err
+		}
+		for v := low; v <= high; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+	return bits, nil
+}
+
+// parseRange parses "*", a single value or a range of values "a-b".
+func parseRange(expr string, f field) (low, high int, _ error) {
+	if expr == "*" {
+		return f.min, f.max, nil
+	}
+	bounds := strings.SplitN(expr, "-", 2)
+	values := make([]int, len(bounds))
+	for i, b := range bounds {
+		v, err := strconv.Atoi(b)
+		if err != nil || v < f.min || v > f.max {
+			return 0, 0, errors.Errorf("invalid value in %s field: %q, should be in range %d-%d",
+				f.name, expr, f.min, f.max)
+		}
+		values[i] = v
+	}
+	low, high = values[0], values[len(values)-1]
+	if low > high {
+		return 0, 0, errors.Errorf("invalid range in %s field: %q", f.name, expr)
+	}
+	return low, high, nil
+}
+
+// Next returns the first time (with zero seconds) after the given time, that matches the schedule.
+func (s cronSchedule) Next(after time.Time) time.Time {
+	t := after.Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(maxSearchYears, 0, 0)
+	for t.Before(limit) {
+		switch {
+		case !has(s.month, int(t.Month())):
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+		case !s.dayMatches(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+		case !has(s.hour, t.Hour()):
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+		case !has(s.minute, t.Minute()):
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// dayMatches checks if the day matches the schedule. As in cron, if both day of month and day of week
+// are restricted, the day matches if either of them matches.
+func (s cronSchedule) dayMatches(t time.Time) bool {
+	domMatches, dowMatches := has(s.dom, t.Day()), has(s.dow, int(t.Weekday()))
+	if s.domRestricted && s.dowRestricted {
+		return domMatches || dowMatches
+	}
+	return domMatches && dowMatches
+}
+
+func has(bits uint64, v int) bool {
+	return bits&(1<<uint(v)) != 0
+}
+
+// Next returns the time after the given time by one interval.
+func (s intervalSchedule) Next(after time.Time) time.Time {
+	return after.Add(time.Duration(s))
+}
@@ -0,0 +1,76 @@
+// Copyright (c) 2020 - for information on the respective copyright owner
+// see the NOTICE file and/or the repository at
+// https://github.com/hyperledger-labs/perun-node
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scheduler_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger-labs/perun-node/scheduler"
+)
+
+func Test_Parse(t *testing.T) {
+	// Wednesday.
+	after := time.Date(2020, time.July, 15, 10, 30, 45, 0, time.UTC)
+
+	tests := []struct {
+		spec string
+		want time.Time
+	}{
+		{"* * * * *", time.Date(2020, time.July, 15, 10, 31, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2020, time.July, 15, 10, 45, 0, 0, time.UTC)},
+		{"0 0 * * *", time.Date(2020, time.July, 16, 0, 0, 0, 0, time.UTC)},
+		{"30 2,22 * * *", time.Date(2020, time.July, 15, 22, 30, 0, 0, time.UTC)},
+		{"0 9-17/4 * * *", time.Date(2020, time.July, 15, 13, 0, 0, 0, time.UTC)},
+		{"0 0 1 1 *", time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 0 * * 7", time.Date(2020, time.July, 19, 0, 0, 0, 0, time.UTC)},
+		{"0 0 1 * 5", time.Date(2020, time.July, 17, 0, 0, 0, 0, time.UTC)},
+		{"0 0 29 2 *", time.Date(2024, time.February, 29, 0, 0, 0, 0, time.UTC)},
+		{"0 0 30 2 *", time.Time{}},
+		{"@daily", time.Date(2020, time.July, 16, 0, 0, 0, 0, time.UTC)},
+		{"@monthly", time.Date(2020, time.August, 1, 0, 0, 0, 0, time.UTC)},
+		{"@every 10m", after.Add(10 * time.Minute)},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run("happy_"+tt.spec, func(t *testing.T) {
+			s, err := scheduler.Parse(tt.spec)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, s.Next(after))
+		})
+	}
+
+	t.Run("happy_timezone", func(t *testing.T) {
+		location := time.FixedZone("IST", 5*3600+1800)
+		s, err := scheduler.Parse("0 * * * *")
+		require.NoError(t, err)
+		assert.Equal(t, time.Date(2020, time.July, 15, 11, 0, 0, 0, location),
+			s.Next(time.Date(2020, time.July, 15, 10, 30, 0, 0, location)))
+	})
+
+	for _, spec := range []string{"", "* * * *", "60 * * * *", "* 24 * * *", "* * 0 * *", "5-1 * * * *",
+		"*/0 * * * *", "a * * * *", "@yearly", "@every 1ms", "@every x"} {
+		spec := spec
+		t.Run("err_"+spec, func(t *testing.T) {
+			_, err := scheduler.Parse(spec)
+			assert.Error(t, err)
+		})
+	}
+}
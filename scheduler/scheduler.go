@@ -0,0 +1,120 @@
+// Copyright (c) 2020 - for information on the respective copyright owner
+// see the NOTICE file and/or the repository at
+// https://github.com/hyperledger-labs/perun-node
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scheduler
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/hyperledger-labs/perun-node/clock"
+	"github.com/hyperledger-labs/perun-node/log"
+)
+
+type (
+	// Job is a task run by the scheduler. The context is canceled when the scheduler is stopped.
+	Job func(ctx context.Context) error
+
+	// Scheduler runs the jobs added to it as per their schedules.
+	Scheduler struct {
+		log.Logger
+
+		clock clock.Clock
+		jobs  map[string]scheduledJob
+	}
+
+	scheduledJob struct {
+		schedule Schedule
+		run      Job
+	}
+)
+
+// New returns a scheduler that uses the clock for evaluating the schedules.
+func New(clk clock.Clock) *Scheduler {
+	return &Scheduler{
+		Logger: log.NewLoggerWithField("component", "scheduler"),
+		clock:  clk,
+		jobs:   make(map[string]scheduledJob),
+	}
+}
+
+// Add adds the job with the given name, to be run as per the schedule spec. Jobs should be added
+// before the scheduler is started.
+func (s *Scheduler) Add(name, spec string, job Job) error {
+	if _, ok := s.jobs[name]; ok {
+		return errors.Errorf("job %s is already added", name)
+	}
+	schedule, err := Parse(spec)
+	if err != nil {
+		return errors.WithMessagef(err, "parsing schedule for job %s", name)
+	}
+	s.jobs[name] = scheduledJob{schedule: schedule, run: job}
+	return nil
+}
+
+// Jobs returns the names of the jobs added to the scheduler in sorted order.
+func (s *Scheduler) Jobs() []string {
+	names := make([]string, 0, len(s.jobs))
+	for name := range s.jobs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Run runs the jobs as per their schedules, until the context is canceled. Each job runs at most once at
+// a time: if a run takes longer than the interval, the activations during the run are skipped.
+// Errors returned by the jobs are logged.
+func (s *Scheduler) Run(ctx context.Context) {
+	wg := sync.WaitGroup{}
+	for name, job := range s.jobs {
+		wg.Add(1)
+		go func(name string, job scheduledJob) {
+			defer wg.Done()
+			s.runJob(ctx, name, job)
+		}(name, job)
+	}
+	wg.Wait()
+}
+
+func (s *Scheduler) runJob(ctx context.Context, name string, job scheduledJob) {
+	for {
+		now := s.clock.Now()
+		next := job.schedule.Next(now)
+		if next.IsZero() {
+			s.Warnf("Job %s will never run, as its schedule has no activation", name)
+			return
+		}
+		timer := time.NewTimer(next.Sub(now))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		start := time.Now()
+		if err := job.run(ctx); err != nil {
+			s.Errorf("Running job %s: %v", name, err)
+			continue
+		}
+		s.Debugf("Completed job %s in %v", name, time.Since(start))
+	}
+}
@@ -0,0 +1,77 @@
+// Copyright (c) 2020 - for information on the respective copyright owner
+// see the NOTICE file and/or the repository at
+// https://github.com/hyperledger-labs/perun-node
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scheduler_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger-labs/perun-node/clock"
+	"github.com/hyperledger-labs/perun-node/scheduler"
+)
+
+func Test_Scheduler(t *testing.T) {
+	clk, err := clock.New("UTC")
+	require.NoError(t, err)
+
+	t.Run("happy", func(t *testing.T) {
+		s := scheduler.New(clk)
+		runs := make(chan struct{}, 10)
+		require.NoError(t, s.Add("job", "@every 1s", func(context.Context) error {
+			runs <- struct{}{}
+			return nil
+		}))
+		require.NoError(t, s.Add("failing-job", "@every 1s", func(context.Context) error {
+			return errors.New("job failed")
+		}))
+		assert.Equal(t, []string{"failing-job", "job"}, s.Jobs())
+
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan struct{})
+		go func() {
+			s.Run(ctx)
+			close(done)
+		}()
+		select {
+		case <-runs:
+		case <-time.After(3 * time.Second):
+			t.Fatal("job was not run")
+		}
+		cancel()
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Fatal("scheduler did not stop")
+		}
+	})
+
+	t.Run("err_duplicate_job", func(t *testing.T) {
+		s := scheduler.New(clk)
+		require.NoError(t, s.Add("job", "@hourly", func(context.Context) error { return nil }))
+		assert.Error(t, s.Add("job", "@daily", func(context.Context) error { return nil }))
+	})
+
+	t.Run("err_invalid_schedule", func(t *testing.T) {
+		s := scheduler.New(clk)
+		assert.Error(t, s.Add("job", "invalid", func(context.Context) error { return nil }))
+	})
+}
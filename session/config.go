@@ -18,22 +18,25 @@ package session
 
 // WalletConfig defines the parameters required to configure a wallet.
 type WalletConfig struct {
-	KeystorePath string
-	Password     string
+	KeystorePath string `yaml:"keystorepath"`
+	Password     string `yaml:"password"`
 }
 
 // UserConfig defines the parameters required to configure a user.
 // Address strings should be parsed using the wallet backend.
 type UserConfig struct {
-	Alias string
+	Alias string `yaml:"alias"`
 
-	OnChainAddr   string
-	OnChainWallet WalletConfig
+	OnChainAddr   string       `yaml:"onchainaddr"`
+	OnChainWallet WalletConfig `yaml:"onchainwallet"`
 
-	PartAddrs      []string
-	OffChainAddr   string
-	OffChainWallet WalletConfig
+	PartAddrs      []string     `yaml:"partaddrs"`
+	OffChainAddr   string       `yaml:"offchainaddr"`
+	OffChainWallet WalletConfig `yaml:"offchainwallet"`
 
-	CommAddr string
-	CommType string
+	CommAddr string `yaml:"commaddr"`
+	CommType string `yaml:"commtype"`
+	// Addresses to listen on for incoming off-chain connections, for example both an IPv4 and an IPv6
+	// address. Defaults to CommAddr.
+	ListenAddrs []string `yaml:"listenaddrs"`
 }
@@ -26,13 +26,29 @@ import (
 // NewUnlockedUser initializes a user and unlocks all the accounts,
 // those corresponding to on-chain address, off-chain address and all participant addresses.
 func NewUnlockedUser(wb perun.WalletBackend, cfg UserConfig) (perun.User, error) {
+	offChainWallet, err := wb.NewWallet(cfg.OffChainWallet.KeystorePath, cfg.OffChainWallet.Password)
+	if err != nil {
+		return perun.User{}, errors.WithMessage(err, "off-chain wallet")
+	}
+	return NewUnlockedUserWithOffChainWallet(wb, cfg, offChainWallet)
+}
+
+// NewUnlockedUserWithOffChainWallet is same as NewUnlockedUser, except that the given wallet is used as the
+// off-chain wallet instead of the one in the config. It is used when the off-chain keys are held by
+// a separate signer process.
+func NewUnlockedUserWithOffChainWallet(wb perun.WalletBackend, cfg UserConfig, offChainWallet wallet.Wallet) (
+	perun.User, error) {
 	var err error
 	u := perun.User{}
 
-	if u.OnChain.Wallet, err = newWallet(wb, cfg.OnChainWallet, cfg.OnChainAddr); err != nil {
+	if u.OnChain.Wallet, err = wb.NewWallet(cfg.OnChainWallet.KeystorePath, cfg.OnChainWallet.Password); err != nil {
+		return perun.User{}, errors.WithMessage(err, "on-chain wallet")
+	}
+	if u.OnChain.Addr, err = parseUnlockOne(wb, u.OnChain.Wallet, cfg.OnChainAddr); err != nil {
 		return perun.User{}, errors.WithMessage(err, "on-chain wallet")
 	}
-	if u.OffChain.Wallet, err = newWallet(wb, cfg.OffChainWallet, cfg.OffChainAddr); err != nil {
+	u.OffChain.Wallet = offChainWallet
+	if u.OffChain.Addr, err = parseUnlockOne(wb, u.OffChain.Wallet, cfg.OffChainAddr); err != nil {
 		return perun.User{}, errors.WithMessage(err, "off-chain wallet")
 	}
 	if u.PartAddrs, err = parseUnlock(wb, u.OffChain.Wallet, cfg.PartAddrs...); err != nil {
@@ -41,19 +57,18 @@ func NewUnlockedUser(wb perun.WalletBackend, cfg UserConfig) (perun.User, error)
 	u.OffChainAddr = u.OffChain.Addr
 	u.CommAddr = cfg.CommAddr
 	u.CommType = cfg.CommType
+	u.ListenAddrs = cfg.ListenAddrs
 
 	return u, nil
 }
 
-// newWallet initializes the wallet using the wallet backend and unlocks accounts corresponding
-// to the given address.
-func newWallet(wb perun.WalletBackend, cfg WalletConfig, addr string) (wallet.Wallet, error) {
-	w, err := wb.NewWallet(cfg.KeystorePath, cfg.Password)
+// parseUnlockOne parses the given address string using the wallet backend and unlocks the corresponding account.
+func parseUnlockOne(wb perun.WalletBackend, w wallet.Wallet, addr string) (wallet.Address, error) {
+	addrs, err := parseUnlock(wb, w, addr)
 	if err != nil {
 		return nil, err
 	}
-	_, err = parseUnlock(wb, w, addr)
-	return w, err
+	return addrs[0], nil
 }
 
 // parseUnlock parses the given addresses string using the wallet backend and unlocks accounts
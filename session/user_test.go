@@ -58,6 +58,26 @@ func Test_New_Happy(t *testing.T) {
 	require.Len(t, gotUser.PartAddrs, int(cntParts))
 }
 
+func Test_NewUnlockedUserWithOffChainWallet(t *testing.T) {
+	rng := rand.New(rand.NewSource(1729))
+	wb, testUser := sessiontest.NewTestUser(t, rng, 0)
+	userCfg := session.UserConfig{
+		Alias:       testUser.Alias,
+		OnChainAddr: testUser.OnChain.Addr.String(),
+		OnChainWallet: session.WalletConfig{
+			KeystorePath: testUser.OnChain.Keystore,
+			Password:     "",
+		},
+		OffChainAddr: testUser.OffChain.Addr.String(),
+	}
+
+	gotUser, err := session.NewUnlockedUserWithOffChainWallet(wb, userCfg, testUser.OffChain.Wallet)
+	require.NoError(t, err)
+	assert.Equal(t, testUser.OffChain.Wallet, gotUser.OffChain.Wallet)
+	assert.True(t, gotUser.OnChain.Addr.Equals(testUser.OnChain.Addr))
+	assert.True(t, gotUser.OffChain.Addr.Equals(testUser.OffChain.Addr))
+}
+
 func Test_New_Invalid_Parts(t *testing.T) {
 	rng := rand.New(rand.NewSource(1729))
 	cntParts := uint(1)
@@ -0,0 +1,126 @@
+// Copyright (c) 2020 - for information on the respective copyright owner
+// see the NOTICE file and/or the repository at
+// https://github.com/hyperledger-labs/perun-node
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signer
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// TokenSize is the size of the token (in bytes) for authenticating the connections to the signer.
+	TokenSize = 32
+
+	challengeSize = 32
+	authOK        = byte(1)
+	authFailed    = byte(0)
+)
+
+// ErrAuthFailed is returned when the authentication of a connection to the signer fails.
+var ErrAuthFailed = errors.New("authentication with signer failed")
+
+// GenerateTokenFile generates a random token and writes it hex encoded to the file,
+// which is accessible only to the owner. It returns an error if the file already exists.
+func GenerateTokenFile(file string) error {
+	token := make([]byte, TokenSize)
+	if _, err := rand.Read(token); err != nil {
+		return errors.Wrap(err, "generating token")
+	}
+	f, err := os.OpenFile(filepath.Clean(file), os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return errors.Wrap(err, "creating token file")
+	}
+	if _, err = f.WriteString(hex.EncodeToString(token) + "\n"); err != nil {
+		f.Close() // nolint: errcheck, gosec  // error in writing is returned.
+		return errors.Wrap(err, "writing token file")
+	}
+	return errors.Wrap(f.Close(), "closing token file")
+}
+
+// ReadTokenFile reads the hex encoded token from the file.
+func ReadTokenFile(file string) ([]byte, error) {
+	data, err := ioutil.ReadFile(filepath.Clean(file))
+	if err != nil {
+		return nil, errors.Wrap(err, "reading token file")
+	}
+	token, err := hex.DecodeString(strings.TrimSpace(string(data)))
+	if err != nil {
+		return nil, errors.Wrap(err, "decoding token")
+	}
+	if len(token) < TokenSize {
+		return nil, errors.Errorf("token should be at least %d bytes, got %d", TokenSize, len(token))
+	}
+	return token, nil
+}
+
+// authenticate sends a random challenge on the connection and verifies the response to it.
+// It is used by the signer.
+func authenticate(conn io.ReadWriter, token []byte) error {
+	challenge := make([]byte, challengeSize)
+	if _, err := rand.Read(challenge); err != nil {
+		return errors.Wrap(err, "generating challenge")
+	}
+	if _, err := conn.Write(challenge); err != nil {
+		return errors.Wrap(err, "sending challenge")
+	}
+	response := make([]byte, sha256.Size)
+	if _, err := io.ReadFull(conn, response); err != nil {
+		return errors.Wrap(err, "reading response")
+	}
+
+	if !hmac.Equal(response, mac(token, challenge)) {
+		_, _ = conn.Write([]byte{authFailed})
+		return ErrAuthFailed
+	}
+	_, err := conn.Write([]byte{authOK})
+	return errors.Wrap(err, "sending result")
+}
+
+// respond reads the challenge on the connection and sends the response to it. It is used by the node.
+func respond(conn io.ReadWriter, token []byte) error {
+	challenge := make([]byte, challengeSize)
+	if _, err := io.ReadFull(conn, challenge); err != nil {
+		return errors.Wrap(err, "reading challenge")
+	}
+	if _, err := conn.Write(mac(token, challenge)); err != nil {
+		return errors.Wrap(err, "sending response")
+	}
+	result := make([]byte, 1)
+	if _, err := io.ReadFull(conn, result); err != nil {
+		return errors.Wrap(err, "reading result")
+	}
+	if !bytes.Equal(result, []byte{authOK}) {
+		return ErrAuthFailed
+	}
+	return nil
+}
+
+func mac(token, challenge []byte) []byte {
+	h := hmac.New(sha256.New, token)
+	h.Write(challenge) // nolint: errcheck, gosec  // hash.Write never returns an error.
+	return h.Sum(nil)
+}
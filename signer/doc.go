@@ -0,0 +1,30 @@
+// Copyright (c) 2020 - for information on the respective copyright owner
+// see the NOTICE file and/or the repository at
+// https://github.com/hyperledger-labs/perun-node
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package signer implements a signer that holds the off-chain keys of the
+// node in a separate process. The signer listens only on a unix socket
+// (accessible only to the owner) and signs data for the node using the
+// accounts unlocked in it. This ensures that a compromise of the networking
+// components of the node cannot directly exfiltrate the off-chain keys.
+//
+// Connections to the signer are authenticated using a token shared between
+// the node and the signer: the signer sends a random challenge and the node
+// should respond with its HMAC-SHA256 computed using the token as key. Only
+// then are the signing requests served on the connection.
+//
+// On the node, the signer is used via Wallet, which implements the wallet
+// interface of go-perun and can be used as the off-chain wallet of a user.
+package signer
@@ -0,0 +1,33 @@
+// Copyright (c) 2020 - for information on the respective copyright owner
+// see the NOTICE file and/or the repository at
+// https://github.com/hyperledger-labs/perun-node
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +build linux darwin
+
+package signer
+
+import (
+	"net"
+	"syscall"
+)
+
+// listenUnix listens on the unix socket at the path. The socket is created with permissions only for the
+// owner, so that no other user can connect before the permissions are changed. As umask is process wide,
+// files created by other goroutines at the same time also get no permissions for group and others.
+func listenUnix(socketPath string) (net.Listener, error) {
+	oldMask := syscall.Umask(0o077)
+	defer syscall.Umask(oldMask)
+	return net.Listen("unix", socketPath)
+}
@@ -0,0 +1,154 @@
+// Copyright (c) 2020 - for information on the respective copyright owner
+// see the NOTICE file and/or the repository at
+// https://github.com/hyperledger-labs/perun-node
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signer
+
+import (
+	"net"
+	"net/rpc"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"perun.network/go-perun/wallet"
+
+	"github.com/hyperledger-labs/perun-node/log"
+)
+
+// authTimeout is the time within which a connection should be authenticated.
+const authTimeout = 5 * time.Second
+
+type (
+	// Server serves signing requests using the accounts unlocked in it.
+	Server struct {
+		log.Logger
+
+		token []byte
+		rpc   *rpc.Server
+
+		mtx      sync.Mutex
+		listener net.Listener
+	}
+
+	// Signer is the service exposed over rpc. Its methods should not be called directly.
+	Signer struct {
+		accounts map[string]wallet.Account
+	}
+
+	// SignArgs are the arguments for signing data.
+	SignArgs struct {
+		Addr string
+		Data []byte
+	}
+)
+
+// NewServer returns a server that signs data using the given accounts and authenticates the
+// connections using the token.
+func NewServer(token []byte, accs ...wallet.Account) (*Server, error) {
+	signer := &Signer{accounts: make(map[string]wallet.Account, len(accs))}
+	for _, acc := range accs {
+		signer.accounts[acc.Address().String()] = acc
+	}
+	rpcServer := rpc.NewServer()
+	if err := rpcServer.Register(signer); err != nil {
+		return nil, errors.Wrap(err, "registering rpc service")
+	}
+	return &Server{
+		Logger: log.NewLoggerWithField("component", "signer"),
+		token:  token,
+		rpc:    rpcServer,
+	}, nil
+}
+
+// Listen listens on the unix socket at the given path, which is accessible only to the owner.
+// Stale socket file at the path, if any, is removed.
+func Listen(socketPath string) (net.Listener, error) {
+	if err := os.Remove(socketPath); err != nil && !os.IsNotExist(err) {
+		return nil, errors.Wrap(err, "removing stale socket")
+	}
+	l, err := listenUnix(socketPath)
+	if err != nil {
+		return nil, errors.Wrap(err, "listening on socket")
+	}
+	if err = os.Chmod(socketPath, 0o600); err != nil {
+		l.Close() // nolint: errcheck, gosec  // error in changing permissions is returned.
+		return nil, errors.Wrap(err, "setting permissions of socket")
+	}
+	return l, nil
+}
+
+// Serve accepts connections on the listener and serves the signing requests on the authenticated
+// connections. It returns when the listener is closed.
+func (s *Server) Serve(l net.Listener) error {
+	s.mtx.Lock()
+	s.listener = l
+	s.mtx.Unlock()
+
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			return errors.Wrap(err, "accepting connection")
+		}
+		go s.serveConn(conn)
+	}
+}
+
+// Close stops accepting new connections.
+func (s *Server) Close() error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	if s.listener == nil {
+		return nil
+	}
+	return errors.Wrap(s.listener.Close(), "closing listener")
+}
+
+func (s *Server) serveConn(conn net.Conn) {
+	if err := conn.SetDeadline(time.Now().Add(authTimeout)); err != nil {
+		s.Error("Setting deadline: ", err)
+		conn.Close() // nolint: errcheck, gosec  // connection is not used.
+		return
+	}
+	if err := authenticate(conn, s.token); err != nil {
+		s.Error("Authenticating connection: ", err)
+		conn.Close() // nolint: errcheck, gosec  // connection is not used.
+		return
+	}
+	if err := conn.SetDeadline(time.Time{}); err != nil {
+		s.Error("Clearing deadline: ", err)
+		conn.Close() // nolint: errcheck, gosec  // connection is not used.
+		return
+	}
+	s.rpc.ServeConn(conn)
+}
+
+// HasAccount sets ok to true if the account for the address is unlocked in the signer.
+func (s *Signer) HasAccount(addr string, ok *bool) error {
+	_, *ok = s.accounts[addr]
+	return nil
+}
+
+// Sign signs the data using the account for the address.
+func (s *Signer) Sign(args SignArgs, sig *[]byte) error {
+	acc, ok := s.accounts[args.Addr]
+	if !ok {
+		return errors.Errorf("no account for address %s", args.Addr)
+	}
+	var err error
+	*sig, err = acc.SignData(args.Data)
+	return err
+}
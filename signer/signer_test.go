@@ -0,0 +1,141 @@
+// Copyright (c) 2020 - for information on the respective copyright owner
+// see the NOTICE file and/or the repository at
+// https://github.com/hyperledger-labs/perun-node
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signer_test
+
+import (
+	"io/ioutil"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	simwallet "perun.network/go-perun/backend/sim/wallet"
+
+	"github.com/hyperledger-labs/perun-node/signer"
+)
+
+func Test_TokenFile(t *testing.T) {
+	t.Run("happy", func(t *testing.T) {
+		tokenFile := filepath.Join(tempDir(t), "signer.token")
+		require.NoError(t, signer.GenerateTokenFile(tokenFile))
+		token, err := signer.ReadTokenFile(tokenFile)
+		require.NoError(t, err)
+		assert.Len(t, token, signer.TokenSize)
+
+		info, err := os.Stat(tokenFile)
+		require.NoError(t, err)
+		assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+	})
+
+	t.Run("err_file_exists", func(t *testing.T) {
+		tokenFile := filepath.Join(tempDir(t), "signer.token")
+		require.NoError(t, signer.GenerateTokenFile(tokenFile))
+		assert.Error(t, signer.GenerateTokenFile(tokenFile))
+	})
+
+	t.Run("err_short_token", func(t *testing.T) {
+		tokenFile := filepath.Join(tempDir(t), "signer.token")
+		require.NoError(t, ioutil.WriteFile(tokenFile, []byte("abcd\n"), 0o600))
+		_, err := signer.ReadTokenFile(tokenFile)
+		assert.Error(t, err)
+	})
+}
+
+func Test_Listen(t *testing.T) {
+	socketPath := filepath.Join(tempDir(t), "signer.sock")
+	require.NoError(t, ioutil.WriteFile(socketPath, nil, 0o644)) // Stale socket.
+
+	l, err := signer.Listen(socketPath)
+	require.NoError(t, err)
+	defer l.Close() // nolint: errcheck  // test cleanup.
+	info, err := os.Stat(socketPath)
+	require.NoError(t, err)
+	assert.Equal(t, os.ModeSocket, info.Mode()&os.ModeType)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+}
+
+func Test_Signer(t *testing.T) {
+	rng := rand.New(rand.NewSource(1729))
+	acc := simwallet.NewRandomAccount(rng)
+	token := make([]byte, signer.TokenSize)
+	rng.Read(token)
+
+	socketPath := filepath.Join(tempDir(t), "signer.sock")
+	l, err := signer.Listen(socketPath)
+	require.NoError(t, err)
+	s, err := signer.NewServer(token, acc)
+	require.NoError(t, err)
+	go s.Serve(l) // nolint: errcheck  // returns an error when the server is closed.
+	t.Cleanup(func() { assert.NoError(t, s.Close()) })
+
+	t.Run("happy", func(t *testing.T) {
+		w, err := signer.Dial(socketPath, token)
+		require.NoError(t, err)
+		defer w.Close() // nolint: errcheck  // test cleanup.
+
+		remoteAcc, err := w.Unlock(acc.Address())
+		require.NoError(t, err)
+		assert.True(t, remoteAcc.Address().Equals(acc.Address()))
+
+		data := []byte("data to be signed")
+		sig, err := remoteAcc.SignData(data)
+		require.NoError(t, err)
+		valid, err := new(simwallet.Backend).VerifySignature(data, sig, acc.Address())
+		require.NoError(t, err)
+		assert.True(t, valid)
+	})
+
+	t.Run("happy_reconnect", func(t *testing.T) {
+		w, err := signer.Dial(socketPath, token)
+		require.NoError(t, err)
+		remoteAcc, err := w.Unlock(acc.Address())
+		require.NoError(t, err)
+
+		require.NoError(t, w.Close())
+		_, err = remoteAcc.SignData([]byte("data to be signed"))
+		assert.NoError(t, err)
+	})
+
+	t.Run("err_unknown_account", func(t *testing.T) {
+		w, err := signer.Dial(socketPath, token)
+		require.NoError(t, err)
+		defer w.Close() // nolint: errcheck  // test cleanup.
+
+		_, err = w.Unlock(simwallet.NewRandomAddress(rng))
+		assert.Error(t, err)
+	})
+
+	t.Run("err_invalid_token", func(t *testing.T) {
+		invalidToken := make([]byte, signer.TokenSize)
+		_, err := signer.Dial(socketPath, invalidToken)
+		assert.True(t, errors.Is(err, signer.ErrAuthFailed))
+	})
+}
+
+func tempDir(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "")
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		if err = os.RemoveAll(dir); err != nil {
+			t.Log("Error in test cleanup: removing dir - " + dir)
+		}
+	})
+	return dir
+}
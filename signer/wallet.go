@@ -0,0 +1,162 @@
+// Copyright (c) 2020 - for information on the respective copyright owner
+// see the NOTICE file and/or the repository at
+// https://github.com/hyperledger-labs/perun-node
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signer
+
+import (
+	"io"
+	"net"
+	"net/rpc"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"perun.network/go-perun/wallet"
+)
+
+// dialTimeout is the timeout for connecting to the signer.
+const dialTimeout = 5 * time.Second
+
+type (
+	// Wallet is a wallet whose accounts are held by the signer. Accounts can only be unlocked
+	// in the signer, so locking and usage tracking are no-ops.
+	//
+	// The connection to the signer is re-established, if it is closed (e.g. when the signer restarts).
+	Wallet struct {
+		socketPath string
+		token      []byte
+
+		mtx    sync.Mutex
+		client *rpc.Client
+	}
+
+	account struct {
+		addr   wallet.Address
+		wallet *Wallet
+	}
+)
+
+// Dial connects to the signer at the socket path and authenticates using the token.
+func Dial(socketPath string, token []byte) (*Wallet, error) {
+	w := &Wallet{socketPath: socketPath, token: token}
+	if _, err := w.rpcClient(); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// Unlock returns the account for the address, if it is unlocked in the signer.
+func (w *Wallet) Unlock(addr wallet.Address) (wallet.Account, error) {
+	var ok bool
+	if err := w.call("Signer.HasAccount", addr.String(), &ok); err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, errors.Errorf("account for address %s is not unlocked in signer", addr)
+	}
+	return &account{addr: addr, wallet: w}, nil
+}
+
+// LockAll is a no-op, as accounts can only be locked in the signer.
+func (w *Wallet) LockAll() {}
+
+// IncrementUsage is a no-op, as accounts can only be locked in the signer.
+func (w *Wallet) IncrementUsage(wallet.Address) {}
+
+// DecrementUsage is a no-op, as accounts can only be locked in the signer.
+func (w *Wallet) DecrementUsage(wallet.Address) {}
+
+// Close closes the connection to the signer.
+func (w *Wallet) Close() error {
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+	if w.client == nil {
+		return nil
+	}
+	err := w.client.Close()
+	w.client = nil
+	return errors.Wrap(err, "closing connection to signer")
+}
+
+// Address returns the address of the account.
+func (a *account) Address() wallet.Address {
+	return a.addr
+}
+
+// SignData signs the data using the signer.
+func (a *account) SignData(data []byte) ([]byte, error) {
+	var sig []byte
+	err := a.wallet.call("Signer.Sign", SignArgs{Addr: a.addr.String(), Data: data}, &sig)
+	return sig, err
+}
+
+// call calls the method on the signer. If the connection was closed, it is re-established and the
+// call is retried once.
+func (w *Wallet) call(method string, args, reply interface{}) error {
+	client, err := w.rpcClient()
+	if err != nil {
+		return err
+	}
+	err = client.Call(method, args, reply)
+	if !isConnClosed(err) {
+		return errors.Wrap(err, "calling signer")
+	}
+
+	w.resetClient(client)
+	if client, err = w.rpcClient(); err != nil {
+		return err
+	}
+	return errors.Wrap(client.Call(method, args, reply), "calling signer")
+}
+
+// rpcClient returns the client for the current connection, establishing one if required.
+func (w *Wallet) rpcClient() (*rpc.Client, error) {
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+	if w.client != nil {
+		return w.client, nil
+	}
+
+	conn, err := net.DialTimeout("unix", w.socketPath, dialTimeout)
+	if err != nil {
+		return nil, errors.Wrap(err, "connecting to signer")
+	}
+	if err = conn.SetDeadline(time.Now().Add(authTimeout)); err == nil {
+		if err = respond(conn, w.token); err == nil {
+			err = conn.SetDeadline(time.Time{})
+		}
+	}
+	if err != nil {
+		conn.Close() // nolint: errcheck, gosec  // connection is not used.
+		return nil, errors.WithMessage(err, "authenticating with signer")
+	}
+	w.client = rpc.NewClient(conn)
+	return w.client, nil
+}
+
+// resetClient closes the given client and resets it, if it is still the current one.
+func (w *Wallet) resetClient(client *rpc.Client) {
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+	if w.client == client {
+		w.client.Close() // nolint: errcheck, gosec  // connection is already broken.
+		w.client = nil
+	}
+}
+
+func isConnClosed(err error) bool {
+	return errors.Is(err, rpc.ErrShutdown) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF)
+}